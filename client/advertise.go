@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+)
+
+// featureAdvertiser advertises the feature again, when the twin reports it missing, e.g. after the twin was reset
+// by the backend, or when a feature update could not be sent. Re-advertisements are at least interval apart,
+// failed ones are retried after the interval.
+type featureAdvertiser struct {
+	interval  time.Duration
+	advertise func() error
+
+	last    time.Time
+	timer   *time.Timer
+	stopped bool
+	mutex   sync.Mutex
+}
+
+func newFeatureAdvertiser(interval time.Duration, advertise func() error) *featureAdvertiser {
+	return &featureAdvertiser{interval: interval, advertise: advertise}
+}
+
+// trigger schedules re-advertisement of the feature, unless one is already scheduled. Does nothing on nil advertiser.
+func (a *featureAdvertiser) trigger() {
+	if a == nil {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.stopped || a.timer != nil {
+		return
+	}
+
+	delay := time.Until(a.last.Add(a.interval))
+	if delay < 0 {
+		delay = 0
+	}
+
+	a.timer = time.AfterFunc(delay, a.run)
+}
+
+func (a *featureAdvertiser) run() {
+	a.mutex.Lock()
+	if a.stopped {
+		a.mutex.Unlock()
+		return
+	}
+	a.timer = nil
+	a.last = time.Now()
+	a.mutex.Unlock()
+
+	if err := a.advertise(); err != nil {
+		logger.Errorf("failed to re-advertise the feature, retrying in %v: %v", a.interval, err)
+		a.trigger()
+	} else {
+		logger.Info("feature re-advertised")
+	}
+}
+
+// stop cancels the scheduled re-advertisement and prevents further ones. Does nothing on nil advertiser.
+func (a *featureAdvertiser) stop() {
+	if a == nil {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.stopped = true
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+}
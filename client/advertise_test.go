@@ -0,0 +1,159 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+)
+
+const testReadvertiseInterval = 100 * time.Millisecond
+
+func newReadvertisingFileUpload(t *testing.T) (*FileUpload, *mockedClient) {
+	f, client := newConnectedFileUpload(t, filepath.Join(t.TempDir(), "*.txt"), ModeStrict)
+	f.uploadable.advertiser = newFeatureAdvertiser(testReadvertiseInterval, f.uploadable.readvertise)
+
+	return f, client
+}
+
+// twinEnvelope returns the next message, sent to the twin channel
+func twinEnvelope(t *testing.T, client *mockedClient) *protocol.Envelope {
+	t.Helper()
+
+	select {
+	case env := <-client.twin:
+		return env
+	case <-time.After(5 * time.Second):
+		t.Fatal("twin message not sent")
+	}
+	return nil
+}
+
+func assertAdvertised(t *testing.T, env *protocol.Envelope) {
+	t.Helper()
+
+	assertEquals(t, "/features/"+featureID, env.Path)
+	assertEquals(t, featureID+twinCorrelationInfix+featureCorrelationSuffix, env.Headers.CorrelationID())
+	assertEquals(t, true, env.Headers.IsResponseRequired())
+
+	props := env.Value.(map[string]interface{})["properties"].(map[string]interface{})
+	assertEquals(t, testCfg.Type, props["type"])
+	assertEquals(t, testCfg.Context, props["context"])
+}
+
+func sendTwinResponse(f *FileUpload, criterion protocol.TopicCriterion, correlationID string, status int) {
+	topic := (&protocol.Topic{}).WithNamespace(namespace).WithEntityName(deviceID).
+		WithGroup(protocol.GroupThings).WithChannel(protocol.ChannelTwin).WithCriterion(criterion)
+	if criterion == protocol.CriterionCommands {
+		topic.WithAction(protocol.ActionModify)
+	}
+
+	msg := &protocol.Envelope{
+		Topic:   topic,
+		Headers: protocol.NewHeaders(protocol.WithCorrelationID(correlationID)),
+		Path:    "/",
+		Value:   map[string]interface{}{"status": status},
+		Status:  status,
+	}
+
+	f.uploadable.messageHandler("testRequestID", msg)
+}
+
+func TestReadvertiseOnTwinError(t *testing.T) {
+	f, client := newReadvertisingFileUpload(t)
+	defer f.Disconnect()
+
+	f.uploadable.UpdateProperty(bandwidthProperty, map[string]interface{}{"bytesPerSecond": 1})
+
+	env := twinEnvelope(t, client)
+	assertEquals(t, "/features/"+featureID+"/properties/"+bandwidthProperty, env.Path)
+	assertEquals(t, true, env.Headers.IsResponseRequired())
+	assertEquals(t, featureID+twinCorrelationInfix+bandwidthProperty, env.Headers.CorrelationID())
+
+	sendTwinResponse(f, protocol.CriterionCommands, env.Headers.CorrelationID(), http.StatusNoContent)
+	sendTwinResponse(f, protocol.CriterionErrors, env.Headers.CorrelationID(), http.StatusBadRequest)
+	sendTwinResponse(f, protocol.CriterionErrors, "otherCorrelationID", http.StatusNotFound)
+	client.assertEmpty(t, twin)
+
+	sendTwinResponse(f, protocol.CriterionErrors, env.Headers.CorrelationID(), http.StatusNotFound)
+	first := time.Now()
+	assertAdvertised(t, twinEnvelope(t, client))
+
+	// the thing itself is missing as well, so the re-advertisement is retried after the interval
+	sendTwinResponse(f, protocol.CriterionErrors, featureID+twinCorrelationInfix+featureCorrelationSuffix, http.StatusNotFound)
+	sendTwinResponse(f, protocol.CriterionErrors, env.Headers.CorrelationID(), http.StatusNotFound)
+	assertAdvertised(t, twinEnvelope(t, client))
+	if elapsed := time.Since(first); elapsed < testReadvertiseInterval-10*time.Millisecond {
+		t.Errorf("re-advertisement expected %v after the previous one, but was %v", testReadvertiseInterval, elapsed)
+	}
+
+	client.assertEmpty(t, twin)
+}
+
+func TestReadvertiseOnSendError(t *testing.T) {
+	f, client := newReadvertisingFileUpload(t)
+	defer f.Disconnect()
+
+	client.setErr(errors.New("connection lost"))
+	f.uploadable.UpdateProperty(bandwidthProperty, map[string]interface{}{"bytesPerSecond": 1})
+	twinEnvelope(t, client) // the failed property update
+
+	assertAdvertised(t, twinEnvelope(t, client)) // failed as well
+	client.setErr(nil)
+
+	assertAdvertised(t, twinEnvelope(t, client))
+	client.assertEmpty(t, twin)
+}
+
+func TestReadvertiseDisabled(t *testing.T) {
+	f, client := newConnectedFileUpload(t, filepath.Join(t.TempDir(), "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	f.uploadable.UpdateProperty(bandwidthProperty, map[string]interface{}{"bytesPerSecond": 1})
+
+	env := twinEnvelope(t, client)
+	assertEquals(t, false, env.Headers.IsResponseRequired())
+
+	sendTwinResponse(f, protocol.CriterionErrors, featureID+twinCorrelationInfix+bandwidthProperty, http.StatusNotFound)
+	client.assertEmpty(t, twin)
+}
+
+func TestReadvertiseStopped(t *testing.T) {
+	advertised := make(chan struct{}, 10)
+	a := newFeatureAdvertiser(time.Hour, func() error {
+		advertised <- struct{}{}
+		return nil
+	})
+
+	a.trigger()
+	select {
+	case <-advertised:
+	case <-time.After(5 * time.Second):
+		t.Fatal("feature not re-advertised")
+	}
+
+	a.trigger() // scheduled after the interval
+	a.stop()
+	a.trigger()
+	assertEquals(t, (*time.Timer)(nil), a.timer)
+
+	(*featureAdvertiser)(nil).trigger()
+	(*featureAdvertiser)(nil).stop()
+}
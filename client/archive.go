@@ -0,0 +1,125 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+// Trigger options for uploading the matched files as a single archive
+const (
+	archiveModeOption      = "archive.mode"
+	archiveNameOption      = "archive.name"
+	archiveDirectoryOption = "archive.directory"
+)
+
+// fileArchive is a temporary archive, bundling the files of a multi-file upload
+type fileArchive struct {
+	path    string   // path of the temporary archive
+	name    string   // name of the uploaded object
+	sources []string // archived files
+}
+
+// newFileArchive bundles the given files into a temporary archive with the specified mode. If the name is empty,
+// the archive is named after the correlation ID, with the archive mode extension appended.
+func newFileArchive(correlationID string, files []string, mode string, name string) (*fileArchive, error) {
+	if name == "" {
+		name = correlationID + uploaders.ArchiveExtension(mode)
+	}
+
+	path, err := uploaders.ArchiveFiles(files, mode, name)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("%d files archived in '%s'", len(files), path)
+
+	return &fileArchive{path, name, files}, nil
+}
+
+// batchSmallFiles bundles the files, not exceeding the given batch size, into a tar archive, if there are at least
+// minFiles of them. Returns the files to upload - the larger files, followed by the archive, if created.
+func batchSmallFiles(correlationID string, files []string, batchSize ByteSize, minFiles int) ([]string, *fileArchive, error) {
+	var small, large []string
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && info.Size() <= int64(batchSize) {
+			small = append(small, file)
+		} else {
+			large = append(large, file)
+		}
+	}
+
+	if len(small) < minFiles {
+		return files, nil, nil
+	}
+
+	archive, err := newFileArchive(correlationID, small, uploaders.ArchiveTar, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return append(large, archive.path), archive, nil
+}
+
+// newDirectoryArchive archives a consistent snapshot of the files in the given directory, accepted by the include
+// function. If the name is empty, the archive is named after the correlation ID, with the archive mode extension
+// appended. Returns nil if there are no files to archive.
+func newDirectoryArchive(correlationID string, dir string, include func(path string) bool, mode string,
+	name string) (*fileArchive, error) {
+	if mode == uploaders.ArchiveNone {
+		return nil, fmt.Errorf("archive mode '%s' is not applicable to directory snapshots", mode)
+	}
+
+	if name == "" {
+		name = correlationID + uploaders.ArchiveExtension(mode)
+	}
+
+	path, files, err := uploaders.ArchiveDirectory(dir, include, mode, name)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &fileArchive{path, name, files}
+	if len(files) == 0 {
+		archive.remove()
+		return nil, nil
+	}
+
+	logger.Infof("snapshot of %d files from directory '%s' archived in '%s'", len(files), dir, path)
+
+	return archive, nil
+}
+
+// remove deletes the temporary archive
+func (a *fileArchive) remove() {
+	if err := os.RemoveAll(filepath.Dir(a.path)); err != nil {
+		logger.Errorf("failed to remove archive '%s': %v", a.path, err)
+	}
+}
+
+// deleteSources deletes the archived files, spaced by the given throttle (if not nil)
+func (a *fileArchive) deleteSources(throttle *deleteThrottle) {
+	for _, file := range a.sources {
+		throttle.wait()
+		if err := os.Remove(file); err != nil {
+			logger.Errorf("failed to delete archived file '%s': %v", file, err)
+		} else {
+			logger.Infof("archived file '%s' deleted", file)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+func TestUploadArchive(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, b, c, _ := getTestFiles(t)
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeScoped)
+	defer f.Disconnect()
+
+	testCfg.Delete = true
+	options := map[string]string{
+		uploadFilesProperty: filepath.Join(basedir, "*.txt") + string(filepath.ListSeparator) + c,
+		archiveModeOption:   uploaders.ArchiveTarGz,
+		archiveNameOption:   "device/logs.tar.gz",
+	}
+	assertNoError(t, f.DoTrigger("archiveCorrelationID", options))
+
+	request := client.liveMsg(t, request)
+	assertEquals(t, "device/logs.tar.gz", getFileFromMsg(t, request))
+	client.assertLiveEmpty(t) // a single upload request for all files
+
+	id := request["correlationId"].(string)
+	archive := f.uploadable.uploads.Get(id).(*SingleUpload).filePath
+	assertEquals(t, "logs.tar.gz", filepath.Base(archive))
+
+	startUploads(t, f.uploadable.uploads, []string{id}, server.URL)
+	waitUploadState(t, client, StateSuccess)
+
+	requests := received.get()
+	assertEquals(t, 1, len(requests))
+	assertEquals(t, map[string]string{
+		uploaders.ArchiveEntryName(a): a,
+		uploaders.ArchiveEntryName(b): b,
+		uploaders.ArchiveEntryName(c): c,
+	}, extractTarGz(t, requests[0].body))
+
+	assertNotExists(t, filepath.Dir(archive))
+	for _, file := range []string{a, b, c} {
+		assertNotExists(t, file)
+	}
+}
+
+func TestUploadArchiveInvalidMode(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	getTestFiles(t)
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	assertError(t, f.DoTrigger("archiveCorrelationID", map[string]string{archiveModeOption: "rar"}))
+	client.assertLiveEmpty(t)
+}
+
+func TestArchiveRemovedOnFailure(t *testing.T) {
+	archive, us, ids, l := addTestArchive(t)
+
+	startUploads(t, us, ids, "http://localhost:0")
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+
+	assertNotExists(t, filepath.Dir(archive.path))
+	for _, file := range archive.sources {
+		if _, err := os.Stat(file); err != nil {
+			t.Errorf("archived file '%s' should not be deleted: %v", file, err)
+		}
+	}
+}
+
+func TestArchiveRemovedOnCancel(t *testing.T) {
+	archive, us, _, l := addTestArchive(t)
+
+	us.Get("testUID").cancel("test code", "test message")
+	l.waitFinish()
+	l.assertStatusState(StateCanceled)
+
+	assertNotExists(t, filepath.Dir(archive.path))
+}
+
+func TestUploadBatch(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, b, c, d := getTestFiles(t)
+	large := filepath.Join(basedir, "large.bin")
+	largeContent := bytes.Repeat([]byte("0123456789"), 200)
+	assertNoError(t, os.WriteFile(large, largeContent, 0666))
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeStrict)
+	defer f.Disconnect()
+
+	testCfg.BatchSize = Kilobyte
+	testCfg.BatchMinFiles = 4
+	assertNoError(t, f.DoTrigger("batchCorrelationID", nil))
+
+	ids := make([]string, 2)
+	files := make(map[string]bool)
+	for i := range ids {
+		request := client.liveMsg(t, request)
+		ids[i] = request["correlationId"].(string)
+		files[getFileFromMsg(t, request)] = true
+	}
+	client.assertLiveEmpty(t) // the small files are uploaded with a single request
+	assertEquals(t, map[string]bool{large: true, "batchCorrelationID.tar": true}, files)
+
+	startUploads(t, f.uploadable.uploads, ids, server.URL)
+	waitUploadState(t, client, StateSuccess)
+
+	requests := received.get()
+	assertEquals(t, 2, len(requests))
+
+	var batch map[string]string
+	for _, request := range requests {
+		if bytes.Equal(largeContent, request.body) {
+			continue
+		}
+		batch = extractTar(t, bytes.NewReader(request.body))
+	}
+	assertEquals(t, map[string]string{
+		uploaders.ArchiveEntryName(a): a,
+		uploaders.ArchiveEntryName(b): b,
+		uploaders.ArchiveEntryName(c): c,
+		uploaders.ArchiveEntryName(d): d,
+	}, batch)
+}
+
+func TestUploadDirectorySnapshot(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a := addTestFile(t, "a.txt")
+	nested := addTestFile(t, "logs/nested.txt")
+	addTestFile(t, "logs/excluded.txt")
+	addTestFile(t, "other.dat")
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "**", "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	testCfg.ExcludeFiles = Globs{"excluded.*"}
+	options := map[string]string{archiveDirectoryOption: basedir, archiveModeOption: uploaders.ArchiveTarGz}
+	assertNoError(t, f.DoTrigger("snapshotCorrelationID", options))
+
+	request := client.liveMsg(t, request)
+	assertEquals(t, "snapshotCorrelationID.tar.gz", getFileFromMsg(t, request))
+	client.assertLiveEmpty(t)
+
+	startUploads(t, f.uploadable.uploads, []string{request["correlationId"].(string)}, server.URL)
+	waitUploadState(t, client, StateSuccess)
+
+	requests := received.get()
+	assertEquals(t, 1, len(requests))
+	assertEquals(t, map[string]string{"a.txt": a, "logs/nested.txt": nested}, extractTarGz(t, requests[0].body))
+}
+
+func TestUploadDirectorySnapshotNoFiles(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	addTestFile(t, "other.dat")
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "**", "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	assertNoError(t, f.DoTrigger("snapshotCorrelationID", map[string]string{archiveDirectoryOption: basedir}))
+	client.assertLiveEmpty(t)
+
+	err := f.DoTrigger("snapshotCorrelationID", map[string]string{
+		archiveDirectoryOption: basedir, archiveModeOption: uploaders.ArchiveNone,
+	})
+	assertError(t, err)
+}
+
+func TestBatchSmallFilesBelowMinimum(t *testing.T) {
+	testFiles := createTestFiles(t, 3, false, false)
+	defer cleanFiles(testFiles)
+
+	files := getPaths(testFiles)
+
+	result, archive, err := batchSmallFiles("testUID", files, Kilobyte, 4)
+	assertNoError(t, err)
+	assertEquals(t, files, result)
+	if archive != nil {
+		t.Errorf("no archive expected, but was '%s'", archive.path)
+	}
+}
+
+func addTestArchive(t *testing.T) (*fileArchive, *Uploads, []string, *TestStatusListener) {
+	files := createTestFiles(t, 3, false, false)
+	t.Cleanup(func() { cleanFiles(files) })
+
+	archive, err := newFileArchive("testUID", getPaths(files), uploaders.ArchiveZip, "")
+	assertNoError(t, err)
+	t.Cleanup(archive.remove)
+	assertEquals(t, "testUID.zip", archive.name)
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.addMulti("testUID", []string{archive.path}, archive, false, nil, &UploadableConfig{Delete: true}, l)
+
+	return archive, us, ids, l
+}
+
+// waitUploadState waits for a 'lastUpload' property update with the given upload state
+func waitUploadState(t *testing.T, client *mockedClient, state string) {
+	t.Helper()
+
+	for {
+		if s, ok := client.twinMsg(t, modify)["state"]; ok && s != StateUploading {
+			assertEquals(t, state, s)
+			return
+		}
+	}
+}
+
+// assertNotExists waits for the path to be removed, as clean-up might follow the final status update
+func assertNotExists(t *testing.T, path string) {
+	t.Helper()
+
+	var err error
+	for end := time.Now().Add(time.Second); time.Now().Before(end); time.Sleep(10 * time.Millisecond) {
+		if _, err = os.Stat(path); os.IsNotExist(err) {
+			return
+		}
+	}
+
+	t.Errorf("'%s' should not exist: %v", path, err)
+}
+
+// extractTarGz returns the content of the archived files, mapped by their names
+func extractTarGz(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	assertNoError(t, err)
+
+	return extractTar(t, gr)
+}
+
+// extractTar returns the content of the archived files, mapped by their names
+func extractTar(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+
+	result := make(map[string]string)
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assertNoError(t, err)
+
+		content, err := io.ReadAll(tr)
+		assertNoError(t, err)
+
+		result[header.Name] = string(content)
+	}
+
+	return result
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// bandwidthWindow is the transfer time, over which the upload bandwidth rolling average is computed
+const bandwidthWindow = 30 * time.Second
+
+// Bandwidth is used for serializing the 'bandwidth' property of the AutoUploadable feature
+type Bandwidth struct {
+	BytesPerSecond int64 `json:"bytesPerSecond"`
+}
+
+type bandwidthSample struct {
+	bytes    int64
+	duration time.Duration
+}
+
+// bandwidthMeter measures the upload bandwidth as a rolling average over the most recent transfers.
+// Only the time spent transferring is taken into account, so idle periods between uploads do not lower the bandwidth.
+type bandwidthMeter struct {
+	window time.Duration
+
+	samples  []bandwidthSample
+	bytes    int64
+	duration time.Duration
+
+	mutex sync.Mutex
+}
+
+func newBandwidthMeter(window time.Duration) *bandwidthMeter {
+	return &bandwidthMeter{window: window}
+}
+
+// add records the number of bytes, transferred for the given duration
+func (m *bandwidthMeter) add(bytes int64, duration time.Duration) {
+	if duration <= 0 {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.samples = append(m.samples, bandwidthSample{bytes, duration})
+	m.bytes += bytes
+	m.duration += duration
+
+	// drop the oldest samples, as long as the remaining ones still cover the window
+	for len(m.samples) > 1 && m.duration-m.samples[0].duration >= m.window {
+		m.bytes -= m.samples[0].bytes
+		m.duration -= m.samples[0].duration
+		m.samples = m.samples[1:]
+	}
+}
+
+// bandwidth returns the average number of bytes transferred per second
+func (m *bandwidthMeter) bandwidth() int64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.duration <= 0 {
+		return 0
+	}
+
+	return int64(float64(m.bytes) / m.duration.Seconds())
+}
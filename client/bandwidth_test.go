@@ -0,0 +1,69 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBandwidthMeter(t *testing.T) {
+	m := newBandwidthMeter(10 * time.Second)
+	assertEquals(t, int64(0), m.bandwidth())
+
+	m.add(1000, time.Second)
+	assertEquals(t, int64(1000), m.bandwidth())
+
+	m.add(3000, time.Second)
+	assertEquals(t, int64(2000), m.bandwidth())
+
+	m.add(100, 0) // ignored
+	assertEquals(t, int64(2000), m.bandwidth())
+
+	// 4000 bytes for 2s + 6000 bytes for 8s
+	m.add(6000, 8*time.Second)
+	assertEquals(t, int64(1000), m.bandwidth())
+
+	// the first two samples are outside the window
+	m.add(4000, 2*time.Second)
+	assertEquals(t, int64(1000), m.bandwidth())
+	assertEquals(t, 2, len(m.samples))
+
+	// the newest sample is always kept
+	m.add(50000, 20*time.Second)
+	assertEquals(t, int64(2500), m.bandwidth())
+	assertEquals(t, 1, len(m.samples))
+}
+
+func TestBandwidthFromProgress(t *testing.T) {
+	us := NewUploads()
+
+	start := time.Now()
+	u := &MultiUpload{uploads: us, totalSizeBytes: 100000, status: &UploadStatus{StartTime: start}}
+
+	for i := 1; i <= 5; i++ {
+		u.totalBytesTransferred += 2000
+		u.updateTransferSpeed(start.Add(time.Duration(i) * time.Second))
+	}
+	assertEquals(t, int64(2000), us.Bandwidth())
+
+	// a new upload, started after an idle period, does not lower the bandwidth
+	start = start.Add(time.Minute)
+	u = &MultiUpload{uploads: us, totalSizeBytes: 100000, status: &UploadStatus{StartTime: start}}
+
+	u.totalBytesTransferred = 12000
+	u.updateTransferSpeed(start.Add(2 * time.Second))
+	assertEquals(t, int64(3142), us.Bandwidth())
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestByteSizeSet(t *testing.T) {
+	valid := map[string]ByteSize{
+		"0":      0,
+		"512":    512,
+		"100B":   100,
+		"1KB":    Kilobyte,
+		"1.5kb":  1536,
+		"50MB":   50 * Megabyte,
+		" 2 GB ": 2 * Gigabyte,
+	}
+	for value, expected := range valid {
+		var b ByteSize
+		assertNoError(t, b.Set(value))
+		assertEquals(t, expected, b)
+
+		var parsed ByteSize
+		assertNoError(t, parsed.Set(b.String()))
+		assertEquals(t, b, parsed)
+	}
+
+	for _, value := range []string{"", "MB", "-1KB", "1TB", "ten"} {
+		var b ByteSize
+		if err := b.Set(value); err == nil {
+			t.Errorf("error expected for '%s'", value)
+		}
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	assertEquals(t, "0", ByteSize(0).String())
+	assertEquals(t, "1023B", (Kilobyte - 1).String())
+	assertEquals(t, "1KB", Kilobyte.String())
+	assertEquals(t, "1536B", ByteSize(1536).String())
+	assertEquals(t, "50MB", (50 * Megabyte).String())
+	assertEquals(t, "2GB", (2 * Gigabyte).String())
+}
+
+func TestByteSizeUnmarshalJSON(t *testing.T) {
+	cfg := struct {
+		Min ByteSize `json:"min"`
+		Max ByteSize `json:"max"`
+	}{}
+
+	assertNoError(t, json.Unmarshal([]byte(`{"min": 1024, "max": "50MB"}`), &cfg))
+	assertEquals(t, Kilobyte, cfg.Min)
+	assertEquals(t, 50*Megabyte, cfg.Max)
+
+	if err := json.Unmarshal([]byte(`{"min": true}`), &cfg); err == nil {
+		t.Error("error expected for invalid byte size")
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+)
+
+// progressCallbackTimeout limits the duration of a single progress callback request
+const progressCallbackTimeout = 5 * time.Second
+
+// progressCallback POSTs the upload status as JSON to a local URL, e.g. for on-device UIs, at most once per interval.
+// Progress updates of an upload, which are not sent yet, are replaced by the newer ones, while the final statuses
+// are always sent. Sending is best effort - errors are only logged.
+type progressCallback struct {
+	url      string
+	interval time.Duration
+	client   *http.Client
+
+	queue []*UploadStatus
+	wake  chan struct{}
+	done  chan struct{}
+
+	mutex sync.Mutex
+}
+
+func newProgressCallback(callbackURL string, interval time.Duration) (*progressCallback, error) {
+	if parsed, err := url.Parse(callbackURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("invalid progress callback URL '%s'", callbackURL)
+	}
+
+	c := &progressCallback{
+		url:      callbackURL,
+		interval: interval,
+		client:   &http.Client{Timeout: progressCallbackTimeout},
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go c.run()
+
+	return c, nil
+}
+
+// add queues the given status to be sent, replacing the queued progress update of the same upload, if any
+func (c *progressCallback) add(status *UploadStatus) {
+	s := *status
+
+	c.mutex.Lock()
+	if last := len(c.queue) - 1; last >= 0 && c.queue[last].CorrelationID == s.CorrelationID && !c.queue[last].finished() {
+		c.queue[last] = &s
+	} else {
+		c.queue = append(c.queue, &s)
+	}
+	c.mutex.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *progressCallback) run() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.wake:
+		}
+
+		for status := c.next(); status != nil; status = c.next() {
+			c.send(status)
+
+			select {
+			case <-c.done:
+				return
+			case <-time.After(c.interval):
+			}
+		}
+	}
+}
+
+func (c *progressCallback) next() *UploadStatus {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.queue) == 0 {
+		return nil
+	}
+
+	status := c.queue[0]
+	c.queue = c.queue[1:]
+
+	return status
+}
+
+func (c *progressCallback) send(status *UploadStatus) {
+	payload, err := json.Marshal(status)
+	if err != nil {
+		logger.Errorf("failed to encode upload status for the progress callback: %v", err)
+		return
+	}
+
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		logger.Debugf("failed to send upload status to the progress callback: %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		logger.Debugf("progress callback responded with status %s", resp.Status)
+	}
+}
+
+func (c *progressCallback) close() {
+	close(c.done)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type callbackRequest struct {
+	received time.Time
+	status   UploadStatus
+}
+
+func startCallbackServer(t *testing.T) (*httptest.Server, chan *callbackRequest) {
+	requests := make(chan *callbackRequest, 100)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := &callbackRequest{received: time.Now()}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected progress callback request %s with content type '%s'", r.Method, r.Header.Get("Content-Type"))
+		}
+		assertNoError(t, json.NewDecoder(r.Body).Decode(&request.status))
+		requests <- request
+	}))
+
+	return server, requests
+}
+
+func readCallbackRequests(t *testing.T, requests chan *callbackRequest, correlationID string) []*callbackRequest {
+	t.Helper()
+
+	var result []*callbackRequest
+	for {
+		select {
+		case request := <-requests:
+			result = append(result, request)
+			if request.status.CorrelationID == correlationID && request.status.finished() {
+				return result
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("final status of upload '%s' not received, received %d statuses", correlationID, len(result))
+		}
+	}
+}
+
+func TestProgressCallback(t *testing.T) {
+	server, requests := startCallbackServer(t)
+	defer server.Close()
+
+	interval := 100 * time.Millisecond
+	u, err := NewAutoUploadable(&UploadableConfig{ProgressCallbackURL: server.URL, ProgressCallbackInterval: Duration(interval)}, nil)
+	assertNoError(t, err)
+	defer u.callback.close()
+
+	start := time.Now()
+	for progress := 0; progress < 100; progress += 10 {
+		u.uploadStatusUpdated(&UploadStatus{CorrelationID: "first", State: StateUploading, StartTime: start, Progress: progress})
+		time.Sleep(interval / 10)
+	}
+	u.uploadStatusUpdated(&UploadStatus{CorrelationID: "first", State: StateSuccess, StartTime: start, Progress: 100})
+	u.uploadStatusUpdated(&UploadStatus{CorrelationID: "second", State: StateFailed, StartTime: start, Message: "failed"})
+
+	received := readCallbackRequests(t, requests, "second")
+	if len(received) < 3 || len(received) > 6 {
+		t.Fatalf("expected throttled progress updates and final statuses, but received %d statuses", len(received))
+	}
+
+	first, last := received[0].status, received[len(received)-1].status
+	assertEquals(t, 0, first.Progress)
+	assertEquals(t, StateUploading, first.State)
+	assertEquals(t, StateSuccess, received[len(received)-2].status.State)
+	assertEquals(t, 100, received[len(received)-2].status.Progress)
+	assertEquals(t, "second", last.CorrelationID)
+	assertEquals(t, "failed", last.Message)
+
+	for i := 1; i < len(received); i++ {
+		if elapsed := received[i].received.Sub(received[i-1].received); elapsed < interval-10*time.Millisecond {
+			t.Errorf("progress callback %d expected %v after the previous one, but was %v", i, interval, elapsed)
+		}
+		if received[i].status.CorrelationID == "first" && received[i].status.Progress < received[i-1].status.Progress {
+			t.Errorf("progress callback %d reported decreasing progress %d", i, received[i].status.Progress)
+		}
+	}
+}
+
+func TestProgressCallbackUnavailable(t *testing.T) {
+	server, requests := startCallbackServer(t)
+	url := server.URL
+	server.Close()
+
+	u, err := NewAutoUploadable(&UploadableConfig{ProgressCallbackURL: url}, nil)
+	assertNoError(t, err)
+	defer u.callback.close()
+
+	u.uploadStatusUpdated(&UploadStatus{CorrelationID: "test", State: StateSuccess}) // errors are only logged
+	select {
+	case <-requests:
+		t.Error("no progress callback expected")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestProgressCallbackInvalidURL(t *testing.T) {
+	for _, url := range []string{"localhost:8080", "ftp://localhost/progress", "http://%zz"} {
+		_, err := NewAutoUploadable(&UploadableConfig{ProgressCallbackURL: url}, nil)
+		assertError(t, err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+// StatusCodeCircuitOpen is reported in the status code of uploads, which failed without contacting the storage,
+// because the circuit of their storage endpoint is open
+const StatusCodeCircuitOpen = "circuit-open"
+
+var errCircuitOpen = errors.New("circuit open")
+
+// circuitBreaker opens the circuit of a storage endpoint after a number of consecutive upload failures. Uploads
+// to an endpoint with open circuit fail right away until the cooldown elapses. The first upload after the cooldown
+// is attempted - on success the circuit is closed, on failure it is opened again for another cooldown.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	circuits map[string]*circuit
+
+	mutex sync.Mutex
+}
+
+type circuit struct {
+	failures int
+	opened   time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, circuits: make(map[string]*circuit)}
+}
+
+// check returns error if the circuit of the given endpoint is open
+func (b *circuitBreaker) check(endpoint string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[endpoint]
+	if !ok || c.failures < b.threshold || time.Since(c.opened) >= b.cooldown {
+		return nil
+	}
+
+	return fmt.Errorf("%w for storage endpoint '%s' after %d consecutive failures, retrying after %v",
+		errCircuitOpen, endpoint, c.failures, c.opened.Add(b.cooldown).Format(time.RFC3339))
+}
+
+// update records the result of an upload to the given endpoint. Errors, which do not indicate unavailability
+// of the endpoint (e.g. rejected credentials), are ignored.
+func (b *circuitBreaker) update(endpoint string, err error) {
+	if err != nil && !uploaders.IsUnavailableError(err) {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[endpoint]
+	if err == nil {
+		if ok && c.failures >= b.threshold {
+			logger.Infof("circuit for storage endpoint '%s' closed", endpoint)
+		}
+		delete(b.circuits, endpoint)
+		return
+	}
+
+	if !ok {
+		c = &circuit{}
+		b.circuits[endpoint] = c
+	}
+
+	c.failures++
+	if c.failures >= b.threshold {
+		c.opened = time.Now()
+		logger.Warnf("circuit for storage endpoint '%s' opened for %v after %d consecutive failures: %v",
+			endpoint, b.cooldown, c.failures, err)
+	}
+}
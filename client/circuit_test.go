@@ -0,0 +1,101 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := newCircuitBreaker(2, 200*time.Millisecond)
+	const endpoint = "http://localhost:1234"
+	unavailable := &uploaders.HTTPError{Code: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+
+	b.update(endpoint, unavailable)
+	assertNoError(t, b.check(endpoint)) // below the threshold
+
+	b.update(endpoint, &uploaders.HTTPError{Code: http.StatusForbidden, Status: "403 Forbidden"})
+	assertNoError(t, b.check(endpoint)) // not an availability error
+
+	b.update(endpoint, unavailable)
+	err := b.check(endpoint)
+	if !errors.Is(err, errCircuitOpen) {
+		t.Fatalf("circuit open error expected, but was %v", err)
+	}
+	assertNoError(t, b.check("http://other:1234"))
+
+	time.Sleep(200 * time.Millisecond)
+	assertNoError(t, b.check(endpoint)) // cooldown elapsed
+
+	b.update(endpoint, unavailable) // failed again after the cooldown
+	assertError(t, b.check(endpoint))
+
+	time.Sleep(200 * time.Millisecond)
+	b.update(endpoint, nil)
+	assertNoError(t, b.check(endpoint))
+
+	b.update(endpoint, unavailable)
+	assertNoError(t, b.check(endpoint)) // failures are counted again from zero
+}
+
+func TestCircuitOpenUploadsSkipped(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	requests := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := &UploadableConfig{FailureThreshold: 2, CircuitCooldown: Duration(500 * time.Millisecond)}
+	us := NewUploads()
+
+	upload := func() UploadStatus {
+		l := NewTestStatusListener(t)
+		ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+		startUploads(t, us, ids, server.URL)
+		l.waitFinish()
+		l.assertStatusState(StateFailed)
+
+		return l.getStatus()
+	}
+
+	upload()
+	status := upload()
+	assertEquals(t, int32(2), atomic.LoadInt32(&requests))
+	assertEquals(t, "", status.StatusCode)
+
+	status = upload() // skipped, the circuit is open
+	assertEquals(t, int32(2), atomic.LoadInt32(&requests))
+	assertEquals(t, StatusCodeCircuitOpen, status.StatusCode)
+
+	time.Sleep(500 * time.Millisecond)
+
+	upload() // attempted again after the cooldown, opening the circuit again
+	assertEquals(t, int32(3), atomic.LoadInt32(&requests))
+
+	status = upload()
+	assertEquals(t, int32(3), atomic.LoadInt32(&requests))
+	assertEquals(t, StatusCodeCircuitOpen, status.StatusCode)
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+)
+
+// coalescedTrigger is a trigger, delayed by the trigger window, into which the triggers within the window are merged
+type coalescedTrigger struct {
+	correlationID string
+	options       map[string]string
+	periodic      bool
+
+	timer *time.Timer
+}
+
+// merge merges the given trigger into this one and returns true, if they are mergeable. Periodic triggers are merged
+// into any trigger, and on-demand triggers take over the periodic ones. On-demand triggers are merged only if their
+// options are the same.
+func (t *coalescedTrigger) merge(correlationID string, options map[string]string, periodic bool) bool {
+	switch {
+	case periodic:
+		return true
+	case t.periodic:
+		t.correlationID, t.options, t.periodic = correlationID, options, false
+		return true
+	default:
+		return (len(t.options) == 0 && len(options) == 0) || reflect.DeepEqual(t.options, options)
+	}
+}
+
+// trigger triggers upload right away, if the trigger window is not configured or on dry run. Otherwise, the trigger is merged into
+// the pending trigger, if mergeable, or else it becomes the pending trigger, which triggers upload when the window
+// elapses. Triggers, which are not mergeable into the pending one, trigger upload right away.
+func (fu *FileUpload) trigger(correlationID string, options map[string]string, periodic bool) error {
+	window := time.Duration(fu.uploadable.cfg.TriggerWindow)
+	if window <= 0 || options[DryRunOption] == "true" {
+		return fu.doTrigger(correlationID, options)
+	}
+
+	if _, _, err := fu.getGlob(options); err != nil {
+		return err // invalid triggers are reported right away
+	}
+
+	fu.mutex.Lock()
+	if pending := fu.pending; pending != nil {
+		previous := pending.correlationID
+		merged := pending.merge(correlationID, options, periodic)
+		current := pending.correlationID
+		if merged { // the operations, waiting for the merged trigger upload, wait for the pending trigger upload
+			fu.uploadable.completions.redirect(correlationID, current)
+		}
+		fu.mutex.Unlock()
+
+		if !merged {
+			return fu.doTrigger(correlationID, options)
+		}
+
+		logger.Infof("trigger %s merged with trigger %s, upload is triggered as %s", correlationID, previous, current)
+		return nil
+	}
+
+	pending := &coalescedTrigger{correlationID: correlationID, options: options, periodic: periodic}
+	pending.timer = time.AfterFunc(window, func() {
+		fu.firePending(pending)
+	})
+	fu.pending = pending
+	fu.mutex.Unlock()
+
+	logger.Infof("trigger %s delayed by the trigger window of %v", correlationID, window)
+	return nil
+}
+
+// firePending triggers the upload of the given pending trigger, when its window elapses. The operations, waiting
+// for its upload, are released, if no upload is started.
+func (fu *FileUpload) firePending(pending *coalescedTrigger) {
+	fu.mutex.Lock()
+	if fu.pending != pending {
+		fu.mutex.Unlock()
+		return // canceled
+	}
+	fu.pending = nil
+	correlationID, options := pending.correlationID, pending.options
+	fu.firing = correlationID
+	fu.mutex.Unlock()
+
+	if err := fu.doTrigger(correlationID, options); err != nil {
+		logger.Errorf("error on trigger %s: %v", correlationID, err)
+	}
+
+	fu.mutex.Lock()
+	fu.firing = ""
+	fu.mutex.Unlock()
+
+	fu.uploadable.triggerFired(correlationID)
+}
+
+// isDelayed returns true, if the given trigger is the pending one, delayed by the trigger window, or its upload
+// is being started
+func (fu *FileUpload) isDelayed(correlationID string) bool {
+	fu.mutex.Lock()
+	defer fu.mutex.Unlock()
+
+	return (fu.pending != nil && fu.pending.correlationID == correlationID) || fu.firing == correlationID
+}
+
+// cancelPending cancels the pending trigger, if any
+func (fu *FileUpload) cancelPending() {
+	fu.mutex.Lock()
+	defer fu.mutex.Unlock()
+
+	if fu.pending != nil {
+		fu.pending.timer.Stop()
+		fu.uploadable.completions.release(fu.pending.correlationID)
+		fu.pending = nil
+	}
+}
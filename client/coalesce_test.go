@@ -0,0 +1,105 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testTriggerWindow = 200 * time.Millisecond
+
+// assertCorrelationID asserts that the upload request belongs to the trigger with the given correlation ID
+func assertCorrelationID(t *testing.T, expected string, msg map[string]interface{}) {
+	t.Helper()
+
+	if id := msg["correlationId"].(string); !strings.HasPrefix(id, expected+"#") {
+		t.Errorf("upload request of trigger %s expected, but was %s", expected, id)
+	}
+}
+
+func TestTriggerWindowMergesPeriodic(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, _, _, _ := getTestFiles(t)
+
+	f, client := newConnectedFileUpload(t, a, ModeStrict)
+	defer f.Disconnect()
+	f.uploadable.cfg.TriggerWindow = Duration(testTriggerWindow)
+
+	start := time.Now()
+	assertNoError(t, f.DoTrigger("onDemandID", nil))
+	f.OnTick()
+	client.assertLiveEmpty(t) // delayed by the window
+
+	msg := client.liveMsg(t, request)
+	if elapsed := time.Since(start); elapsed < testTriggerWindow {
+		t.Errorf("upload expected to be triggered after %v, but was after %v", testTriggerWindow, elapsed)
+	}
+	assertCorrelationID(t, "onDemandID", msg)
+	assertEquals(t, a, getFileFromMsg(t, msg))
+	client.assertLiveEmpty(t)
+
+	// the on-demand trigger takes over the periodic one
+	f.OnTick()
+	assertNoError(t, f.DoTrigger("onDemandID2", nil))
+
+	msg = client.liveMsg(t, request)
+	assertCorrelationID(t, "onDemandID2", msg)
+	client.assertLiveEmpty(t)
+}
+
+func TestTriggerWindowOptions(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, b, _, _ := getTestFiles(t)
+
+	f, client := newConnectedFileUpload(t, a, ModeLax)
+	defer f.Disconnect()
+	f.uploadable.cfg.TriggerWindow = Duration(testTriggerWindow)
+
+	assertNoError(t, f.DoTrigger("firstID", map[string]string{uploadFilesProperty: a}))
+	assertNoError(t, f.DoTrigger("sameID", map[string]string{uploadFilesProperty: a}))
+
+	// not mergeable, so triggered right away
+	assertNoError(t, f.DoTrigger("otherID", map[string]string{uploadFilesProperty: b}))
+	msg := client.liveMsg(t, request)
+	assertCorrelationID(t, "otherID", msg)
+	assertEquals(t, b, getFileFromMsg(t, msg))
+
+	msg = client.liveMsg(t, request)
+	assertCorrelationID(t, "firstID", msg)
+	assertEquals(t, a, getFileFromMsg(t, msg))
+	client.assertLiveEmpty(t)
+}
+
+func TestTriggerWindowCanceled(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, _, _, _ := getTestFiles(t)
+
+	f, client := newConnectedFileUpload(t, a, ModeStrict)
+	f.uploadable.cfg.TriggerWindow = Duration(testTriggerWindow)
+
+	assertNoError(t, f.DoTrigger("canceledID", nil))
+	f.Disconnect()
+
+	time.Sleep(2 * testTriggerWindow)
+	client.assertLiveEmpty(t)
+}
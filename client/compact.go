@@ -0,0 +1,192 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Encodings of the upload status, reported in the 'lastUpload' property
+const (
+	StatusEncodingJSON = "json"
+	StatusEncodingCBOR = "cbor"
+)
+
+// compactStates are encoded as their indexes, other states are encoded as text
+var compactStates = []string{StatePending, StateUploading, StatePaused, StateSuccess, StateFailed, StateCanceled}
+
+// compactStatus is the compact form of the upload status - a CBOR map with integer keys, omitting the empty fields.
+// Times are Unix milliseconds. The keys should never be changed or reused, since they are part of the wire format.
+type compactStatus struct {
+	CorrelationID  string            `cbor:"0,keyasint,omitempty"`
+	State          compactState      `cbor:"1,keyasint,omitempty"`
+	StartTime      int64             `cbor:"2,keyasint,omitempty"`
+	EndTime        int64             `cbor:"3,keyasint,omitempty"`
+	StatusCode     string            `cbor:"4,keyasint,omitempty"`
+	Message        string            `cbor:"5,keyasint,omitempty"`
+	Progress       int               `cbor:"6,keyasint,omitempty"`
+	BytesPerSecond int64             `cbor:"7,keyasint,omitempty"`
+	ETASeconds     int               `cbor:"8,keyasint,omitempty"`
+	Info           map[string]string `cbor:"9,keyasint,omitempty"`
+	Provider       string            `cbor:"10,keyasint,omitempty"`
+	Files          []compactFile     `cbor:"11,keyasint,omitempty"`
+	Stale          []string          `cbor:"12,keyasint,omitempty"`
+	Logs           []string          `cbor:"13,keyasint,omitempty"`
+}
+
+// compactFile is the compact form of the file status - a CBOR array of the path, the state and the progress
+type compactFile struct {
+	_        struct{} `cbor:",toarray"`
+	Path     string
+	State    compactState
+	Progress int
+}
+
+// compactState is encoded as the index of a known state, otherwise as text
+type compactState string
+
+// MarshalCBOR implements cbor.Marshaler
+func (s compactState) MarshalCBOR() ([]byte, error) {
+	for i, state := range compactStates {
+		if state == string(s) {
+			return compactMode.Marshal(i)
+		}
+	}
+
+	return compactMode.Marshal(string(s))
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler
+func (s *compactState) UnmarshalCBOR(data []byte) error {
+	var v interface{}
+	if err := cbor.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch state := v.(type) {
+	case uint64:
+		if state >= uint64(len(compactStates)) {
+			return fmt.Errorf("unknown compact state %d", state)
+		}
+		*s = compactState(compactStates[state])
+	case string:
+		*s = compactState(state)
+	default:
+		return fmt.Errorf("unexpected compact state %v", v)
+	}
+
+	return nil
+}
+
+// compactMode encodes with the core deterministic encoding, e.g. the info keys are sorted
+var compactMode = func() cbor.EncMode {
+	mode, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		panic(err) // MUST not happen with the predefined options
+	}
+	return mode
+}()
+
+// compactDecMode rejects unknown keys
+var compactDecMode = func() cbor.DecMode {
+	mode, err := cbor.DecOptions{ExtraReturnErrors: cbor.ExtraDecErrorUnknownField}.DecMode()
+	if err != nil {
+		panic(err) // MUST not happen with valid options
+	}
+	return mode
+}()
+
+// CompactStatus returns the base64 encoded compact form of the upload status, reported in the 'lastUpload' property
+// with the 'cbor' status encoding
+func CompactStatus(status *UploadStatus) string {
+	return base64.StdEncoding.EncodeToString(EncodeCompactStatus(status))
+}
+
+// EncodeCompactStatus encodes the upload status as CBOR map with integer keys, omitting the empty fields.
+// Times are encoded as Unix milliseconds and known states as integers. The info keys are sorted as required by
+// the core deterministic encoding (RFC 8949).
+func EncodeCompactStatus(status *UploadStatus) []byte {
+	compact := &compactStatus{
+		CorrelationID:  status.CorrelationID,
+		State:          compactState(status.State),
+		StartTime:      unixMillis(status.StartTime),
+		EndTime:        unixMillis(status.EndTime),
+		StatusCode:     status.StatusCode,
+		Message:        status.Message,
+		Progress:       status.Progress,
+		BytesPerSecond: status.BytesPerSecond,
+		ETASeconds:     status.ETASeconds,
+		Info:           status.Info,
+		Provider:       status.Provider,
+		Stale:          status.Stale,
+		Logs:           status.Logs,
+	}
+	for _, file := range status.Files {
+		compact.Files = append(compact.Files, compactFile{Path: file.Path, State: compactState(file.State), Progress: file.Progress})
+	}
+
+	data, _ := compactMode.Marshal(compact) // MUST not return error, since all fields are encodable
+
+	return data
+}
+
+// DecodeCompactStatus decodes the upload status from its compact CBOR encoding
+func DecodeCompactStatus(data []byte) (*UploadStatus, error) {
+	compact := &compactStatus{}
+	if err := compactDecMode.Unmarshal(data, compact); err != nil {
+		return nil, err
+	}
+
+	status := &UploadStatus{
+		CorrelationID:  compact.CorrelationID,
+		State:          string(compact.State),
+		StartTime:      fromUnixMillis(compact.StartTime),
+		EndTime:        fromUnixMillis(compact.EndTime),
+		StatusCode:     compact.StatusCode,
+		Message:        compact.Message,
+		Progress:       compact.Progress,
+		BytesPerSecond: compact.BytesPerSecond,
+		ETASeconds:     compact.ETASeconds,
+		Info:           compact.Info,
+		Provider:       compact.Provider,
+		Stale:          compact.Stale,
+		Logs:           compact.Logs,
+	}
+	for _, file := range compact.Files {
+		status.Files = append(status.Files, FileStatus{Path: file.Path, State: string(file.State), Progress: file.Progress})
+	}
+
+	return status, nil
+}
+
+// unixMillis returns the given time as Unix milliseconds, or zero for zero time
+func unixMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// fromUnixMillis returns the time of the given Unix milliseconds, or zero time for zero
+func fromUnixMillis(millis int64) time.Time {
+	if millis == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, millis*int64(time.Millisecond))
+}
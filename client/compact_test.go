@@ -0,0 +1,114 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCompactStatus() *UploadStatus {
+	start := time.Date(2026, 3, 4, 5, 6, 7, 123000000, time.UTC)
+
+	return &UploadStatus{
+		CorrelationID:  "upload-id-1678943167#n",
+		State:          StateUploading,
+		StartTime:      start,
+		EndTime:        start.Add(90 * time.Second),
+		StatusCode:     "",
+		Message:        "uploading",
+		Progress:       42,
+		BytesPerSecond: 1 << 20,
+		ETASeconds:     75,
+		Info:           map[string]string{"key1": "value1", "key2": "value2"},
+		Provider:       "aws",
+		Files: []FileStatus{
+			{Path: "/var/log/app/first.log", State: StateSuccess, Progress: 100},
+			{Path: "/var/log/app/second.log", State: StateUploading, Progress: 17},
+			{Path: "/var/log/app/third.log", State: "QUEUED", Progress: 0},
+		},
+		Stale: []string{"/var/log/app/first.log"},
+		Logs:  []string{"WARN upload [correlationID: upload-id-1678943167#n, file: /var/log/app/second.log] interrupted"},
+	}
+}
+
+func assertCompactRoundTrip(t *testing.T, status *UploadStatus) {
+	t.Helper()
+
+	decoded, err := DecodeCompactStatus(EncodeCompactStatus(status))
+	assertNoError(t, err)
+
+	if !decoded.StartTime.Equal(status.StartTime) || !decoded.EndTime.Equal(status.EndTime) {
+		t.Fatalf("expected times %v - %v, but were %v - %v", status.StartTime, status.EndTime, decoded.StartTime, decoded.EndTime)
+	}
+
+	expected := *status
+	expected.StartTime, expected.EndTime = time.Time{}, time.Time{}
+	decoded.StartTime, decoded.EndTime = time.Time{}, time.Time{}
+	assertEquals(t, &expected, decoded)
+}
+
+func TestCompactStatusRoundTrip(t *testing.T) {
+	assertCompactRoundTrip(t, testCompactStatus())
+	assertCompactRoundTrip(t, &UploadStatus{})
+	assertCompactRoundTrip(t, &UploadStatus{
+		CorrelationID: strings.Repeat("x", 300), State: StateFailed, Message: strings.Repeat("error ", 20000),
+		StatusCode: "500", BytesPerSecond: 1 << 40, StartTime: time.Unix(-86400, 0),
+	})
+
+	encoded := CompactStatus(testCompactStatus())
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	assertNoError(t, err)
+	assertEquals(t, EncodeCompactStatus(testCompactStatus()), data)
+}
+
+func TestCompactStatusSize(t *testing.T) {
+	for _, status := range []*UploadStatus{testCompactStatus(), {CorrelationID: "id", State: StateSuccess, Progress: 100}} {
+		encoded, err := json.Marshal(status)
+		assertNoError(t, err)
+
+		compact := EncodeCompactStatus(status)
+		if len(compact) >= len(encoded)/2 {
+			t.Errorf("compact status (%d bytes) expected to be less than half of the JSON status (%d bytes)", len(compact), len(encoded))
+		}
+		if len(CompactStatus(status)) >= len(encoded) {
+			t.Errorf("base64 compact status (%d bytes) expected to be less than the JSON status (%d bytes)", len(CompactStatus(status)), len(encoded))
+		}
+	}
+}
+
+func TestDecodeCompactStatusErrors(t *testing.T) {
+	data := EncodeCompactStatus(testCompactStatus())
+	for i := 0; i < len(data); i++ {
+		if _, err := DecodeCompactStatus(data[:i]); err == nil {
+			t.Fatalf("error expected for compact status truncated to %d bytes", i)
+		}
+	}
+
+	for _, invalid := range [][]byte{
+		{0x80},             // array instead of map
+		{0xa1, 0x18, 0x63}, // unknown key
+		{0xa1, 0x01, 0x09}, // unknown state
+		{0xa1, 0x00, 0x01}, // integer correlation ID
+		append(data, 0x00), // trailing data
+	} {
+		if _, err := DecodeCompactStatus(invalid); err == nil {
+			t.Errorf("error expected for compact status %x", invalid)
+		}
+	}
+}
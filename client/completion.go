@@ -0,0 +1,134 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"sync"
+)
+
+// pendingUpload is the reply of a 'trigger' operation, waiting for the upload completion, which did not complete
+// within the timeout. Its final status is reported in the 'lastUpload' property.
+type pendingUpload struct {
+	CorrelationID string `json:"correlationId"`
+}
+
+// completionWaiters notifies the operations, waiting for uploads to complete, with the final upload status
+type completionWaiters struct {
+	waiters map[string][]chan UploadStatus
+
+	mutex sync.Mutex
+}
+
+func newCompletionWaiters() *completionWaiters {
+	return &completionWaiters{waiters: make(map[string][]chan UploadStatus)}
+}
+
+// add registers a waiter for the completion of the upload with the given correlation ID
+func (w *completionWaiters) add(correlationID string) chan UploadStatus {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	ch := make(chan UploadStatus, 1)
+	w.waiters[correlationID] = append(w.waiters[correlationID], ch)
+
+	return ch
+}
+
+// remove unregisters the given waiter, e.g. when its wait times out. The waiter might have been redirected
+// to another upload, since it was added.
+func (w *completionWaiters) remove(ch chan UploadStatus) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if correlationID, ok := w.target(ch); ok {
+		waiters := w.waiters[correlationID]
+		for i, waiter := range waiters {
+			if waiter == ch {
+				waiters = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+
+		if len(waiters) == 0 {
+			delete(w.waiters, correlationID)
+		} else {
+			w.waiters[correlationID] = waiters
+		}
+	}
+}
+
+// waitsFor returns the correlation ID of the upload, for which the given waiter waits, or false, if the waiter
+// is already notified or released
+func (w *completionWaiters) waitsFor(ch chan UploadStatus) (string, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.target(ch)
+}
+
+func (w *completionWaiters) target(ch chan UploadStatus) (string, bool) {
+	for correlationID, waiters := range w.waiters {
+		for _, waiter := range waiters {
+			if waiter == ch {
+				return correlationID, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// redirect moves the waiters for the upload with the given correlation ID to the upload with the other one,
+// e.g. when a trigger is merged into another one
+func (w *completionWaiters) redirect(from string, to string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if waiters, ok := w.waiters[from]; ok && from != to {
+		w.waiters[to] = append(w.waiters[to], waiters...)
+		delete(w.waiters, from)
+	}
+}
+
+// notify notifies the waiters of the given upload status, if final. Does not block.
+func (w *completionWaiters) notify(status UploadStatus) {
+	if status.finished() {
+		w.notifyAs(status.CorrelationID, status)
+	}
+}
+
+// notifyAs notifies the waiters for the upload with the given correlation ID of the given final status, which might be
+// the status of another upload, e.g. of the last file batch of a trigger. Does not block.
+func (w *completionWaiters) notifyAs(correlationID string, status UploadStatus) {
+	w.mutex.Lock()
+	waiters := w.waiters[correlationID]
+	delete(w.waiters, correlationID)
+	w.mutex.Unlock()
+
+	for _, ch := range waiters {
+		ch <- status
+	}
+}
+
+// release closes the waiters for the upload with the given correlation ID without status, when no upload is started
+// for it, e.g. a delayed trigger finds no files to upload
+func (w *completionWaiters) release(correlationID string) {
+	w.mutex.Lock()
+	waiters := w.waiters[correlationID]
+	delete(w.waiters, correlationID)
+	w.mutex.Unlock()
+
+	for _, ch := range waiters {
+		close(ch)
+	}
+}
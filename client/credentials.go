@@ -0,0 +1,144 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+// credentialsRefreshRetry is the interval, after which a failed proactive credentials refresh is retried
+const credentialsRefreshRetry = 10 * time.Second
+
+// credentialsStore provides storage credentials, loaded from a local JSON file, containing 'start' operation options
+// (e.g. {"aws.access.key.id": "...", "aws.secret.access.key": "..."}). The file is reloaded when the refresh period
+// elapses, when the loaded credentials are about to expire, i.e. their remaining lifetime is within the refresh
+// margin, or when they are invalidated, because they were rejected by the storage.
+type credentialsStore struct {
+	file    string
+	refresh time.Duration
+	margin  time.Duration
+	retry   time.Duration
+
+	options    map[string]string
+	loaded     time.Time
+	expiration time.Time // zero, if the credentials do not expire
+
+	mutex sync.Mutex
+}
+
+func newCredentialsStore(file string, refresh time.Duration, margin time.Duration) *credentialsStore {
+	return &credentialsStore{file: file, refresh: refresh, margin: margin, retry: credentialsRefreshRetry}
+}
+
+// get returns the current credentials, reloading them from the credentials file if necessary
+func (s *credentialsStore) get() (map[string]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.options == nil || (s.refresh > 0 && time.Since(s.loaded) >= s.refresh) || s.expiresWithinMargin() {
+		data, err := ioutil.ReadFile(s.file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials file '%s': %v", s.file, err)
+		}
+
+		options := make(map[string]string)
+		if err := json.Unmarshal(data, &options); err != nil {
+			return nil, fmt.Errorf("failed to parse credentials file '%s': %v", s.file, err)
+		}
+
+		expiration, err := uploaders.CredentialsExpiration(options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse credentials file '%s': %v", s.file, err)
+		}
+
+		s.options = options
+		s.loaded = time.Now()
+		s.expiration = expiration
+
+		logger.Infof("credentials loaded from '%s'", s.file)
+	}
+
+	return s.options, nil
+}
+
+// invalidate forces reloading of the credentials on the next get
+func (s *credentialsStore) invalidate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.options = nil
+}
+
+// expiresAt returns the expiration time of the loaded credentials, or zero time, if they do not expire
+func (s *credentialsStore) expiresAt() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.expiration
+}
+
+func (s *credentialsStore) expiresWithinMargin() bool {
+	return !s.expiration.IsZero() && time.Until(s.expiration) <= s.margin
+}
+
+// keepFresh proactively refreshes the credentials of a running upload, until done is closed. When the remaining
+// lifetime of the credentials, with which the uploader was created, falls within the refresh margin, the credentials
+// file is reloaded and the new credentials are passed to the uploader. If the reloaded credentials do not expire
+// later, e.g. the file is not updated yet, or the refresh fails, it is retried after the retry interval.
+func (s *credentialsStore) keepFresh(refresher uploaders.CredentialsRefresher, done <-chan struct{}) {
+	expiration := s.expiresAt()
+
+	var retry time.Duration
+	for !expiration.IsZero() {
+		wait := time.Until(expiration) - s.margin
+		if wait < retry {
+			wait = retry
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.invalidate()
+		options, err := s.get()
+		if err == nil {
+			err = refresher.RefreshCredentials(options)
+		}
+		if err != nil {
+			logger.Errorf("failed to refresh credentials from '%s': %v", s.file, err)
+			retry = s.retry
+			continue
+		}
+
+		next := s.expiresAt()
+		if !next.IsZero() && !next.After(expiration) {
+			logger.Warnf("credentials in '%s' are not renewed, they expire at %v", s.file, next)
+			retry = s.retry
+		} else {
+			logger.Infof("credentials refreshed from '%s', before they expire at %v", s.file, expiration)
+			retry = 0
+		}
+		expiration = next
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+type authServer struct {
+	mutex    sync.Mutex
+	token    string
+	rejected int32
+}
+
+func (s *authServer) setToken(token string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.token = token
+}
+
+func (s *authServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	ioutil.ReadAll(r.Body)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+s.token {
+		atomic.AddInt32(&s.rejected, 1)
+		w.WriteHeader(http.StatusForbidden)
+	}
+}
+
+func TestCredentialsReloadOnAuthorizationFailure(t *testing.T) {
+	auth := &authServer{token: "first"}
+	server := httptest.NewServer(auth)
+	defer server.Close()
+
+	credentials := writeCredentialsFile(t, "first")
+	defer os.Remove(credentials)
+
+	us := NewUploads()
+	cfg := &UploadableConfig{CredentialsFile: credentials, CredentialsRefresh: Duration(time.Hour)}
+
+	uploadWithCredentials(t, us, cfg, server.URL, StateSuccess)
+
+	// credentials are rotated - the cached ones are rejected and reloaded
+	auth.setToken("second")
+	writeCredentials(t, credentials, "second")
+
+	uploadWithCredentials(t, us, cfg, server.URL, StateSuccess)
+	assertEquals(t, int32(1), atomic.LoadInt32(&auth.rejected))
+
+	// credentials are revoked - reloaded ones are still invalid
+	auth.setToken("third")
+
+	uploadWithCredentials(t, us, cfg, server.URL, StateFailed)
+}
+
+func TestCredentialsPeriodicReload(t *testing.T) {
+	auth := &authServer{token: "first"}
+	server := httptest.NewServer(auth)
+	defer server.Close()
+
+	credentials := writeCredentialsFile(t, "first")
+	defer os.Remove(credentials)
+
+	us := NewUploads()
+	cfg := &UploadableConfig{CredentialsFile: credentials, CredentialsRefresh: Duration(10 * time.Millisecond)}
+
+	uploadWithCredentials(t, us, cfg, server.URL, StateSuccess)
+
+	auth.setToken("second")
+	writeCredentials(t, credentials, "second")
+	time.Sleep(20 * time.Millisecond)
+
+	uploadWithCredentials(t, us, cfg, server.URL, StateSuccess)
+	assertEquals(t, int32(0), atomic.LoadInt32(&auth.rejected))
+}
+
+func TestCredentialsFileErrors(t *testing.T) {
+	us := NewUploads()
+	ids := us.AddMultiWithConfig("testUID", []string{"test.txt"}, &UploadableConfig{CredentialsFile: "non-existing.json"}, nil)
+
+	if err := us.Get(ids[0]).start(map[string]string{uploaders.URLProp: "http://localhost"}); err == nil {
+		t.Error("error for missing credentials file expected")
+	}
+}
+
+func TestCredentialsReloadBeforeExpiration(t *testing.T) {
+	credentials := writeCredentialsFile(t, "first")
+	defer os.Remove(credentials)
+
+	writeOptions(t, credentials, map[string]string{"key": "first",
+		uploaders.CredentialsExpirationProp: time.Now().Add(10 * time.Minute).Format(time.RFC3339)})
+
+	store := newCredentialsStore(credentials, time.Hour, 5*time.Minute)
+	options, err := store.get()
+	assertNoError(t, err)
+	assertEquals(t, "first", options["key"])
+
+	writeOptions(t, credentials, map[string]string{"key": "second"})
+	options, err = store.get()
+	assertNoError(t, err)
+	assertEquals(t, "first", options["key"]) // not expiring within the margin yet
+
+	store.margin = 15 * time.Minute
+	options, err = store.get()
+	assertNoError(t, err)
+	assertEquals(t, "second", options["key"])
+	assertEquals(t, true, store.expiresAt().IsZero())
+}
+
+type testCredentialsRefresher struct {
+	mutex     sync.Mutex
+	refreshed []string
+	times     []time.Time
+}
+
+func (r *testCredentialsRefresher) RefreshCredentials(options map[string]string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.refreshed = append(r.refreshed, options["key"])
+	r.times = append(r.times, time.Now())
+
+	return nil
+}
+
+func (r *testCredentialsRefresher) get() ([]string, []time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.refreshed, r.times
+}
+
+func TestCredentialsKeepFresh(t *testing.T) {
+	credentials := writeCredentialsFile(t, "first")
+	defer os.Remove(credentials)
+
+	expiration := time.Now().Add(time.Second)
+	writeOptions(t, credentials, map[string]string{"key": "first",
+		uploaders.CredentialsExpirationProp: expiration.Format(time.RFC3339Nano)})
+
+	store := newCredentialsStore(credentials, time.Hour, 700*time.Millisecond)
+	store.retry = 50 * time.Millisecond
+	_, err := store.get()
+	assertNoError(t, err)
+
+	refresher := &testCredentialsRefresher{}
+	done := make(chan struct{})
+	go store.keepFresh(refresher, done)
+
+	// the credentials are not renewed yet - the refresh is retried
+	time.Sleep(400 * time.Millisecond)
+	writeOptions(t, credentials, map[string]string{"key": "second",
+		uploaders.CredentialsExpirationProp: time.Now().Add(time.Hour).Format(time.RFC3339)})
+
+	for i := 0; i < 50; i++ {
+		if refreshed, _ := refresher.get(); len(refreshed) > 0 && refreshed[len(refreshed)-1] == "second" {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	close(done)
+
+	refreshed, times := refresher.get()
+	if len(refreshed) < 2 || refreshed[0] != "first" || refreshed[len(refreshed)-1] != "second" {
+		t.Fatalf("credentials expected to be refreshed with 'first', until renewed with 'second', but were %v", refreshed)
+	}
+	if last := times[len(times)-1]; !last.Before(expiration) {
+		t.Errorf("credentials expected to be refreshed before %v, but were at %v", expiration, last)
+	}
+}
+
+// TestCredentialsRefreshDuringUpload uploads to Azure with a short-lived shared access signature. The storage
+// responds slowly to the blob upload, after which the checksum manifest is uploaded with the refreshed signature.
+func TestCredentialsRefreshDuringUpload(t *testing.T) {
+	expiration := time.Now().Add(1500 * time.Millisecond).UTC()
+
+	var mutex sync.Mutex
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		ioutil.ReadAll(r.Body)
+
+		query := r.URL.Query()
+		mutex.Lock()
+		signatures = append(signatures, query.Get("sig"))
+		first := len(signatures) == 1
+		mutex.Unlock()
+
+		if se, err := time.Parse(time.RFC3339, query.Get("se")); err != nil || time.Now().After(se) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if first {
+			time.Sleep(2 * time.Second)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	credentials := writeCredentialsFile(t, "first")
+	defer os.Remove(credentials)
+	writeOptions(t, credentials, map[string]string{
+		uploaders.AzureSAS: "se=" + url.QueryEscape(expiration.Format(time.RFC3339Nano)) + "&sig=first",
+	})
+
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	cfg := &UploadableConfig{
+		CredentialsFile:          credentials,
+		CredentialsRefresh:       Duration(time.Hour),
+		CredentialsRefreshMargin: Duration(time.Second),
+		Checksum:                 true,
+	}
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+
+	// the signature is renewed, while the blob is uploaded
+	time.AfterFunc(200*time.Millisecond, func() {
+		writeOptions(t, credentials, map[string]string{
+			uploaders.AzureSAS: "se=" + url.QueryEscape(time.Now().Add(time.Hour).UTC().Format(time.RFC3339)) + "&sig=second",
+		})
+	})
+
+	assertNoError(t, us.Get(ids[0]).start(map[string]string{
+		StorageProvider:                 uploaders.StorageProviderAzure,
+		uploaders.AzureEndpoint:         server.URL + "/",
+		uploaders.AzureContainerName:    "test",
+		uploaders.AzureChecksumLocation: uploaders.ChecksumLocationManifest,
+	}))
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assertEquals(t, []string{"first", "second"}, signatures)
+}
+
+func uploadWithCredentials(t *testing.T, us *Uploads, cfg *UploadableConfig, url string, expected string) {
+	t.Helper()
+
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+
+	startUploads(t, us, ids, url)
+
+	l.waitFinish()
+	l.assertStatusState(expected)
+}
+
+func writeCredentialsFile(t *testing.T, token string) string {
+	f, err := os.CreateTemp(".", "credentials")
+	assertNoError(t, err)
+	f.Close()
+
+	writeCredentials(t, f.Name(), token)
+
+	return f.Name()
+}
+
+func writeCredentials(t *testing.T, file string, token string) {
+	content := fmt.Sprintf(`{"%sAuthorization": "Bearer %s"}`, uploaders.HeadersPrefix, token)
+
+	assertNoError(t, os.WriteFile(file, []byte(content), 0600))
+}
+
+func writeOptions(t *testing.T, file string, options map[string]string) {
+	data, err := json.Marshal(options)
+	assertNoError(t, err)
+
+	assertNoError(t, os.WriteFile(file, data, 0600))
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+)
+
+// Probability is a number between 0 and 1, which can be specified as a decimal fraction (e.g. '0.001')
+// or in percent (e.g. '0.1%')
+type Probability float64
+
+// UnmarshalJSON un-marshals Probability from JSON number or string
+func (p *Probability) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch value := v.(type) {
+	case float64:
+		return p.Set(strconv.FormatFloat(value, 'g', -1, 64))
+	case string:
+		return p.Set(value)
+	default:
+		return errors.New("invalid probability")
+	}
+}
+
+// Set implements flag.Value Set method
+func (p *Probability) Set(value string) error {
+	s := strings.TrimSpace(value)
+
+	scale := 1.0
+	if strings.HasSuffix(s, "%") {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+		scale = 100
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 || v/scale > 1 {
+		return fmt.Errorf("invalid probability '%s'", value)
+	}
+
+	*p = Probability(v / scale)
+
+	return nil
+}
+
+func (p Probability) String() string {
+	return strconv.FormatFloat(float64(p), 'g', -1, 64)
+}
+
+// dedupState is the persisted state of the deduplication filter
+type dedupState struct {
+	Bits     uint64    `json:"bits"`
+	Hashes   uint32    `json:"hashes"`
+	Rotated  time.Time `json:"rotated"`
+	Current  []byte    `json:"current"`
+	Previous []byte    `json:"previous"`
+}
+
+// dedupFilter remembers the recently uploaded files in a pair of bloom filters with fixed memory, regardless
+// of the number of files. The files are recorded in the current filter, which replaces the previous one, once
+// the window elapses, so uploaded files are remembered for at least the window and at most twice the window.
+// Files are identified by their path, size and modification time, so modified files are not remembered.
+// A file, which was not uploaded, is remembered by mistake with at most the configured false positive rate.
+type dedupFilter struct {
+	window time.Duration
+	bits   uint64 // size of each filter
+	hashes uint32 // number of bits set per file
+
+	rotated  time.Time // when the current filter replaced the previous one
+	current  []byte
+	previous []byte
+
+	file   string        // state file, empty if the filter is not persisted
+	maxAge time.Duration // maximum age of the restored state file, zero if not limited
+	dirty  bool          // changed since last saved
+
+	mutex     sync.Mutex
+	saving    sync.Mutex  // serializes the saves, so an older state never replaces a newer one
+	saveTimer *time.Timer // pending background save, nil if none
+}
+
+// dedupSaveDelay is the delay of the background saves of the deduplication filter, so the changes of the uploads,
+// finished within the delay, are saved at once
+const dedupSaveDelay = time.Second
+
+// newDedupFilter creates a filter, sized for the given number of files per window, with the given false positive
+// rate. The filter state is restored from the given state file (if not empty), if it exists and was saved with
+// the same filter size and is not older than the given maximum age (if not zero). The state file is compacted,
+// if the restored filters aged out while not running.
+func newDedupFilter(window time.Duration, capacity int, rate float64, file string, maxAge time.Duration) (*dedupFilter, error) {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	// files are looked up in both filters, so each one has half of the false positive rate
+	bits := uint64(math.Ceil(-float64(capacity) * math.Log(rate/2) / (math.Ln2 * math.Ln2)))
+	hashes := uint32(math.Max(1, math.Round(float64(bits)/float64(capacity)*math.Ln2)))
+
+	f := &dedupFilter{
+		window:   window,
+		bits:     bits,
+		hashes:   hashes,
+		rotated:  time.Now(),
+		current:  make([]byte, (bits+7)/8),
+		previous: make([]byte, (bits+7)/8),
+		file:     file,
+		maxAge:   maxAge,
+	}
+
+	if file != "" {
+		if err := f.load(); err != nil {
+			return nil, err
+		}
+		if err := f.save(); err != nil {
+			logger.Warnf("failed to compact deduplication state file '%s': %v", file, err)
+		}
+	}
+
+	return f, nil
+}
+
+// load restores the filter state from the state file, if it exists. A state with different filter size or older
+// than the maximum age is discarded. The restored filters are rotated by the windows, elapsed while not running.
+func (f *dedupFilter) load() error {
+	info, err := os.Stat(f.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read deduplication state file '%s': %w", f.file, err)
+	}
+
+	if age := time.Since(info.ModTime()); f.maxAge > 0 && age > f.maxAge {
+		logger.Warnf("deduplication state file '%s' discarded - saved %v ago", f.file, age.Round(time.Second))
+		f.dirty = true // rewritten empty
+		return nil
+	}
+
+	data, err := os.ReadFile(f.file)
+	if err != nil {
+		return fmt.Errorf("failed to read deduplication state file '%s': %w", f.file, err)
+	}
+
+	state := &dedupState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return fmt.Errorf("invalid deduplication state file '%s': %w", f.file, err)
+	}
+
+	if state.Bits != f.bits || state.Hashes != f.hashes ||
+		len(state.Current) != len(f.current) || len(state.Previous) != len(f.previous) {
+		logger.Warnf("deduplication state file '%s' discarded - the filter size is changed", f.file)
+		return nil
+	}
+
+	f.rotated, f.current, f.previous = state.Rotated, state.Current, state.Previous
+	f.rotate(time.Now())
+
+	return nil
+}
+
+// save writes the filter state to the state file, if changed since last saved. The state file is replaced atomically.
+// Concurrent saves are serialized, so the state file is replaced with the latest state.
+func (f *dedupFilter) save() error {
+	f.saving.Lock()
+	defer f.saving.Unlock()
+
+	f.mutex.Lock()
+	if f.file == "" || !f.dirty {
+		f.mutex.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(&dedupState{f.bits, f.hashes, f.rotated, f.current, f.previous})
+	f.dirty = false
+	f.mutex.Unlock()
+
+	if err == nil {
+		err = writeStateFile(f.file, data)
+	}
+	if err != nil {
+		f.mutex.Lock()
+		f.dirty = true // retried with the next save
+		f.mutex.Unlock()
+	}
+
+	return err
+}
+
+// saveLater saves the filter state in background after the save delay, unless a save is already pending.
+// Does not block, so it can be called by the upload status listeners.
+func (f *dedupFilter) saveLater() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == "" || f.saveTimer != nil {
+		return
+	}
+
+	f.saveTimer = time.AfterFunc(dedupSaveDelay, func() {
+		f.mutex.Lock()
+		f.saveTimer = nil
+		f.mutex.Unlock()
+
+		if err := f.save(); err != nil {
+			logger.Errorf("failed to save the deduplication state file: %v", err)
+		}
+	})
+}
+
+// writeStateFile replaces the given state file with the given data, written to a temporary file in the same directory
+func writeStateFile(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after successful rename
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// add records the given uploaded file
+func (f *dedupFilter) add(path string, info os.FileInfo) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.rotate(time.Now())
+	f.locations(path, info, func(bit uint64) bool {
+		f.current[bit/8] |= 1 << (bit % 8)
+		return true
+	})
+	f.dirty = true
+}
+
+// contains returns true, if the given file was recorded within the window, or by mistake with the false positive rate
+func (f *dedupFilter) contains(path string, info os.FileInfo) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.rotate(time.Now())
+
+	return f.isSet(f.current, path, info) || f.isSet(f.previous, path, info)
+}
+
+// isSet returns true, if all bits of the given file are set in the given filter
+func (f *dedupFilter) isSet(filter []byte, path string, info os.FileInfo) bool {
+	return f.locations(path, info, func(bit uint64) bool {
+		return filter[bit/8]&(1<<(bit%8)) != 0
+	})
+}
+
+// rotate replaces the previous filter with the current one, once the window elapses. Both filters are cleared,
+// if the window elapsed twice. The rotation time is moved by whole windows, so a file is remembered for at most
+// twice the window, regardless of when the filter is rotated.
+func (f *dedupFilter) rotate(now time.Time) {
+	elapsed := now.Sub(f.rotated)
+	if elapsed < f.window {
+		return
+	}
+
+	windows := elapsed / f.window
+	if windows >= 2 {
+		f.previous = make([]byte, len(f.current)) // the current filter is older than the window
+	} else {
+		f.previous = f.current
+	}
+	f.current = make([]byte, len(f.previous))
+	f.rotated = f.rotated.Add(f.window * windows)
+	f.dirty = true
+}
+
+// locations calls the given function with the bit locations of the given file, while it returns true.
+// The locations are derived from two hashes of the file path, size and modification time (double hashing).
+func (f *dedupFilter) locations(path string, info os.FileInfo, visit func(bit uint64) bool) bool {
+	h := fnv.New128a()
+	h.Write([]byte(path))
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[:8], uint64(info.Size()))
+	binary.LittleEndian.PutUint64(buf[8:], uint64(info.ModTime().UnixNano()))
+	h.Write(buf[:])
+
+	sum := h.Sum(nil)
+	h1, h2 := binary.LittleEndian.Uint64(sum[:8]), binary.LittleEndian.Uint64(sum[8:])|1
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		if !visit((h1 + i*h2) % f.bits) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,351 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// dedupFileInfo is the file info of a file, which does not exist
+type dedupFileInfo struct {
+	os.FileInfo
+	size    int64
+	modTime time.Time
+}
+
+func (i *dedupFileInfo) Size() int64        { return i.size }
+func (i *dedupFileInfo) ModTime() time.Time { return i.modTime }
+
+func TestDedupFilterAccuracy(t *testing.T) {
+	const capacity = 10000
+	const rate = 0.01
+
+	f, err := newDedupFilter(time.Hour, capacity, rate, "", 0)
+	assertNoError(t, err)
+
+	modTime := time.Now()
+	for i := 0; i < capacity; i++ {
+		f.add(fmt.Sprintf("/var/log/uploaded-%d.log", i), &dedupFileInfo{size: int64(i), modTime: modTime})
+	}
+
+	for i := 0; i < capacity; i++ {
+		if !f.contains(fmt.Sprintf("/var/log/uploaded-%d.log", i), &dedupFileInfo{size: int64(i), modTime: modTime}) {
+			t.Fatalf("uploaded file %d not remembered", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10*capacity; i++ {
+		if f.contains(fmt.Sprintf("/var/log/new-%d.log", i), &dedupFileInfo{size: int64(i), modTime: modTime}) {
+			falsePositives++
+		}
+	}
+	if actual := float64(falsePositives) / (10 * capacity); actual > 1.5*rate {
+		t.Errorf("false positive rate %v exceeds the configured rate %v", actual, rate)
+	}
+
+	modified := 0
+	for i := 0; i < capacity; i++ {
+		if f.contains(fmt.Sprintf("/var/log/uploaded-%d.log", i), &dedupFileInfo{size: int64(i), modTime: modTime.Add(time.Second)}) {
+			modified++
+		}
+	}
+	if actual := float64(modified) / capacity; actual > 1.5*rate {
+		t.Errorf("modified files are skipped with rate %v, exceeding the configured rate %v", actual, rate)
+	}
+}
+
+func TestDedupFilterWindow(t *testing.T) {
+	f, err := newDedupFilter(time.Hour, 100, 0.01, "", 0)
+	assertNoError(t, err)
+
+	info := &dedupFileInfo{size: 10, modTime: time.Now()}
+	f.add("uploaded.log", info)
+
+	f.rotate(f.rotated.Add(time.Hour))
+	assertEquals(t, true, f.contains("uploaded.log", info))
+
+	f.rotate(f.rotated.Add(time.Hour))
+	assertEquals(t, false, f.contains("uploaded.log", info))
+
+	f.add("uploaded.log", info)
+	f.rotate(f.rotated.Add(2 * time.Hour)) // both filters are outdated
+	assertEquals(t, false, f.contains("uploaded.log", info))
+}
+
+func TestDedupFilterExpiry(t *testing.T) {
+	f, err := newDedupFilter(time.Hour, 100, 0.01, "", 0)
+	assertNoError(t, err)
+
+	start := f.rotated
+	info := &dedupFileInfo{size: 10, modTime: time.Now()}
+	f.add("uploaded.log", info)
+
+	remembered := func() bool {
+		return f.isSet(f.current, "uploaded.log", info) || f.isSet(f.previous, "uploaded.log", info)
+	}
+
+	// rotated late, but aligned to the window
+	f.rotate(start.Add(90 * time.Minute))
+	assertEquals(t, start.Add(time.Hour), f.rotated)
+	assertEquals(t, true, remembered())
+
+	f.rotate(start.Add(2*time.Hour - time.Nanosecond))
+	assertEquals(t, true, remembered())
+
+	// expired at exactly twice the window
+	f.rotate(start.Add(2 * time.Hour))
+	assertEquals(t, start.Add(2*time.Hour), f.rotated)
+	assertEquals(t, false, remembered())
+
+	// a promoted filter, older than the window, is cleared
+	f.add("uploaded.log", info)
+	f.rotate(f.rotated.Add(2*time.Hour + 30*time.Minute))
+	assertEquals(t, start.Add(4*time.Hour), f.rotated)
+	assertEquals(t, false, remembered())
+}
+
+func TestDedupFilterStateFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "dedup.json")
+	info := &dedupFileInfo{size: 10, modTime: time.Now()}
+
+	f, err := newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	f.add("uploaded.log", info)
+	assertNoError(t, f.save())
+
+	f, err = newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	assertEquals(t, true, f.contains("uploaded.log", info))
+
+	// the state of a filter with different size is discarded
+	f, err = newDedupFilter(time.Hour, 1000, 0.01, file, 0)
+	assertNoError(t, err)
+	assertEquals(t, false, f.contains("uploaded.log", info))
+
+	assertNoError(t, os.WriteFile(file, []byte("{"), 0666))
+	_, err = newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertError(t, err)
+}
+
+func TestDedupFilterStateFileCompaction(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "dedup.json")
+	info := &dedupFileInfo{size: 10, modTime: time.Now()}
+
+	f, err := newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	f.add("aged.log", info)
+	f.rotated = time.Now().Add(-90 * time.Minute) // recorded in the previous window after the next rotation
+	f.dirty = true
+	assertNoError(t, f.save())
+
+	// the state is rotated and rewritten on restore, the aged file is kept for another window
+	f, err = newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	assertEquals(t, true, f.contains("aged.log", info))
+	f.add("active.log", info)
+	f.rotated = time.Now().Add(-90 * time.Minute)
+	f.dirty = true
+	assertNoError(t, f.save())
+
+	// the aged file is removed, while the active one is preserved
+	f, err = newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	assertEquals(t, false, f.contains("aged.log", info))
+	assertEquals(t, true, f.contains("active.log", info))
+
+	data, err := os.ReadFile(file)
+	assertNoError(t, err)
+	state := &dedupState{}
+	assertNoError(t, json.Unmarshal(data, state))
+	if time.Since(state.Rotated) >= time.Hour {
+		t.Errorf("state file not compacted on restore, rotated at %v", state.Rotated)
+	}
+
+	// the state is cleared, when aged out twice
+	f.rotated = time.Now().Add(-3 * time.Hour)
+	f.dirty = true
+	assertNoError(t, f.save())
+
+	f, err = newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	assertEquals(t, false, f.contains("active.log", info))
+}
+
+func TestDedupFilterStateMaxAge(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "dedup.json")
+	info := &dedupFileInfo{size: 10, modTime: time.Now()}
+
+	f, err := newDedupFilter(24*time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	f.add("uploaded.log", info)
+	assertNoError(t, f.save())
+
+	saved := time.Now().Add(-2 * time.Hour)
+	assertNoError(t, os.Chtimes(file, saved, saved))
+
+	f, err = newDedupFilter(24*time.Hour, 100, 0.01, file, 3*time.Hour)
+	assertNoError(t, err)
+	assertEquals(t, true, f.contains("uploaded.log", info))
+
+	// an older state file is discarded and rewritten empty
+	f, err = newDedupFilter(24*time.Hour, 100, 0.01, file, time.Hour)
+	assertNoError(t, err)
+	assertEquals(t, false, f.contains("uploaded.log", info))
+
+	f, err = newDedupFilter(24*time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	assertEquals(t, false, f.contains("uploaded.log", info))
+}
+
+func TestDedupFilterConcurrentSaves(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "dedup.json")
+	info := &dedupFileInfo{size: 10, modTime: time.Now()}
+
+	f, err := newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			f.add(fmt.Sprintf("uploaded-%d.log", i), info)
+			assertNoError(t, f.save())
+		}(i)
+	}
+	wg.Wait()
+
+	f, err = newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	for i := 0; i < 20; i++ {
+		assertEquals(t, true, f.contains(fmt.Sprintf("uploaded-%d.log", i), info))
+	}
+}
+
+func TestDedupFilterSaveLater(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "dedup.json")
+	info := &dedupFileInfo{size: 10, modTime: time.Now()}
+
+	f, err := newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	f.add("first.log", info)
+	f.saveLater()
+	f.add("second.log", info)
+	f.saveLater() // saved with the pending save
+
+	_, err = os.Stat(file)
+	assertEquals(t, true, os.IsNotExist(err))
+
+	deadline := time.Now().Add(5 * dedupSaveDelay)
+	for {
+		if _, err := os.Stat(file); err == nil { // replaced atomically
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("filter state not saved in background")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	f, err = newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	assertEquals(t, true, f.contains("first.log", info))
+	assertEquals(t, true, f.contains("second.log", info))
+}
+
+func TestDedupFilterSaveError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "missing", "dedup.json")
+	info := &dedupFileInfo{size: 10, modTime: time.Now()}
+
+	f, err := newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	f.add("uploaded.log", info)
+	assertError(t, f.save())
+
+	// the state is saved with the next save, once the directory exists
+	assertNoError(t, os.Mkdir(filepath.Dir(file), 0755))
+	assertNoError(t, f.save())
+
+	f, err = newDedupFilter(time.Hour, 100, 0.01, file, 0)
+	assertNoError(t, err)
+	assertEquals(t, true, f.contains("uploaded.log", info))
+}
+
+func TestDedupSelection(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	stateFile := filepath.Join(t.TempDir(), "dedup.json")
+
+	uploaded := addTestFile(t, "uploaded.log")
+	modified := addTestFile(t, "modified.log")
+	pending := addTestFile(t, "pending.log")
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.log"), ModeLax)
+	defer f.Disconnect()
+
+	var err error
+	f.uploadable.dedup, err = newDedupFilter(time.Hour, 100, 0.001, stateFile, 0)
+	assertNoError(t, err)
+
+	for _, path := range []string{uploaded, modified} {
+		info, err := os.Stat(path)
+		assertNoError(t, err)
+		f.uploadable.fileUploaded(path, info.Size())
+	}
+	setModTime(t, modified, time.Now().Add(-time.Minute))
+
+	checkUploadTrigger(t, f, client, nil, modified, pending)
+
+	// remembered across restarts
+	f.uploadable.saveDedup()
+	f.uploadable.dedup, err = newDedupFilter(time.Hour, 100, 0.001, stateFile, 0)
+	assertNoError(t, err)
+
+	checkUploadTrigger(t, f, client, nil, modified, pending)
+}
+
+func TestProbability(t *testing.T) {
+	for value, expected := range map[string]Probability{"0.001": 0.001, "0.1%": 0.001, " 5 % ": 0.05, "0": 0, "1": 1} {
+		var p Probability
+		assertNoError(t, p.Set(value))
+		assertEquals(t, expected, p)
+	}
+
+	for _, value := range []string{"", "-0.1", "1.5", "200%", "one"} {
+		var p Probability
+		assertError(t, p.Set(value))
+	}
+
+	var p Probability
+	assertNoError(t, p.Set("0.25"))
+	assertEquals(t, "0.25", p.String())
+
+	var cfg struct {
+		Number Probability `json:"number"`
+		Text   Probability `json:"text"`
+	}
+	assertNoError(t, json.Unmarshal([]byte(`{"number":0.02,"text":"2%"}`), &cfg))
+	assertEquals(t, Probability(0.02), cfg.Number)
+	assertEquals(t, Probability(0.02), cfg.Text)
+	assertError(t, json.Unmarshal([]byte(`{"number":true}`), &cfg))
+}
@@ -41,6 +41,11 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MarshalJSON marshals duration as string, e.g. '1m30s', as expected by UnmarshalJSON
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
 // Set duration set from string, used for flag set
 func (d *Duration) Set(s string) error {
 	v, err := time.ParseDuration(s)
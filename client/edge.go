@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"sync"
 	"time"
 
 	"github.com/eclipse-kanto/file-upload/logger"
@@ -38,6 +39,20 @@ type BrokerConfig struct {
 	CaCert   string `json:"caCert,omitempty" descr:"A PEM encoded CA certificates 'file' for MQTT broker connection"`
 	Cert     string `json:"cert,omitempty" descr:"A PEM encoded certificate 'file' for MQTT broker connection"`
 	Key      string `json:"key,omitempty" descr:"A PEM encoded unencrypted private key 'file' for MQTT broker connection"`
+
+	KeepAlive            Duration `json:"keepAlive,omitempty" def:"30s" descr:"Keep alive period of the MQTT broker connection. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+	ConnectRetry         bool     `json:"connectRetry,omitempty" def:"false" descr:"Retry the initial MQTT broker connection, instead of failing on startup, if the broker is not available"`
+	ConnectRetryInterval Duration `json:"connectRetryInterval,omitempty" def:"30s" descr:"Interval between the initial MQTT broker connection attempts, when connection retry is enabled. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+	MaxReconnectInterval Duration `json:"maxReconnectInterval,omitempty" def:"10m" descr:"Maximum interval between the attempts to reconnect to the MQTT broker, after the connection is lost. The interval is doubled after each failed attempt, up to this maximum. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	WillTopic    string `json:"willTopic,omitempty" def:"" descr:"Topic of the MQTT last will message, published by the broker when the file upload connection is lost unexpectedly, so the backend is notified that the service is unavailable. No last will is set if empty."`
+	WillPayload  string `json:"willPayload,omitempty" def:"" descr:"Payload of the MQTT last will message"`
+	WillQoS      int    `json:"willQos,omitempty" def:"1" descr:"Quality of service of the MQTT last will message. Allowed values are 0, 1 and 2"`
+	WillRetained bool   `json:"willRetained,omitempty" def:"false" descr:"Retain the MQTT last will message"`
+
+	BrokerTLSMinVersion   string       `json:"brokerTlsMinVersion,omitempty" def:"1.2" descr:"Minimum TLS version of the MQTT broker connection. Allowed values are '1.2' and '1.3'"`
+	BrokerTLSMaxVersion   string       `json:"brokerTlsMaxVersion,omitempty" def:"1.3" descr:"Maximum TLS version of the MQTT broker connection. Allowed values are '1.2' and '1.3'. Should not be lower than 'brokerTlsMinVersion'"`
+	BrokerTLSCipherSuites CipherSuites `json:"brokerTlsCipherSuites,omitempty" def:"" descr:"Cipher suites, allowed for the TLS 1.2 connection to the MQTT broker, e.g. 'TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256'. Only secure cipher suites are supported, all of them are allowed by default. TLS 1.3 cipher suites are not configurable. Specified as a JSON array in the configuration file and as a comma-separated list on the command line."`
 }
 
 // EdgeConfiguration represents local Edge Thing configuration - its device, tenant and policy identifiers.
@@ -52,6 +67,8 @@ type EdgeConnector struct {
 	mqttClient MQTT.Client
 	cfg        *EdgeConfiguration
 	edgeClient EdgeClient
+
+	mutex sync.Mutex // guards the edge configuration and the edge client notifications
 }
 
 // EdgeClient receives notifications of Edge Thing configuration changes from EdgeConnector
@@ -60,8 +77,76 @@ type EdgeClient interface {
 	Disconnect()
 }
 
+// EdgeConnectionListener can be implemented by EdgeClient instances, which should be notified when the MQTT broker
+// connection is lost or re-established, while they are connected
+type EdgeConnectionListener interface {
+	ConnectionChanged(connected bool)
+}
+
 // NewEdgeConnector create EdgeConnector with the given BrokerConfig for the given EdgeClient
 func NewEdgeConnector(cfg *BrokerConfig, ecl EdgeClient) (*EdgeConnector, error) {
+	opts, err := newClientOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &EdgeConnector{edgeClient: ecl}
+	opts.SetConnectionLostHandler(func(client MQTT.Client, err error) {
+		logger.Warnf("connection to MQTT broker lost: %v", err)
+		p.connectionChanged(false)
+	}).SetReconnectingHandler(func(client MQTT.Client, opts *MQTT.ClientOptions) {
+		logger.Debug("reconnecting to MQTT broker")
+	}).SetOnConnectHandler(func(client MQTT.Client) {
+		p.connectionChanged(true)
+	})
+
+	p.mqttClient = MQTT.NewClient(opts)
+	if token := p.mqttClient.Connect(); token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+
+	if token := p.mqttClient.Subscribe(topic, 1, func(client MQTT.Client, message MQTT.Message) {
+		localCfg := &EdgeConfiguration{}
+		err := json.Unmarshal(message.Payload(), localCfg)
+		if err != nil {
+			logger.Errorf("could not unmarshal edge configuration: %v", err)
+			return
+		}
+
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		if p.cfg == nil || *localCfg != *p.cfg {
+			logger.Infof("new edge configuration received: %v", localCfg)
+			if p.cfg != nil {
+				p.edgeClient.Disconnect()
+			}
+			p.cfg = localCfg
+			ecl.Connect(p.mqttClient, p.cfg)
+		}
+
+	}); token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+
+	if token := p.mqttClient.Publish("edge/thing/request", 1, false, ""); token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+
+	return p, nil
+}
+
+// newClientOptions creates the MQTT client options for the broker connection, including TLS, credentials and last will
+func newClientOptions(cfg *BrokerConfig) (*MQTT.ClientOptions, error) {
+	minVersion, maxVersion, err := uploaders.ParseTLSVersions(cfg.BrokerTLSMinVersion, cfg.BrokerTLSMaxVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := uploaders.ParseCipherSuites(cfg.BrokerTLSCipherSuites.String())
+	if err != nil {
+		return nil, err
+	}
+
 	var tlsConfig *tls.Config
 	var certificates []tls.Certificate
 	var caCertPool *x509.CertPool
@@ -85,62 +170,56 @@ func NewEdgeConnector(cfg *BrokerConfig, ecl EdgeClient) (*EdgeConnector, error)
 			InsecureSkipVerify: false,
 			RootCAs:            caCertPool,
 			Certificates:       certificates,
-			MinVersion:         tls.VersionTLS12,
-			MaxVersion:         tls.VersionTLS13,
-			CipherSuites:       uploaders.SupportedCipherSuites(),
+			MinVersion:         minVersion,
+			MaxVersion:         maxVersion,
+			CipherSuites:       cipherSuites,
 		}
 	}
 	opts := MQTT.NewClientOptions().
 		AddBroker(cfg.Broker).
 		SetClientID(uuid.New().String()).
-		SetKeepAlive(30 * time.Second).
+		SetKeepAlive(time.Duration(cfg.KeepAlive)).
 		SetCleanSession(true).
-		SetAutoReconnect(true)
+		SetAutoReconnect(true).
+		SetMaxReconnectInterval(time.Duration(cfg.MaxReconnectInterval)).
+		SetConnectRetry(cfg.ConnectRetry).
+		SetConnectRetryInterval(time.Duration(cfg.ConnectRetryInterval))
 	if tlsConfig != nil {
 		opts = opts.SetTLSConfig(tlsConfig)
 	}
 	if len(cfg.Username) > 0 {
 		opts = opts.SetUsername(cfg.Username).SetPassword(cfg.Password)
 	}
-
-	p := &EdgeConnector{mqttClient: MQTT.NewClient(opts), edgeClient: ecl}
-	if token := p.mqttClient.Connect(); token.Wait() && token.Error() != nil {
-		return nil, token.Error()
+	if len(cfg.WillTopic) > 0 {
+		opts = opts.SetWill(cfg.WillTopic, cfg.WillPayload, byte(cfg.WillQoS), cfg.WillRetained)
 	}
 
-	if token := p.mqttClient.Subscribe(topic, 1, func(client MQTT.Client, message MQTT.Message) {
-		localCfg := &EdgeConfiguration{}
-		err := json.Unmarshal(message.Payload(), localCfg)
-		if err != nil {
-			logger.Errorf("could not unmarshal edge configuration: %v", err)
-			return
-		}
+	return opts, nil
+}
 
-		if p.cfg == nil || *localCfg != *p.cfg {
-			logger.Infof("new edge configuration received: %v", localCfg)
-			if p.cfg != nil {
-				p.edgeClient.Disconnect()
-			}
-			p.cfg = localCfg
-			ecl.Connect(p.mqttClient, p.cfg)
-		}
+// connectionChanged notifies the edge client for MQTT broker connection changes, if it is connected
+// and interested in them
+func (p *EdgeConnector) connectionChanged(connected bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 
-	}); token.Wait() && token.Error() != nil {
-		return nil, token.Error()
+	if p.cfg == nil {
+		return // not connected yet or already closed
 	}
 
-	if token := p.mqttClient.Publish("edge/thing/request", 1, false, ""); token.Wait() && token.Error() != nil {
-		return nil, token.Error()
+	if listener, ok := p.edgeClient.(EdgeConnectionListener); ok {
+		listener.ConnectionChanged(connected)
 	}
-
-	return p, nil
 }
 
 // Close the EdgeConnector
 func (p *EdgeConnector) Close() {
+	p.mutex.Lock()
 	if p.cfg != nil {
 		p.edgeClient.Disconnect()
+		p.cfg = nil
 	}
+	p.mutex.Unlock()
 
 	p.mqttClient.Unsubscribe(topic)
 	p.mqttClient.Disconnect(200)
@@ -0,0 +1,176 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+)
+
+type testEdgeClient struct {
+	connected []bool
+	closed    bool
+}
+
+func (c *testEdgeClient) Connect(client MQTT.Client, cfg *EdgeConfiguration) {}
+
+func (c *testEdgeClient) Disconnect() {
+	c.closed = true
+}
+
+func (c *testEdgeClient) ConnectionChanged(connected bool) {
+	c.connected = append(c.connected, connected)
+}
+
+func TestEdgeConnectorConnectionChanged(t *testing.T) {
+	ecl := &testEdgeClient{}
+	p := &EdgeConnector{mqttClient: newMockedClient(), edgeClient: ecl}
+
+	p.connectionChanged(true) // initial connection, the edge client is not connected yet
+	assertEquals(t, 0, len(ecl.connected))
+
+	p.cfg = &EdgeConfiguration{DeviceID: "test:device"}
+	p.connectionChanged(false)
+	p.connectionChanged(true)
+	assertEquals(t, []bool{false, true}, ecl.connected)
+
+	p.Close()
+	assertEquals(t, true, ecl.closed)
+
+	p.connectionChanged(false) // ignored after close
+	assertEquals(t, []bool{false, true}, ecl.connected)
+}
+
+func TestClientOptionsConnection(t *testing.T) {
+	cfg := &BrokerConfig{
+		Broker:               "tcp://localhost:1883",
+		KeepAlive:            Duration(45 * time.Second),
+		ConnectRetry:         true,
+		ConnectRetryInterval: Duration(15 * time.Second),
+		MaxReconnectInterval: Duration(2 * time.Minute),
+	}
+
+	opts, err := newClientOptions(cfg)
+	assertNoError(t, err)
+	assertEquals(t, int64(45), opts.KeepAlive)
+	assertEquals(t, true, opts.ConnectRetry)
+	assertEquals(t, 15*time.Second, opts.ConnectRetryInterval)
+	assertEquals(t, 2*time.Minute, opts.MaxReconnectInterval)
+	assertEquals(t, true, opts.AutoReconnect)
+}
+
+func TestClientOptionsWill(t *testing.T) {
+	cfg := &BrokerConfig{
+		Broker:       "tcp://localhost:1883",
+		WillTopic:    "edge/file-upload/availability",
+		WillPayload:  "offline",
+		WillQoS:      2,
+		WillRetained: true,
+	}
+
+	opts, err := newClientOptions(cfg)
+	assertNoError(t, err)
+	assertEquals(t, true, opts.WillEnabled)
+	assertEquals(t, cfg.WillTopic, opts.WillTopic)
+	assertEquals(t, []byte(cfg.WillPayload), opts.WillPayload)
+	assertEquals(t, byte(2), opts.WillQos)
+	assertEquals(t, true, opts.WillRetained)
+}
+
+func TestClientOptionsNoWill(t *testing.T) {
+	opts, err := newClientOptions(&BrokerConfig{Broker: "tcp://localhost:1883", Username: "testUser", Password: "testPass"})
+	assertNoError(t, err)
+	assertEquals(t, false, opts.WillEnabled)
+	assertEquals(t, "testUser", opts.Username)
+}
+
+func TestClientOptionsInvalidKeyPair(t *testing.T) {
+	_, err := newClientOptions(&BrokerConfig{Broker: "tcp://localhost:1883", Cert: "missing.crt", Key: "missing.key"})
+	assertError(t, err)
+}
+
+// writeKeyPair writes a self-signed client certificate and its private key to PEM files in a temporary directory
+func writeKeyPair(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assertNoError(t, err)
+
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assertNoError(t, err)
+
+	der, err := x509.MarshalECPrivateKey(key)
+	assertNoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	assertNoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0600))
+	assertNoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600))
+
+	return certFile, keyFile
+}
+
+func TestClientOptionsTLS(t *testing.T) {
+	cert, key := writeKeyPair(t)
+
+	opts, err := newClientOptions(&BrokerConfig{Broker: "ssl://localhost:8883", Cert: cert, Key: key})
+	assertNoError(t, err)
+	assertEquals(t, uint16(tls.VersionTLS12), opts.TLSConfig.MinVersion)
+	assertEquals(t, uint16(tls.VersionTLS13), opts.TLSConfig.MaxVersion)
+	assertEquals(t, uploaders.SupportedCipherSuites(), opts.TLSConfig.CipherSuites)
+	assertEquals(t, 1, len(opts.TLSConfig.Certificates))
+
+	opts, err = newClientOptions(&BrokerConfig{
+		Broker:                "ssl://localhost:8883",
+		Cert:                  cert,
+		Key:                   key,
+		BrokerTLSMinVersion:   uploaders.TLSVersion13,
+		BrokerTLSMaxVersion:   uploaders.TLSVersion13,
+		BrokerTLSCipherSuites: CipherSuites{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"},
+	})
+	assertNoError(t, err)
+	assertEquals(t, uint16(tls.VersionTLS13), opts.TLSConfig.MinVersion)
+	assertEquals(t, uint16(tls.VersionTLS13), opts.TLSConfig.MaxVersion)
+	assertEquals(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}, opts.TLSConfig.CipherSuites)
+
+	opts, err = newClientOptions(&BrokerConfig{Broker: "ssl://localhost:8883", Cert: cert, Key: key, BrokerTLSMaxVersion: uploaders.TLSVersion12})
+	assertNoError(t, err)
+	assertEquals(t, uint16(tls.VersionTLS12), opts.TLSConfig.MaxVersion)
+}
+
+func TestClientOptionsTLSErrors(t *testing.T) {
+	for _, cfg := range []*BrokerConfig{
+		{BrokerTLSMinVersion: "1.1"},
+		{BrokerTLSMaxVersion: "1.4"},
+		{BrokerTLSMinVersion: uploaders.TLSVersion13, BrokerTLSMaxVersion: uploaders.TLSVersion12},
+		{BrokerTLSCipherSuites: CipherSuites{"TLS_RSA_WITH_RC4_128_SHA"}},
+	} {
+		cfg.Broker = "ssl://localhost:8883"
+		if _, err := newClientOptions(cfg); err == nil {
+			t.Errorf("error expected for broker TLS configuration %+v", cfg)
+		}
+	}
+}
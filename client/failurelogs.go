@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+)
+
+// failureLogsCapacity is the number of the most recent log lines, from which the lines of a failed upload are taken
+const failureLogsCapacity = 1000
+
+// redactedValue replaces the secrets in the captured log lines
+const redactedValue = "REDACTED"
+
+var (
+	// secretAssignment matches values of secret options, query parameters and headers, e.g. 'sig=...',
+	// 'aws.secret.access.key:...' or 'https.auth.basic.password=...'
+	secretAssignment = regexp.MustCompile(`(?i)((?:sig|signature|credential|token|password|secret|key|authorization)[\w.-]*["']?\s*[:=]\s*["']?)([^\s"',&\]}]+)`)
+	// secretCredentials matches credentials in authorization header values and in URLs
+	secretCredentials = regexp.MustCompile(`(?i)(\b(?:bearer|basic)\s+)[\w.~+/=-]+|(://)[^/\s:@]+:[^/\s@]+@`)
+)
+
+// failureLogs captures the most recent log lines, so the lines mentioning a failed upload can be attached
+// to its failure status
+type failureLogs struct {
+	lines  int // maximum number of lines, attached to a failure status
+	remove func()
+
+	captured []string // ring buffer of the most recent log lines
+	next     int
+	mutex    sync.Mutex
+}
+
+func newFailureLogs(lines int) *failureLogs {
+	result := &failureLogs{lines: lines, captured: make([]string, 0, failureLogsCapacity)}
+	result.remove = logger.AddHook(result.capture)
+
+	return result
+}
+
+func (l *failureLogs) capture(level logger.LogLevel, msg string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	line := fmt.Sprintf("%s %s", logLevelName(level), msg)
+	if len(l.captured) < cap(l.captured) {
+		l.captured = append(l.captured, line)
+	} else {
+		l.captured[l.next] = line
+		l.next = (l.next + 1) % len(l.captured)
+	}
+}
+
+// get returns the last redacted log lines, which mention the upload with the given correlation ID
+// or any of its file uploads
+func (l *failureLogs) get(correlationID string) []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var result []string
+	for i := len(l.captured) - 1; i >= 0 && len(result) < l.lines; i-- {
+		line := l.captured[(l.next+i)%len(l.captured)]
+		if mentions(line, correlationID) {
+			result = append(result, redactSecrets(line))
+		}
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return result
+}
+
+// close stops capturing of log lines. Does nothing on nil failure logs.
+func (l *failureLogs) close() {
+	if l != nil {
+		l.remove()
+	}
+}
+
+// mentions returns true, if the given line contains the given correlation ID, not followed by other ID characters,
+// so 'upload-id-1' is not mentioned by 'upload-id-12', but it is mentioned by its file upload 'upload-id-1#2'
+func mentions(line string, correlationID string) bool {
+	for from := 0; ; {
+		i := strings.Index(line[from:], correlationID)
+		if i < 0 {
+			return false
+		}
+
+		end := from + i + len(correlationID)
+		if end == len(line) || !isIDChar(line[end]) {
+			return true
+		}
+		from = end
+	}
+}
+
+func isIDChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+// redactSecrets replaces the secrets in the given log line
+func redactSecrets(line string) string {
+	line = secretCredentials.ReplaceAllStringFunc(line, func(match string) string {
+		if strings.HasPrefix(match, "://") {
+			return "://" + redactedValue + "@"
+		}
+		return match[:strings.IndexAny(match, " \t")+1] + redactedValue
+	})
+
+	return secretAssignment.ReplaceAllString(line, "${1}"+redactedValue)
+}
+
+func logLevelName(level logger.LogLevel) string {
+	switch level {
+	case logger.ERROR:
+		return "ERROR"
+	case logger.WARN:
+		return "WARN"
+	case logger.INFO:
+		return "INFO"
+	case logger.DEBUG:
+		return "DEBUG"
+	default:
+		return "TRACE"
+	}
+}
@@ -15,8 +15,13 @@ package client
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/eclipse-kanto/file-upload/logger"
 	MQTT "github.com/eclipse/paho.mqtt.golang"
@@ -31,6 +36,10 @@ type FileUpload struct {
 	mode      AccessMode
 
 	uploadable *AutoUploadable
+
+	pending *coalescedTrigger // delayed trigger, into which the triggers within the trigger window are merged
+	firing  string            // correlation ID of the delayed trigger, whose upload is being started
+	mutex   sync.Mutex
 }
 
 // NewFileUpload construct FileUpload from the provided configurations
@@ -47,6 +56,8 @@ func NewFileUpload(filesGlob string, mode AccessMode, uploadableCfg *UploadableC
 		return nil, err
 	}
 
+	uploadable.info["files"] = filesGlob
+	uploadable.info["mode"] = mode.String()
 	result.uploadable = uploadable
 
 	return result, nil
@@ -57,15 +68,128 @@ func (fu *FileUpload) Connect(client MQTT.Client, edgeCfg *EdgeConfiguration) {
 	fu.uploadable.Connect(client, edgeCfg)
 }
 
+// ConnectionChanged updates the MQTT broker connection status of the FileUpload feature
+func (fu *FileUpload) ConnectionChanged(connected bool) {
+	fu.uploadable.ConnectionChanged(connected)
+}
+
 // Disconnect disconnects the FileUpload feature to the Ditto endpoint
 func (fu *FileUpload) Disconnect() {
+	fu.cancelPending()
 	fu.uploadable.Disconnect()
 }
 
+// ForceStop cancels the running uploads right away, instead of waiting for them to complete, when disconnecting
+func (fu *FileUpload) ForceStop() {
+	fu.uploadable.ForceStop()
+}
+
 // DoTrigger triggers file upload operation.
-// Can be invoked from the backend or from periodic upload tick
+// Can be invoked from the backend or from periodic upload tick.
+// With the 'dryRun' option set to 'true', the files are selected and requested, but not uploaded.
+// With trigger window configured, the upload is delayed by the window and merged with the triggers within it.
 func (fu *FileUpload) DoTrigger(correlationID string, options map[string]string) error {
+	return fu.trigger(correlationID, options, false)
+}
+
+// doTrigger selects the files and requests their upload right away
+func (fu *FileUpload) doTrigger(correlationID string, options map[string]string) error {
+	glob, listed, err := fu.getGlob(options)
+	if err != nil {
+		return err
+	}
+
+	if options[DryRunOption] == "true" {
+		logger.Infof("dry run of trigger %s - the selected files will not be uploaded", correlationID)
+	}
+
+	single := fu.uploadable.cfg.SingleUpload
+	if options["force"] == "true" {
+		single = false
+	}
+
+	if single && fu.uploadable.uploads.hasPendingUploads() {
+		return errors.New("there is an ongoing upload -  set the 'force' option to 'true' to force trigger the upload")
+	}
+
+	if dir := options[archiveDirectoryOption]; dir != "" {
+		return fu.uploadDirectory(correlationID, dir, glob, options)
+	}
+
+	files, err := fu.getFiles(glob, listed)
+	if err != nil {
+		logger.Errorf("failed to trigger upload %s: %v", correlationID, err)
+
+		return err
+	}
+
+	if len(files) == 0 {
+		logger.Infof("no files to upload for trigger %s", correlationID)
+
+		return nil
+	}
+
+	if max := fu.uploadable.cfg.MaxFilesPerUpload; max > 0 && len(files) > max {
+		err = fu.uploadable.UploadBatches(correlationID, splitBatches(files, max), options)
+	} else {
+		err = fu.uploadable.UploadFiles(correlationID, files, options)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to trigger upload %s: %v", correlationID, err)
+	}
+
+	return err
+}
+
+// splitBatches splits the given files into batches of at most the given number of files
+func splitBatches(files []string, max int) [][]string {
+	batches := make([][]string, 0, (len(files)+max-1)/max)
+	for len(files) > max {
+		batches = append(batches, files[:max])
+		files = files[max:]
+	}
+
+	return append(batches, files)
+}
+
+// ListFiles returns the files, which would be uploaded by DoTrigger with the same options, without uploading them
+func (fu *FileUpload) ListFiles(options map[string]string) ([]FileInfo, *ErrorResponse) {
+	glob, listed, err := fu.getGlob(options)
+	if err != nil {
+		return nil, &ErrorResponse{http.StatusBadRequest, ErrorCodeParameterInvalid, err.Error()}
+	}
+
+	var files []string
+	if dir := options[archiveDirectoryOption]; dir != "" {
+		files, err = fu.getDirectoryFiles(dir, glob)
+	} else {
+		files, err = fu.getFiles(glob, listed)
+	}
+
+	if err != nil {
+		return nil, &ErrorResponse{http.StatusInternalServerError, ErrorCodeExecutionFailed, err.Error()}
+	}
+
+	result := make([]FileInfo, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			logger.Warnf("skipping file '%s' - cannot get its stats: %v", file, err)
+			continue
+		}
+
+		result = append(result, FileInfo{file, info.Size()})
+	}
+
+	return result, nil
+}
+
+// getGlob returns the files glob from the options, if permitted by the access mode, or the configured one.
+// The returned flag is true if the files list should be used instead.
+func (fu *FileUpload) getGlob(options map[string]string) (string, bool, error) {
 	glob, ok := options[uploadFilesProperty]
+	listed := !ok && fu.uploadable.cfg.FilesList != ""
 
 	if !ok {
 		glob = fu.filesGlob
@@ -73,35 +197,80 @@ func (fu *FileUpload) DoTrigger(correlationID string, options map[string]string)
 		ok, err := fu.isGlobUploadPermitted(glob)
 
 		if err != nil {
-			return err
+			return "", false, err
 		}
 
 		if !ok {
-			return fmt.Errorf("uploading '%s' with mode '%s' is not permitted", glob, fu.mode)
+			return "", false, fmt.Errorf("uploading '%s' with mode '%s' is not permitted", glob, fu.mode)
 		}
 	}
 
-	if glob == "" {
-		return errors.New("upload files not specified")
+	if glob == "" && (!listed || options[archiveDirectoryOption] != "") {
+		return "", false, errors.New("upload files not specified")
 	}
 
-	single := fu.uploadable.cfg.SingleUpload
-	if options["force"] == "true" {
-		single = false
+	return glob, listed, nil
+}
+
+// getFiles returns the files from the files list or matching the glob, which are selected by the upload configuration
+func (fu *FileUpload) getFiles(glob string, listed bool) ([]string, error) {
+	var files []string
+	var err error
+	if listed {
+		files, err = fu.readFilesList()
+	} else {
+		files, err = expandGlobs(splitGlob(glob), fu.uploadable.cfg.GlobWorkers)
 	}
 
-	if single && fu.uploadable.uploads.hasPendingUploads() {
-		return errors.New("there is an ongoing upload -  set the 'force' option to 'true' to force trigger the upload")
+	if err != nil {
+		return nil, err
+	}
+
+	return fu.selectFiles(files), nil
+}
+
+// getDirectoryFiles returns the files in the given directory, which would be included in its snapshot
+func (fu *FileUpload) getDirectoryFiles(dir string, glob string) ([]string, error) {
+	include := fu.directoryFilter(glob)
+
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type().IsRegular() && include(path) {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+
+	return files, err
+}
+
+// directoryFilter returns a filter of the directory files, which match the glob and are selected by the upload configuration
+func (fu *FileUpload) directoryFilter(glob string) func(path string) bool {
+	patterns := splitGlob(glob)
+	return func(path string) bool {
+		ok, err := matchAny(patterns, path)
+		return err == nil && ok && len(fu.selectFiles([]string{path})) == 1
 	}
+}
 
-	files, err := filepath.Glob(glob)
+// uploadDirectory uploads a consistent snapshot of the files in the given directory, which match the glob
+// and are selected by the upload configuration
+func (fu *FileUpload) uploadDirectory(correlationID string, dir string, glob string, options map[string]string) error {
+	ok, err := fu.uploadable.UploadDirectory(correlationID, dir, fu.directoryFilter(glob), options)
 	if err != nil {
 		logger.Errorf("failed to trigger upload %s: %v", correlationID, err)
 
 		return err
 	}
 
-	fu.uploadable.UploadFiles(correlationID, files, options)
+	if !ok {
+		logger.Infof("no files to upload for trigger %s", correlationID)
+	}
 
 	return nil
 }
@@ -114,13 +283,136 @@ func (fu *FileUpload) HandleOperation(operation string, payload []byte) *ErrorRe
 
 // OnTick triggers periodic file uploads. Invoked from the periodic executor in AutoUploadable
 func (fu *FileUpload) OnTick() {
-	err := fu.DoTrigger(fu.uploadable.nextUID(), nil)
+	if fu.uploadable.cfg.TickPolicy == TickPolicySkipIfRunning && fu.uploadable.uploads.hasPendingUploads() {
+		logger.Infof("periodic trigger skipped - the previous upload is still running")
+		return
+	}
+
+	err := fu.trigger(fu.uploadable.nextUID(), nil, true)
 
 	if err != nil {
 		logger.Errorf("error on periodic trigger: %v", err)
 	}
 }
 
+// selectFiles removes the files, which match the exclude patterns or do not satisfy the file age and size restrictions,
+// as well as the files, uploaded within the deduplication window
+func (fu *FileUpload) selectFiles(files []string) []string {
+	cfg := fu.uploadable.cfg
+	filter := !cfg.FileFilter.isEmpty()
+	dedup := fu.uploadable.dedup
+	checkStats := cfg.MinFileAge > 0 || cfg.MaxFileAge > 0 || cfg.MinFileSize > 0 || cfg.MaxFileSize > 0 || filter ||
+		dedup != nil
+	if len(cfg.ExcludeFiles) == 0 && !checkStats && cfg.FileAttribute == "" {
+		return files
+	}
+
+	attribute, err := parseFileAttribute(cfg.FileAttribute)
+	if err != nil {
+		logger.Errorf("cannot select files by extended attribute: %v", err)
+		return nil
+	}
+
+	now := time.Now()
+	result := make([]string, 0, len(files))
+	var excluded []string
+	for _, file := range files {
+		if cfg.ExcludeFiles.Match(file) {
+			excluded = append(excluded, file)
+			continue
+		}
+
+		if checkStats {
+			info, err := os.Stat(file)
+			if err != nil {
+				logger.Warnf("skipping file '%s' - cannot get its stats: %v", file, err)
+				continue
+			}
+
+			age := now.Sub(info.ModTime())
+			if age < time.Duration(cfg.MinFileAge) || (cfg.MaxFileAge > 0 && age > time.Duration(cfg.MaxFileAge)) {
+				logger.Debugf("skipping file '%s' - last modified %v ago", file, age)
+				continue
+			}
+
+			size := ByteSize(info.Size())
+			if size < cfg.MinFileSize || (cfg.MaxFileSize > 0 && size > cfg.MaxFileSize) {
+				logger.Debugf("skipping file '%s' - size %d bytes is out of the allowed range", file, size)
+				continue
+			}
+
+			if filter && !cfg.FileFilter.matches(file, info, now) {
+				logger.Debugf("skipping file '%s' - not matching the file filter", file)
+				continue
+			}
+
+			if dedup != nil && dedup.contains(file, info) {
+				logger.Debugf("skipping file '%s' - uploaded within the deduplication window", file)
+				continue
+			}
+		}
+
+		if attribute != nil {
+			ok, err := attribute.matches(file)
+			if err != nil {
+				logger.Warnf("skipping file '%s' - cannot get its extended attribute '%s': %v", file, attribute.name, err)
+				continue
+			}
+			if !ok {
+				logger.Debugf("skipping file '%s' - extended attribute '%s' not set or different", file, attribute.name)
+				continue
+			}
+		}
+
+		result = append(result, file)
+	}
+
+	if len(excluded) > 0 {
+		logger.Debugf("files excluded from upload: %v", excluded)
+	}
+
+	return result
+}
+
+// readFilesList reads the newline-separated paths from the files list. Paths, which are not permitted
+// by the access mode, are skipped.
+func (fu *FileUpload) readFilesList() ([]string, error) {
+	data, err := os.ReadFile(fu.uploadable.cfg.FilesList)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(data), "\n") {
+		path := strings.TrimSpace(line)
+		if path == "" {
+			continue
+		}
+
+		ok, err := fu.isListedUploadPermitted(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			files = append(files, path)
+		} else {
+			logger.Warnf("uploading listed file '%s' with mode '%s' is not permitted - skipping it", path, fu.mode)
+		}
+	}
+
+	return files, nil
+}
+
+// isListedUploadPermitted checks if a path from the files list matches the files glob, unless the mode is lax
+func (fu *FileUpload) isListedUploadPermitted(path string) (bool, error) {
+	if fu.mode == ModeLax {
+		return true, nil
+	}
+
+	return matchAny(splitGlob(fu.filesGlob), path)
+}
+
 func (fu *FileUpload) isGlobUploadPermitted(glob string) (bool, error) {
 	switch fu.mode {
 	case ModeLax:
@@ -128,10 +420,27 @@ func (fu *FileUpload) isGlobUploadPermitted(glob string) (bool, error) {
 	case ModeStrict:
 		return glob == fu.filesGlob, nil
 	case ModeScoped:
-		return filepath.Match(fu.filesGlob, glob)
+		for _, pattern := range splitGlob(glob) {
+			if ok, err := matchAny(splitGlob(fu.filesGlob), pattern); !ok || err != nil {
+				return false, err
+			}
+		}
+
+		return true, nil
 	default:
 		logger.Errorf("unexpected file upload mode value: %v", fu.mode)
 
 		return false, nil
 	}
 }
+
+// matchAny checks if any of the patterns matches the given name
+func matchAny(patterns []string, name string) (bool, error) {
+	for _, pattern := range patterns {
+		if ok, err := MatchGlob(pattern, name); ok || err != nil {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
@@ -16,7 +16,11 @@ package client
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -25,6 +29,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/eclipse-kanto/file-upload/uploaders"
 	"github.com/eclipse/ditto-clients-golang/protocol"
 	MQTT "github.com/eclipse/paho.mqtt.golang"
 )
@@ -172,6 +177,492 @@ func TestUploadDynamicGlobError(t *testing.T) {
 	assertNoError(t, err)
 }
 
+func TestUploadFilesList(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, b, c, _ := getTestFiles(t)
+	list := addTestFile(t, "files.list")
+	assertNoError(t, os.WriteFile(list, []byte(a+"\n"+c+"\n\n  "+b+"  \n"), 0666))
+
+	glob := filepath.Join(basedir, "*.txt")
+
+	f, client := newConnectedFileUpload(t, glob, ModeStrict)
+	testCfg.FilesList = list
+	checkUploadTrigger(t, f, client, nil, a, b)
+	f.Disconnect()
+
+	f, client = newConnectedFileUpload(t, glob, ModeScoped)
+	testCfg.FilesList = list
+	checkUploadTrigger(t, f, client, nil, a, b)
+	checkUploadTrigger(t, f, client, map[string]string{uploadFilesProperty: a}, a) // dynamic globs are not affected
+	f.Disconnect()
+
+	f, client = newConnectedFileUpload(t, "", ModeLax)
+	testCfg.FilesList = list
+	checkUploadTrigger(t, f, client, nil, a, b, c)
+
+	// the list is re-read on each trigger
+	assertNoError(t, os.WriteFile(list, []byte(c), 0666))
+	checkUploadTrigger(t, f, client, nil, c)
+	f.Disconnect()
+}
+
+func TestUploadFilesListError(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	f, _ := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	testCfg.FilesList = filepath.Join(basedir, "missing.list")
+	assertError(t, f.DoTrigger("testCorrelationID", nil))
+}
+
+func TestUploadExcludeFiles(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, b, c, d := getTestFiles(t)
+	tmp := addTestFile(t, "e.tmp")
+	gz := addTestFile(t, "logs/f.log.gz")
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeScoped)
+	testCfg.ExcludeFiles = Globs{"*.tmp", "*.gz", filepath.Join(basedir, "d.*")}
+
+	checkUploadTrigger(t, f, client, nil, a, b, c)
+	f.Disconnect()
+
+	f, client = newConnectedFileUpload(t, "", ModeLax)
+	defer f.Disconnect()
+
+	testCfg.ExcludeFiles = Globs{"*.tmp", "*.gz", filepath.Join(basedir, "d.*")}
+
+	checkUploadTrigger(t, f, client, map[string]string{uploadFilesProperty: filepath.Join(basedir, "*.*")}, a, b, c)
+	checkUploadTrigger(t, f, client, map[string]string{uploadFilesProperty: gz})
+	checkUploadTrigger(t, f, client, map[string]string{uploadFilesProperty: tmp})
+	checkUploadTrigger(t, f, client, map[string]string{uploadFilesProperty: d})
+}
+
+func TestListFiles(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, b, c, d := getTestFiles(t)
+	a1 := addTestFile(t, "a1.txt")
+	addTestFile(t, "e.tmp")
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeScoped)
+	defer f.Disconnect()
+
+	testCfg.ExcludeFiles = Globs{"*.tmp"}
+
+	checkListFiles(t, f, nil, a, a1, b, c, d)
+	checkUploadTrigger(t, f, client, nil, a, a1, b, c, d)
+
+	options := map[string]string{uploadFilesProperty: filepath.Join(basedir, "?.txt")}
+	checkListFiles(t, f, options, a, b)
+	checkUploadTrigger(t, f, client, options, a, b)
+
+	options[uploadFilesProperty] = filepath.Join(basedir, "..", "*")
+	files, err := f.ListFiles(options)
+	assertEquals(t, 0, len(files))
+	assertEquals(t, http.StatusBadRequest, err.Status)
+}
+
+func TestListFilesModeStrict(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, b, _, _ := getTestFiles(t)
+
+	f, _ := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	checkListFiles(t, f, nil, a, b)
+
+	_, err := f.ListFiles(map[string]string{uploadFilesProperty: filepath.Join(basedir, "*.dat")})
+	assertEquals(t, ErrorCodeParameterInvalid, err.ErrorCode)
+}
+
+func TestUploadFileAge(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, b, c, d := getTestFiles(t)
+
+	now := time.Now()
+	setModTime(t, a, now.Add(-time.Hour))
+	setModTime(t, b, now.Add(-10*time.Minute))
+	setModTime(t, c, now.Add(-time.Minute))
+	// d is just written
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeLax)
+	defer f.Disconnect()
+
+	testCfg.MinFileAge = Duration(5 * time.Minute)
+	checkUploadTrigger(t, f, client, nil, a, b)
+
+	testCfg.MaxFileAge = Duration(30 * time.Minute)
+	checkUploadTrigger(t, f, client, nil, b)
+
+	// applies to dynamic globs too
+	checkUploadTrigger(t, f, client, map[string]string{uploadFilesProperty: filepath.Join(basedir, "*.dat")})
+
+	testCfg.MinFileAge = 0
+	checkUploadTrigger(t, f, client, map[string]string{uploadFilesProperty: filepath.Join(basedir, "*.dat")}, c, d)
+
+	// files, which cannot be stat'd, are skipped
+	list := addTestFile(t, "files.list")
+	assertNoError(t, os.WriteFile(list, []byte(b+"\n"+filepath.Join(basedir, "missing.txt")), 0666))
+	testCfg.FilesList = list
+	checkUploadTrigger(t, f, client, nil, b)
+}
+
+func TestUploadFileSize(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	empty := addTestFile(t, "empty.txt")
+	assertNoError(t, os.WriteFile(empty, nil, 0666))
+
+	files := make(map[ByteSize]string)
+	for _, size := range []ByteSize{Kilobyte - 1, Kilobyte, Kilobyte + 1, 2 * Kilobyte} {
+		path := addTestFile(t, fmt.Sprintf("%d.dat", size))
+		assertNoError(t, os.WriteFile(path, make([]byte, size), 0666))
+		files[size] = path
+	}
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeLax)
+	defer f.Disconnect()
+
+	testCfg.MinFileSize = Kilobyte
+	checkUploadTrigger(t, f, client, nil, files[Kilobyte], files[Kilobyte+1], files[2*Kilobyte])
+
+	testCfg.MaxFileSize = Kilobyte + 1
+	checkUploadTrigger(t, f, client, nil, files[Kilobyte], files[Kilobyte+1])
+
+	testCfg.MinFileSize = 0
+	checkUploadTrigger(t, f, client, nil, empty, files[Kilobyte-1], files[Kilobyte], files[Kilobyte+1])
+
+	// all files are filtered out - no upload is created
+	testCfg.MinFileSize = 3 * Kilobyte
+	testCfg.MaxFileSize = 0
+	assertNoError(t, f.DoTrigger("emptyCorrelationID", nil))
+	client.assertLiveEmpty(t)
+	if f.uploadable.uploads.Get("emptyCorrelationID") != nil {
+		t.Error("no upload expected, when all files are filtered out")
+	}
+}
+
+func setModTime(t *testing.T, path string, modTime time.Time) {
+	t.Helper()
+
+	assertNoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestTickPolicyOverlap(t *testing.T) {
+	testTickPolicy(t, TickPolicyOverlap)
+}
+
+func TestTickPolicySkipIfRunning(t *testing.T) {
+	testTickPolicy(t, TickPolicySkipIfRunning)
+}
+
+func testTickPolicy(t *testing.T, policy string) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, _, _, _ := getTestFiles(t)
+
+	f, client := newConnectedFileUpload(t, a, ModeStrict)
+	defer f.Disconnect()
+
+	testCfg.TickPolicy = policy
+
+	server := startTestServer(t, time.Second, false) // long-running upload cycle
+	defer server.Close()
+
+	f.OnTick()
+
+	msg := client.liveMsg(t, request)
+	id := msg["correlationId"].(string)
+	assertNoError(t, f.uploadable.uploads.Get(id).start(map[string]string{uploaders.URLProp: server.URL}))
+
+	f.OnTick()
+	if policy == TickPolicySkipIfRunning {
+		client.assertLiveEmpty(t)
+	} else {
+		assertEquals(t, a, getFileFromMsg(t, client.liveMsg(t, request)))
+	}
+
+	for f.uploadable.uploads.hasPendingUploads() {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	f.OnTick()
+	assertEquals(t, a, getFileFromMsg(t, client.liveMsg(t, request)))
+}
+
+func TestTickPolicySkipIfRunningPeriodic(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, _, _, _ := getTestFiles(t)
+
+	f, client := newConnectedFileUpload(t, a, ModeStrict)
+	defer f.Disconnect()
+
+	testCfg.TickPolicy = TickPolicySkipIfRunning
+	testCfg.Period = Duration(100 * time.Millisecond)
+
+	server := startTestServer(t, time.Second, false) // long-running upload
+	defer server.Close()
+
+	f.uploadable.startExecutor()
+	defer f.uploadable.stopExecutor()
+
+	msg := client.liveMsg(t, request)
+	id := msg["correlationId"].(string)
+	assertNoError(t, f.uploadable.uploads.Get(id).start(map[string]string{uploaders.URLProp: server.URL}))
+
+	time.Sleep(500 * time.Millisecond) // several periods elapse while uploading
+	client.assertLiveEmpty(t)
+
+	waitUploadState(t, client, StateSuccess)
+	assertEquals(t, a, getFileFromMsg(t, client.liveMsg(t, request))) // ticks resume after the upload
+}
+
+func TestInFlightPolicySkip(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a := addTestFile(t, "a.txt")
+	b := addTestFile(t, "b.txt")
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	testCfg.InFlightPolicy = InFlightPolicySkip
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assertNoError(t, f.DoTrigger(fmt.Sprintf("trigger%d", i), nil))
+		}(i)
+	}
+	wg.Wait()
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	var uploaded []string
+	for i := 0; i < 2; i++ {
+		msg := client.liveMsg(t, request)
+		uploaded = append(uploaded, getFileFromMsg(t, msg))
+		startUploads(t, f.uploadable.uploads, []string{msg["correlationId"].(string)}, server.URL)
+	}
+	client.assertLiveEmpty(t) // each file requested once
+
+	sort.Strings(uploaded)
+	assertEquals(t, []string{a, b}, uploaded)
+
+	for f.uploadable.uploads.hasPendingUploads() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assertEquals(t, 2, len(received.get()))
+
+	checkUploadTrigger(t, f, client, nil, a, b) // released after the uploads
+}
+
+func TestUploadDryRun(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, b, c, _ := getTestFiles(t)
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeScoped)
+	defer f.Disconnect()
+
+	testCfg.Delete = true
+	options := map[string]string{uploadFilesProperty: filepath.Join(basedir, "*.txt"), DryRunOption: "true"}
+	assertNoError(t, f.DoTrigger("dryRunCorrelationID", options))
+
+	var ids, requested []string
+	for i := 0; i < 2; i++ {
+		msg := client.liveMsg(t, request)
+		assertEquals(t, "true", msg["options"].(map[string]interface{})[DryRunOption])
+		ids = append(ids, msg["correlationId"].(string))
+		requested = append(requested, getFileFromMsg(t, msg))
+	}
+	client.assertLiveEmpty(t)
+
+	sort.Strings(requested)
+	assertEquals(t, []string{a, b}, requested)
+
+	startUploads(t, f.uploadable.uploads, ids, server.URL)
+
+	status := waitFinalStatus(t, client)
+	assertEquals(t, StateSuccess, status["state"])
+	assertEquals(t, float64(100), status["progress"])
+	assertEquals(t, dryRunMessage, status["message"])
+
+	assertEquals(t, 0, len(received.get()))
+	for _, file := range []string{a, b} {
+		if _, err := os.Stat(file); err != nil {
+			t.Errorf("file '%s' should not be deleted on dry run: %v", file, err)
+		}
+	}
+
+	options[uploadFilesProperty] = filepath.Join(basedir, "..", "*.txt")
+	assertError(t, f.DoTrigger("dryRunCorrelationID", options)) // not permitted in scoped mode
+
+	options[uploadFilesProperty] = c
+	checkUploadTrigger(t, f, client, options, c)
+}
+
+func TestUploadTriggerTags(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	_, _, c, _ := getTestFiles(t)
+
+	tags := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			tags <- r.Header.Get("x-ms-tags")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeScoped)
+	defer f.Disconnect()
+
+	options := map[string]string{uploadFilesProperty: c, "tag.source": "kanto", "tag.kind": "logs"}
+	assertNoError(t, f.DoTrigger("tagsCorrelationID", options))
+
+	msg := client.liveMsg(t, request)
+	requestOptions := msg["options"].(map[string]interface{})
+	assertEquals(t, "kanto", requestOptions["tag.source"])
+	assertEquals(t, "logs", requestOptions["tag.kind"])
+
+	assertNoError(t, f.uploadable.uploads.Get(msg["correlationId"].(string)).start(map[string]string{
+		StorageProvider:              uploaders.StorageProviderAzure,
+		uploaders.AzureEndpoint:      server.URL + "/",
+		uploaders.AzureContainerName: "test",
+		uploaders.AzureSAS:           "sig=test",
+		"tag.kind":                   "traces", // overrides the trigger tag
+	}))
+
+	status := waitFinalStatus(t, client)
+	assertEquals(t, StateSuccess, status["state"])
+
+	received, err := url.ParseQuery(<-tags)
+	assertNoError(t, err)
+	assertEquals(t, url.Values{"source": {"kanto"}, "kind": {"traces"}}, received)
+}
+
+func TestUploadBatches(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	getTestFiles(t)
+	addTestFile(t, "e.txt")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeScoped)
+	defer f.Disconnect()
+	f.uploadable.cfg.MaxFilesPerUpload = 2
+
+	assertNoError(t, f.DoTrigger("batchesCorrelationID", nil))
+
+	var uploaded []string
+	for i, size := range []int{2, 2, 1} {
+		prefix := fmt.Sprintf("batchesCorrelationID-batch%d#", i+1)
+
+		var ids []string
+		for j := 0; j < size; j++ {
+			msg := client.liveMsg(t, request)
+			id := msg["correlationId"].(string)
+			if !strings.HasPrefix(id, prefix) {
+				t.Fatalf("request of batch %d expected with correlation ID prefix '%s', but was '%s'", i+1, prefix, id)
+			}
+			ids = append(ids, id)
+			uploaded = append(uploaded, msg["options"].(map[string]interface{})[filePathOption].(string))
+		}
+		client.assertLiveEmpty(t) // the next batch is requested, when this one finishes
+
+		for _, id := range ids {
+			assertNoError(t, f.uploadable.uploads.Get(id).start(map[string]string{uploaders.URLProp: server.URL}))
+		}
+		status := waitFinalStatus(t, client)
+		assertEquals(t, StateSuccess, status["state"])
+		assertEquals(t, fmt.Sprintf("batchesCorrelationID-batch%d", i+1), status["correlationId"])
+	}
+
+	sort.Strings(uploaded)
+	assertEquals(t, []string{"a.txt", "b.txt", "c.dat", "d.dat", "e.txt"}, baseNames(uploaded))
+	client.assertLiveEmpty(t)
+}
+
+func TestOnTickBatches(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	getTestFiles(t)
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeScoped)
+	defer f.Disconnect()
+	f.uploadable.cfg.MaxFilesPerUpload = 3
+
+	f.OnTick()
+
+	for i := 0; i < 3; i++ {
+		id := client.liveMsg(t, request)["correlationId"].(string)
+		if !strings.Contains(id, "-batch1#") {
+			t.Fatalf("periodic upload request expected in the first batch, but was '%s'", id)
+		}
+	}
+	client.assertLiveEmpty(t)
+}
+
+func TestSplitBatches(t *testing.T) {
+	files := []string{"a", "b", "c", "d", "e"}
+
+	assertEquals(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, splitBatches(files, 2))
+	assertEquals(t, [][]string{{"a", "b", "c", "d", "e"}}, splitBatches(files, 5))
+	assertEquals(t, [][]string{{"a", "b", "c", "d"}, {"e"}}, splitBatches(files, 4))
+	assertEquals(t, [][]string{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}}, splitBatches(files, 1))
+}
+
+func baseNames(paths []string) []string {
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+
+	return names
+}
+
+func TestClaimFiles(t *testing.T) {
+	us := NewUploads()
+
+	assertEquals(t, []string{"a", "b"}, us.claimFiles("first", []string{"a", "b"}))
+	assertEquals(t, []string{"c"}, us.claimFiles("second", []string{"a", "b", "c"}))
+	assertEquals(t, []string{"a"}, us.claimFiles("first", []string{"a"}))
+
+	us.releaseFiles("first")
+	assertEquals(t, []string{"a", "b", "c"}, us.claimFiles("second", []string{"a", "b", "c"}))
+}
+
 func checkUploadTrigger(t *testing.T, f *FileUpload, client *mockedClient, options map[string]string, expected ...string) {
 	t.Helper()
 
@@ -199,6 +690,26 @@ func checkUploadTrigger(t *testing.T, f *FileUpload, client *mockedClient, optio
 	assertEquals(t, expected, actual)
 }
 
+func checkListFiles(t *testing.T, f *FileUpload, options map[string]string, expected ...string) {
+	t.Helper()
+
+	files, err := f.ListFiles(options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual := make([]string, len(files))
+	for i, file := range files {
+		actual[i] = file.Path
+		assertEquals(t, int64(len(file.Path)), file.Size) // test files content is their path
+	}
+
+	sort.Strings(expected)
+	sort.Strings(actual)
+
+	assertEquals(t, expected, actual)
+}
+
 func addTestFile(t *testing.T, path string) string {
 	t.Helper()
 
@@ -240,6 +751,18 @@ func newConnectedFileUpload(t *testing.T, filesGlob string, mode AccessMode) (*F
 	return u, client
 }
 
+// waitFinalStatus waits for a 'lastUpload' property update with a final upload state and returns it
+func waitFinalStatus(t *testing.T, client *mockedClient) map[string]interface{} {
+	t.Helper()
+
+	for {
+		status := client.twinMsg(t, modify)
+		if s, ok := status["state"].(string); ok && isFinalState(s) {
+			return status
+		}
+	}
+}
+
 func getFileFromMsg(t *testing.T, v map[string]interface{}) string {
 	requestOptions, ok := v["options"].(map[string]interface{})
 	if !ok {
@@ -269,10 +792,11 @@ const (
 
 // mockedClient represents mocked MQTT.Client interface used for testing.
 type mockedClient struct {
-	err  error
-	twin chan *protocol.Envelope
-	live chan *protocol.Envelope
-	mu   sync.Mutex
+	err      error
+	errMutex sync.RWMutex // guards err, which is switched by tests, while the client is in use
+	twin     chan *protocol.Envelope
+	live     chan *protocol.Envelope
+	mu       sync.Mutex
 }
 
 func newMockedClient() *mockedClient {
@@ -359,6 +883,22 @@ func (client *mockedClient) getChannel(channel string) chan *protocol.Envelope {
 	return nil
 }
 
+// getErr returns the error, with which the client operations fail.
+func (client *mockedClient) getErr() error {
+	client.errMutex.RLock()
+	defer client.errMutex.RUnlock()
+
+	return client.err
+}
+
+// setErr sets the error, with which the client operations fail.
+func (client *mockedClient) setErr(err error) {
+	client.errMutex.Lock()
+	defer client.errMutex.Unlock()
+
+	client.err = err
+}
+
 // IsConnected returns true.
 func (client *mockedClient) IsConnected() bool {
 	return true
@@ -371,7 +911,7 @@ func (client *mockedClient) IsConnectionOpen() bool {
 
 // Connect returns finished token.
 func (client *mockedClient) Connect() MQTT.Token {
-	return &mockedToken{err: client.err}
+	return &mockedToken{err: client.getErr()}
 }
 
 // Disconnect do nothing.
@@ -394,22 +934,22 @@ func (client *mockedClient) Publish(topic string, qos byte, retained bool, paylo
 		log.Fatalf("unexpected message topic: %v", env.Topic)
 	}
 
-	return &mockedToken{err: client.err}
+	return &mockedToken{err: client.getErr()}
 }
 
 // Subscribe returns finished token.
 func (client *mockedClient) Subscribe(topic string, qos byte, callback MQTT.MessageHandler) MQTT.Token {
-	return &mockedToken{err: client.err}
+	return &mockedToken{err: client.getErr()}
 }
 
 // SubscribeMultiple returns finished token.
 func (client *mockedClient) SubscribeMultiple(filters map[string]byte, callback MQTT.MessageHandler) MQTT.Token {
-	return &mockedToken{err: client.err}
+	return &mockedToken{err: client.getErr()}
 }
 
 // Unsubscribe returns finished token.
 func (client *mockedClient) Unsubscribe(topics ...string) MQTT.Token {
-	return &mockedToken{err: client.err}
+	return &mockedToken{err: client.getErr()}
 }
 
 // AddRoute do nothing.
@@ -446,3 +986,40 @@ func (token *mockedToken) Done() <-chan struct{} {
 func (token *mockedToken) Error() error {
 	return token.err
 }
+
+func TestInfoProperty(t *testing.T) {
+	cfg := &UploadableConfig{
+		FeatureID:       featureID,
+		Period:          Duration(time.Hour),
+		Cron:            "0 2 * * *",
+		Delete:          true,
+		Checksum:        true,
+		CredentialsFile: "/etc/file-upload/credentials.json",
+		ServerCert:      "/etc/file-upload/server.crt",
+	}
+
+	u, err := NewFileUpload("/var/log/*.log", ModeScoped, cfg)
+	assertNoError(t, err)
+
+	info := u.uploadable.feature().Properties["info"].(map[string]string)
+	assertEquals(t, map[string]string{
+		"files":              "/var/log/*.log",
+		"mode":               ModeNameScoped,
+		"period":             "1h0m0s",
+		"cron":               "0 2 * * *",
+		"active":             "false",
+		"delete":             "true",
+		"checksum":           "true",
+		"compress":           "false",
+		"encrypt":            "false",
+		"supportedProviders": strings.Join(RegisteredStorageProviders(), ","),
+	}, info)
+
+	for key, value := range info {
+		for _, secret := range []string{"credentials", "password", "key", "cert"} {
+			if strings.Contains(strings.ToLower(key), secret) || strings.Contains(value, cfg.CredentialsFile) {
+				t.Errorf("unexpected sensitive info property %s=%s", key, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileFilter is an expression, combining file selection criteria. A file is selected, if it matches all criteria
+// of the expression, all 'and' expressions, at least one of the 'or' expressions, if any, and not the 'not'
+// expression. The empty expression selects all files. Specified as a JSON object in the configuration file and
+// on the command line, e.g. '{"or":[{"glob":"*.log","minSize":"1KB"},{"maxAge":"1h"}]}'.
+type FileFilter struct {
+	And []FileFilter `json:"and,omitempty"`
+	Or  []FileFilter `json:"or,omitempty"`
+	Not *FileFilter  `json:"not,omitempty"`
+
+	Glob    string   `json:"glob,omitempty"`    // matched against the full path or the base name
+	MinSize ByteSize `json:"minSize,omitempty"` // inclusive
+	MaxSize ByteSize `json:"maxSize,omitempty"` // inclusive, zero means no limit
+	MinAge  Duration `json:"minAge,omitempty"`  // time since the last modification
+	MaxAge  Duration `json:"maxAge,omitempty"`  // i.e. modified since, zero means no limit
+}
+
+// String returns the JSON expression, or empty string if the filter is empty
+func (f FileFilter) String() string {
+	if f.isEmpty() {
+		return ""
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+// Set implements flag.Value Set method
+func (f *FileFilter) Set(v string) error {
+	filter := FileFilter{}
+	if v != "" {
+		if err := json.Unmarshal([]byte(v), &filter); err != nil {
+			return fmt.Errorf("invalid file filter '%s': %w", v, err)
+		}
+		if err := filter.validate(); err != nil {
+			return err
+		}
+	}
+	*f = filter
+
+	return nil
+}
+
+func (f *FileFilter) isEmpty() bool {
+	return len(f.And) == 0 && len(f.Or) == 0 && f.Not == nil && f.Glob == "" &&
+		f.MinSize == 0 && f.MaxSize == 0 && f.MinAge == 0 && f.MaxAge == 0
+}
+
+func (f *FileFilter) validate() error {
+	if f.Glob != "" {
+		if err := ValidateGlob(f.Glob); err != nil {
+			return err
+		}
+	}
+	if f.MinSize < 0 || f.MaxSize < 0 || f.MinAge < 0 || f.MaxAge < 0 {
+		return errors.New("file filter sizes and ages should not be negative")
+	}
+
+	for _, children := range [][]FileFilter{f.And, f.Or} {
+		for i := range children {
+			if err := children[i].validate(); err != nil {
+				return err
+			}
+		}
+	}
+	if f.Not != nil {
+		return f.Not.validate()
+	}
+
+	return nil
+}
+
+// matches returns true if the file with the given path and stats is selected by the filter at the given time
+func (f *FileFilter) matches(path string, info os.FileInfo, now time.Time) bool {
+	if f.Glob != "" {
+		ok, _ := MatchGlob(f.Glob, path)
+		if !ok {
+			ok, _ = MatchGlob(f.Glob, filepath.Base(path))
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	size := ByteSize(info.Size())
+	if size < f.MinSize || (f.MaxSize > 0 && size > f.MaxSize) {
+		return false
+	}
+
+	age := now.Sub(info.ModTime())
+	if age < time.Duration(f.MinAge) || (f.MaxAge > 0 && age > time.Duration(f.MaxAge)) {
+		return false
+	}
+
+	for i := range f.And {
+		if !f.And[i].matches(path, info, now) {
+			return false
+		}
+	}
+
+	if len(f.Or) > 0 {
+		matched := false
+		for i := range f.Or {
+			if f.Or[i].matches(path, info, now) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return f.Not == nil || !f.Not.matches(path, info, now)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileFilterSelection(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	old := time.Now().Add(-2 * time.Hour)
+	addFile := func(name string, size int, modTime time.Time) string {
+		path := addTestFile(t, name)
+		assertNoError(t, os.WriteFile(path, make([]byte, size), 0666))
+		setModTime(t, path, modTime)
+		return path
+	}
+
+	smallLog := addFile("small.log", 100, time.Now())
+	bigLog := addFile("big.log", 2048, time.Now())
+	oldLog := addFile("old.log", 2048, old)
+	bigTxt := addFile("big.txt", 2048, time.Now())
+	oldTmp := addFile("old.tmp", 10, old)
+	newTmp := addFile("new.tmp", 10, time.Now())
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeLax)
+	defer f.Disconnect()
+
+	for _, test := range []struct {
+		filter   string
+		expected []string
+	}{
+		{``, []string{smallLog, bigLog, oldLog, bigTxt, oldTmp, newTmp}},
+		{`{"glob":"*.log","minSize":"1KB","maxAge":"1h"}`, []string{bigLog}},
+		{`{"or":[{"glob":"*.txt"},{"maxSize":"512B"}]}`, []string{smallLog, bigTxt, oldTmp, newTmp}},
+		{`{"and":[{"glob":"*.log"}],"not":{"minAge":"1h"}}`, []string{smallLog, bigLog}},
+		{`{"or":[{"glob":"*.tmp","minAge":"1h"},{"and":[{"glob":"*.log"},{"minSize":"1KB"}]}]}`, []string{bigLog, oldLog, oldTmp}},
+		{`{"glob":"*.dat"}`, nil},
+	} {
+		assertNoError(t, f.uploadable.cfg.FileFilter.Set(test.filter))
+		checkUploadTrigger(t, f, client, nil, test.expected...)
+	}
+
+	// combined with the other selection criteria
+	assertNoError(t, f.uploadable.cfg.FileFilter.Set(`{"or":[{"glob":"*.log"},{"glob":"*.tmp"}]}`))
+	f.uploadable.cfg.ExcludeFiles = Globs{"old.*"}
+	f.uploadable.cfg.MinFileSize = 50
+	checkUploadTrigger(t, f, client, nil, smallLog, bigLog)
+}
+
+func TestFileFilterSet(t *testing.T) {
+	filter := FileFilter{}
+	assertNoError(t, filter.Set(`{"or":[{"glob":"*.log","minSize":"1KB"},{"maxAge":"90m","not":{"glob":"*.tmp"}}]}`))
+	assertEquals(t, FileFilter{Or: []FileFilter{
+		{Glob: "*.log", MinSize: Kilobyte},
+		{MaxAge: Duration(90 * time.Minute), Not: &FileFilter{Glob: "*.tmp"}},
+	}}, filter)
+
+	parsed := FileFilter{}
+	assertNoError(t, parsed.Set(filter.String()))
+	assertEquals(t, filter, parsed)
+
+	assertNoError(t, filter.Set(""))
+	assertEquals(t, FileFilter{}, filter)
+	assertEquals(t, "", filter.String())
+
+	for _, invalid := range []string{`{`, `{"glob":"[a"}`, `{"and":[{"minSize":"big"}]}`, `{"not":{"maxAge":"-1h"}}`} {
+		assertError(t, filter.Set(invalid))
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// recursiveWildcard is a path element, matching zero or more directories
+const recursiveWildcard = "**"
+
+// Glob returns the names of all files matching the pattern. Besides the filepath.Match syntax, the pattern may contain
+// '**' path elements, matching zero or more directories, e.g. '/var/log/**/*.log'. Patterns with '**' match only
+// files (or symbolic links to files) - symbolic links to directories are not followed, which guards against loops.
+// Simple patterns are delegated to filepath.Glob.
+func Glob(pattern string) ([]string, error) {
+	if !isRecursiveGlob(pattern) {
+		return filepath.Glob(pattern)
+	}
+
+	if err := ValidateGlob(pattern); err != nil {
+		return nil, err
+	}
+
+	elements := splitPath(pattern)
+	root := globRoot(elements)
+
+	var matches []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root && os.IsNotExist(err) {
+				return filepath.SkipDir // no matches, as with filepath.Glob
+			}
+			return nil // skip unreadable directories
+		}
+
+		if isFile(path, d) && matchElements(elements, splitPath(path)) {
+			matches = append(matches, path)
+		}
+
+		return nil
+	})
+
+	return matches, err
+}
+
+// MatchGlob reports whether the name matches the pattern, supporting '**' path elements like Glob
+func MatchGlob(pattern string, name string) (bool, error) {
+	if !isRecursiveGlob(pattern) {
+		return filepath.Match(pattern, name)
+	}
+
+	if err := ValidateGlob(pattern); err != nil {
+		return false, err
+	}
+
+	return matchElements(splitPath(pattern), splitPath(name)), nil
+}
+
+// ValidateGlob checks the syntax of the pattern, without accessing the file system
+func ValidateGlob(pattern string) error {
+	for _, element := range splitPath(pattern) {
+		if _, err := filepath.Match(element, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isFile checks if the walked entry is a regular file or a symbolic link to such
+func isFile(path string, d fs.DirEntry) bool {
+	if d.Type()&fs.ModeSymlink != 0 {
+		info, err := os.Stat(path)
+		return err == nil && info.Mode().IsRegular()
+	}
+
+	return d.Type().IsRegular()
+}
+
+func isRecursiveGlob(pattern string) bool {
+	for _, element := range splitPath(pattern) {
+		if element == recursiveWildcard {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitPath(path string) []string {
+	return strings.Split(filepath.Clean(path), string(filepath.Separator))
+}
+
+// globRoot returns the directory, from which matching of the pattern elements should start,
+// i.e. the path formed by the leading elements without special characters
+func globRoot(elements []string) string {
+	i := 0
+	for i < len(elements)-1 && !hasMeta(elements[i]) {
+		i++
+	}
+
+	root := strings.Join(elements[:i], string(filepath.Separator))
+	if root == "" {
+		if i > 0 { // absolute pattern
+			return string(filepath.Separator)
+		}
+		return "."
+	}
+
+	return root
+}
+
+func hasMeta(element string) bool {
+	magic := `*?[`
+	if filepath.Separator != '\\' {
+		magic = `*?[\`
+	}
+	return strings.ContainsAny(element, magic)
+}
+
+func matchElements(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == recursiveWildcard {
+			pattern = pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchElements(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := filepath.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestGlobRecursive(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a := addTestFile(t, "a.log")
+	b := addTestFile(t, "logs/b.log")
+	c := addTestFile(t, "logs/app/c.log")
+	d := addTestFile(t, "logs/app/d.txt")
+	e := addTestFile(t, "other/app/e.log")
+
+	// symbolic link loops and links to directories are not followed
+	assertNoError(t, os.Symlink("..", filepath.Join(basedir, "logs", "app", "parent")))
+	assertNoError(t, os.Symlink("loop", filepath.Join(basedir, "logs", "loop")))
+
+	checkGlob(t, filepath.Join(basedir, "**"), a, b, c, d, e)
+	checkGlob(t, filepath.Join(basedir, "**", "*.log"), a, b, c, e)
+	checkGlob(t, filepath.Join(basedir, "logs", "**"), b, c, d)
+	checkGlob(t, filepath.Join(basedir, "**", "app", "*"), c, d, e)
+	checkGlob(t, filepath.Join(basedir, "*", "**", "*.txt"), d)
+	checkGlob(t, filepath.Join(basedir, "missing", "**"))
+
+	// simple patterns are delegated to filepath.Glob
+	checkGlob(t, filepath.Join(basedir, "logs", "*", "c.log"), c)
+
+	if _, err := Glob(filepath.Join(basedir, "**", "[")); err == nil {
+		t.Error("error expected for malformed pattern")
+	}
+}
+
+func TestGlobRecursiveRelative(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a := addTestFile(t, "a.log")
+	b := addTestFile(t, "logs/b.log")
+
+	checkGlob(t, filepath.Join(".", basedir, "**", "*.log"), a, b)
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"/var/log/**", "/var/log/a.log", true},
+		{"/var/log/**", "/var/log/app/a.log", true},
+		{"/var/log/**/*.log", "/var/log/a.log", true},
+		{"/var/log/**/*.log", "/var/log/app/x/a.log", true},
+		{"/var/log/**/*.log", "/var/log/app/a.txt", false},
+		{"/var/log/**", "/var/other/a.log", false},
+		{"/var/**/app/*.log", "/var/log/app/a.log", true},
+		{"/var/**/app/*.log", "/var/app/a.log", true},
+		{"/var/**/app/*.log", "/var/log/a.log", false},
+		{"/var/log/**", "/var/log/../../etc/passwd", false},
+		{"/var/log/*.log", "/var/log/a.log", true},
+		{"/var/log/*.log", "/var/log/app/a.log", false},
+		// patterns as names - used to validate dynamically requested globs in scoped mode
+		{"/var/log/**", "/var/log/app/*.log", true},
+		{"/var/log/**", "/var/log/**", true},
+		{"/var/log/**", "/var/log/**/*.log", true},
+		{"/var/log/*.log", "/var/log/**", false},
+		{"/var/log/app/**", "/var/log/**", false},
+	}
+
+	for _, test := range tests {
+		match, err := MatchGlob(filepath.FromSlash(test.pattern), filepath.FromSlash(test.name))
+		assertNoError(t, err)
+		if match != test.match {
+			t.Errorf("unexpected match result for pattern '%s' and name '%s': %v", test.pattern, test.name, match)
+		}
+	}
+
+	if _, err := MatchGlob("/var/**/[", "/var/log/a.log"); err == nil {
+		t.Error("error expected for malformed pattern")
+	}
+}
+
+func TestUploadRecursiveGlobScoped(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a := addTestFile(t, "a.txt")
+	b := addTestFile(t, "logs/b.log")
+	c := addTestFile(t, "logs/app/c.log")
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "**"), ModeScoped)
+	defer f.Disconnect()
+
+	checkUploadTrigger(t, f, client, nil, a, b, c)
+	checkUploadTrigger(t, f, client, map[string]string{uploadFilesProperty: filepath.Join(basedir, "logs", "**")}, b, c)
+	checkUploadTrigger(t, f, client, map[string]string{uploadFilesProperty: filepath.Join(basedir, "**", "c.log")}, c)
+
+	assertError(t, f.DoTrigger("testCorrelationID", map[string]string{uploadFilesProperty: filepath.Join(basedir, "..", "**")}))
+}
+
+func checkGlob(t *testing.T, pattern string, expected ...string) {
+	t.Helper()
+
+	actual, err := Glob(pattern)
+	assertNoError(t, err)
+
+	for i := range expected {
+		expected[i] = filepath.Clean(expected[i])
+	}
+	sort.Strings(expected)
+	sort.Strings(actual)
+
+	if len(expected) == 0 && len(actual) == 0 {
+		return
+	}
+	assertEquals(t, expected, actual)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Globs is a list of glob patterns. Specified as a JSON array in the configuration file
+// and as a comma-separated list on the command line.
+type Globs []string
+
+// String returns the comma-separated glob patterns
+func (g Globs) String() string {
+	return strings.Join(g, ",")
+}
+
+// Set implements flag.Value Set method
+func (g *Globs) Set(v string) error {
+	if v == "" {
+		*g = nil
+		return nil
+	}
+
+	globs := strings.Split(v, ",")
+	for i, glob := range globs {
+		globs[i] = strings.TrimSpace(glob)
+		if err := ValidateGlob(globs[i]); err != nil {
+			return err
+		}
+	}
+	*g = globs
+
+	return nil
+}
+
+// Match checks if any of the glob patterns matches the given path or its base name
+func (g Globs) Match(path string) bool {
+	for _, glob := range g {
+		if ok, _ := MatchGlob(glob, path); ok {
+			return true
+		}
+
+		if ok, _ := MatchGlob(glob, filepath.Base(path)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitGlob splits a glob, specifying multiple patterns separated by the OS-specific path list separator
+// (':' on Linux, ';' on Windows)
+func splitGlob(glob string) []string {
+	return filepath.SplitList(glob)
+}
+
+// expandGlobs expands the glob patterns concurrently, using at most the given number of workers.
+// The result contains the matches of all patterns, ordered as the patterns, without duplicates.
+func expandGlobs(patterns []string, workers int) ([]string, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	matches := make([][]string, len(patterns))
+	errs := make([]error, len(patterns))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers && i < len(patterns); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				matches[index], errs[index] = Glob(patterns[index])
+			}
+		}()
+	}
+
+	for i := range patterns {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	var result []string
+	found := make(map[string]bool)
+	for i := range patterns {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+
+		for _, match := range matches[i] {
+			if !found[match] {
+				found[match] = true
+				result = append(result, match)
+			}
+		}
+	}
+
+	return result, nil
+}
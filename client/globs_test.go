@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandGlobs(t *testing.T) {
+	dir := createGlobRoots(t, 3, 2)
+	defer os.RemoveAll(dir)
+
+	patterns := []string{
+		filepath.Join(dir, "root2", "*"),
+		filepath.Join(dir, "root0", "file1.log"),
+		filepath.Join(dir, "root*", "file1.log"), // overlaps with the previous patterns
+		filepath.Join(dir, "missing", "*"),
+	}
+
+	expected := []string{
+		filepath.Join(dir, "root2", "file0.log"),
+		filepath.Join(dir, "root2", "file1.log"),
+		filepath.Join(dir, "root0", "file1.log"),
+		filepath.Join(dir, "root1", "file1.log"),
+	}
+
+	for _, workers := range []int{0, 1, 2, 10} {
+		actual, err := expandGlobs(patterns, workers)
+		assertNoError(t, err)
+		assertEquals(t, expected, actual)
+	}
+
+	if _, err := expandGlobs([]string{filepath.Join(dir, "*"), "["}, 2); err == nil {
+		t.Error("error expected for malformed pattern")
+	}
+}
+
+func TestSplitGlob(t *testing.T) {
+	sep := string(os.PathListSeparator)
+
+	assertEquals(t, []string{"/var/log/*.log"}, splitGlob("/var/log/*.log"))
+	assertEquals(t, []string{"/var/log/*.log", "/tmp/*.txt"}, splitGlob("/var/log/*.log"+sep+"/tmp/*.txt"))
+}
+
+func TestUploadMultipleGlobs(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, b, c, d := getTestFiles(t)
+	sep := string(os.PathListSeparator)
+	glob := filepath.Join(basedir, "a.*") + sep + filepath.Join(basedir, "*.dat")
+
+	f, client := newConnectedFileUpload(t, glob, ModeScoped)
+	defer f.Disconnect()
+
+	checkUploadTrigger(t, f, client, nil, a, c, d)
+	checkUploadTrigger(t, f, client, map[string]string{uploadFilesProperty: c + sep + d}, c, d)
+
+	assertError(t, f.DoTrigger("testCorrelationID", map[string]string{uploadFilesProperty: c + sep + b}))
+}
+
+func BenchmarkExpandGlobsSerial(b *testing.B) {
+	benchmarkExpandGlobs(b, 1)
+}
+
+func BenchmarkExpandGlobsParallel(b *testing.B) {
+	benchmarkExpandGlobs(b, 8)
+}
+
+func benchmarkExpandGlobs(b *testing.B, workers int) {
+	dir := createGlobRoots(b, 64, 50)
+	defer os.RemoveAll(dir)
+
+	patterns := make([]string, 64)
+	for i := range patterns {
+		patterns[i] = filepath.Join(dir, fmt.Sprintf("root%d", i), "*.log")
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := expandGlobs(patterns, workers); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func createGlobRoots(t testing.TB, roots int, files int) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp(".", "globs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < roots; i++ {
+		root := filepath.Join(dir, fmt.Sprintf("root%d", i))
+		if err := os.Mkdir(root, 0700); err != nil {
+			t.Fatal(err)
+		}
+
+		for j := 0; j < files; j++ {
+			name := filepath.Join(root, fmt.Sprintf("file%d.log", j))
+			if err := os.WriteFile(name, []byte(strings.Repeat("x", j)), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	return dir
+}
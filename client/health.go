@@ -0,0 +1,133 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+const endpointHealthProperty = "endpointHealth"
+
+// EndpointHealth is the cached health of a storage endpoint, as determined by the most recent upload to it
+type EndpointHealth struct {
+	Healthy bool      `json:"healthy"`
+	Checked time.Time `json:"checked"`
+	Message string    `json:"message,omitempty"`
+}
+
+// EndpointsHealth is used for serializing the 'endpointHealth' property of the AutoUploadable feature,
+// mapping storage endpoints to their health
+type EndpointsHealth map[string]EndpointHealth
+
+// endpointHealthListener is implemented by upload status listeners, which are notified when the health of a storage
+// endpoint changes
+type endpointHealthListener interface {
+	endpointHealthUpdated(health EndpointsHealth) // should not block
+}
+
+// healthCache caches the health of the storage endpoints. Uploads to endpoints, which were found unavailable,
+// fail right away until the TTL elapses, instead of waiting for yet another storage failure.
+type healthCache struct {
+	ttl time.Duration
+
+	endpoints EndpointsHealth
+
+	mutex sync.Mutex
+}
+
+func newHealthCache(ttl time.Duration) *healthCache {
+	return &healthCache{ttl: ttl, endpoints: make(EndpointsHealth)}
+}
+
+// check returns error if the given endpoint was found unavailable within the TTL
+func (c *healthCache) check(endpoint string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	health, ok := c.endpoints[endpoint]
+	if !ok || health.Healthy || time.Since(health.Checked) >= c.ttl {
+		return nil
+	}
+
+	return fmt.Errorf("storage endpoint '%s' is unavailable until %v: %s",
+		endpoint, health.Checked.Add(c.ttl).Format(time.RFC3339), health.Message)
+}
+
+// update records the health of the given endpoint from the result of an upload to it. Errors, which do not indicate
+// unavailability of the endpoint (e.g. rejected credentials), are ignored. Returns a snapshot of the endpoints health
+// and true, if the health of the endpoint changed.
+func (c *healthCache) update(endpoint string, err error) (EndpointsHealth, bool) {
+	if err != nil && !uploaders.IsUnavailableError(err) {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	health := EndpointHealth{Healthy: err == nil, Checked: time.Now()}
+	if err != nil {
+		health.Message = err.Error()
+	}
+
+	previous, ok := c.endpoints[endpoint]
+	c.endpoints[endpoint] = health
+
+	if ok && previous.Healthy == health.Healthy {
+		return nil, false
+	}
+
+	if health.Healthy {
+		logger.Infof("storage endpoint '%s' is available", endpoint)
+	} else {
+		logger.Warnf("storage endpoint '%s' is unavailable for %v: %s", endpoint, c.ttl, health.Message)
+	}
+
+	snapshot := make(EndpointsHealth, len(c.endpoints))
+	for k, v := range c.endpoints {
+		snapshot[k] = v
+	}
+
+	return snapshot, true
+}
+
+// endpointOf returns the storage endpoint, to which files are uploaded with the given 'start' operation options,
+// e.g. the scheme and host of the HTTP upload URL or the AWS bucket
+func endpointOf(options map[string]string) string {
+	switch strings.ToLower(options[StorageProvider]) {
+	case uploaders.StorageProviderAWS:
+		return uploaders.StorageProviderAWS + ":" + options[uploaders.AWSBucket]
+	case uploaders.StorageProviderAzure:
+		return uploaders.StorageProviderAzure + ":" + strings.TrimSuffix(options[uploaders.AzureEndpoint], "/") +
+			"/" + options[uploaders.AzureContainerName]
+	case uploaders.StorageProviderFile:
+		return uploaders.StorageProviderFile + ":" + options[uploaders.FileDirectory]
+	case uploaders.StorageProviderLocal:
+		return uploaders.StorageProviderLocal + ":" + options[uploaders.LocalDirectory]
+	case uploaders.StorageProviderWebDAV:
+		return uploaders.StorageProviderWebDAV + ":" + options[uploaders.WebDAVURL]
+	}
+
+	u, err := url.Parse(options[uploaders.URLProp])
+	if err != nil || u.Host == "" {
+		return options[uploaders.URLProp]
+	}
+
+	return u.Scheme + "://" + u.Host
+}
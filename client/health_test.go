@@ -0,0 +1,104 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+func TestHealthCache(t *testing.T) {
+	c := newHealthCache(200 * time.Millisecond)
+	const endpoint = "http://localhost:1234"
+
+	assertNoError(t, c.check(endpoint))
+
+	health, changed := c.update(endpoint, &uploaders.HTTPError{Code: http.StatusServiceUnavailable, Status: "503 Service Unavailable"})
+	assertEquals(t, true, changed)
+	assertEquals(t, false, health[endpoint].Healthy)
+	assertError(t, c.check(endpoint))
+	assertNoError(t, c.check("http://other:1234"))
+
+	_, changed = c.update(endpoint, &uploaders.HTTPError{Code: http.StatusBadGateway, Status: "502 Bad Gateway"})
+	assertEquals(t, false, changed)
+
+	time.Sleep(200 * time.Millisecond)
+	assertNoError(t, c.check(endpoint)) // expired
+
+	_, changed = c.update(endpoint, &uploaders.HTTPError{Code: http.StatusForbidden, Status: "403 Forbidden"})
+	assertEquals(t, false, changed) // not an availability error
+
+	health, changed = c.update(endpoint, nil)
+	assertEquals(t, true, changed)
+	assertEquals(t, true, health[endpoint].Healthy)
+	assertNoError(t, c.check(endpoint))
+}
+
+func TestEndpointOf(t *testing.T) {
+	assertEquals(t, "https://storage:8443", endpointOf(map[string]string{uploaders.URLProp: "https://storage:8443/upload?sig=1"}))
+	assertEquals(t, "aws:test-bucket", endpointOf(map[string]string{
+		StorageProvider: uploaders.StorageProviderAWS, uploaders.AWSBucket: "test-bucket"}))
+	assertEquals(t, "azure:https://account.blob.core.windows.net/test-container", endpointOf(map[string]string{
+		StorageProvider:              uploaders.StorageProviderAzure,
+		uploaders.AzureEndpoint:      "https://account.blob.core.windows.net/",
+		uploaders.AzureContainerName: "test-container"}))
+	assertEquals(t, "file:/tmp/uploads", endpointOf(map[string]string{
+		StorageProvider: uploaders.StorageProviderFile, uploaders.FileDirectory: "/tmp/uploads"}))
+}
+
+func TestUnhealthyEndpointSkipped(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	requests := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := &UploadableConfig{EndpointHealthTTL: Duration(500 * time.Millisecond)}
+	us := NewUploads()
+
+	upload := func() *TestStatusListener {
+		l := NewTestStatusListener(t)
+		ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+		startUploads(t, us, ids, server.URL)
+		l.waitFinish()
+		l.assertStatusState(StateFailed)
+
+		return l
+	}
+
+	upload()
+	assertEquals(t, int32(1), atomic.LoadInt32(&requests))
+
+	l := upload() // skipped, the endpoint is cached as unavailable
+	assertEquals(t, int32(1), atomic.LoadInt32(&requests))
+	if !strings.Contains(l.getStatus().Message, "is unavailable") {
+		t.Errorf("unavailable endpoint error expected, but was '%s'", l.getStatus().Message)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	upload() // attempted again after the TTL
+	assertEquals(t, int32(2), atomic.LoadInt32(&requests))
+}
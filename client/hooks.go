@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+)
+
+// Outcomes of the file upload, passed to the post-upload hook
+const (
+	hookOutcomeSuccess = "success"
+	hookOutcomeFailure = "failure"
+)
+
+// Environment variables, passed to the upload hooks
+const (
+	hookEnvPath          = "FILE_UPLOAD_PATH"
+	hookEnvCorrelationID = "FILE_UPLOAD_CORRELATION_ID"
+	hookEnvOutcome       = "FILE_UPLOAD_OUTCOME"
+	hookEnvError         = "FILE_UPLOAD_ERROR"
+)
+
+// runPreUploadHook runs the pre-upload hook command (if enabled and configured) for the uploaded file.
+// The file path is passed as argument. Returns error if the hook fails, in which case the file should not be uploaded.
+func runPreUploadHook(cfg *UploadableConfig, correlationID string, path string) error {
+	if !cfg.Hooks || cfg.PreUploadHook == "" {
+		return nil
+	}
+
+	env := []string{hookEnvPath + "=" + path, hookEnvCorrelationID + "=" + correlationID}
+	if err := runHook(cfg.PreUploadHook, time.Duration(cfg.HookTimeout), env, path); err != nil {
+		return fmt.Errorf("pre-upload hook failed: %v", err)
+	}
+
+	return nil
+}
+
+// runPostUploadHook runs the post-upload hook command (if enabled and configured) for the uploaded file.
+// The file path and the upload outcome are passed as arguments. Hook failures are only logged.
+func runPostUploadHook(cfg *UploadableConfig, correlationID string, path string, uploadErr error) {
+	if !cfg.Hooks || cfg.PostUploadHook == "" {
+		return
+	}
+
+	outcome := hookOutcomeSuccess
+	env := []string{hookEnvPath + "=" + path, hookEnvCorrelationID + "=" + correlationID}
+	if uploadErr != nil {
+		outcome = hookOutcomeFailure
+		env = append(env, hookEnvError+"="+uploadErr.Error())
+	}
+	env = append(env, hookEnvOutcome+"="+outcome)
+
+	if err := runHook(cfg.PostUploadHook, time.Duration(cfg.HookTimeout), env, path, outcome); err != nil {
+		logger.Errorf("post-upload hook for file '%s' failed: %v", path, err)
+	}
+}
+
+// runHook executes the hook command with the given arguments and additional environment variables,
+// killing it if it does not complete within the timeout. The command output is discarded.
+func runHook(command string, timeout time.Duration, env []string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = append(os.Environ(), env...)
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("'%s' timed out after %v", command, timeout)
+	}
+
+	if err != nil {
+		return fmt.Errorf("'%s' failed: %v", command, err)
+	}
+
+	logger.Debugf("hook '%s %s' completed", command, strings.Join(args, " "))
+
+	return nil
+}
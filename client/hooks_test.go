@@ -0,0 +1,158 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUploadHooks(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+	path := files[0].Name()
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	cfg := &UploadableConfig{
+		Hooks:          true,
+		PreUploadHook:  writeHookScript(t, dir, "pre", calls, 0),
+		PostUploadHook: writeHookScript(t, dir, "post", calls, 0),
+		HookTimeout:    Duration(5 * time.Second),
+	}
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", []string{path}, cfg, l)
+	startUploads(t, us, ids, server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+	assertEquals(t, 1, len(received.requests))
+
+	assertEquals(t, []string{
+		"pre " + path + " env: " + path + " testUID ",
+		"post " + path + " success env: " + path + " testUID success",
+	}, readHookCalls(t, calls))
+}
+
+func TestUploadPreHookFailure(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+	path := files[0].Name()
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	cfg := &UploadableConfig{
+		Hooks:          true,
+		PreUploadHook:  writeHookScript(t, dir, "pre", calls, 1),
+		PostUploadHook: writeHookScript(t, dir, "post", calls, 0),
+		HookTimeout:    Duration(5 * time.Second),
+	}
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", []string{path}, cfg, l)
+	startUploads(t, us, ids, server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+	assertEquals(t, 0, len(received.requests)) // upload skipped
+
+	assertEquals(t, []string{
+		"pre " + path + " env: " + path + " testUID ",
+		"post " + path + " failure env: " + path + " testUID failure",
+	}, readHookCalls(t, calls))
+}
+
+func TestUploadHooksDisabled(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	cfg := &UploadableConfig{
+		PreUploadHook:  writeHookScript(t, dir, "pre", calls, 1),
+		PostUploadHook: writeHookScript(t, dir, "post", calls, 0),
+		HookTimeout:    Duration(5 * time.Second),
+	}
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+	startUploads(t, us, ids, server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+	assertEquals(t, 1, len(received.requests))
+	assertNotExists(t, calls)
+}
+
+func TestPreUploadHookTimeout(t *testing.T) {
+	dir := t.TempDir()
+	hook := filepath.Join(dir, "slow")
+	assertNoError(t, os.WriteFile(hook, []byte("#!/bin/sh\nsleep 10\n"), 0700))
+
+	cfg := &UploadableConfig{Hooks: true, PreUploadHook: hook, HookTimeout: Duration(100 * time.Millisecond)}
+
+	start := time.Now()
+	err := runPreUploadHook(cfg, "testUID", "test.txt")
+	assertError(t, err)
+	if !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("timeout error expected, but was %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("hook not killed on timeout")
+	}
+}
+
+// writeHookScript writes a hook script, which appends its name, arguments and environment to the calls file
+// and exits with the given code
+func writeHookScript(t *testing.T, dir string, name string, calls string, exitCode int) string {
+	t.Helper()
+
+	script := filepath.Join(dir, name)
+	content := "#!/bin/sh\n" +
+		"echo \"" + name + " $@ env: $FILE_UPLOAD_PATH $FILE_UPLOAD_CORRELATION_ID $FILE_UPLOAD_OUTCOME\" >> " + calls + "\n" +
+		"exit " + strconv.Itoa(exitCode) + "\n"
+	assertNoError(t, os.WriteFile(script, []byte(content), 0700))
+
+	return script
+}
+
+func readHookCalls(t *testing.T, calls string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(calls)
+	assertNoError(t, err)
+
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
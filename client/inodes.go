@@ -0,0 +1,46 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errInodesExhausted is returned, when the free inodes on a file system are not enough to create the needed files
+var errInodesExhausted = errors.New("free inodes exhausted")
+
+// freeInodes returns the number of free inodes on the file system of the given directory and false,
+// if not supported on the platform or by the file system. Replaced in the tests.
+var freeInodes = statFreeInodes
+
+// checkFreeInodes returns error, if the file system of the given directory has less than the given minimum free inodes,
+// so operations creating files fail with a clear error, instead of cryptic failures to create the files. The check is
+// skipped, where not supported, and if the minimum is zero.
+func checkFreeInodes(dir string, min int) error {
+	if min <= 0 {
+		return nil
+	}
+
+	free, supported, err := freeInodes(dir)
+	if err != nil || !supported {
+		return nil // the file creation itself will fail, if the directory is not accessible
+	}
+
+	if free < uint64(min) {
+		return fmt.Errorf("%w on the file system of '%s' - %d free, at least %d required to create temporary files",
+			errInodesExhausted, dir, free, min)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func mockFreeInodes(t *testing.T, free uint64, supported bool, err error) {
+	original := freeInodes
+	freeInodes = func(dir string) (uint64, bool, error) {
+		return free, supported, err
+	}
+	t.Cleanup(func() { freeInodes = original })
+}
+
+func TestCheckFreeInodes(t *testing.T) {
+	mockFreeInodes(t, 10, true, nil)
+	assertNoError(t, checkFreeInodes("/tmp", 0))
+	assertNoError(t, checkFreeInodes("/tmp", 10))
+
+	err := checkFreeInodes("/tmp", 11)
+	if !errors.Is(err, errInodesExhausted) || !strings.Contains(err.Error(), "'/tmp' - 10 free, at least 11 required") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	mockFreeInodes(t, 0, false, nil)
+	assertNoError(t, checkFreeInodes("/tmp", 16))
+
+	mockFreeInodes(t, 0, false, errors.New("not accessible"))
+	assertNoError(t, checkFreeInodes("/tmp", 16))
+}
+
+func TestUploadInodesExhausted(t *testing.T) {
+	mockFreeInodes(t, 3, true, nil)
+
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{Compress: true, CompressFormat: "gzip", MinFreeInodes: 16}, l)
+
+	startUploads(t, us, ids, server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+	if status := l.getStatus(); !strings.Contains(status.Message, errInodesExhausted.Error()) {
+		t.Errorf("unexpected failure message %s", status.Message)
+	}
+	assertEquals(t, 0, len(received.get()))
+
+	// files, uploaded as they are, do not need free inodes
+	l = NewTestStatusListener(t)
+	ids = us.AddMultiWithConfig("testUID2", getPaths(files), &UploadableConfig{MinFreeInodes: 16}, l)
+
+	startUploads(t, us, ids, server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+}
+
+func TestArchiveInodesExhausted(t *testing.T) {
+	mockFreeInodes(t, 0, true, nil)
+
+	files := createTestFiles(t, 2, false, false)
+	defer cleanFiles(files)
+
+	u, err := NewAutoUploadable(&UploadableConfig{MinFreeInodes: 1}, nil)
+	assertNoError(t, err)
+
+	err = u.UploadFiles("testUID", getPaths(files), map[string]string{archiveModeOption: "tar"})
+	if !errors.Is(err, errInodesExhausted) {
+		t.Fatalf("expected free inodes error, but was %v", err)
+	}
+
+	_, err = u.UploadDirectory("testUID", os.TempDir(), func(path string) bool { return true }, nil)
+	if !errors.Is(err, errInodesExhausted) {
+		t.Fatalf("expected free inodes error, but was %v", err)
+	}
+}
+
+func TestStatFreeInodes(t *testing.T) {
+	free, supported, err := statFreeInodes(t.TempDir())
+	assertNoError(t, err)
+	if supported && free == 0 {
+		t.Errorf("no free inodes reported for the temporary directory")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Event journal rotation settings, same as the log file defaults
+const (
+	journalFileSize   = 2 // MB
+	journalFileCount  = 5
+	journalFileMaxAge = 28 // days
+)
+
+// Upload lifecycle events, written to the event journal
+const (
+	JournalEventStart  = "start"
+	JournalEventFinish = "finish"
+	JournalEventFail   = "fail"
+	JournalEventCancel = "cancel"
+)
+
+// JournalEntry is a single line of the event journal
+type JournalEntry struct {
+	Time   time.Time    `json:"time"`
+	Event  string       `json:"event"`
+	Status UploadStatus `json:"status"`
+}
+
+// eventJournal appends upload lifecycle events as JSON lines to a local file. Progress updates are not journaled,
+// only the start of an upload and its terminal state.
+type eventJournal struct {
+	out     io.WriteCloser
+	started map[string]bool
+
+	mutex sync.Mutex
+}
+
+func newEventJournal(file string) (*eventJournal, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+
+	out := &lumberjack.Logger{
+		Filename:   file,
+		MaxSize:    journalFileSize,
+		MaxBackups: journalFileCount,
+		MaxAge:     journalFileMaxAge,
+		LocalTime:  true,
+		Compress:   true,
+	}
+
+	return &eventJournal{out: out, started: make(map[string]bool)}, nil
+}
+
+// add writes a journal entry, if the given status corresponds to a lifecycle event
+func (j *eventJournal) add(status *UploadStatus) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var event string
+	switch status.State {
+	case StateUploading:
+		if j.started[status.CorrelationID] {
+			return // progress update
+		}
+		j.started[status.CorrelationID] = true
+		event = JournalEventStart
+	case StateSuccess:
+		event = JournalEventFinish
+	case StateFailed:
+		event = JournalEventFail
+	case StateCanceled:
+		event = JournalEventCancel
+	default:
+		return
+	}
+
+	if event != JournalEventStart {
+		delete(j.started, status.CorrelationID)
+	}
+
+	data, err := json.Marshal(&JournalEntry{time.Now(), event, *status})
+	if err == nil {
+		_, err = j.out.Write(append(data, '\n'))
+	}
+
+	if err != nil {
+		logger.Errorf("failed to write '%s' event for upload %s to the event journal: %v", event, status.CorrelationID, err)
+	}
+}
+
+func (j *eventJournal) close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.out.Close()
+}
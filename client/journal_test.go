@@ -0,0 +1,101 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type journalingListener struct {
+	*TestStatusListener
+	journal *eventJournal
+}
+
+func (l *journalingListener) uploadStatusUpdated(s *UploadStatus) {
+	l.journal.add(s)
+	l.TestStatusListener.uploadStatusUpdated(s)
+}
+
+func TestEventJournal(t *testing.T) {
+	dir, err := os.MkdirTemp(".", "journal")
+	assertNoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "events", "journal.log")
+	journal, err := newEventJournal(file)
+	assertNoError(t, err)
+
+	files := createTestFiles(t, 2, true, false)
+	defer cleanFiles(files)
+
+	server := startTestServer(t, 0, false)
+	defer server.Close()
+
+	us := NewUploads()
+
+	l := &journalingListener{NewTestStatusListener(t), journal}
+	ids := us.AddMultiWithConfig("completed", getPaths(files), &UploadableConfig{}, l)
+	startUploads(t, us, ids, server.URL)
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	l = &journalingListener{NewTestStatusListener(t), journal}
+	ids = us.AddMultiWithConfig("failed", []string{"non-existing.grbg"}, &UploadableConfig{}, l)
+	startUploads(t, us, ids, server.URL)
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+
+	assertNoError(t, journal.close())
+
+	entries := readJournal(t, file)
+	assertEquals(t, 4, len(entries))
+
+	expected := []struct{ id, event, state string }{
+		{"completed", JournalEventStart, StateUploading},
+		{"completed", JournalEventFinish, StateSuccess},
+		{"failed", JournalEventStart, StateUploading},
+		{"failed", JournalEventFail, StateFailed},
+	}
+	for i, e := range expected {
+		assertEquals(t, e.id, entries[i].Status.CorrelationID)
+		assertEquals(t, e.event, entries[i].Event)
+		assertEquals(t, e.state, entries[i].Status.State)
+	}
+
+	assertEquals(t, 100, entries[1].Status.Progress)
+	if entries[3].Status.Message == "" {
+		t.Error("failure message expected in the journal")
+	}
+}
+
+func readJournal(t *testing.T, file string) []JournalEntry {
+	t.Helper()
+
+	data, err := os.ReadFile(file)
+	assertNoError(t, err)
+
+	var entries []JournalEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		entry := JournalEntry{}
+		assertNoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+)
+
+// localEvents publishes the upload lifecycle events - the upload status on each upload state transition - to a topic
+// of the local MQTT broker, so other on-device components, e.g. a UI or a cleanup service, can react to them.
+// Progress updates, which do not change the upload state, are not published. Publishing is best effort - errors
+// are only logged.
+type localEvents struct {
+	topic    string
+	encoding string
+
+	client MQTT.Client
+	states map[string]string // last published state, by upload correlation ID
+
+	mutex sync.Mutex
+}
+
+func newLocalEvents(topic string, encoding string) *localEvents {
+	return &localEvents{topic: topic, encoding: encoding, states: make(map[string]string)}
+}
+
+// connect sets the client of the local MQTT broker, to which the events are published
+func (e *localEvents) connect(client MQTT.Client) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.client = client
+}
+
+// add publishes the given status, if the upload state changed. Does not block.
+func (e *localEvents) add(status *UploadStatus) {
+	e.mutex.Lock()
+	client := e.client
+	changed := e.states[status.CorrelationID] != status.State
+	if status.finished() {
+		delete(e.states, status.CorrelationID)
+	} else {
+		e.states[status.CorrelationID] = status.State
+	}
+	e.mutex.Unlock()
+
+	if client == nil || !changed {
+		return
+	}
+
+	var payload []byte
+	if e.encoding == StatusEncodingCBOR {
+		payload = EncodeCompactStatus(status)
+	} else {
+		var err error
+		if payload, err = json.Marshal(status); err != nil {
+			logger.Errorf("failed to encode the lifecycle event of upload %s: %v", status.CorrelationID, err)
+			return
+		}
+	}
+
+	token := client.Publish(e.topic, 1, false, payload)
+	go func() {
+		if token.Wait() && token.Error() != nil {
+			logger.Errorf("failed to publish the lifecycle event of upload %s to '%s': %v",
+				status.CorrelationID, e.topic, token.Error())
+		}
+	}()
+}
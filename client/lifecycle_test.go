@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+)
+
+const testLocalEventsTopic = "kanto/fileupload/events"
+
+// localEventsClient records the messages, published to the local events topic
+type localEventsClient struct {
+	*mockedClient
+	events chan []byte
+}
+
+func (client *localEventsClient) Publish(topic string, qos byte, retained bool, payload interface{}) MQTT.Token {
+	if topic != testLocalEventsTopic {
+		return client.mockedClient.Publish(topic, qos, retained, payload)
+	}
+
+	client.events <- payload.([]byte)
+	return &mockedToken{}
+}
+
+func (client *localEventsClient) event(t *testing.T) []byte {
+	t.Helper()
+
+	select {
+	case payload := <-client.events:
+		return payload
+	case <-time.After(5 * time.Second):
+		t.Fatal("local event not published")
+	}
+	return nil
+}
+
+func TestLocalEvents(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	addTestFile(t, "a.txt")
+
+	server := startTestServer(t, 0, false)
+	defer server.Close()
+
+	testCfg = &UploadableConfig{FeatureID: featureID, Type: "test_type", Context: "test_context",
+		LocalEventsTopic: testLocalEventsTopic, LocalEventsEncoding: StatusEncodingJSON}
+
+	f, err := NewFileUpload(filepath.Join(basedir, "*.txt"), ModeStrict, testCfg)
+	assertNoError(t, err)
+
+	client := &localEventsClient{newMockedClient(), make(chan []byte, 10)}
+	f.Connect(client, &EdgeConfiguration{DeviceID: namespace + ":" + deviceID, TenantID: "testTenantID"})
+	defer f.Disconnect()
+	client.twinMsg(t, modify)
+
+	assertNoError(t, f.DoTrigger("lifecycle", nil))
+	id := client.liveMsg(t, request)["correlationId"].(string)
+	assertNoError(t, f.uploadable.uploads.Get(id).start(map[string]string{uploaders.URLProp: server.URL}))
+
+	var states []string
+	for {
+		status := &UploadStatus{}
+		assertNoError(t, json.Unmarshal(client.event(t), status))
+		assertEquals(t, "lifecycle", status.CorrelationID)
+		states = append(states, status.State)
+
+		if status.finished() {
+			break
+		}
+	}
+	assertEquals(t, []string{StateUploading, StateSuccess}, states)
+
+	select {
+	case payload := <-client.events:
+		t.Fatalf("no more local events expected, but received %s", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestLocalEventsTransitions(t *testing.T) {
+	e := newLocalEvents(testLocalEventsTopic, StatusEncodingCBOR)
+
+	client := &localEventsClient{newMockedClient(), make(chan []byte, 10)}
+	e.add(&UploadStatus{CorrelationID: "first", State: StateUploading}) // not connected yet
+	e.connect(client)
+
+	for _, status := range []*UploadStatus{
+		{CorrelationID: "first", State: StateUploading, Progress: 10},
+		{CorrelationID: "second", State: StateUploading},
+		{CorrelationID: "first", State: StatePaused, Progress: 50},
+		{CorrelationID: "second", State: StateUploading, Progress: 70},
+		{CorrelationID: "first", State: StateUploading, Progress: 50},
+		{CorrelationID: "first", State: StateFailed, Progress: 60},
+	} {
+		e.add(status)
+	}
+
+	for _, expected := range []*UploadStatus{
+		{CorrelationID: "second", State: StateUploading},
+		{CorrelationID: "first", State: StatePaused, Progress: 50},
+		{CorrelationID: "first", State: StateUploading, Progress: 50},
+		{CorrelationID: "first", State: StateFailed, Progress: 60},
+	} {
+		status, err := DecodeCompactStatus(client.event(t))
+		assertNoError(t, err)
+		assertEquals(t, expected, status)
+	}
+
+	assertEquals(t, 0, len(client.events))
+	assertEquals(t, map[string]string{"second": StateUploading}, e.states)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// Names of the upload metrics, shared by the metrics exporters
+const (
+	MetricUploadsStarted   = "uploads.started"
+	MetricUploadsSucceeded = "uploads.succeeded"
+	MetricUploadsFailed    = "uploads.failed"
+	MetricUploadsCanceled  = "uploads.canceled"
+	MetricUploadDuration   = "upload.duration"
+	MetricBandwidth        = "bandwidth"
+)
+
+// UploadMetrics is a snapshot of the upload counters
+type UploadMetrics struct {
+	Started   int64
+	Succeeded int64
+	Failed    int64
+	Canceled  int64
+
+	InFlight int64 // started, but not finished uploads
+
+	Duration time.Duration // total duration of the finished uploads
+}
+
+// metricsSink is notified on each change of the upload metrics
+type metricsSink interface {
+	count(metric string)
+	timing(metric string, duration time.Duration)
+	gauge(metric string, value int64)
+}
+
+// uploadMetrics counts upload lifecycle events, derived from the upload status updates
+type uploadMetrics struct {
+	metrics UploadMetrics
+	started map[string]bool
+
+	sinks []metricsSink
+
+	mutex sync.Mutex
+}
+
+func newUploadMetrics(sinks ...metricsSink) *uploadMetrics {
+	return &uploadMetrics{started: make(map[string]bool), sinks: sinks}
+}
+
+// add updates the metrics with the given status. The given bandwidth is reported when an upload is finished.
+func (m *uploadMetrics) add(status *UploadStatus, bandwidth int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if status.State == StateUploading {
+		if !m.started[status.CorrelationID] {
+			m.started[status.CorrelationID] = true
+			m.metrics.Started++
+			m.count(MetricUploadsStarted)
+		}
+		return
+	}
+
+	var metric string
+	switch status.State {
+	case StateSuccess:
+		m.metrics.Succeeded++
+		metric = MetricUploadsSucceeded
+	case StateFailed:
+		m.metrics.Failed++
+		metric = MetricUploadsFailed
+	case StateCanceled:
+		m.metrics.Canceled++
+		metric = MetricUploadsCanceled
+	default:
+		return
+	}
+	delete(m.started, status.CorrelationID)
+
+	m.count(metric)
+
+	if !status.StartTime.IsZero() && !status.EndTime.IsZero() {
+		duration := status.EndTime.Sub(status.StartTime)
+		m.metrics.Duration += duration
+		for _, sink := range m.sinks {
+			sink.timing(MetricUploadDuration, duration)
+		}
+	}
+
+	for _, sink := range m.sinks {
+		sink.gauge(MetricBandwidth, bandwidth)
+	}
+}
+
+func (m *uploadMetrics) count(metric string) {
+	for _, sink := range m.sinks {
+		sink.count(metric)
+	}
+}
+
+// snapshot returns the current values of the upload counters
+func (m *uploadMetrics) snapshot() UploadMetrics {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	metrics := m.metrics
+	metrics.InFlight = int64(len(m.started))
+
+	return metrics
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"strconv"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+// Policies for choosing between multipart and single request uploads
+const (
+	MultipartPolicyDefault   = "default"
+	MultipartPolicyAuto      = "auto"
+	MultipartPolicyMultipart = "multipart"
+	MultipartPolicySingle    = "single"
+)
+
+// chooseMultipart returns the policy, multipart or single, with which a file is uploaded under the given policy
+// and measured upload bandwidth. The automatic policy chooses multipart uploads on links slower than the given
+// threshold, on which a failed part is re-attempted instead of the whole file, and single request uploads with
+// lower overhead on faster links. Returns the default policy, if the bandwidth is not measured yet.
+func chooseMultipart(policy string, threshold int64, bandwidth int64) string {
+	if policy != MultipartPolicyAuto {
+		return policy
+	}
+
+	switch {
+	case bandwidth <= 0:
+		return MultipartPolicyDefault
+	case bandwidth < threshold:
+		return MultipartPolicyMultipart
+	default:
+		return MultipartPolicySingle
+	}
+}
+
+// withMultipartOptions returns the given 'start' operation options, with the multipart part size set according
+// to the configured multipart policy and the given measured upload bandwidth, overriding the one specified by the
+// backend. Only AWS S3 uploads are multipart, the options of the other storage providers are returned unchanged.
+func withMultipartOptions(options map[string]string, cfg *UploadableConfig, bandwidth int64) map[string]string {
+	if providerOf(options) != uploaders.StorageProviderAWS {
+		return options
+	}
+
+	var partSize int64
+	switch chooseMultipart(cfg.MultipartPolicy, int64(cfg.MultipartBandwidthThreshold), bandwidth) {
+	case MultipartPolicyMultipart:
+		partSize = uploaders.AWSMinPartSize
+	case MultipartPolicySingle:
+		partSize = uploaders.AWSMaxPartSize
+	default:
+		return options
+	}
+
+	logger.Debugf("uploading with part size %d bytes, measured bandwidth is %d bytes per second", partSize, bandwidth)
+
+	result := make(map[string]string, len(options)+1)
+	for k, v := range options {
+		result[k] = v
+	}
+	result[uploaders.AWSMultipartPartSize] = strconv.FormatInt(partSize, 10)
+
+	return result
+}
+
+// withPartialUploadOptions returns the given 'start' operation options, set to leave the parts of incomplete
+// AWS S3 multipart uploads in the storage, if partial uploads are kept. The options of the other storage providers
+// are returned unchanged.
+func withPartialUploadOptions(options map[string]string, cfg *UploadableConfig) map[string]string {
+	if !cfg.KeepPartialUploads || providerOf(options) != uploaders.StorageProviderAWS {
+		return options
+	}
+
+	result := make(map[string]string, len(options)+1)
+	for k, v := range options {
+		result[k] = v
+	}
+	result[uploaders.AWSMultipartLeaveParts] = "true"
+
+	return result
+}
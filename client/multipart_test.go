@@ -0,0 +1,100 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+func measuredBandwidth(bytes int64, duration time.Duration) int64 {
+	m := newBandwidthMeter(bandwidthWindow)
+	for i := 0; i < 10; i++ {
+		m.add(bytes/10, duration/10)
+	}
+
+	return m.bandwidth()
+}
+
+func TestChooseMultipart(t *testing.T) {
+	const threshold = 1024 * 1024
+
+	good := measuredBandwidth(100*1024*1024, 10*time.Second) // 10 MB/s
+	flaky := measuredBandwidth(1024*1024, 8*time.Second)     // 128 KB/s
+
+	assertEquals(t, MultipartPolicySingle, chooseMultipart(MultipartPolicyAuto, threshold, good))
+	assertEquals(t, MultipartPolicyMultipart, chooseMultipart(MultipartPolicyAuto, threshold, flaky))
+	assertEquals(t, MultipartPolicySingle, chooseMultipart(MultipartPolicyAuto, threshold, threshold))
+	assertEquals(t, MultipartPolicyDefault, chooseMultipart(MultipartPolicyAuto, threshold, 0)) // not measured yet
+
+	for _, policy := range []string{MultipartPolicyDefault, MultipartPolicyMultipart, MultipartPolicySingle} {
+		assertEquals(t, policy, chooseMultipart(policy, threshold, good))
+		assertEquals(t, policy, chooseMultipart(policy, threshold, flaky))
+	}
+}
+
+func TestWithMultipartOptions(t *testing.T) {
+	cfg := &UploadableConfig{MultipartPolicy: MultipartPolicyAuto, MultipartBandwidthThreshold: 1024 * 1024}
+
+	options := map[string]string{
+		StorageProvider:                uploaders.StorageProviderAWS,
+		uploaders.AWSBucket:            "testBucket",
+		uploaders.AWSRegion:            "eu-central-1",
+		uploaders.AWSAccessKeyID:       "testKey",
+		uploaders.AWSSecretAccessKey:   "testSecret",
+		uploaders.AWSMultipartPartSize: "10485760",
+	}
+
+	single := withMultipartOptions(options, cfg, 10*1024*1024)
+	assertEquals(t, strconv.FormatInt(uploaders.AWSMaxPartSize, 10), single[uploaders.AWSMultipartPartSize])
+	_, err := uploaders.NewAWSUploader(single)
+	assertNoError(t, err)
+
+	multipart := withMultipartOptions(options, cfg, 100*1024)
+	assertEquals(t, strconv.FormatInt(uploaders.AWSMinPartSize, 10), multipart[uploaders.AWSMultipartPartSize])
+	_, err = uploaders.NewAWSUploader(multipart)
+	assertNoError(t, err)
+
+	assertEquals(t, "10485760", options[uploaders.AWSMultipartPartSize]) // not modified
+	assertEquals(t, "10485760", withMultipartOptions(options, cfg, 0)[uploaders.AWSMultipartPartSize])
+
+	http := map[string]string{uploaders.URLProp: "http://localhost:1234/upload"}
+	assertEquals(t, http, withMultipartOptions(http, cfg, 10*1024*1024))
+}
+
+func TestWithPartialUploadOptions(t *testing.T) {
+	options := map[string]string{
+		StorageProvider:              uploaders.StorageProviderAWS,
+		uploaders.AWSBucket:          "testBucket",
+		uploaders.AWSRegion:          "eu-central-1",
+		uploaders.AWSAccessKeyID:     "testKey",
+		uploaders.AWSSecretAccessKey: "testSecret",
+	}
+
+	assertEquals(t, options, withPartialUploadOptions(options, &UploadableConfig{}))
+
+	kept := withPartialUploadOptions(options, &UploadableConfig{KeepPartialUploads: true})
+	assertEquals(t, "true", kept[uploaders.AWSMultipartLeaveParts])
+	_, ok := options[uploaders.AWSMultipartLeaveParts] // not modified
+	assertEquals(t, false, ok)
+	_, err := uploaders.NewAWSUploader(kept)
+	assertNoError(t, err)
+
+	http := map[string]string{uploaders.URLProp: "http://localhost:1234/upload"}
+	assertEquals(t, http, withPartialUploadOptions(http, &UploadableConfig{KeepPartialUploads: true}))
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+)
+
+const (
+	// prometheusPath is the path, on which the metrics are exposed
+	prometheusPath = "/metrics"
+	// prometheusPrefix is prepended to the names of the exposed metrics
+	prometheusPrefix = "file_upload_"
+
+	prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// prometheusDurationBuckets are the upper bounds in seconds of the upload duration histogram buckets
+var prometheusDurationBuckets = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 600, 1800, 3600}
+
+// prometheusExporter exposes the upload metrics over HTTP in the Prometheus text format. The upload counters and
+// the in-flight uploads gauge are taken from the upload metrics snapshot, the transferred bytes and the bandwidth
+// from the uploads, on each scrape. As a metrics sink, it only records the upload durations in a histogram.
+type prometheusExporter struct {
+	server *http.Server
+	addr   net.Addr
+
+	metrics *uploadMetrics
+	uploads *Uploads
+
+	durationCounts []int64 // per bucket, not cumulative
+	durationSum    float64
+	durationCount  int64
+
+	mutex sync.Mutex
+}
+
+// newPrometheusExporter starts serving metrics on the given address. The upload metrics should be set,
+// before the exporter is scraped.
+func newPrometheusExporter(addr string, uploads *Uploads) (*prometheusExporter, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start metrics server on '%s': %w", addr, err)
+	}
+
+	e := &prometheusExporter{
+		addr:           listener.Addr(),
+		uploads:        uploads,
+		durationCounts: make([]int64, len(prometheusDurationBuckets)+1),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(prometheusPath, e)
+	e.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := e.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("metrics server failed: %v", err)
+		}
+	}()
+	logger.Infof("serving metrics on %s%s", e.addr, prometheusPath)
+
+	return e, nil
+}
+
+func (e *prometheusExporter) count(metric string) {
+	// counters are taken from the upload metrics snapshot
+}
+
+func (e *prometheusExporter) timing(metric string, duration time.Duration) {
+	if metric != MetricUploadDuration {
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	seconds := duration.Seconds()
+	i := 0
+	for i < len(prometheusDurationBuckets) && seconds > prometheusDurationBuckets[i] {
+		i++
+	}
+	e.durationCounts[i]++
+	e.durationSum += seconds
+	e.durationCount++
+}
+
+func (e *prometheusExporter) gauge(metric string, value int64) {
+	// bandwidth is taken from the uploads
+}
+
+// ServeHTTP writes the current metrics in the Prometheus text format
+func (e *prometheusExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", prometheusContentType)
+	e.write(w)
+}
+
+func (e *prometheusExporter) write(w io.Writer) {
+	var m UploadMetrics
+	if e.metrics != nil {
+		m = e.metrics.snapshot()
+	}
+
+	writeMetric(w, "uploads_started_total", "counter", "Number of started uploads", m.Started)
+	writeMetric(w, "uploads_succeeded_total", "counter", "Number of successful uploads", m.Succeeded)
+	writeMetric(w, "uploads_failed_total", "counter", "Number of failed uploads", m.Failed)
+	writeMetric(w, "uploads_canceled_total", "counter", "Number of canceled uploads", m.Canceled)
+	writeMetric(w, "uploads_in_flight", "gauge", "Number of started, but not yet finished uploads", m.InFlight)
+	writeMetric(w, "bytes_transferred_total", "counter", "Number of uploaded bytes", e.uploads.BytesTransferred())
+	writeMetric(w, "bandwidth_bytes_per_second", "gauge", "Upload bandwidth, averaged over the recent transfers", e.uploads.Bandwidth())
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	name := prometheusPrefix + "upload_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Duration of the finished uploads\n# TYPE %s histogram\n", name, name)
+	cumulative := int64(0)
+	for i, bound := range prometheusDurationBuckets {
+		cumulative += e.durationCounts[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, e.durationCount)
+	fmt.Fprintf(w, "%s_sum %s\n", name, strconv.FormatFloat(e.durationSum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count %d\n", name, e.durationCount)
+}
+
+func writeMetric(w io.Writer, name string, metricType string, help string, value int64) {
+	name = prometheusPrefix + name
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, metricType, name, value)
+}
+
+// close shuts down the metrics server, waiting for the active scrapes up to the given timeout
+func (e *prometheusExporter) close(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return e.server.Shutdown(ctx)
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	u, err := NewAutoUploadable(&UploadableConfig{MetricsAddr: "127.0.0.1:0"}, nil)
+	assertNoError(t, err)
+	defer u.prometheus.close(time.Second)
+
+	url := "http://" + u.prometheus.addr.String() + prometheusPath
+	metrics := scrapeMetrics(t, url)
+	assertEquals(t, "0", metrics["file_upload_uploads_started_total"])
+	assertEquals(t, "0", metrics["file_upload_upload_duration_seconds_count"])
+
+	files := createTestFiles(t, 2, false, false)
+	defer cleanFiles(files)
+
+	server := startTestServer(t, 0, false)
+	defer server.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	cfg := &UploadableConfig{}
+	startUploads(t, u.uploads, u.uploads.AddMultiWithConfig("success", getPaths(files), cfg, u), server.URL)
+	waitMetric(t, url, "file_upload_uploads_succeeded_total", "1")
+
+	startUploads(t, u.uploads, u.uploads.AddMultiWithConfig("failure", getPaths(files[:1]), cfg, u), failing.URL)
+	waitMetric(t, url, "file_upload_uploads_failed_total", "1")
+
+	metrics = scrapeMetrics(t, url)
+	assertEquals(t, "2", metrics["file_upload_uploads_started_total"])
+	assertEquals(t, "0", metrics["file_upload_uploads_canceled_total"])
+	assertEquals(t, "0", metrics["file_upload_uploads_in_flight"])
+	assertEquals(t, "2", metrics["file_upload_upload_duration_seconds_count"])
+	assertEquals(t, "2", metrics[`file_upload_upload_duration_seconds_bucket{le="+Inf"}`])
+
+	transferred, err := strconv.ParseInt(metrics["file_upload_bytes_transferred_total"], 10, 64)
+	assertNoError(t, err)
+	if size := fileSize(t, files[0]) + fileSize(t, files[1]); transferred < size {
+		t.Errorf("at least %d transferred bytes expected, but were %d", size, transferred)
+	}
+
+	assertNoError(t, u.prometheus.close(time.Second))
+	if _, err := http.Get(url); err == nil {
+		t.Error("metrics server expected to be stopped")
+	}
+}
+
+func TestPrometheusDurationHistogram(t *testing.T) {
+	e := &prometheusExporter{uploads: NewUploads(), durationCounts: make([]int64, len(prometheusDurationBuckets)+1)}
+
+	e.timing(MetricUploadDuration, 500*time.Millisecond)
+	e.timing(MetricUploadDuration, time.Second)
+	e.timing(MetricUploadDuration, 2*time.Hour)
+
+	out := &strings.Builder{}
+	e.write(out)
+	metrics := parseMetrics(out.String())
+
+	assertEquals(t, "0", metrics[`file_upload_upload_duration_seconds_bucket{le="0.1"}`])
+	assertEquals(t, "1", metrics[`file_upload_upload_duration_seconds_bucket{le="0.5"}`])
+	assertEquals(t, "2", metrics[`file_upload_upload_duration_seconds_bucket{le="1"}`])
+	assertEquals(t, "2", metrics[`file_upload_upload_duration_seconds_bucket{le="3600"}`])
+	assertEquals(t, "3", metrics[`file_upload_upload_duration_seconds_bucket{le="+Inf"}`])
+	assertEquals(t, "7201.5", metrics["file_upload_upload_duration_seconds_sum"])
+	assertEquals(t, "3", metrics["file_upload_upload_duration_seconds_count"])
+}
+
+func TestPrometheusInvalidAddress(t *testing.T) {
+	_, err := NewAutoUploadable(&UploadableConfig{MetricsAddr: "invalid:address:0"}, nil)
+	assertError(t, err)
+}
+
+func scrapeMetrics(t *testing.T, url string) map[string]string {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	assertNoError(t, err)
+	defer resp.Body.Close()
+
+	assertEquals(t, prometheusContentType, resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	assertNoError(t, err)
+
+	return parseMetrics(string(body))
+}
+
+func parseMetrics(text string) map[string]string {
+	metrics := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		if i := strings.LastIndex(line, " "); i > 0 && !strings.HasPrefix(line, "#") {
+			metrics[line[:i]] = line[i+1:]
+		}
+	}
+
+	return metrics
+}
+
+func waitMetric(t *testing.T, url string, name string, value string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for scrapeMetrics(t, url)[name] != value {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for metric '%s' to become %s", name, value)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func fileSize(t *testing.T, f *os.File) int64 {
+	t.Helper()
+
+	info, err := f.Stat()
+	assertNoError(t, err)
+
+	return info.Size()
+}
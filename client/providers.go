@@ -0,0 +1,153 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+// StorageProviders is an ordered list of storage providers. Specified as a JSON array in the configuration file
+// and as a comma-separated list on the command line.
+type StorageProviders []string
+
+// String returns the comma-separated storage providers
+func (p StorageProviders) String() string {
+	return strings.Join(p, ",")
+}
+
+// Set implements flag.Value Set method
+func (p *StorageProviders) Set(v string) error {
+	if v == "" {
+		*p = nil
+		return nil
+	}
+
+	providers := strings.Split(v, ",")
+	for i, provider := range providers {
+		providers[i] = strings.ToLower(strings.TrimSpace(provider))
+		if err := ValidateStorageProvider(providers[i]); err != nil {
+			return err
+		}
+	}
+	*p = providers
+
+	return nil
+}
+
+// uploaderFactory creates an uploader from the 'start' operation options
+type uploaderFactory func(options map[string]string, serverCert string) (uploaders.Uploader, error)
+
+// storageProviders holds the registered storage providers and their uploader factories, in the order, in which
+// the providers are advertised to the backend
+var storageProviders = []struct {
+	name    string
+	factory uploaderFactory
+}{
+	{uploaders.StorageProviderAWS, func(options map[string]string, _ string) (uploaders.Uploader, error) {
+		return uploaders.NewAWSUploader(options)
+	}},
+	{uploaders.StorageProviderAzure, func(options map[string]string, _ string) (uploaders.Uploader, error) {
+		return uploaders.NewAzureUploader(options)
+	}},
+	{uploaders.StorageProviderHTTP, uploaders.NewHTTPUploader},
+	{uploaders.StorageProviderFile, func(options map[string]string, _ string) (uploaders.Uploader, error) {
+		return uploaders.NewFileSinkUploader(options)
+	}},
+	{uploaders.StorageProviderLocal, func(options map[string]string, _ string) (uploaders.Uploader, error) {
+		return uploaders.NewLocalUploader(options)
+	}},
+	{uploaders.StorageProviderWebDAV, uploaders.NewWebDAVUploader},
+}
+
+// RegisteredStorageProviders returns the names of the storage providers, for which uploaders are registered
+func RegisteredStorageProviders() []string {
+	names := make([]string, len(storageProviders))
+	for i, provider := range storageProviders {
+		names[i] = provider.name
+	}
+
+	return names
+}
+
+// uploaderFactoryOf returns the uploader factory of the given storage provider, or nil if it is not registered
+func uploaderFactoryOf(provider string) uploaderFactory {
+	for _, p := range storageProviders {
+		if p.name == strings.ToLower(provider) {
+			return p.factory
+		}
+	}
+
+	return nil
+}
+
+// ValidateStorageProvider returns error, if the given storage provider is not supported
+func ValidateStorageProvider(provider string) error {
+	if uploaderFactoryOf(provider) != nil {
+		return nil
+	}
+
+	names := RegisteredStorageProviders()
+
+	return fmt.Errorf("unsupported storage provider '%s' - supported are '%s' and '%s'", provider,
+		strings.Join(names[:len(names)-1], "', '"), names[len(names)-1])
+}
+
+// advertisedProviders returns the storage providers, advertised to the backend in the upload requests and
+// in the 'info' property - the configured supported providers, if specified, or else all registered providers
+func advertisedProviders(cfg *UploadableConfig) []string {
+	if len(cfg.SupportedProviders) > 0 {
+		return cfg.SupportedProviders
+	}
+
+	return RegisteredStorageProviders()
+}
+
+// isProviderSupported returns true, if uploads to the given storage provider are allowed by the configured
+// supported providers
+func isProviderSupported(cfg *UploadableConfig, provider string) bool {
+	if len(cfg.SupportedProviders) == 0 {
+		return true
+	}
+
+	for _, supported := range cfg.SupportedProviders {
+		if strings.EqualFold(supported, provider) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// providerOf returns the storage provider, to which files are uploaded with the given 'start' operation options
+func providerOf(options map[string]string) string {
+	if provider, ok := options[StorageProvider]; ok && provider != "" {
+		return strings.ToLower(provider)
+	}
+
+	return uploaders.StorageProviderHTTP
+}
+
+// withProvider returns a copy of the given 'start' operation options, uploading to the given storage provider.
+// The provider specific options, e.g. credentials, should be present in the options or in the credentials file.
+func withProvider(options map[string]string, provider string) map[string]string {
+	result := make(map[string]string, len(options)+1)
+	for k, v := range options {
+		result[k] = v
+	}
+	result[StorageProvider] = provider
+
+	return result
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+func TestStorageProvidersSet(t *testing.T) {
+	var providers StorageProviders
+
+	assertNoError(t, providers.Set("AWS, file,generic"))
+	assertEquals(t, StorageProviders{"aws", "file", "generic"}, providers)
+	assertEquals(t, "aws,file,generic", providers.String())
+
+	assertNoError(t, providers.Set("Local"))
+	assertEquals(t, StorageProviders{uploaders.StorageProviderLocal}, providers)
+
+	assertNoError(t, providers.Set(""))
+	assertEquals(t, 0, len(providers))
+
+	assertError(t, providers.Set("aws,ftp"))
+}
+
+func TestFallbackProvider(t *testing.T) {
+	files := createTestFiles(t, 2, false, false)
+	defer cleanFiles(files)
+
+	requests := int32(0)
+	primary := startStatusServer(t, http.StatusServiceUnavailable, &requests)
+	defer primary.Close()
+
+	sink := t.TempDir()
+	cfg := &UploadableConfig{FallbackProviders: StorageProviders{uploaders.StorageProviderFile}}
+
+	l := startFallbackUpload(t, NewUploads(), files, cfg, primary.URL, sink)
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	assertEquals(t, uploaders.StorageProviderFile, l.getStatus().Provider)
+	assertEquals(t, int32(2), atomic.LoadInt32(&requests))
+	for _, f := range files {
+		actual, err := os.ReadFile(filepath.Join(sink, filepath.Base(f.Name())))
+		assertNoError(t, err)
+		expected, err := os.ReadFile(f.Name())
+		assertNoError(t, err)
+		assertEquals(t, string(expected), string(actual))
+	}
+}
+
+func TestFallbackProviderFails(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	requests := int32(0)
+	primary := startStatusServer(t, http.StatusBadGateway, &requests)
+	defer primary.Close()
+
+	cfg := &UploadableConfig{FallbackProviders: StorageProviders{uploaders.StorageProviderFile}}
+
+	l := startFallbackUpload(t, NewUploads(), files, cfg, primary.URL, "") // no fallback directory
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+
+	if !strings.Contains(l.getStatus().Message, "502") {
+		t.Errorf("primary provider error expected, but was '%s'", l.getStatus().Message)
+	}
+	assertEquals(t, "", l.getStatus().Provider)
+}
+
+func TestFallbackProviderPermanentError(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	requests := int32(0)
+	primary := startStatusServer(t, http.StatusForbidden, &requests)
+	defer primary.Close()
+
+	sink := t.TempDir()
+	cfg := &UploadableConfig{FallbackProviders: StorageProviders{uploaders.StorageProviderFile}}
+
+	l := startFallbackUpload(t, NewUploads(), files, cfg, primary.URL, sink)
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+
+	entries, err := os.ReadDir(sink)
+	assertNoError(t, err)
+	assertEquals(t, 0, len(entries))
+}
+
+func TestFallbackProviderUnavailableEndpoint(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	requests := int32(0)
+	primary := startStatusServer(t, http.StatusServiceUnavailable, &requests)
+	defer primary.Close()
+
+	cfg := &UploadableConfig{
+		EndpointHealthTTL: Duration(time.Minute),
+		FallbackProviders: StorageProviders{uploaders.StorageProviderFile},
+	}
+	us := NewUploads()
+
+	l := startFallbackUpload(t, us, files, cfg, primary.URL, t.TempDir())
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+	assertEquals(t, int32(1), atomic.LoadInt32(&requests))
+
+	l = startFallbackUpload(t, us, files, cfg, primary.URL, t.TempDir())
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+	assertEquals(t, uploaders.StorageProviderFile, l.getStatus().Provider)
+	assertEquals(t, int32(1), atomic.LoadInt32(&requests)) // the primary endpoint is cached as unavailable
+}
+
+func TestPrimaryProviderReported(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	server := startTestServer(t, 0, false)
+	defer server.Close()
+
+	cfg := &UploadableConfig{FallbackProviders: StorageProviders{uploaders.StorageProviderFile}}
+
+	l := startFallbackUpload(t, NewUploads(), files, cfg, server.URL, t.TempDir())
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+	assertEquals(t, uploaders.StorageProviderHTTP, l.getStatus().Provider)
+}
+
+func startStatusServer(t *testing.T, status int, requests *int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.WriteHeader(status)
+	}))
+}
+
+func startFallbackUpload(t *testing.T, us *Uploads, files []*os.File, cfg *UploadableConfig, url string, dir string) *TestStatusListener {
+	t.Helper()
+
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+
+	options := map[string]string{uploaders.URLProp: url}
+	if dir != "" {
+		options[uploaders.FileDirectory] = dir
+	}
+	for _, id := range ids {
+		assertNoError(t, us.Get(id).start(options))
+	}
+
+	return l
+}
+
+func TestRegisteredStorageProviders(t *testing.T) {
+	registered := RegisteredStorageProviders()
+	assertEquals(t, []string{uploaders.StorageProviderAWS, uploaders.StorageProviderAzure, uploaders.StorageProviderHTTP,
+		uploaders.StorageProviderFile, uploaders.StorageProviderLocal, uploaders.StorageProviderWebDAV}, registered)
+
+	for _, provider := range registered {
+		assertNoError(t, ValidateStorageProvider(strings.ToUpper(provider)))
+	}
+	assertError(t, ValidateStorageProvider("ftp"))
+
+	u, err := NewAutoUploadable(&UploadableConfig{}, nil)
+	assertNoError(t, err)
+	assertEquals(t, strings.Join(registered, ","), u.info["supportedProviders"])
+
+	u, err = NewAutoUploadable(&UploadableConfig{SupportedProviders: StorageProviders{"azure", "local"}}, nil)
+	assertNoError(t, err)
+	assertEquals(t, "azure,local", u.info["supportedProviders"])
+}
+
+func TestAdvertisedStorageProviders(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	_, _, c, _ := getTestFiles(t)
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeScoped)
+	defer f.Disconnect()
+
+	assertNoError(t, f.DoTrigger("registeredCorrelationID", map[string]string{uploadFilesProperty: c}))
+	msg := client.liveMsg(t, request)
+	assertEquals(t, strings.Join(RegisteredStorageProviders(), ", "), msg["options"].(map[string]interface{})["storage.providers"])
+
+	f.uploadable.cfg.SupportedProviders = StorageProviders{uploaders.StorageProviderHTTP, uploaders.StorageProviderLocal}
+	assertNoError(t, f.DoTrigger("supportedCorrelationID", map[string]string{uploadFilesProperty: c}))
+	msg = client.liveMsg(t, request)
+	assertEquals(t, "generic, local", msg["options"].(map[string]interface{})["storage.providers"])
+}
+
+func TestUnsupportedStorageProvider(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	cfg := &UploadableConfig{SupportedProviders: StorageProviders{uploaders.StorageProviderAWS}}
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+
+	assertError(t, us.Get(ids[0]).start(map[string]string{
+		StorageProvider:         uploaders.StorageProviderFile,
+		uploaders.FileDirectory: t.TempDir(),
+	}))
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+)
+
+// errUploadTooSlow is returned, when an upload is aborted, because its rate stayed below the minimum upload rate
+var errUploadTooSlow = errors.New("upload rate is below the minimum upload rate")
+
+// errUploadStalled is returned, when an upload is aborted, because no bytes were sent within the per-file timeout
+var errUploadStalled = errors.New("upload made no progress")
+
+// progressCheck checks the number of bytes of an upload, sent within the last window, and returns the error,
+// with which the upload should be aborted, if the progress is not sufficient
+type progressCheck struct {
+	window time.Duration
+	check  func(sent int64) error
+}
+
+// progressChecks returns the configured checks of the upload progress - the minimum upload rate, which is not checked
+// for resumed uploads, and the per-file timeout, which aborts uploads making no progress at all
+func (u *SingleUpload) progressChecks(offset int64) []progressCheck {
+	var checks []progressCheck
+
+	floor, window := int64(u.parent.cfg.MinUploadRate), time.Duration(u.parent.cfg.MinUploadRateWindow)
+	if floor > 0 && window > 0 && offset == 0 {
+		checks = append(checks, progressCheck{window, func(sent int64) error {
+			if rate := int64(float64(sent) / window.Seconds()); rate < floor {
+				return fmt.Errorf("%w of %d B/s - %d B/s within the last %v, the upload is aborted to be retried later",
+					errUploadTooSlow, floor, rate, window)
+			}
+			return nil
+		}})
+	}
+
+	if timeout := time.Duration(u.parent.cfg.PerFileTimeout); timeout > 0 {
+		checks = append(checks, progressCheck{timeout, func(sent int64) error {
+			if sent == 0 {
+				return fmt.Errorf("%w within the last %v, the upload is aborted to be retried later", errUploadStalled, timeout)
+			}
+			return nil
+		}})
+	}
+
+	return checks
+}
+
+// watchProgress aborts the upload of the given file with the given cancel function, if the number of bytes, sent
+// within the window of any of the progress checks, is not sufficient, e.g. on a degraded link or a hanging connection,
+// so the upload fails and can be retried later, instead of crawling or hanging. The sent bytes are sampled a few times
+// per window, so each window slides. The progress is no longer watched, once the whole file is sent. Returns function,
+// which stops watching and returns the error, with which the upload was aborted, if any.
+func (u *SingleUpload) watchProgress(upload *os.File, offset int64, cancel context.CancelFunc) func() error {
+	checks := u.progressChecks(offset)
+	if len(checks) == 0 {
+		return func() error { return nil }
+	}
+
+	info, err := upload.Stat()
+	if err != nil {
+		return func() error { return nil }
+	}
+	size := info.Size()
+
+	shortest, longest := checks[0].window, checks[0].window
+	for _, c := range checks[1:] {
+		if c.window < shortest {
+			shortest = c.window
+		}
+		if c.window > longest {
+			longest = c.window
+		}
+	}
+
+	done := make(chan struct{})
+	result := make(chan error, 1)
+
+	go func() {
+		ticker := time.NewTicker(shortest / 4)
+		defer ticker.Stop()
+
+		sent, _ := u.sent(upload)
+		samples := []transferSample{{time.Now(), sent}}
+		for {
+			select {
+			case <-done:
+				result <- nil
+				return
+			case now := <-ticker.C:
+				sent, open := u.sent(upload)
+				if !open || sent >= size { // waiting for the storage response
+					result <- nil
+					return
+				}
+
+				if sent < samples[len(samples)-1].bytes { // a re-attempted upload reports from the beginning
+					samples = samples[:0]
+				}
+				samples = append(samples, transferSample{now, sent})
+
+				for _, c := range checks {
+					start := sampleBefore(samples, now.Add(-c.window))
+					if start < 0 { // not watched for the whole window yet
+						continue
+					}
+
+					if err := c.check(sent - samples[start].bytes); err != nil {
+						logger.Warnf("aborting upload %v: %v", u, err)
+
+						cancel()
+						upload.Close() // unblocks uploaders, which cannot be canceled with a context
+
+						result <- err
+						return
+					}
+				}
+
+				if oldest := sampleBefore(samples, now.Add(-longest)); oldest > 0 { // older samples are not needed
+					samples = samples[oldest:]
+				}
+			}
+		}
+	}()
+
+	return func() error {
+		close(done)
+		return <-result
+	}
+}
+
+// sampleBefore returns the index of the latest of the given samples, taken not after the given time, or -1 if none
+func sampleBefore(samples []transferSample, t time.Time) int {
+	for i := len(samples) - 1; i >= 0; i-- {
+		if !samples[i].time.After(t) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// sent returns the number of bytes of the given file, sent so far - as reported by the uploader or else the read
+// position of the file, for uploaders, which do not report the transferred bytes. Returns false, if the file is
+// already closed, e.g. by the HTTP client, once the request body is sent.
+func (u *SingleUpload) sent(upload *os.File) (int64, bool) {
+	sent := atomic.LoadInt64(&u.sentBytes)
+
+	position, err := upload.Seek(0, io.SeekCurrent)
+	if errors.Is(err, os.ErrClosed) {
+		return sent, false
+	}
+	if err == nil && position > sent {
+		sent = position
+	}
+
+	return sent, true
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMinUploadRateAbort(t *testing.T) {
+	testStalledUpload(t, &UploadableConfig{MinUploadRate: Megabyte, MinUploadRateWindow: Duration(300 * time.Millisecond)},
+		errUploadTooSlow)
+}
+
+func TestPerFileTimeoutAbort(t *testing.T) {
+	testStalledUpload(t, &UploadableConfig{PerFileTimeout: Duration(300 * time.Millisecond)}, errUploadStalled)
+}
+
+func TestMinUploadRateWaitingForResponse(t *testing.T) {
+	testSlowResponse(t, &UploadableConfig{MinUploadRate: Megabyte, MinUploadRateWindow: Duration(100 * time.Millisecond)})
+}
+
+func TestPerFileTimeoutWaitingForResponse(t *testing.T) {
+	testSlowResponse(t, &UploadableConfig{PerFileTimeout: Duration(100 * time.Millisecond)})
+}
+
+// testStalledUpload uploads a large file to a server, which reads the beginning of the request body and stalls,
+// and expects the upload to be aborted with the given error
+func testStalledUpload(t *testing.T, cfg *UploadableConfig, expected error) {
+	path := filepath.Join(t.TempDir(), "large.bin")
+	assertNoError(t, os.WriteFile(path, make([]byte, 32*Megabyte), 0644))
+
+	// the upload stalls mid-body, once the connection buffers are full
+	release := make(chan struct{})
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.CopyN(io.Discard, r.Body, int64(Megabyte))
+		<-release
+	}))
+	defer stalled.Close()
+	defer close(release)
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", []string{path}, cfg, l)
+
+	started := time.Now()
+	startUploads(t, us, ids, stalled.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+	if status := l.getStatus(); !strings.Contains(status.Message, expected.Error()) {
+		t.Errorf("unexpected failure message %s", status.Message)
+	}
+	if elapsed := time.Since(started); elapsed > 10*time.Second {
+		t.Errorf("upload aborted after %v", elapsed)
+	}
+}
+
+// testSlowResponse uploads files to a server, which responds slowly after reading the whole request body,
+// and expects the uploads to succeed, since the slow response is not counted against the upload progress
+func testSlowResponse(t *testing.T, cfg *UploadableConfig) {
+	files := createTestFiles(t, 2, true, false)
+	defer cleanFiles(files)
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+
+	startUploads(t, us, ids, slow.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+}
+
+func TestWatchProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.bin")
+	assertNoError(t, os.WriteFile(path, make([]byte, 1000), 0644))
+
+	window := Duration(40 * time.Millisecond)
+	stall := func(u *SingleUpload) { time.Sleep(150 * time.Millisecond) }
+	crawl := func(u *SingleUpload) { // slow, but making progress
+		for i := int64(1); i <= 15; i++ {
+			u.progress(i)
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	complete := func(u *SingleUpload) {
+		u.progress(1000)
+		stall(u)
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *UploadableConfig
+		offset   int64
+		progress func(u *SingleUpload)
+		expected error
+	}{
+		{"too slow", &UploadableConfig{MinUploadRate: Kilobyte, MinUploadRateWindow: window}, 0, crawl, errUploadTooSlow},
+		{"too slow resumed", &UploadableConfig{MinUploadRate: Kilobyte, MinUploadRateWindow: window}, 1, crawl, nil},
+		{"rate sent", &UploadableConfig{MinUploadRate: Kilobyte, MinUploadRateWindow: window}, 0, complete, nil},
+		{"stalled", &UploadableConfig{PerFileTimeout: window}, 0, stall, errUploadStalled},
+		{"stalled resumed", &UploadableConfig{PerFileTimeout: window}, 1, stall, errUploadStalled},
+		{"crawling", &UploadableConfig{PerFileTimeout: window}, 0, crawl, nil},
+		{"timeout sent", &UploadableConfig{PerFileTimeout: window}, 0, complete, nil},
+		{"both stalled", &UploadableConfig{MinUploadRate: 1, MinUploadRateWindow: window * 10, PerFileTimeout: window}, 0,
+			stall, errUploadStalled},
+		{"disabled", &UploadableConfig{MinUploadRateWindow: window}, 0, stall, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := os.Open(path)
+			assertNoError(t, err)
+			defer f.Close()
+
+			var canceled int32
+			u := &SingleUpload{parent: &MultiUpload{cfg: test.cfg}}
+			stop := u.watchProgress(f, test.offset, func() { atomic.StoreInt32(&canceled, 1) })
+			test.progress(u)
+
+			err = stop()
+			if !errors.Is(err, test.expected) || (test.expected == nil && err != nil) {
+				t.Errorf("expected %v, but was %v", test.expected, err)
+			}
+			assertEquals(t, test.expected != nil, atomic.LoadInt32(&canceled) == 1)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	dailyReportProperty = "dailyReport"
+
+	dailyReportTopFiles    = 10
+	dailyReportFilePrefix  = "upload-report-"
+	dailyReportCorrelation = "daily-report-"
+
+	reportDateLayout = "2006-01-02"
+)
+
+// DailyReport summarizes the uploads of a single day. It is reported in the 'dailyReport' property.
+type DailyReport struct {
+	Date string `json:"date"`
+
+	BytesUploaded int64 `json:"bytesUploaded"`
+
+	Started   int64 `json:"started"`
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"`
+	Canceled  int64 `json:"canceled"`
+
+	TopFiles []ReportFile `json:"topFiles,omitempty"` // largest uploaded files, in descending size order
+}
+
+// ReportFile is an uploaded file, listed in the daily report
+type ReportFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// fileUploadListener is implemented by upload status listeners, which are notified when a file is uploaded
+type fileUploadListener interface {
+	fileUploaded(path string, size int64) // should not block
+}
+
+// dailyReports aggregates the uploads of each day, from the lifetime upload counters at the day boundaries in the
+// configured time zone, and publishes the report of each day, once the day is over
+type dailyReports struct {
+	location *time.Location
+	counters func() (UploadMetrics, int64) // lifetime upload counters and transferred bytes
+	publish  func(report *DailyReport)
+
+	day           time.Time // start of the current day
+	baseline      UploadMetrics
+	baselineBytes int64
+	topFiles      []ReportFile
+
+	timer *time.Timer
+	mutex sync.Mutex
+}
+
+func newDailyReports(location *time.Location, counters func() (UploadMetrics, int64),
+	publish func(report *DailyReport)) *dailyReports {
+	r := &dailyReports{location: location, counters: counters, publish: publish}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.day = startOfDay(time.Now(), location)
+	r.baseline, r.baselineBytes = counters()
+	r.scheduleNext()
+
+	return r
+}
+
+// startOfDay returns the midnight, starting the day of the given time in the given location
+func startOfDay(t time.Time, location *time.Location) time.Time {
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
+
+// nextDay returns the midnight, ending the day, which starts at the given midnight. Days are not always 24 hours,
+// e.g. when daylight saving time starts or ends.
+func nextDay(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+}
+
+func (r *dailyReports) scheduleNext() {
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Until(nextDay(r.day)), func() {
+		r.mutex.Lock()
+		if r.timer != timer { // stopped
+			r.mutex.Unlock()
+			return
+		}
+		report := r.rollover(time.Now())
+		r.scheduleNext()
+		r.mutex.Unlock()
+
+		r.publish(report)
+	})
+	r.timer = timer
+}
+
+// rollover completes the report of the current day and starts a new day, to which the given time belongs
+func (r *dailyReports) rollover(now time.Time) *DailyReport {
+	metrics, bytes := r.counters()
+
+	report := &DailyReport{
+		Date:          r.day.Format(reportDateLayout),
+		BytesUploaded: bytes - r.baselineBytes,
+		Started:       metrics.Started - r.baseline.Started,
+		Succeeded:     metrics.Succeeded - r.baseline.Succeeded,
+		Failed:        metrics.Failed - r.baseline.Failed,
+		Canceled:      metrics.Canceled - r.baseline.Canceled,
+		TopFiles:      r.topFiles,
+	}
+
+	r.day = startOfDay(now, r.location)
+	r.baseline, r.baselineBytes = metrics, bytes
+	r.topFiles = nil
+
+	return report
+}
+
+// fileUploaded ranks the given uploaded file among the largest files of the day
+func (r *dailyReports) fileUploaded(path string, size int64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.topFiles) == dailyReportTopFiles && size <= r.topFiles[len(r.topFiles)-1].Size {
+		return
+	}
+
+	i := sort.Search(len(r.topFiles), func(i int) bool { return r.topFiles[i].Size < size })
+	r.topFiles = append(r.topFiles, ReportFile{})
+	copy(r.topFiles[i+1:], r.topFiles[i:])
+	r.topFiles[i] = ReportFile{Path: path, Size: size}
+
+	if len(r.topFiles) > dailyReportTopFiles {
+		r.topFiles = r.topFiles[:dailyReportTopFiles]
+	}
+}
+
+func (r *dailyReports) stop() {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+}
+
+// writeReport writes the given report as JSON file in the given directory and returns its path
+func writeReport(dir string, report *DailyReport) (string, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, dailyReportFilePrefix+report.Date+".json")
+
+	return path, os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDailyReportRollover(t *testing.T) {
+	location := time.FixedZone("UTC+2", 2*60*60)
+
+	metrics, bytes := UploadMetrics{Started: 5, Succeeded: 3, Failed: 1}, int64(1000)
+	r := &dailyReports{
+		location: location,
+		counters: func() (UploadMetrics, int64) { return metrics, bytes },
+		day:      time.Date(2026, 10, 16, 0, 0, 0, 0, location),
+		baseline: metrics, baselineBytes: bytes,
+	}
+
+	metrics = UploadMetrics{Started: 9, Succeeded: 6, Failed: 2, Canceled: 1, InFlight: 1}
+	bytes = 5000
+	r.fileUploaded("/var/log/a.log", 1000)
+	r.fileUploaded("/var/log/b.log", 3000)
+
+	// 23:30 UTC is already the next day in UTC+2
+	report := r.rollover(time.Date(2026, 10, 16, 23, 30, 0, 0, time.UTC))
+	assertEquals(t, &DailyReport{
+		Date: "2026-10-16", BytesUploaded: 4000, Started: 4, Succeeded: 3, Failed: 1, Canceled: 1,
+		TopFiles: []ReportFile{{"/var/log/b.log", 3000}, {"/var/log/a.log", 1000}},
+	}, report)
+	assertEquals(t, time.Date(2026, 10, 17, 0, 0, 0, 0, location), r.day)
+
+	report = r.rollover(time.Date(2026, 10, 17, 22, 0, 0, 0, time.UTC))
+	assertEquals(t, &DailyReport{Date: "2026-10-17"}, report)
+	assertEquals(t, time.Date(2026, 10, 18, 0, 0, 0, 0, location), r.day)
+}
+
+func TestDailyReportTopFiles(t *testing.T) {
+	r := &dailyReports{}
+
+	for _, size := range []int64{5, 12, 1, 7, 3, 11, 2, 9, 8, 4, 6, 10, 7} {
+		r.fileUploaded(fmt.Sprintf("file%d", size), size)
+	}
+
+	var sizes []int64
+	for _, file := range r.topFiles {
+		sizes = append(sizes, file.Size)
+		assertEquals(t, fmt.Sprintf("file%d", file.Size), file.Path)
+	}
+	assertEquals(t, []int64{12, 11, 10, 9, 8, 7, 7, 6, 5, 4}, sizes)
+}
+
+func TestDailyReportDayBoundary(t *testing.T) {
+	location := time.FixedZone("UTC-5", -5*60*60)
+
+	day := startOfDay(time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC), location)
+	assertEquals(t, time.Date(2026, 10, 16, 0, 0, 0, 0, location), day)
+	assertEquals(t, time.Date(2026, 10, 17, 0, 0, 0, 0, location), nextDay(day))
+	assertEquals(t, time.Date(2027, 1, 1, 0, 0, 0, 0, location), nextDay(time.Date(2026, 12, 31, 0, 0, 0, 0, location)))
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	// daylight saving time ends, the day is 25 hours long
+	day = startOfDay(time.Date(2026, 10, 25, 12, 0, 0, 0, time.UTC), berlin)
+	assertEquals(t, 25*time.Hour, nextDay(day).Sub(day))
+}
+
+func TestDailyReportCounters(t *testing.T) {
+	dir := t.TempDir()
+
+	u, err := NewAutoUploadable(&UploadableConfig{DailyReport: true, DailyReportTimezone: "UTC"}, nil)
+	assertNoError(t, err)
+	defer u.reports.stop()
+
+	start := time.Now()
+	u.metrics.add(&UploadStatus{CorrelationID: "1", State: StateUploading}, 0)
+	u.metrics.add(&UploadStatus{CorrelationID: "1", State: StateSuccess, StartTime: start, EndTime: start}, 0)
+	u.metrics.add(&UploadStatus{CorrelationID: "2", State: StateUploading}, 0)
+	u.uploads.addTransferred(2048)
+	u.fileUploaded("/var/log/a.log", 2048)
+
+	u.reports.mutex.Lock()
+	report := u.reports.rollover(time.Now())
+	u.reports.mutex.Unlock()
+
+	assertEquals(t, &DailyReport{
+		Date: time.Now().UTC().Format(reportDateLayout), BytesUploaded: 2048, Started: 2, Succeeded: 1,
+		TopFiles: []ReportFile{{"/var/log/a.log", 2048}},
+	}, report)
+
+	path, err := writeReport(filepath.Join(dir, "reports"), report)
+	assertNoError(t, err)
+	assertEquals(t, filepath.Join(dir, "reports", "upload-report-"+report.Date+".json"), path)
+
+	data, err := os.ReadFile(path)
+	assertNoError(t, err)
+
+	written := &DailyReport{}
+	assertNoError(t, json.Unmarshal(data, written))
+	assertEquals(t, report, written)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+)
+
+// pendingRetry is the persisted re-attempt of a throttled file upload, waiting for the delay requested by the storage
+type pendingRetry struct {
+	CorrelationID string    `json:"correlationId"`
+	Attempt       int       `json:"attempt"`   // number of the waiting re-attempt, starting from 1
+	Next          time.Time `json:"next"`      // when the re-attempt is due
+	Attempted     time.Time `json:"attempted"` // first attempt of the file, from which the maximum retry duration is measured
+}
+
+// retryState persists the pending re-attempts of the throttled file uploads by file path, so when a file is
+// uploaded again after a restart, its re-attempts continue with the same attempt count and schedule, instead of
+// starting over. A re-attempt is removed, once made.
+type retryState struct {
+	file    string
+	pending map[string]pendingRetry
+
+	mutex sync.Mutex
+}
+
+// newRetryState restores the pending re-attempts from the given state file, if it exists. The re-attempts of the
+// files, which no longer exist, are dropped. An invalid state file is discarded.
+func newRetryState(file string) *retryState {
+	s := &retryState{file: file, pending: make(map[string]pendingRetry)}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return s
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &s.pending)
+	}
+	if err != nil {
+		logger.Warnf("retry state file '%s' discarded: %v", file, err)
+		s.pending = make(map[string]pendingRetry)
+		return s
+	}
+
+	for path := range s.pending {
+		if _, err := os.Stat(path); err != nil {
+			delete(s.pending, path)
+		}
+	}
+
+	return s
+}
+
+// get returns the pending re-attempt of the given file, if any
+func (s *retryState) get(path string) (pendingRetry, bool) {
+	if s == nil {
+		return pendingRetry{}, false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	retry, ok := s.pending[path]
+
+	return retry, ok
+}
+
+// add records the pending re-attempt of the given file and saves the state file
+func (s *retryState) add(path string, retry pendingRetry) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending[path] = retry
+	s.save()
+}
+
+// remove removes the re-attempt of the given file, if pending, and saves the state file
+func (s *retryState) remove(path string) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.pending[path]; !ok {
+		return
+	}
+
+	delete(s.pending, path)
+	s.save()
+}
+
+// save replaces the state file with the pending re-attempts. Should be called with the mutex locked.
+func (s *retryState) save() {
+	data, err := json.Marshal(s.pending)
+	if err == nil {
+		err = writeStateFile(s.file, data)
+	}
+	if err != nil {
+		logger.Errorf("failed to save the retry state file '%s': %v", s.file, err)
+	}
+}
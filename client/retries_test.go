@@ -0,0 +1,138 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryStateFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "retries")
+	existing := filepath.Join(dir, "existing")
+	if err := os.WriteFile(existing, []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().Round(0)
+	s := newRetryState(file)
+	s.add(existing, pendingRetry{"testUID#1", 2, now.Add(time.Minute), now})
+	s.add(filepath.Join(dir, "removed"), pendingRetry{"testUID#2", 1, now, now})
+
+	restored := newRetryState(file) // the files, which no longer exist, are dropped
+	assertEquals(t, 1, len(restored.pending))
+	retry, ok := restored.get(existing)
+	if !ok || retry.CorrelationID != "testUID#1" || retry.Attempt != 2 || !retry.Next.Equal(now.Add(time.Minute)) ||
+		!retry.Attempted.Equal(now) {
+		t.Fatalf("pending re-attempt not restored: %+v", retry)
+	}
+
+	restored.remove(existing)
+	assertEquals(t, 0, len(newRetryState(file).pending))
+
+	if err := os.WriteFile(file, []byte("invalid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, len(newRetryState(file).pending))
+
+	var disabled *retryState
+	disabled.add(existing, retry)
+	disabled.remove(existing)
+	if _, ok := disabled.get(existing); ok {
+		t.Fatal("no re-attempts expected, if not persisted")
+	}
+}
+
+func TestRetryStateRestart(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+	path := files[0].Name()
+
+	requests := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "10") // always throttled
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "retries")
+	cfg := &UploadableConfig{RetryAfterAttempts: 3, MaxRetryAfter: Duration(200 * time.Millisecond), RetryStateFile: file}
+
+	l := NewTestStatusListener(t)
+	us := NewUploads()
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+	startUploads(t, us, ids, server.URL)
+
+	// the state file, as left by an agent, stopped while waiting for the first re-attempt
+	var stopped map[string]pendingRetry
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, err := os.ReadFile(file)
+		if err == nil && json.Unmarshal(data, &stopped) == nil && len(stopped) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pending re-attempt not saved: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	retry := stopped[path]
+	if retry.CorrelationID != ids[0] || retry.Attempt != 1 {
+		t.Fatalf("unexpected pending re-attempt: %+v", retry)
+	}
+
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+	assertEquals(t, int32(4), atomic.LoadInt32(&requests)) // the upload and all re-attempts
+	assertEquals(t, 0, len(newRetryState(file).pending))   // removed, once made
+
+	// restarted during the backoff - the pending re-attempt is made when due and the re-attempts continue from it
+	retry.Next = time.Now().Add(300 * time.Millisecond)
+	retry.Attempt = 2
+	data, err := json.Marshal(map[string]pendingRetry{path: retry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&requests, 0)
+
+	l = NewTestStatusListener(t)
+	us = NewUploads()
+	ids = us.AddMultiWithConfig("testUID2", getPaths(files), cfg, l)
+
+	start := time.Now()
+	startUploads(t, us, ids, server.URL)
+	l.waitFinish()
+
+	l.assertStatusState(StateFailed)
+	assertEquals(t, int32(2), atomic.LoadInt32(&requests)) // the restored 2nd re-attempt and the 3rd one
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("restored re-attempt expected when due after 300ms, but the upload took %v", elapsed)
+	}
+	assertEquals(t, 0, len(newRetryState(file).pending))
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/logger"
+)
+
+// statsDPrefix is prepended to the names of the metrics, pushed to StatsD
+const statsDPrefix = "file_upload."
+
+// statsDClient pushes metrics to a StatsD server over UDP. Sending is best effort - errors are only logged.
+type statsDClient struct {
+	conn net.Conn
+}
+
+func newStatsDClient(addr string) (*statsDClient, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to StatsD server '%s': %w", addr, err)
+	}
+
+	return &statsDClient{conn}, nil
+}
+
+func (c *statsDClient) count(metric string) {
+	c.send("%s%s:1|c", statsDPrefix, metric)
+}
+
+func (c *statsDClient) timing(metric string, duration time.Duration) {
+	c.send("%s%s:%d|ms", statsDPrefix, metric, duration.Milliseconds())
+}
+
+func (c *statsDClient) gauge(metric string, value int64) {
+	c.send("%s%s:%d|g", statsDPrefix, metric, value)
+}
+
+func (c *statsDClient) send(format string, args ...interface{}) {
+	if _, err := fmt.Fprintf(c.conn, format, args...); err != nil {
+		logger.Debugf("failed to send metric to StatsD server: %v", err)
+	}
+}
+
+func (c *statsDClient) close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestStatsDMetrics(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assertNoError(t, err)
+	defer listener.Close()
+
+	cfg := &UploadableConfig{StatsDAddr: listener.LocalAddr().String()}
+	u, err := NewAutoUploadable(cfg, nil)
+	assertNoError(t, err)
+	defer u.statsD.close()
+
+	start := time.Now()
+	statuses := []*UploadStatus{
+		{CorrelationID: "first", State: StateUploading, StartTime: start},
+		{CorrelationID: "first", State: StateUploading, StartTime: start, Progress: 50}, // progress update
+		{CorrelationID: "first", State: StateSuccess, StartTime: start, EndTime: start.Add(1500 * time.Millisecond)},
+		{CorrelationID: "second", State: StateUploading, StartTime: start},
+		{CorrelationID: "second", State: StateFailed, StartTime: start, EndTime: start.Add(time.Second)},
+		{CorrelationID: "third", State: StateCanceled}, // canceled before started
+	}
+	for _, status := range statuses {
+		u.metrics.add(status, 1024)
+	}
+
+	expected := []string{
+		"file_upload.uploads.started:1|c",
+		"file_upload.uploads.succeeded:1|c",
+		"file_upload.upload.duration:1500|ms",
+		"file_upload.bandwidth:1024|g",
+		"file_upload.uploads.started:1|c",
+		"file_upload.uploads.failed:1|c",
+		"file_upload.upload.duration:1000|ms",
+		"file_upload.bandwidth:1024|g",
+		"file_upload.uploads.canceled:1|c",
+		"file_upload.bandwidth:1024|g",
+	}
+	assertEquals(t, expected, readStatsDLines(t, listener, len(expected)))
+
+	assertEquals(t, UploadMetrics{Started: 2, Succeeded: 1, Failed: 1, Canceled: 1, Duration: 2500 * time.Millisecond},
+		u.metrics.snapshot())
+}
+
+func TestStatsDInvalidAddress(t *testing.T) {
+	_, err := NewAutoUploadable(&UploadableConfig{StatsDAddr: "localhost"}, nil)
+	assertError(t, err)
+}
+
+func readStatsDLines(t *testing.T, listener net.PacketConn, count int) []string {
+	t.Helper()
+
+	assertNoError(t, listener.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	lines := make([]string, count)
+	buf := make([]byte, 512)
+	for i := range lines {
+		n, _, err := listener.ReadFrom(buf)
+		assertNoError(t, err)
+		lines[i] = string(buf[:n])
+	}
+
+	return lines
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// deleteThrottle spaces the deletions of uploaded files, so that at most the configured number of files
+// are deleted per second, to avoid stalling the file system when many files are uploaded at once
+type deleteThrottle struct {
+	rate     int
+	interval time.Duration
+
+	mutex sync.Mutex
+	next  time.Time // time of the next allowed deletion
+}
+
+func newDeleteThrottle(rate int) *deleteThrottle {
+	return &deleteThrottle{rate: rate, interval: time.Second / time.Duration(rate)}
+}
+
+// wait blocks until the next deletion is allowed. A nil throttle never blocks.
+func (t *deleteThrottle) wait() {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	t.mutex.Unlock()
+
+	time.Sleep(delay)
+}
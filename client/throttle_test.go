@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestDeleteThrottleSpacing(t *testing.T) {
+	throttle := newDeleteThrottle(20)
+
+	var times []time.Time
+	for i := 0; i < 5; i++ {
+		throttle.wait()
+		times = append(times, time.Now())
+	}
+
+	// deletions are scheduled relative to the first one, so a late wake up does not delay the following ones
+	for i := 1; i < len(times); i++ {
+		expected := time.Duration(i) * throttle.interval
+		if elapsed := times[i].Sub(times[0]); elapsed < expected-time.Millisecond {
+			t.Errorf("deletion %d expected %v after the first one, but was %v", i, expected, elapsed)
+		}
+	}
+}
+
+func TestDeleteThrottleNil(t *testing.T) {
+	var throttle *deleteThrottle
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		throttle.wait()
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("nil throttle expected not to wait, but waited %v", elapsed)
+	}
+
+	assertEquals(t, (*deleteThrottle)(nil), NewUploads().getDeleteThrottle(&UploadableConfig{}))
+}
+
+func TestUploadDeleteRate(t *testing.T) {
+	const count, rate = 6, 10
+
+	dir := t.TempDir()
+	var paths []string
+	for i := 0; i < count; i++ {
+		path := filepath.Join(dir, "file"+string(rune('a'+i))+".log")
+		assertNoError(t, os.WriteFile(path, []byte("content"), 0600))
+		paths = append(paths, path)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	startUploads(t, us, us.AddMultiWithConfig("testUID", paths, &UploadableConfig{Delete: true, DeleteRate: rate}, l), server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	// files are deleted after the upload status is reported, so poll for their deletion times
+	deleted := make(map[string]time.Time)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(deleted) < count && time.Now().Before(deadline) {
+		for _, path := range paths {
+			if _, ok := deleted[path]; !ok {
+				if _, err := os.Stat(path); os.IsNotExist(err) {
+					deleted[path] = time.Now()
+				}
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	assertEquals(t, count, len(deleted))
+
+	var times []time.Time
+	for _, deletedAt := range deleted {
+		times = append(times, deletedAt)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	interval := time.Second / rate
+	for i := 1; i < len(times); i++ {
+		if elapsed := times[i].Sub(times[i-1]); elapsed < interval-20*time.Millisecond { // allow for the polling period
+			t.Errorf("deletion %d expected %v after the previous one, but was %v", i, interval, elapsed)
+		}
+	}
+}
@@ -13,15 +13,35 @@
 package client
 
 import (
+	"math/rand"
 	"sync"
 	"time"
+
+	"github.com/robfig/cron/v3"
 )
 
+// cronParser parses standard cron expressions with an optional leading seconds field, as well as descriptors
+// like '@daily' and '@every 1h'
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseCron parses the given cron expression
+func ParseCron(expression string) (cron.Schedule, error) {
+	return cronParser.Parse(expression)
+}
+
+// taskExecutor is implemented by the executors of the periodic tasks
+type taskExecutor interface {
+	Stop()
+}
+
 // PeriodicExecutor can be used to periodically executed given task in specified time frame.
 type PeriodicExecutor struct {
 	period time.Duration
+	jitter time.Duration
 	task   func()
 
+	random *rand.Rand
+
 	fromTimer *time.Timer
 	toTimer   *time.Timer
 
@@ -37,9 +57,18 @@ type PeriodicExecutor struct {
 // starts right away. The execution continues till the to time is reached, unless to is nil. In that case execution
 // continues until the Stop is invoked
 func NewPeriodicExecutor(from *time.Time, to *time.Time, period time.Duration, task func()) *PeriodicExecutor {
+	return NewJitteredPeriodicExecutor(from, to, period, 0, task)
+}
+
+// NewJitteredPeriodicExecutor constructs a PeriodicExecutor, like NewPeriodicExecutor, which delays each task
+// invocation (including the first one) with a random duration in the range [0, jitter). Used to spread the load,
+// when many devices have the same period.
+func NewJitteredPeriodicExecutor(from *time.Time, to *time.Time, period time.Duration, jitter time.Duration, task func()) *PeriodicExecutor {
 	e := &PeriodicExecutor{}
 	e.period = period
+	e.jitter = jitter
 	e.task = task
+	e.random = rand.New(rand.NewSource(time.Now().UnixNano()))
 
 	if from != nil {
 		e.fromTimer = time.AfterFunc(time.Until(*from), func() {
@@ -66,8 +95,6 @@ func (e *PeriodicExecutor) startTicker() {
 	e.ticker = time.NewTicker(e.period)
 
 	go func() {
-		e.task() //invoke at the start of the period
-
 		defer func() {
 			e.mutex.Lock()
 			defer e.mutex.Unlock()
@@ -75,17 +102,41 @@ func (e *PeriodicExecutor) startTicker() {
 			e.ticker = nil
 		}()
 
+		if !e.runTask() { //invoke at the start of the period
+			return
+		}
+
 		for {
 			select {
 			case <-e.done:
 				return
 			case <-e.ticker.C:
-				e.task()
+				if !e.runTask() {
+					return
+				}
 			}
 		}
 	}()
 }
 
+// runTask invokes the task after a random delay, if jitter is configured.
+// Returns false if the executor is stopped while waiting.
+func (e *PeriodicExecutor) runTask() bool {
+	if e.jitter > 0 {
+		delay := time.NewTimer(time.Duration(e.random.Int63n(int64(e.jitter))))
+
+		select {
+		case <-e.done:
+			delay.Stop()
+			return false
+		case <-delay.C:
+		}
+	}
+
+	e.task()
+	return true
+}
+
 func (e *PeriodicExecutor) stopTicker() {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
@@ -113,3 +164,72 @@ func (e *PeriodicExecutor) Stop() {
 	}
 
 }
+
+// CronExecutor can be used to execute given task at the times of a cron schedule in specified time frame.
+type CronExecutor struct {
+	schedule cron.Schedule
+	to       *time.Time
+	task     func()
+
+	timer *time.Timer
+	mutex sync.Mutex
+}
+
+// NewCronExecutor constructs a CronExecutor for given time frame (from, to). The task function will be
+// invoked at the times of the given schedule.
+//
+// Unlike PeriodicExecutor, the task is not invoked when from time is reached, but at the first scheduled time after it.
+// If from is nil or in the past, the first scheduled time after the current time is used. The execution continues
+// till the to time is reached, unless to is nil. In that case execution continues until the Stop is invoked
+func NewCronExecutor(from *time.Time, to *time.Time, schedule cron.Schedule, task func()) *CronExecutor {
+	e := &CronExecutor{}
+	e.schedule = schedule
+	e.to = to
+	e.task = task
+
+	start := time.Now()
+	if from != nil && from.After(start) {
+		start = *from
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.scheduleNext(start)
+
+	return e
+}
+
+// scheduleNext schedules the task invocation at the first scheduled time after the given one, if within the time frame
+func (e *CronExecutor) scheduleNext(after time.Time) {
+	next := e.schedule.Next(after)
+	if next.IsZero() || (e.to != nil && next.After(*e.to)) {
+		e.timer = nil
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Until(next), func() {
+		e.mutex.Lock()
+		if e.timer != timer { // stopped
+			e.mutex.Unlock()
+			return
+		}
+		e.scheduleNext(time.Now())
+		e.mutex.Unlock()
+
+		e.task()
+	})
+	e.timer = timer
+}
+
+// Stop stops the scheduled execution.
+func (e *CronExecutor) Stop() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+}
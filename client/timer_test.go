@@ -106,3 +106,153 @@ func TestTicks(t *testing.T) {
 
 	}
 }
+
+func TestJitteredTicks(t *testing.T) {
+	const period = 200 * time.Millisecond
+	const jitter = 100 * time.Millisecond
+	const tolerance = 30 * time.Millisecond
+
+	var mutex sync.Mutex
+	var ticks []time.Time
+	start := time.Now()
+	e := NewJitteredPeriodicExecutor(nil, nil, period, jitter, func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		ticks = append(ticks, time.Now())
+	})
+
+	time.Sleep(10*period + jitter)
+	e.Stop()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if len(ticks) < 8 {
+		t.Fatalf("too few ticks received - %d", len(ticks))
+	}
+
+	if first := ticks[0].Sub(start); first > jitter+tolerance {
+		t.Errorf("first tick delay - %v - should be less than the jitter %v", first, jitter)
+	}
+
+	min, max := time.Duration(1<<62), time.Duration(0)
+	for i := 1; i < len(ticks); i++ {
+		interval := ticks[i].Sub(ticks[i-1])
+		if interval < period-jitter-tolerance || interval > period+jitter+tolerance {
+			t.Errorf("tick interval - %v - is out of the range [%v, %v]", interval, period-jitter, period+jitter)
+		}
+		if interval < min {
+			min = interval
+		}
+		if interval > max {
+			max = interval
+		}
+	}
+
+	if max-min < jitter/10 {
+		t.Errorf("tick intervals should vary, but were between %v and %v", min, max)
+	}
+}
+
+func TestJitteredStop(t *testing.T) {
+	c := int32(0)
+	e := NewJitteredPeriodicExecutor(nil, nil, time.Hour, 30*time.Minute, func() {
+		atomic.AddInt32(&c, 1)
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	stopped := make(chan bool)
+	go func() {
+		e.Stop() // cancels the pending jittered tick
+		stopped <- true
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("stop blocked by the pending jittered tick")
+	}
+
+	if c = atomic.LoadInt32(&c); c != 0 {
+		t.Fatalf("no ticks expected, but were %d", c)
+	}
+}
+
+func TestCronTicks(t *testing.T) {
+	schedule, err := ParseCron("* * * * * *") // every second
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Add(time.Second)
+	end := start.Add(2500 * time.Millisecond)
+
+	var mutex sync.Mutex
+	var ticks []time.Time
+	e := NewCronExecutor(&start, &end, schedule, func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		ticks = append(ticks, time.Now())
+	})
+	defer e.Stop()
+
+	time.Sleep(time.Until(end) + time.Second)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if len(ticks) < 2 || len(ticks) > 3 {
+		t.Fatalf("expected 2 or 3 ticks, but were %v", ticks)
+	}
+
+	for _, tick := range ticks {
+		if tick.Before(start) || tick.After(end) {
+			t.Errorf("tick time - %v - is out of the time frame [%v, %v]", tick, start, end)
+		}
+		if offset := tick.Sub(tick.Truncate(time.Second)); offset > 100*time.Millisecond {
+			t.Errorf("tick time - %v - should be at the start of a second", tick)
+		}
+	}
+}
+
+func TestCronStop(t *testing.T) {
+	schedule, err := ParseCron("* * * * * *") // every second
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := int32(0)
+	e := NewCronExecutor(nil, nil, schedule, func() {
+		atomic.AddInt32(&c, 1)
+	})
+
+	time.Sleep(1500 * time.Millisecond)
+	e.Stop()
+	stopped := atomic.LoadInt32(&c)
+
+	time.Sleep(1200 * time.Millisecond)
+
+	if stopped == 0 {
+		t.Fatal("at least one tick expected, but there were none")
+	}
+	if c = atomic.LoadInt32(&c); c != stopped {
+		t.Fatalf("ticks received after stop - %d before, %d after", stopped, c)
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	for _, expression := range []string{"0 2 * * *", "30 0 2 * * *", "@daily", "@every 1h", "CRON_TZ=UTC 0 2 * * MON-FRI"} {
+		if _, err := ParseCron(expression); err != nil {
+			t.Errorf("expression '%s' should be valid: %v", expression, err)
+		}
+	}
+
+	for _, expression := range []string{"", "* * *", "61 * * * *", "@sometimes"} {
+		if _, err := ParseCron(expression); err == nil {
+			t.Errorf("expression '%s' should be invalid", expression)
+		}
+	}
+}
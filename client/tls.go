@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"strings"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+// CipherSuites is a list of TLS cipher suite names. Specified as a JSON array in the configuration file
+// and as a comma-separated list on the command line.
+type CipherSuites []string
+
+// String returns the comma-separated cipher suites
+func (c CipherSuites) String() string {
+	return strings.Join(c, ",")
+}
+
+// Set implements flag.Value Set method
+func (c *CipherSuites) Set(v string) error {
+	if v == "" {
+		*c = nil
+		return nil
+	}
+
+	suites := strings.Split(v, ",")
+	for i, suite := range suites {
+		suites[i] = strings.TrimSpace(suite)
+	}
+	if _, err := uploaders.ParseCipherSuites(strings.Join(suites, ",")); err != nil {
+		return err
+	}
+	*c = suites
+
+	return nil
+}
+
+// withTLSOptions returns the given 'start' operation options, with the TLS options set from the upload
+// configuration, overriding the ones specified by the backend
+func withTLSOptions(options map[string]string, cfg *UploadableConfig) map[string]string {
+	if cfg.TLSMinVersion == "" && cfg.TLSMaxVersion == "" && len(cfg.TLSCipherSuites) == 0 {
+		return options
+	}
+
+	result := make(map[string]string, len(options)+3)
+	for k, v := range options {
+		result[k] = v
+	}
+	if cfg.TLSMinVersion != "" {
+		result[uploaders.TLSMinVersionProp] = cfg.TLSMinVersion
+	}
+	if cfg.TLSMaxVersion != "" {
+		result[uploaders.TLSMaxVersionProp] = cfg.TLSMaxVersion
+	}
+	if len(cfg.TLSCipherSuites) > 0 {
+		result[uploaders.TLSCipherSuitesProp] = cfg.TLSCipherSuites.String()
+	}
+
+	return result
+}
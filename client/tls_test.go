@@ -0,0 +1,53 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"testing"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+)
+
+func TestCipherSuitesSet(t *testing.T) {
+	var suites CipherSuites
+
+	assertNoError(t, suites.Set("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"))
+	assertEquals(t, CipherSuites{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"}, suites)
+	assertEquals(t, "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", suites.String())
+
+	assertNoError(t, suites.Set(""))
+	assertEquals(t, 0, len(suites))
+
+	assertError(t, suites.Set("TLS_RSA_WITH_RC4_128_SHA"))
+}
+
+func TestWithTLSOptions(t *testing.T) {
+	options := map[string]string{uploaders.URLProp: "https://localhost/up", uploaders.TLSMinVersionProp: uploaders.TLSVersion12}
+
+	assertEquals(t, options, withTLSOptions(options, &UploadableConfig{}))
+
+	cfg := &UploadableConfig{
+		TLSMinVersion:   uploaders.TLSVersion13,
+		TLSMaxVersion:   uploaders.TLSVersion13,
+		TLSCipherSuites: CipherSuites{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+	}
+	assertEquals(t, map[string]string{
+		uploaders.URLProp:             "https://localhost/up",
+		uploaders.TLSMinVersionProp:   uploaders.TLSVersion13, // the configuration overrides the backend options
+		uploaders.TLSMaxVersionProp:   uploaders.TLSVersion13,
+		uploaders.TLSCipherSuitesProp: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	}, withTLSOptions(options, cfg))
+	assertEquals(t, uploaders.TLSVersion12, options[uploaders.TLSMinVersionProp])
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// hookEnvOutput is the environment variable, passed to the pre-upload transforms, with the path of the output file
+const hookEnvOutput = "FILE_UPLOAD_OUTPUT"
+
+// Transforms is a list of pre-upload transforms in the form 'pattern=command'. Specified as a JSON array in the
+// configuration file and as a comma-separated list on the command line.
+type Transforms []string
+
+// String returns the comma-separated transforms
+func (t Transforms) String() string {
+	return strings.Join(t, ",")
+}
+
+// Set implements flag.Value Set method
+func (t *Transforms) Set(v string) error {
+	if v == "" {
+		*t = nil
+		return nil
+	}
+
+	transforms := strings.Split(v, ",")
+	for i, transform := range transforms {
+		transforms[i] = strings.TrimSpace(transform)
+		if _, _, err := parseTransform(transforms[i]); err != nil {
+			return err
+		}
+	}
+	*t = transforms
+
+	return nil
+}
+
+// command returns the command of the first transform, whose pattern matches the given path or its base name,
+// or empty string, if the file is not transformed
+func (t Transforms) command(path string) string {
+	for _, transform := range t {
+		if glob, command, err := parseTransform(transform); err == nil && (Globs{glob}).Match(path) {
+			return command
+		}
+	}
+
+	return ""
+}
+
+// partition splits the given files into the ones, which are uploaded as they are, and the transformed ones
+func (t Transforms) partition(files []string) ([]string, []string) {
+	var plain, transformed []string
+	for _, file := range files {
+		if t.command(file) != "" {
+			transformed = append(transformed, file)
+		} else {
+			plain = append(plain, file)
+		}
+	}
+
+	return plain, transformed
+}
+
+// parseTransform returns the glob pattern and the command of the given 'pattern=command' transform
+func parseTransform(transform string) (string, string, error) {
+	parts := strings.SplitN(transform, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return "", "", fmt.Errorf("invalid transform '%s' - should be in the form 'pattern=command'", transform)
+	}
+
+	glob := strings.TrimSpace(parts[0])
+	if err := ValidateGlob(glob); err != nil {
+		return "", "", err
+	}
+
+	return glob, strings.TrimSpace(parts[1]), nil
+}
+
+// runTransform runs the transform command for the file with the given path and returns the opened output file,
+// which is uploaded instead of the file. The file path and the output path in a new temporary directory are passed
+// as arguments and in the FILE_UPLOAD_PATH and FILE_UPLOAD_OUTPUT environment variables. The output file should be
+// removed with uploaders.RemoveTempFile.
+func runTransform(cfg *UploadableConfig, correlationID string, command string, path string) (*os.File, error) {
+	dir, err := os.MkdirTemp("", "file-upload-")
+	if err != nil {
+		return nil, err
+	}
+
+	output := filepath.Join(dir, filepath.Base(path))
+	env := []string{hookEnvPath + "=" + path, hookEnvOutput + "=" + output, hookEnvCorrelationID + "=" + correlationID}
+	if err := runHook(command, time.Duration(cfg.HookTimeout), env, path, output); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("pre-upload transform failed: %v", err)
+	}
+
+	file, err := os.Open(output)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("pre-upload transform produced no output: %w", err)
+	}
+
+	return file, nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTransformsSet(t *testing.T) {
+	var transforms Transforms
+
+	assertNoError(t, transforms.Set("*.db=/usr/bin/backup, /var/lib/**/*.wal = /usr/bin/checkpoint"))
+	assertEquals(t, Transforms{"*.db=/usr/bin/backup", "/var/lib/**/*.wal = /usr/bin/checkpoint"}, transforms)
+	assertEquals(t, "/usr/bin/backup", transforms.command("/var/lib/app/state.db"))
+	assertEquals(t, "/usr/bin/checkpoint", transforms.command("/var/lib/app/state.wal"))
+	assertEquals(t, "", transforms.command("/var/lib/app/state.log"))
+
+	plain, transformed := transforms.partition([]string{"a.db", "b.log", "c.db"})
+	assertEquals(t, []string{"b.log"}, plain)
+	assertEquals(t, []string{"a.db", "c.db"}, transformed)
+
+	assertNoError(t, transforms.Set(""))
+	assertEquals(t, 0, len(transforms))
+
+	for _, invalid := range []string{"*.db", "=/usr/bin/backup", "*.db=", "[=/usr/bin/backup"} {
+		assertError(t, transforms.Set(invalid))
+	}
+}
+
+func TestUploadTransform(t *testing.T) {
+	dir := t.TempDir()
+
+	db := filepath.Join(dir, "state.db")
+	assertNoError(t, os.WriteFile(db, []byte("live database"), 0600))
+	log := filepath.Join(dir, "app.log")
+	assertNoError(t, os.WriteFile(log, []byte("log line"), 0600))
+
+	transform := filepath.Join(dir, "backup")
+	script := "#!/bin/sh\n[ \"$1\" = \"$FILE_UPLOAD_PATH\" ] && [ \"$2\" = \"$FILE_UPLOAD_OUTPUT\" ] || exit 1\n" +
+		"tr a-z A-Z < \"$1\" > \"$2\"\n"
+	assertNoError(t, os.WriteFile(transform, []byte(script), 0700))
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	cfg := &UploadableConfig{
+		Hooks:       true,
+		Transforms:  Transforms{"*.db=" + transform},
+		HookTimeout: Duration(5 * time.Second),
+	}
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", []string{db, log}, cfg, l)
+	startUploads(t, us, ids, server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	var bodies []string
+	for _, request := range received.requests {
+		bodies = append(bodies, string(request.body))
+	}
+	sort.Strings(bodies)
+	assertEquals(t, []string{"LIVE DATABASE", "log line"}, bodies)
+
+	data, err := os.ReadFile(db)
+	assertNoError(t, err)
+	assertEquals(t, "live database", string(data)) // the source file is not modified
+}
+
+func TestUploadTransformFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	db := filepath.Join(dir, "state.db")
+	assertNoError(t, os.WriteFile(db, []byte("live database"), 0600))
+
+	transform := filepath.Join(dir, "backup")
+	assertNoError(t, os.WriteFile(transform, []byte("#!/bin/sh\nexit 1\n"), 0700))
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	cfg := &UploadableConfig{Hooks: true, Transforms: Transforms{"*.db=" + transform}, HookTimeout: Duration(5 * time.Second)}
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", []string{db}, cfg, l)
+	startUploads(t, us, ids, server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+	assertEquals(t, 0, len(received.requests))
+}
@@ -17,6 +17,8 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -33,6 +35,8 @@ import (
 const (
 	autoUploadProperty = "autoUpload"
 	lastUploadProperty = "lastUpload"
+	bandwidthProperty  = "bandwidth"
+	connectionProperty = "connection"
 
 	optionsPrefix = "options."
 
@@ -40,6 +44,29 @@ const (
 
 	defaultDisconnectTimeout = 250 * time.Millisecond
 	defaultKeepAlive         = 20 * time.Second
+
+	metricsShutdownTimeout = 5 * time.Second
+
+	twinCorrelationInfix     = "/twin/"
+	featureCorrelationSuffix = "feature"
+)
+
+// Periodic executor tick policies, applied when the previous periodic task is still running
+const (
+	TickPolicyOverlap       = "overlap"
+	TickPolicySkipIfRunning = "skip-if-running"
+)
+
+// Active end policies, applied to the running uploads when the end of the active time frame is reached
+const (
+	ActiveEndPolicyContinue = "continue"
+	ActiveEndPolicyGraceful = "graceful"
+)
+
+// In-flight file policies, applied when triggered uploads match files, which are still being uploaded by another trigger
+const (
+	InFlightPolicyUpload = "upload"
+	InFlightPolicySkip   = "skip"
 )
 
 // UploadableConfig contains configuration for the AutoUploadable feature
@@ -48,17 +75,142 @@ type UploadableConfig struct {
 	Context   string   `json:"context,omitempty" def:"edge" descr:"Context of the files uploaded by {feature} feature, unique in the scope of the type."`
 	Type      string   `json:"type,omitempty" def:"file" descr:"Type of the files, uploaded by {feature} feature."`
 	Period    Duration `json:"period,omitempty" def:"10h" descr:"{period}. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+	Cron      string   `json:"cron,omitempty" def:"" descr:"Cron expression, scheduling the periodic {actions} at specific times, e.g. '0 2 * * *' for every day at 02:00 local time. Takes precedence over the period. Standard five fields expressions are supported, with an optional leading seconds field, as well as descriptors like '@daily' or '@every 1h'. A time zone can be specified with a 'CRON_TZ=<zone>' prefix."`
+
+	PeriodJitter Duration `json:"periodJitter,omitempty" def:"0s" descr:"Maximum random delay of each periodic {action}, including the first one, to spread the load when many devices have the same period. Should be less than the period. Not applied to cron schedules. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	TickPolicy string `json:"tickPolicy,omitempty" def:"overlap" descr:"Behavior of the periodic {actions}, when the previous one is still running. Allowed values are:\n'overlap' - start the next periodic {action} regardless of the running one\n'skip-if-running' - skip the periodic {action} while the previous one is running"`
+
+	TriggerWindow Duration `json:"triggerWindow,omitempty" def:"0s" descr:"Time, by which uploads are delayed after a trigger, to coalesce the triggers within it into a single upload, e.g. an on-demand trigger just before a periodic one. Periodic triggers are merged into on-demand ones, which are merged with each other only if their options are the same. Dry runs are not delayed. Zero disables coalescing. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
 
 	Active     bool  `json:"active,omitempty" def:"false" descr:"Activate periodic {actions}"`
 	ActiveFrom Xtime `json:"activeFrom,omitempty" descr:"Time from which periodic {actions} should be active, in RFC 3339 format (2006-01-02T15:04:05Z07:00). If omitted (and 'active' flag is set) current time will be used as start of the periodic {actions}."`
 	ActiveTill Xtime `json:"activeTill,omitempty" descr:"Time till which periodic {actions} should be active, in RFC 3339 format (2006-01-02T15:04:05Z07:00). If omitted (and 'active' flag is set) periodic {actions} will be active indefinitely."`
 
+	ActiveEndPolicy      string   `json:"activeEndPolicy,omitempty" def:"continue" descr:"Behavior of the running uploads, when the 'activeTill' time is reached. Allowed values are:\n'continue' - continue the running uploads, including their files, which are not yet started\n'graceful' - finish the files, which are being uploaded, within the 'activeEndGracePeriod', but do not start the remaining files of the running uploads"`
+	ActiveEndGracePeriod Duration `json:"activeEndGracePeriod,omitempty" def:"5m" descr:"Time to wait for the files, which are being uploaded when the 'activeTill' time is reached, to finish with 'graceful' active end policy, after which their uploads are canceled. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	GlobWorkers  int      `json:"globWorkers,omitempty" def:"4" descr:"Maximum number of file patterns, which are expanded concurrently, when the files glob specifies multiple patterns"`
+	FilesList    string   `json:"filesList,omitempty" def:"" descr:"File, containing a newline-separated list of paths to upload, used instead of the 'files' glob. The list is read on each trigger.\nUnless the 'mode' is 'lax', only listed files matching the 'files' glob are uploaded."`
+	ExcludeFiles Globs    `json:"excludeFiles,omitempty" def:"" descr:"Glob patterns for files, which should never be uploaded, e.g. rotated or temporary files. Patterns are matched against the full path and the base name of each file. Specified as a JSON array in the configuration file and as a comma-separated list on the command line."`
+	MinFileAge   Duration `json:"minFileAge,omitempty" def:"0s" descr:"Minimum time since the last modification of a file, for the file to be uploaded. Prevents uploading of files, which are still being written. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+	MaxFileAge   Duration `json:"maxFileAge,omitempty" def:"0s" descr:"Maximum time since the last modification of a file, for the file to be uploaded. Zero means no limit. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+	MinFileSize  ByteSize `json:"minFileSize,omitempty" def:"0" descr:"Minimum size of a file, for the file to be uploaded, e.g. '1KB'. Allowed units are 'B', 'KB', 'MB' and 'GB' (powers of 1024)."`
+	MaxFileSize  ByteSize `json:"maxFileSize,omitempty" def:"0" descr:"Maximum size of a file, for the file to be uploaded, e.g. '50MB'. Zero means no limit. Allowed units are 'B', 'KB', 'MB' and 'GB' (powers of 1024)."`
+
+	FileFilter FileFilter `json:"fileFilter,omitempty" def:"" descr:"Expression, combining file selection criteria, which a file should match, in addition to the other selection criteria, for the file to be uploaded. The criteria are 'glob' (matched against the full path and the base name), 'minSize', 'maxSize', 'minAge' and 'maxAge' (time since the last modification), combined with 'and', 'or' and 'not' expressions. A file matches an expression, if it matches all of its criteria, all 'and' expressions, at least one of the 'or' expressions, if any, and not the 'not' expression. Specified as a JSON object in the configuration file and on the command line, e.g. '{\"or\":[{\"glob\":\"*.log\",\"minSize\":\"1KB\"},{\"maxAge\":\"1h\"}]}'."`
+
+	FileAttribute string `json:"fileAttribute,omitempty" def:"" descr:"Extended file attribute, which a file should have, in addition to matching the files glob, for the file to be uploaded, e.g. 'user.upload=yes' for 'user.upload' attribute with 'yes' value or 'user.upload' for any value. Use a broad files glob, e.g. '/var/log/**', to select the files by the attribute only. Supported on Linux only."`
+
 	Delete       bool `json:"delete,omitempty" def:"false" descr:"Delete successfully uploaded files"`
+	DeleteRate   int  `json:"deleteRate,omitempty" def:"0" descr:"Maximum number of uploaded files, deleted per second, to avoid stalling the file system when many files are uploaded at once. Zero means no limit."`
 	Checksum     bool `json:"checksum,omitempty" def:"false" descr:"Send MD5 checksum for uploaded files to ensure data integrity. Computing checksums incurs additional CPU/disk usage."`
 	SingleUpload bool `json:"singleUpload,omitempty" def:"false" descr:"Forbid triggering of new uploads when there is upload in progress. Trigger can be forced from the backend with the 'force' option."`
 
+	VerifyAfterUpload bool `json:"verifyAfterUpload,omitempty" def:"false" descr:"Download each uploaded object after upload and compare its checksum to the uploaded content, for critical transfers. The upload fails, if the content differs. Storage providers, which do not support downloading, are not verified. Generic HTTP uploads are downloaded with a GET request from the 'https.verify.url' start option or else from the upload URL."`
+
+	InFlightPolicy string `json:"inFlightPolicy,omitempty" def:"upload" descr:"Behavior of the uploads, when triggered concurrently for the same files. Allowed values are:\n'upload' - upload the files again, regardless of the running uploads\n'skip' - skip the files, which are still being uploaded by another trigger"`
+
+	DetectModification bool `json:"detectModification,omitempty" def:"false" descr:"Snapshot the inode, size and modification time of each file before upload and compare them after upload, to detect files, which were modified or replaced during upload, e.g. rotated. Such files are reported as possibly stale in the 'stale' list of the upload status and are not deleted."`
+
+	SkipIfRemoteNewer bool `json:"skipIfRemoteNewer,omitempty" def:"false" descr:"Skip uploading of files, which are older than their already uploaded objects, for idempotent synchronization. The object modification time is retrieved from the storage before each upload, e.g. with a HEAD request for generic HTTP uploads. Skipped files are reported as uploaded, but are not deleted."`
+
+	DedupWindow            Duration    `json:"dedupWindow,omitempty" def:"0s" descr:"Time, for which successfully uploaded files are remembered and skipped by the following triggers, unless their size or modification time changes. Files are remembered in a bloom filter with fixed memory, regardless of the number of files, for at least the window and at most twice the window. Files in archives are not remembered. Zero disables the deduplication. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+	DedupCapacity          int         `json:"dedupCapacity,omitempty" def:"100000" descr:"Expected number of files, uploaded within the deduplication window, for which the bloom filter is sized. The filter takes about 2.8 bytes per file at 1% false positive rate. More uploaded files increase the false positive rate."`
+	DedupFalsePositiveRate Probability `json:"dedupFalsePositiveRate,omitempty" def:"0.01" descr:"Probability, with which a file, not uploaded within the deduplication window, is skipped by mistake. Specified as a decimal fraction, e.g. '0.001', or in percent, e.g. '0.1%'. Lower rates take more memory."`
+	DedupStateFile         string      `json:"dedupStateFile,omitempty" def:"" descr:"Local file, in which the deduplication filter is persisted after each upload, so uploaded files are remembered across restarts. The files, which aged out of the deduplication window while not running, are removed from it on startup. The filter is kept only in memory, if not specified."`
+	DedupStateMaxAge       Duration    `json:"dedupStateMaxAge,omitempty" def:"0s" descr:"Maximum age of the deduplication state file, restored on startup, e.g. after a long downtime. An older state file is discarded and rewritten empty, so the files are uploaded again by the following triggers. Zero means no limit. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	Compress         bool   `json:"compress,omitempty" def:"false" descr:"Compress files before upload. Files are compressed on the fly while uploaded to AWS, or with HTTP upload, if the backend allows chunked transfer encoding with the 'https.chunked' option. Otherwise, or if checksums, encryption or verification after upload are enabled, files are compressed into temporary copies first. The compression format extension is appended to the uploaded object name. Upload progress is reported based on the number of uploaded files."`
+	CompressFormat   string `json:"compressFormat,omitempty" def:"gzip" descr:"Compression format, used when compression is enabled. Allowed values are 'gzip', 'zstd' and 'brotli'"`
+	Decompress       bool   `json:"decompress,omitempty" def:"false" descr:"Decompress gzip compressed files (with '.gz' extension), e.g. rotated and compressed by logrotate, before upload, so their plain content is uploaded. The '.gz' extension is removed from the uploaded object name. Applied before the compression, if both are enabled."`
+	ConvertEncoding  Globs  `json:"convertEncoding,omitempty" def:"" descr:"Glob patterns for text files, which are converted to UTF-8 before upload, if they start with a UTF-16 byte order mark. UTF-8 byte order marks are stripped. The source files are not modified and checksums cover the converted content. Patterns are matched against the full path and the base name of each file. Specified as a JSON array in the configuration file and as a comma-separated list on the command line."`
+	ContentAddressed bool   `json:"contentAddressed,omitempty" def:"false" descr:"Use the SHA-256 hash of the file content as uploaded object name, so identical files are stored as the same object. Applies to AWS, Azure and file storage providers - the HTTP upload URLs are specified by the backend."`
+	Encrypt          bool   `json:"encrypt,omitempty" def:"false" descr:"Encrypt files with AES-256-GCM before upload, using the hex or base64 encoded key from the 'encryption.key' start option. The '.enc' extension is appended to the uploaded object name."`
+
+	SplitSize     ByteSize `json:"splitSize,omitempty" def:"0" descr:"Size of the parts, into which files exceeding it are split, e.g. '5GB'. Parts are uploaded as separate objects with '.part001', '.part002', etc. appended to the object name, followed by a JSON manifest with '.manifest.json' appended, which lists the parts for reassembly. Zero disables splitting. Applies to AWS, Azure and file storage providers. Allowed units are 'B', 'KB', 'MB' and 'GB' (powers of 1024)."`
+	BatchSize     ByteSize `json:"batchSize,omitempty" def:"0" descr:"Maximum size of a file, for the file to be batched, e.g. '64KB'. When a trigger matches at least 'batchMinFiles' such small files, they are bundled in a tar archive, named after the upload correlation ID, and uploaded with a single request instead of one request per file. Larger files are uploaded separately. Zero disables batching. Allowed units are 'B', 'KB', 'MB' and 'GB' (powers of 1024)."`
+	BatchMinFiles int      `json:"batchMinFiles,omitempty" def:"10" descr:"Minimum number of small files, for them to be uploaded as a batch, when batching is enabled with 'batchSize'"`
+	ObjectKeyRoot string   `json:"objectKeyRoot,omitempty" def:"" descr:"Root path, to which object keys, supplied by the backend with the 'object.key' start option, are restricted. The supplied key overrides the object name, derived from the upload configuration. Keys should always be relative paths, which do not escape the root with '..' elements."`
+
+	MinUploadRate       ByteSize `json:"minUploadRate,omitempty" def:"0" descr:"Minimum upload rate per file, in bytes per second, e.g. '10KB'. If fewer bytes are sent within the minimum upload rate window, e.g. on a degraded link, the file upload is aborted and fails, so it can be retried later, instead of crawling. Zero disables the check. Allowed units are 'B', 'KB', 'MB' and 'GB' (powers of 1024)."`
+	MinUploadRateWindow Duration `json:"minUploadRateWindow,omitempty" def:"1m" descr:"Period, for which the upload rate should stay below the minimum upload rate, for the upload to be aborted. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	PerFileTimeout Duration `json:"perFileTimeout,omitempty" def:"0s" descr:"Time, within which each file upload should make progress, i.e. send more bytes, for the upload not to be considered stuck. Stuck uploads are aborted and fail, so they can be retried later, instead of hanging indefinitely. Slow uploads, which still make progress, are not aborted. Waiting for the storage response, after the whole file is sent, is not limited. Zero disables the check. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	MinFreeInodes int `json:"minFreeInodes,omitempty" def:"16" descr:"Minimum number of free inodes on the file system of the temporary directory, checked before creating temporary files, e.g. archives, transformed, compressed or encrypted copies of the uploaded files, so such uploads fail with a clear error, when the inodes are exhausted. Checked only on Linux, on file systems with a fixed number of inodes. Zero disables the check."`
+
+	MaxFilesPerUpload int `json:"maxFilesPerUpload,omitempty" def:"0" descr:"Maximum number of files per upload. When a trigger matches more files, they are uploaded in sequential batches of at most that many files, with the trigger correlation ID suffixed with '-batch1', '-batch2', etc. The next batch is requested, when the previous one finishes. Zero means no limit."`
+
 	StopTimeout Duration `json:"stopTimeout,omitempty" def:"30s" descr:"Time to wait for running {running_actions} to finish when stopping. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
 	ServerCert  string   `json:"serverCert,omitempty" def:"" descr:"A PEM encoded server certificate for secure file {transfers}.\nThis certificate will be added to the trusted certificates during HTTPS {transfers}. Useful for servers with self-signed certificates."`
+
+	TLSMinVersion   string       `json:"tlsMinVersion,omitempty" def:"1.2" descr:"Minimum TLS version of the connections to the storage endpoints. Allowed values are '1.2' and '1.3'"`
+	TLSMaxVersion   string       `json:"tlsMaxVersion,omitempty" def:"1.3" descr:"Maximum TLS version of the connections to the storage endpoints. Allowed values are '1.2' and '1.3'. Should not be lower than 'tlsMinVersion'"`
+	TLSCipherSuites CipherSuites `json:"tlsCipherSuites,omitempty" def:"" descr:"Cipher suites, allowed for the TLS 1.2 connections to the storage endpoints, e.g. 'TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256'. Only secure cipher suites are supported, all of them are allowed by default. TLS 1.3 cipher suites are not configurable. Specified as a JSON array in the configuration file and as a comma-separated list on the command line."`
+
+	CredentialsFile    string   `json:"credentialsFile,omitempty" def:"" descr:"JSON file with locally provisioned storage credentials, i.e. 'start' operation options like 'aws.secret.access.key' or 'https.header.Authorization', which override the options received from the backend.\nThe file is reloaded periodically and when the storage rejects the credentials, so rotated credentials are picked up without restart."`
+	CredentialsRefresh Duration `json:"credentialsRefresh,omitempty" def:"1h" descr:"Period for reloading the credentials file. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	CredentialsRefreshMargin Duration `json:"credentialsRefreshMargin,omitempty" def:"5m" descr:"Remaining lifetime of expiring credentials from the credentials file, at which they are reloaded, also during running uploads to AWS and to Azure with shared access signature, so long uploads are not interrupted when the credentials expire. The expiration is taken from the RFC 3339 'credentials.expiration' option in the file, or from the expiry of the Azure shared access signature. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	Hooks          bool     `json:"hooks,omitempty" def:"false" descr:"Enable running of the pre-upload and post-upload hook commands. Hooks execute arbitrary commands, so they should be enabled only when the configuration is trusted."`
+	PreUploadHook  string   `json:"preUploadHook,omitempty" def:"" descr:"Executable, run before each file upload, when hooks are enabled. The file path is passed as argument and in the FILE_UPLOAD_PATH environment variable, along with the upload correlation ID in FILE_UPLOAD_CORRELATION_ID. If the command fails, the file is not uploaded and its upload fails."`
+	PostUploadHook string   `json:"postUploadHook,omitempty" def:"" descr:"Executable, run after each file upload, when hooks are enabled. The file path and the upload outcome ('success' or 'failure') are passed as arguments and in the FILE_UPLOAD_PATH and FILE_UPLOAD_OUTCOME environment variables, along with FILE_UPLOAD_CORRELATION_ID and the failure message in FILE_UPLOAD_ERROR. The command runs before the file is deleted, if deletion is enabled. Failures are only logged."`
+	HookTimeout    Duration `json:"hookTimeout,omitempty" def:"30s" descr:"Time, after which the hook commands are killed. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	Transforms Transforms `json:"transforms,omitempty" def:"" descr:"Pre-upload transforms in the form 'pattern=command', e.g. '*.db=/usr/local/bin/sqlite-backup', run when hooks are enabled. For files matching the glob pattern (against the full path or the base name), the executable is run with the file path and an output path as arguments, also passed in the FILE_UPLOAD_PATH and FILE_UPLOAD_OUTPUT environment variables, and the output file is uploaded instead of the file. Useful for uploading a consistent copy of a live database, e.g. with 'sqlite3 .backup'. The first matching transform applies. Transformed files are not batched and their interrupted uploads are not resumed. The command is killed after 'hookTimeout' and if it fails, the upload of the file fails. Specified as a JSON array in the configuration file and as a comma-separated list on the command line."`
+
+	ResumeUploads bool     `json:"resumeUploads,omitempty" def:"false" descr:"Request the backend to resume failed file uploads with a 'resume' message, reporting the number of transferred bytes. The backend replies with the 'resume' operation, instructing the upload to continue from an offset, restart or abort. Continuing is supported for HTTP uploads, which are not encrypted."`
+	ResumeTimeout Duration `json:"resumeTimeout,omitempty" def:"10m" descr:"Time to wait for the backend to resume a failed upload, after which the upload fails. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	RetryAfterAttempts int      `json:"retryAfterAttempts,omitempty" def:"0" descr:"Number of times a generic HTTP upload is re-attempted, after the storage responded with 429 (Too Many Requests) or 503 (Service Unavailable) and a 'Retry-After' header, waiting for the requested delay before each attempt. Uploads failed without a 'Retry-After' header are not re-attempted. Zero disables the re-attempts."`
+	MaxRetryAfter      Duration `json:"maxRetryAfter,omitempty" def:"1m" descr:"Maximum delay before re-attempting an upload, regardless of the longer delay requested by the storage with the 'Retry-After' header. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+	MaxRetryDuration   Duration `json:"maxRetryDuration,omitempty" def:"0s" descr:"Maximum total time spent re-attempting the upload of a single file, measured from its first attempt, so a persistently throttled file does not block the queue indefinitely. Applies to all re-attempts - after the 'Retry-After' delay, with reloaded credentials, to the fallback providers and resumed by the backend. No further re-attempts are made, when the next one would start after the maximum duration, even if re-attempts remain. Zero means no limit. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+	RetryStateFile     string   `json:"retryStateFile,omitempty" def:"" descr:"Local file, in which the pending re-attempts of the throttled uploads are persisted, so when a file is uploaded again after a restart, its re-attempts continue with the same attempt count and schedule, instead of starting over. The re-attempts start over after a restart, if not specified."`
+
+	EndpointHealthTTL Duration `json:"endpointHealthTtl,omitempty" def:"0s" descr:"Time, for which a storage endpoint is considered unavailable, after an upload to it failed with a server error or a connection failure. Uploads to unavailable endpoints fail right away, without contacting the storage. The endpoints health is reported in the 'endpointHealth' property. Zero disables endpoint health caching. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	FailureThreshold int      `json:"failureThreshold,omitempty" def:"0" descr:"Number of consecutive uploads to a storage endpoint, failed with a server error or a connection failure, after which the circuit of the endpoint is opened. Uploads to an endpoint with open circuit fail right away with status code 'circuit-open', without contacting the storage, until the cooldown elapses. The first upload after the cooldown closes the circuit on success or opens it again on failure. Zero disables circuit breaking."`
+	CircuitCooldown  Duration `json:"circuitCooldown,omitempty" def:"5m" descr:"Time, for which the circuit of a storage endpoint stays open. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	MultipartPolicy             string   `json:"multipartPolicy,omitempty" def:"default" descr:"Policy for choosing between multipart and single request AWS S3 uploads, overriding the part size specified by the backend. Allowed values are:\n'default' - as specified by the backend or the upload defaults, multipart for files larger than 5 MiB\n'auto' - multipart, if the measured upload bandwidth is below 'multipartBandwidthThreshold', re-attempting only the failed parts on flaky links, single request with lower overhead otherwise\n'multipart' - multipart with the minimum part size of 5 MiB\n'single' - single request for files up to 5 GiB"`
+	MultipartBandwidthThreshold ByteSize `json:"multipartBandwidthThreshold,omitempty" def:"1MB" descr:"Upload bandwidth, in bytes per second, below which files are uploaded in multiple parts with the 'auto' multipart policy, e.g. '512KB'. Allowed units are 'B', 'KB', 'MB' and 'GB' (powers of 1024)."`
+
+	KeepPartialUploads bool `json:"keepPartialUploads,omitempty" def:"false" descr:"Keep the partial objects of canceled or failed uploads in the storage for manual recovery, i.e. the uploaded parts of incomplete AWS S3 multipart uploads. By default, incomplete multipart uploads are aborted, so their parts do not count towards the storage usage."`
+
+	WaitForCompletionTimeout Duration `json:"waitForCompletionTimeout,omitempty" def:"1m" descr:"Time to wait for the upload to complete, before replying to a 'trigger' operation with the 'waitForCompletion' parameter set. The final upload status is returned in the reply, if the upload completes in time, otherwise the reply has status 202 and the final upload status is reported only in the 'lastUpload' property. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	SupportedProviders StorageProviders `json:"supportedProviders,omitempty" def:"" descr:"Storage providers, advertised to the backend in the upload requests and in the 'info' property, to which uploads are allowed. All registered providers are supported by default. Allowed values are 'aws', 'azure', 'generic', 'file', 'local' and 'webdav'. Specified as a JSON array in the configuration file and as a comma-separated list on the command line."`
+
+	FallbackProviders StorageProviders `json:"fallbackProviders,omitempty" def:"" descr:"Storage providers, to which a file is uploaded in the given order, if the upload to the storage provider, requested by the backend, fails with a server error or a connection failure. Allowed values are 'generic', 'aws', 'azure', 'file', 'local' and 'webdav'. The 'start' operation options, e.g. credentials, for the fallback providers should be supplied by the backend along with the requested provider options or in the credentials file. The provider, to which the files were uploaded, is reported in the upload status. Specified as a JSON array in the configuration file and as a comma-separated list on the command line."`
+
+	EventJournal string `json:"eventJournal,omitempty" def:"" descr:"Local file, to which upload lifecycle events (start, finish, fail and cancel) are appended as JSON lines for offline auditing. The file is rotated like the log file."`
+
+	DailyReport         bool   `json:"dailyReport,omitempty" def:"false" descr:"Report a summary of the uploads of each day in the 'dailyReport' property, once the day is over - the uploaded bytes, the number of started, succeeded, failed and canceled uploads and the largest uploaded files"`
+	DailyReportTimezone string `json:"dailyReportTimezone,omitempty" def:"Local" descr:"Time zone, in which the days of the daily reports start and end, e.g. 'UTC' or 'Europe/Berlin'. The local time zone of the device is used by default."`
+	DailyReportDir      string `json:"dailyReportDir,omitempty" def:"" descr:"Directory, in which each daily report is also written as 'upload-report-<date>.json' file and uploaded, with correlation ID 'daily-report-<date>'. The reports are only reported in the 'dailyReport' property by default."`
+
+	DetailedStatus bool `json:"detailedStatus,omitempty" def:"false" descr:"Include the path, state and progress of each file in the upload status of multi-file uploads. Disabled by default, to keep the status small."`
+
+	FailureLogLines int `json:"failureLogLines,omitempty" def:"0" descr:"Maximum number of the last log lines, mentioning a failed upload or its files, which are included in the 'logs' list of the failure status, so the failure can be diagnosed from the backend. Error, warning and info lines are captured regardless of the log level. Secrets like signatures, passwords, keys and tokens are redacted. Zero disables the log lines."`
+
+	StatusEncoding string `json:"statusEncoding,omitempty" def:"json" descr:"Encoding of the upload status, reported in the 'lastUpload' property. Allowed values are:\n'json' - JSON object\n'cbor' - base64 encoded CBOR map with integer keys, Unix millisecond times and integer states, for low-bandwidth links"`
+
+	ProgressCallbackURL      string   `json:"progressCallbackUrl,omitempty" def:"" descr:"Local URL, e.g. 'http://localhost:8080/upload/progress', to which the upload status is POSTed as JSON, in addition to the 'lastUpload' property updates, for on-device UIs"`
+	ProgressCallbackInterval Duration `json:"progressCallbackInterval,omitempty" def:"1s" descr:"Minimum interval between the progress callback requests. Progress updates of an upload in between are coalesced, the final upload statuses are always sent. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
+
+	LocalEventsTopic    string `json:"localEventsTopic,omitempty" def:"" descr:"Topic of the local MQTT broker, e.g. 'kanto/fileupload/events', to which the upload status is published on each upload state transition, so other on-device components can react to the upload lifecycle. Progress updates, which do not change the upload state, are not published. Empty disables the local events."`
+	LocalEventsEncoding string `json:"localEventsEncoding,omitempty" def:"json" descr:"Encoding of the upload status, published to the local events topic. Allowed values are:\n'json' - JSON object\n'cbor' - CBOR map with integer keys, Unix millisecond times and integer states"`
+
+	StatsDAddr string `json:"statsdAddr,omitempty" def:"" descr:"Address (host:port) of a StatsD server, to which upload metrics (started, succeeded, failed and canceled uploads counters, upload duration timer and bandwidth gauge) are pushed over UDP"`
+
+	MetricsAddr string `json:"metricsAddr,omitempty" def:"" descr:"Address (host:port), on which upload metrics (started, succeeded, failed and canceled uploads counters, in-flight uploads gauge, transferred bytes counter, upload duration histogram and bandwidth gauge) are exposed in Prometheus text format on the '/metrics' path. The metrics server is disabled by default"`
+
+	StructuredErrors bool `json:"structuredErrors,omitempty" def:"false" descr:"Reply to failed operations with a structured error object, containing error code, category ('client' or 'server'), message and correlation ID, so the backend can handle failures programmatically"`
+
+	Readvertise         bool     `json:"readvertise,omitempty" def:"false" descr:"Request responses to the {feature} feature updates and advertise the feature again, when the twin reports it missing, e.g. after the twin was reset by the backend, or when an update could not be sent. Failed re-advertisements are retried."`
+	ReadvertiseInterval Duration `json:"readvertiseInterval,omitempty" def:"10s" descr:"Minimum interval between the automatic re-advertisements of the {feature} feature, also used as retry interval of the failed ones. Should be a sequence of decimal numbers, each with optional fraction and a unit suffix, such as '300ms', '1.5h', '10m30s', etc. Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'"`
 }
 
 // AutoUploadableState is used for serializing the state property of the AutoUploadable feature
@@ -69,6 +221,12 @@ type AutoUploadableState struct {
 	EndTime   *time.Time `json:"endTime"`
 }
 
+// ConnectionStatus is used for serializing the connection property of the AutoUploadable feature
+type ConnectionStatus struct {
+	Connected bool      `json:"connected"`
+	Since     time.Time `json:"since"`
+}
+
 // AutoUploadable feature implementation. Implements all required communication with the backend.
 // Customized with UploadCustomizer
 type AutoUploadable struct {
@@ -79,7 +237,8 @@ type AutoUploadable struct {
 
 	info map[string]string
 
-	state AutoUploadableState
+	state      AutoUploadableState
+	connection ConnectionStatus
 
 	definitions []string
 	cfg         *UploadableConfig
@@ -89,10 +248,26 @@ type AutoUploadable struct {
 	uidCounter int64
 
 	statusEvents *StatusEventsConsumer
-
-	uploads *Uploads
-
-	executor *PeriodicExecutor
+	journal      *eventJournal
+	metrics      *uploadMetrics
+	statsD       *statsDClient
+	callback     *progressCallback
+	localEvents  *localEvents
+	prometheus   *prometheusExporter
+	advertiser   *featureAdvertiser
+	failureLogs  *failureLogs
+	reports      *dailyReports
+	dedup        *dedupFilter
+
+	uploads     *Uploads
+	batches     map[string]*uploadBatches // remaining batches of trigger uploads, by the correlation ID of the running batch
+	completions *completionWaiters        // 'trigger' operations, waiting for the upload completion
+
+	forceStop     chan struct{} // closed to cancel the running uploads right away, when disconnecting
+	forceStopOnce sync.Once
+
+	executor taskExecutor
+	endTimer *time.Timer // ends the running uploads gracefully, when the active time frame ends
 	mutex    sync.Mutex
 }
 
@@ -116,12 +291,55 @@ func (e *ErrorResponse) Error() string {
 	return fmt.Sprintf("error response [status=%d, error code=%v, msg=%s]", e.Status, e.ErrorCode, e.Message)
 }
 
+// Error categories of the structured error response
+const (
+	ErrorCategoryClient = "client"
+	ErrorCategoryServer = "server"
+)
+
+// StructuredErrorResponse is replied to failed operations, when structured errors are enabled
+type StructuredErrorResponse struct {
+	Code          ErrorCode `json:"code"`
+	Category      string    `json:"category"`
+	Message       string    `json:"message"`
+	Status        int       `json:"status"`
+	CorrelationID string    `json:"correlationId,omitempty"`
+}
+
+// newStructuredErrorResponse creates structured error response from the given error response. The correlation ID
+// of the operation payload is used, if present, otherwise the correlation ID of the request message.
+func newStructuredErrorResponse(e *ErrorResponse, payload []byte, headers *protocol.Headers) *StructuredErrorResponse {
+	params := &struct {
+		CorrelationID string `json:"correlationId"`
+	}{}
+	if err := json.Unmarshal(payload, params); err != nil || params.CorrelationID == "" {
+		params.CorrelationID = headers.CorrelationID()
+	}
+
+	category := ErrorCategoryServer
+	if e.Status < http.StatusInternalServerError {
+		category = ErrorCategoryClient
+	}
+
+	return &StructuredErrorResponse{e.ErrorCode, category, e.Message, e.Status, params.CorrelationID}
+}
+
+// FileInfo describes a file, which would be uploaded on trigger. Replied to the list operation.
+type FileInfo struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
 // UploadCustomizer is used to customize AutoUploadable behavior.
 type UploadCustomizer interface {
 	// DoTrigger is responsible for starting file uploads (by calling UploadFiles).
 	// Called when trigger operation is invoked from the backend.
 	DoTrigger(correlationID string, options map[string]string) error
 
+	// ListFiles returns the files, which would be uploaded by DoTrigger with the given options.
+	// Called when list operation is invoked from the backend.
+	ListFiles(options map[string]string) ([]FileInfo, *ErrorResponse)
+
 	// HandleOperation is called when unknown operation is invoked from the backend.
 	// Used when extending AutoUploadable with new operations
 	HandleOperation(operation string, payload []byte) *ErrorResponse
@@ -136,6 +354,16 @@ func (cfg *UploadableConfig) Validate() {
 		log.Fatalln("Period should be larger than zero!")
 	}
 
+	if cfg.PeriodJitter < 0 || cfg.PeriodJitter >= cfg.Period {
+		log.Fatalln("'periodJitter' should not be negative and should be less than the period")
+	}
+
+	if cfg.Cron != "" {
+		if _, err := ParseCron(cfg.Cron); err != nil {
+			log.Fatalf("Invalid cron expression '%s': %v", cfg.Cron, err)
+		}
+	}
+
 	if cfg.ActiveFrom.Time != nil || cfg.ActiveTill.Time != nil {
 		if cfg.ActiveFrom.Time != nil && cfg.ActiveTill.Time != nil && cfg.ActiveTill.Time.Before(*cfg.ActiveFrom.Time) {
 			log.Fatalf("'activeFrom' time should be before 'activeTill' time")
@@ -143,6 +371,226 @@ func (cfg *UploadableConfig) Validate() {
 
 		cfg.Active = true
 	}
+
+	if cfg.TickPolicy != TickPolicyOverlap && cfg.TickPolicy != TickPolicySkipIfRunning {
+		log.Fatalf("Unsupported tick policy '%s' - allowed values are '%s' and '%s'", cfg.TickPolicy, TickPolicyOverlap, TickPolicySkipIfRunning)
+	}
+
+	if cfg.ActiveEndPolicy != ActiveEndPolicyContinue && cfg.ActiveEndPolicy != ActiveEndPolicyGraceful {
+		log.Fatalf("Unsupported active end policy '%s' - allowed values are '%s' and '%s'", cfg.ActiveEndPolicy, ActiveEndPolicyContinue, ActiveEndPolicyGraceful)
+	}
+
+	if cfg.ActiveEndGracePeriod < 0 {
+		log.Fatalln("'activeEndGracePeriod' should not be negative")
+	}
+
+	if cfg.InFlightPolicy != InFlightPolicyUpload && cfg.InFlightPolicy != InFlightPolicySkip {
+		log.Fatalf("Unsupported in-flight policy '%s' - allowed values are '%s' and '%s'", cfg.InFlightPolicy, InFlightPolicyUpload, InFlightPolicySkip)
+	}
+
+	if cfg.StatusEncoding != StatusEncodingJSON && cfg.StatusEncoding != StatusEncodingCBOR {
+		log.Fatalf("Unsupported status encoding '%s' - allowed values are '%s' and '%s'", cfg.StatusEncoding, StatusEncodingJSON, StatusEncodingCBOR)
+	}
+
+	if cfg.LocalEventsTopic != "" && cfg.LocalEventsEncoding != StatusEncodingJSON && cfg.LocalEventsEncoding != StatusEncodingCBOR {
+		log.Fatalf("Unsupported local events encoding '%s' - allowed values are '%s' and '%s'", cfg.LocalEventsEncoding, StatusEncodingJSON, StatusEncodingCBOR)
+	}
+
+	if _, err := parseFileAttribute(cfg.FileAttribute); err != nil {
+		log.Fatalf("Invalid 'fileAttribute': %v", err)
+	}
+
+	if cfg.FailureLogLines < 0 {
+		log.Fatalln("'failureLogLines' should not be negative")
+	}
+
+	if cfg.TriggerWindow < 0 {
+		log.Fatalln("'triggerWindow' should not be negative")
+	}
+
+	if cfg.DeleteRate < 0 {
+		log.Fatalln("'deleteRate' should not be negative")
+	}
+
+	if cfg.GlobWorkers < 1 {
+		log.Fatalln("'globWorkers' should be larger than zero")
+	}
+
+	if cfg.MaxFileAge > 0 && cfg.MaxFileAge < cfg.MinFileAge {
+		log.Fatalln("'maxFileAge' should not be less than 'minFileAge'")
+	}
+
+	if cfg.MinFileSize < 0 || cfg.MaxFileSize < 0 {
+		log.Fatalln("File size limits should not be negative")
+	}
+
+	if cfg.MaxFileSize > 0 && cfg.MaxFileSize < cfg.MinFileSize {
+		log.Fatalln("'maxFileSize' should not be less than 'minFileSize'")
+	}
+
+	if cfg.SplitSize < 0 {
+		log.Fatalln("'splitSize' should not be negative")
+	}
+
+	if cfg.BatchSize < 0 {
+		log.Fatalln("'batchSize' should not be negative")
+	}
+
+	if cfg.MaxFilesPerUpload < 0 {
+		log.Fatalln("'maxFilesPerUpload' should not be negative")
+	}
+
+	if cfg.BatchSize > 0 && cfg.BatchMinFiles < 2 {
+		log.Fatalln("'batchMinFiles' should be at least 2")
+	}
+
+	if cfg.Hooks && cfg.HookTimeout <= 0 {
+		log.Fatalln("'hookTimeout' should be larger than zero")
+	}
+
+	if len(cfg.Transforms) > 0 && !cfg.Hooks {
+		log.Fatalln("'transforms' require 'hooks' to be enabled")
+	}
+
+	for _, transform := range cfg.Transforms {
+		if _, _, err := parseTransform(transform); err != nil {
+			log.Fatalf("Invalid pre-upload transform: %v", err)
+		}
+	}
+
+	if cfg.ResumeUploads && cfg.ResumeTimeout <= 0 {
+		log.Fatalln("'resumeTimeout' should be larger than zero")
+	}
+
+	if cfg.RetryAfterAttempts < 0 {
+		log.Fatalln("'retryAfterAttempts' should not be negative")
+	}
+
+	if cfg.RetryAfterAttempts > 0 && cfg.MaxRetryAfter <= 0 {
+		log.Fatalln("'maxRetryAfter' should be larger than zero")
+	}
+
+	if cfg.MaxRetryDuration < 0 {
+		log.Fatalln("'maxRetryDuration' should not be negative")
+	}
+
+	if cfg.EndpointHealthTTL < 0 {
+		log.Fatalln("'endpointHealthTtl' should not be negative")
+	}
+
+	if cfg.FailureThreshold < 0 {
+		log.Fatalln("'failureThreshold' should not be negative")
+	}
+
+	if cfg.FailureThreshold > 0 && cfg.CircuitCooldown <= 0 {
+		log.Fatalln("'circuitCooldown' should be larger than zero")
+	}
+
+	switch cfg.MultipartPolicy {
+	case "", MultipartPolicyDefault, MultipartPolicyAuto, MultipartPolicyMultipart, MultipartPolicySingle:
+	default:
+		log.Fatalf("Unsupported multipart policy '%s' - allowed values are '%s', '%s', '%s' and '%s'", cfg.MultipartPolicy,
+			MultipartPolicyDefault, MultipartPolicyAuto, MultipartPolicyMultipart, MultipartPolicySingle)
+	}
+
+	if cfg.MultipartPolicy == MultipartPolicyAuto && cfg.MultipartBandwidthThreshold <= 0 {
+		log.Fatalln("'multipartBandwidthThreshold' should be larger than zero")
+	}
+
+	if cfg.WaitForCompletionTimeout <= 0 {
+		log.Fatalln("'waitForCompletionTimeout' should be larger than zero")
+	}
+
+	if cfg.MinUploadRate < 0 {
+		log.Fatalln("'minUploadRate' should not be negative")
+	}
+
+	if cfg.MinUploadRate > 0 && cfg.MinUploadRateWindow <= 0 {
+		log.Fatalln("'minUploadRateWindow' should be larger than zero")
+	}
+
+	if cfg.PerFileTimeout < 0 {
+		log.Fatalln("'perFileTimeout' should not be negative")
+	}
+
+	if cfg.MinFreeInodes < 0 {
+		log.Fatalln("'minFreeInodes' should not be negative")
+	}
+
+	if cfg.DedupWindow < 0 {
+		log.Fatalln("'dedupWindow' should not be negative")
+	}
+
+	if cfg.DedupWindow > 0 && cfg.DedupCapacity <= 0 {
+		log.Fatalln("'dedupCapacity' should be larger than zero")
+	}
+
+	if cfg.DedupWindow > 0 && (cfg.DedupFalsePositiveRate <= 0 || cfg.DedupFalsePositiveRate >= 1) {
+		log.Fatalln("'dedupFalsePositiveRate' should be between 0 and 1")
+	}
+
+	if cfg.DedupStateMaxAge < 0 {
+		log.Fatalln("'dedupStateMaxAge' should not be negative")
+	}
+
+	if cfg.CredentialsRefreshMargin < 0 {
+		log.Fatalln("'credentialsRefreshMargin' should not be negative")
+	}
+
+	if cfg.DailyReport {
+		if _, err := time.LoadLocation(cfg.DailyReportTimezone); err != nil {
+			log.Fatalf("Invalid 'dailyReportTimezone': %v", err)
+		}
+	}
+
+	if cfg.ProgressCallbackInterval < 0 {
+		log.Fatalln("'progressCallbackInterval' should not be negative")
+	}
+
+	if cfg.Readvertise && cfg.ReadvertiseInterval <= 0 {
+		log.Fatalln("'readvertiseInterval' should be larger than zero")
+	}
+
+	if _, _, err := uploaders.ParseTLSVersions(cfg.TLSMinVersion, cfg.TLSMaxVersion); err != nil {
+		log.Fatalf("Invalid 'tlsMinVersion' or 'tlsMaxVersion': %v", err)
+	}
+
+	if _, err := uploaders.ParseCipherSuites(cfg.TLSCipherSuites.String()); err != nil {
+		log.Fatalf("Invalid 'tlsCipherSuites': %v", err)
+	}
+
+	for _, provider := range cfg.SupportedProviders {
+		if err := ValidateStorageProvider(provider); err != nil {
+			log.Fatalf("Invalid supported provider: %v", err)
+		}
+	}
+
+	for _, provider := range cfg.FallbackProviders {
+		if err := ValidateStorageProvider(provider); err != nil {
+			log.Fatalf("Invalid fallback provider: %v", err)
+		}
+		if !isProviderSupported(cfg, provider) {
+			log.Fatalf("Fallback provider '%s' is not among the supported providers", provider)
+		}
+	}
+
+	for _, glob := range cfg.ExcludeFiles {
+		if err := ValidateGlob(glob); err != nil {
+			log.Fatalf("Invalid exclude files pattern '%s': %v", glob, err)
+		}
+	}
+
+	for _, glob := range cfg.ConvertEncoding {
+		if err := ValidateGlob(glob); err != nil {
+			log.Fatalf("Invalid convert encoding pattern '%s': %v", glob, err)
+		}
+	}
+
+	if cfg.Compress {
+		if err := uploaders.ValidateCompressionFormat(cfg.CompressFormat); err != nil {
+			log.Fatalln(err)
+		}
+	}
 }
 
 // NewAutoUploadable constructs AutoUploadable from the provided configurations
@@ -156,22 +604,117 @@ func NewAutoUploadable(uploadableCfg *UploadableConfig, handler UploadCustomizer
 	result.uidCounter = time.Now().Unix()
 
 	result.statusEvents = NewStatusEventsConsumer(100)
+	result.forceStop = make(chan struct{})
 
 	result.state.Active = uploadableCfg.Active
 	result.state.StartTime = uploadableCfg.ActiveFrom.Time
 	result.state.EndTime = uploadableCfg.ActiveTill.Time
 
-	result.info = map[string]string{"supportedProviders": uploaders.StorageProviderAWS + "," + uploaders.StorageProviderAzure + "," + uploaders.StorageProviderHTTP}
+	result.info = configInfo(uploadableCfg)
 
 	result.uploads = NewUploads()
+	result.completions = newCompletionWaiters()
+	result.batches = make(map[string]*uploadBatches)
+
+	if uploadableCfg.EventJournal != "" {
+		var err error
+		if result.journal, err = newEventJournal(uploadableCfg.EventJournal); err != nil {
+			return nil, err
+		}
+	}
+
+	if uploadableCfg.DedupWindow > 0 {
+		var err error
+		if result.dedup, err = newDedupFilter(time.Duration(uploadableCfg.DedupWindow), uploadableCfg.DedupCapacity,
+			float64(uploadableCfg.DedupFalsePositiveRate), uploadableCfg.DedupStateFile,
+			time.Duration(uploadableCfg.DedupStateMaxAge)); err != nil {
+			return nil, err
+		}
+	}
+
+	if uploadableCfg.ProgressCallbackURL != "" {
+		var err error
+		if result.callback, err = newProgressCallback(uploadableCfg.ProgressCallbackURL,
+			time.Duration(uploadableCfg.ProgressCallbackInterval)); err != nil {
+			return nil, err
+		}
+	}
+
+	if uploadableCfg.LocalEventsTopic != "" {
+		result.localEvents = newLocalEvents(uploadableCfg.LocalEventsTopic, uploadableCfg.LocalEventsEncoding)
+	}
+
+	if uploadableCfg.Readvertise {
+		result.advertiser = newFeatureAdvertiser(time.Duration(uploadableCfg.ReadvertiseInterval), result.readvertise)
+	}
+
+	var sinks []metricsSink
+	if uploadableCfg.StatsDAddr != "" {
+		var err error
+		if result.statsD, err = newStatsDClient(uploadableCfg.StatsDAddr); err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, result.statsD)
+	}
+	if uploadableCfg.MetricsAddr != "" {
+		var err error
+		if result.prometheus, err = newPrometheusExporter(uploadableCfg.MetricsAddr, result.uploads); err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, result.prometheus)
+	}
+	result.metrics = newUploadMetrics(sinks...)
+	if result.prometheus != nil {
+		result.prometheus.metrics = result.metrics
+	}
+
+	if uploadableCfg.FailureLogLines > 0 {
+		result.failureLogs = newFailureLogs(uploadableCfg.FailureLogLines)
+	}
+
+	if uploadableCfg.DailyReport {
+		location, err := time.LoadLocation(uploadableCfg.DailyReportTimezone)
+		if err != nil {
+			return nil, err
+		}
+		result.reports = newDailyReports(location, func() (UploadMetrics, int64) {
+			return result.metrics.snapshot(), result.uploads.BytesTransferred()
+		}, result.dailyReportCompleted)
+	}
 
 	return result, nil
 }
 
+// configInfo returns the effective configuration, reported in the 'info' property, for remote diagnostics.
+// Only settings, which shape the uploads, are included - never credentials, keys or other secrets.
+func configInfo(cfg *UploadableConfig) map[string]string {
+	info := map[string]string{
+		"supportedProviders": strings.Join(advertisedProviders(cfg), ","),
+		"period":             cfg.Period.String(),
+		"active":             strconv.FormatBool(cfg.Active),
+		"delete":             strconv.FormatBool(cfg.Delete),
+		"checksum":           strconv.FormatBool(cfg.Checksum),
+		"compress":           strconv.FormatBool(cfg.Compress),
+		"encrypt":            strconv.FormatBool(cfg.Encrypt),
+	}
+	if cfg.Cron != "" {
+		info["cron"] = cfg.Cron
+	}
+
+	return info
+}
+
 // Connect AutoUploadable to the Ditto endpoint
 func (u *AutoUploadable) Connect(mqttClient MQTT.Client, edgeCfg *EdgeConfiguration) {
 	u.deviceID = edgeCfg.DeviceID
 	u.tenantID = edgeCfg.TenantID
+	u.uploads.setDeviceID(edgeCfg.DeviceID)
+	if u.localEvents != nil {
+		u.localEvents.connect(mqttClient)
+	}
+	u.mutex.Lock()
+	u.connection = ConnectionStatus{Connected: true, Since: time.Now()}
+	u.mutex.Unlock()
 
 	config := ditto.NewConfiguration().
 		WithDisconnectTimeout(defaultDisconnectTimeout).
@@ -196,35 +739,103 @@ func (u *AutoUploadable) Connect(mqttClient MQTT.Client, edgeCfg *EdgeConfigurat
 	}
 
 	u.statusEvents.Start(func(e interface{}) {
-		u.UpdateProperty(lastUploadProperty, e)
+		switch v := e.(type) {
+		case Bandwidth:
+			u.UpdateProperty(bandwidthProperty, v)
+		case EndpointsHealth:
+			u.UpdateProperty(endpointHealthProperty, v)
+		case ConnectionStatus:
+			u.UpdateProperty(connectionProperty, v)
+		case DailyReport:
+			u.UpdateProperty(dailyReportProperty, v)
+		case UploadStatus:
+			if u.cfg.StatusEncoding == StatusEncodingCBOR {
+				u.UpdateProperty(lastUploadProperty, CompactStatus(&v))
+			} else {
+				u.UpdateProperty(lastUploadProperty, v)
+			}
+		default:
+			u.UpdateProperty(lastUploadProperty, e)
+		}
 	})
 
 	logger.Info("ditto client connected")
 }
 
+// ConnectionChanged updates the connection property with the MQTT broker connection status. The property is updated
+// asynchronously, so it is published once the connection is re-established.
+func (u *AutoUploadable) ConnectionChanged(connected bool) {
+	status := ConnectionStatus{Connected: connected, Since: time.Now()}
+	if connected {
+		logger.Info("connection to MQTT broker restored")
+	}
+
+	u.mutex.Lock()
+	u.connection = status
+	u.mutex.Unlock()
+
+	u.statusEvents.Add(status)
+}
+
+// ForceStop cancels the running uploads right away, instead of waiting up to the stop timeout for them to complete,
+// when disconnecting. Should be called only when shutting down, e.g. on a second termination signal.
+func (u *AutoUploadable) ForceStop() {
+	u.forceStopOnce.Do(func() {
+		close(u.forceStop)
+	})
+}
+
 // Disconnect AutoUploadable from the Ditto endpoint and clean up used resources
 func (u *AutoUploadable) Disconnect() {
 	u.statusEvents.Stop()
 
+	u.mutex.Lock()
+	u.batches = nil // the remaining batches are not uploaded
+	u.mutex.Unlock()
+
+	u.advertiser.stop()
+
 	u.client.Unsubscribe()
 	logger.Info("ditto client unsubscribed")
 	u.client.Disconnect()
 	logger.Info("ditto client disconnected")
 
 	u.stopExecutor() //stop periodic triggers
+	u.reports.stop()
+
+	u.uploads.Stop(time.Duration(u.cfg.StopTimeout), u.forceStop) // stop active uploads
+
+	if u.journal != nil {
+		if err := u.journal.close(); err != nil {
+			logger.Errorf("failed to close the event journal: %v", err)
+		}
+	}
 
-	u.uploads.Stop(time.Duration(u.cfg.StopTimeout)) // stop active uploads
+	u.saveDedup()
+
+	if u.callback != nil {
+		u.callback.close()
+	}
+
+	u.failureLogs.close()
+
+	if u.statsD != nil {
+		if err := u.statsD.close(); err != nil {
+			logger.Errorf("failed to close the StatsD connection: %v", err)
+		}
+	}
+
+	if u.prometheus != nil {
+		if err := u.prometheus.close(metricsShutdownTimeout); err != nil {
+			logger.Errorf("failed to shut down the metrics server: %v", err)
+		}
+	}
 
 	logger.Info("ditto client disconnected")
 }
 
 func (u *AutoUploadable) connectHandler(client *ditto.Client) {
-	feature := &model.Feature{}
-
-	feature.WithDefinitionFrom(u.definitions...).
-		WithProperty("type", u.cfg.Type).WithProperty("context", u.cfg.Context).WithProperty("info", u.info).WithProperty(autoUploadProperty, u.state)
-
-	cmd := things.NewCommand(model.NewNamespacedIDFrom(u.deviceID)).Twin().Feature(u.cfg.FeatureID).Modify(feature)
+	cmd := things.NewCommand(model.NewNamespacedIDFrom(u.deviceID)).Twin().Feature(u.cfg.FeatureID).Modify(u.feature())
 	msg := cmd.Envelope(protocol.WithResponseRequired(false))
 
 	err := client.Send(msg)
@@ -237,6 +848,54 @@ func (u *AutoUploadable) connectHandler(client *ditto.Client) {
 	}
 }
 
+// feature returns the feature, as advertised to the twin
+func (u *AutoUploadable) feature() *model.Feature {
+	u.mutex.Lock()
+	connection := u.connection
+	u.mutex.Unlock()
+
+	feature := &model.Feature{}
+
+	return feature.WithDefinitionFrom(u.definitions...).
+		WithProperty("type", u.cfg.Type).WithProperty("context", u.cfg.Context).WithProperty("info", u.info).WithProperty(autoUploadProperty, u.state).
+		WithProperty(connectionProperty, connection)
+}
+
+// readvertise sends the feature to the twin again, requesting a response, so a missing twin is detected as well
+func (u *AutoUploadable) readvertise() error {
+	cmd := things.NewCommand(model.NewNamespacedIDFrom(u.deviceID)).Twin().Feature(u.cfg.FeatureID).Modify(u.feature())
+
+	return u.client.Send(cmd.Envelope(protocol.WithResponseRequired(true),
+		protocol.WithCorrelationID(u.twinCorrelationID(featureCorrelationSuffix))))
+}
+
+// twinCorrelationID returns the correlation ID of the twin update of the given feature property or of the whole feature
+func (u *AutoUploadable) twinCorrelationID(name string) string {
+	return u.cfg.FeatureID + twinCorrelationInfix + name
+}
+
+// twinResponseHandler re-advertises the feature, when the twin reports it missing in response to its update
+func (u *AutoUploadable) twinResponseHandler(msg *protocol.Envelope) {
+	if u.advertiser == nil || !strings.HasPrefix(msg.Headers.CorrelationID(), u.cfg.FeatureID+twinCorrelationInfix) {
+		return
+	}
+
+	if model.NewNamespacedID(msg.Topic.Namespace, msg.Topic.EntityName).String() != u.deviceID {
+		return
+	}
+
+	if msg.Topic.Criterion != protocol.CriterionErrors && msg.Status < http.StatusBadRequest {
+		return // acknowledged
+	}
+
+	if msg.Status == http.StatusNotFound {
+		logger.Warnf("feature update '%s' failed, the twin reports the feature missing: %v", msg.Headers.CorrelationID(), msg.Value)
+		u.advertiser.trigger()
+	} else {
+		logger.Errorf("feature update '%s' failed with status %d: %v", msg.Headers.CorrelationID(), msg.Status, msg.Value)
+	}
+}
+
 func (u *AutoUploadable) sendUploadRequest(correlationID string, options map[string]string, filePath string) {
 	type uploadRequest struct {
 		CorrelationID string            `json:"correlationId"`
@@ -257,8 +916,35 @@ func (u *AutoUploadable) sendUploadRequest(correlationID string, options map[str
 	}
 }
 
+func (u *AutoUploadable) sendResumeRequest(correlationID string, offset int64, size int64, uploadErr error) {
+	type resumeRequest struct {
+		CorrelationID string `json:"correlationId"`
+		Offset        int64  `json:"offset"`
+		Size          int64  `json:"size"`
+		Message       string `json:"message"`
+	}
+
+	request := resumeRequest{correlationID, offset, size, uploadErr.Error()}
+
+	msg := things.NewMessage(model.NewNamespacedIDFrom(u.deviceID)).Feature(u.cfg.FeatureID).Outbox("resume").WithPayload(request)
+
+	replyTo := fmt.Sprintf("command/%s", u.tenantID)
+	err := u.client.Send(msg.Envelope(protocol.WithResponseRequired(false), protocol.WithContentType("application/json"), protocol.WithReplyTo(replyTo)))
+
+	if err != nil {
+		logger.Errorf("failed to send resume upload message '%v': %v", request, err)
+	} else {
+		logger.Infof("resume upload message '%v' sent", msg)
+	}
+}
+
 // messageHandler should be called in separate go routine for each request
 func (u *AutoUploadable) messageHandler(requestID string, msg *protocol.Envelope) {
+	if msg.Topic.Channel == protocol.ChannelTwin {
+		u.twinResponseHandler(msg)
+		return
+	}
+
 	if !strings.HasPrefix(msg.Path, "/features/"+u.cfg.FeatureID) {
 		return //not for me
 	}
@@ -289,14 +975,21 @@ func (u *AutoUploadable) messageHandler(requestID string, msg *protocol.Envelope
 	}
 
 	responseError := (*ErrorResponse)(nil)
+	result := interface{}(nil)
 
 	switch operation {
 	case "start":
 		responseError = u.start(payload)
 	case "trigger":
-		responseError = u.trigger(payload)
+		result, responseError = u.trigger(payload)
+	case "list":
+		result, responseError = u.list(payload)
+	case "resume":
+		responseError = u.resume(payload)
 	case "cancel":
 		responseError = u.cancel(payload)
+	case "cancelAll":
+		responseError = u.cancelAll(payload)
 	case "activate":
 		responseError = u.activate(payload)
 	case "deactivate":
@@ -308,10 +1001,22 @@ func (u *AutoUploadable) messageHandler(requestID string, msg *protocol.Envelope
 	status := http.StatusNoContent
 	message := interface{}(nil)
 
+	if result != nil {
+		status = http.StatusOK
+		if _, ok := result.(*pendingUpload); ok {
+			status = http.StatusAccepted
+		}
+		message = result
+	}
+
 	if responseError != nil {
 		status = responseError.Status
 		message = responseError
 
+		if u.cfg.StructuredErrors {
+			message = newStructuredErrorResponse(responseError, payload, msg.Headers)
+		}
+
 		logger.Errorf("error while executing operation %s: %s", operation, responseError.Message)
 	}
 
@@ -338,9 +1043,13 @@ func (u *AutoUploadable) UpdateProperty(featureID string, value interface{}) {
 	command := things.NewCommand(model.NewNamespacedIDFrom(u.deviceID)).Twin().FeatureProperty(u.cfg.FeatureID, featureID).Modify(value)
 
 	envelope := command.Envelope(protocol.WithResponseRequired(false))
+	if u.advertiser != nil {
+		envelope = command.Envelope(protocol.WithResponseRequired(true), protocol.WithCorrelationID(u.twinCorrelationID(featureID)))
+	}
 
 	if err := u.client.Send(envelope); err != nil {
 		logger.Errorf("could not send Ditto message: %v", err)
+		u.advertiser.trigger()
 	} else {
 		logger.Infof("feature property '%s' value updated: %v", featureID, value)
 	}
@@ -357,12 +1066,101 @@ func (u *AutoUploadable) uploadStatusUpdated(status *UploadStatus) {
 		}
 	}()
 
+	if status.State == StateFailed && u.failureLogs != nil {
+		failed := *status
+		failed.Logs = u.failureLogs.get(status.CorrelationID)
+		status = &failed
+	}
+
+	if u.journal != nil {
+		u.journal.add(status)
+	}
+
+	if u.callback != nil {
+		u.callback.add(status)
+	}
+
+	if u.localEvents != nil {
+		u.localEvents.add(status)
+	}
+
+	if status.finished() {
+		u.uploadBatchFinished(*status)
+		if u.dedup != nil {
+			u.dedup.saveLater() // the listener should not block on the state file
+		}
+	}
+
 	s := *status
 	u.statusEvents.Add(s)
+	u.completions.notify(s)
+
+	bandwidth := int64(0)
+	if s.finished() {
+		bandwidth = u.uploads.Bandwidth()
+		u.statusEvents.Add(Bandwidth{bandwidth})
+	}
+
+	u.metrics.add(&s, bandwidth)
+}
+
+func (u *AutoUploadable) uploadResumeRequested(correlationID string, offset int64, size int64, err error) {
+	go u.sendResumeRequest(correlationID, offset, size, err)
+}
+
+func (u *AutoUploadable) endpointHealthUpdated(health EndpointsHealth) {
+	u.statusEvents.Add(health)
+}
+
+func (u *AutoUploadable) fileUploaded(path string, size int64) {
+	if u.reports != nil {
+		u.reports.fileUploaded(path, size)
+	}
+
+	if u.dedup != nil {
+		// a file with different size was modified after the upload and should be uploaded again
+		if info, err := os.Stat(path); err == nil && info.Size() == size {
+			u.dedup.add(path, info)
+		}
+	}
 }
 
 // ******* END UploadStatusListener methods *******//
 
+// saveDedup persists the deduplication filter right away, if configured
+func (u *AutoUploadable) saveDedup() {
+	if u.dedup == nil {
+		return
+	}
+
+	if err := u.dedup.save(); err != nil {
+		logger.Errorf("failed to save the deduplication state file: %v", err)
+	}
+}
+
+// dailyReportCompleted reports the summary of the uploads of the day, which is over, and uploads it,
+// if the daily reports directory is configured
+func (u *AutoUploadable) dailyReportCompleted(report *DailyReport) {
+	logger.Infof("uploads of %s: %d started, %d succeeded, %d failed, %d canceled, %d bytes uploaded", report.Date,
+		report.Started, report.Succeeded, report.Failed, report.Canceled, report.BytesUploaded)
+
+	u.statusEvents.Add(*report)
+
+	if u.cfg.DailyReportDir == "" {
+		return
+	}
+
+	path, err := writeReport(u.cfg.DailyReportDir, report)
+	if err != nil {
+		logger.Errorf("failed to write the daily report of %s: %v", report.Date, err)
+		return
+	}
+
+	if err := u.UploadFiles(dailyReportCorrelation+report.Date, []string{path}, nil); err != nil {
+		logger.Errorf("failed to upload the daily report of %s: %v", report.Date, err)
+	}
+}
+
 func (u *AutoUploadable) activate(payload []byte) *ErrorResponse {
 	type inputParams struct {
 		From *time.Time `json:"from"`
@@ -405,37 +1203,91 @@ func (u *AutoUploadable) deactivate(payload []byte) *ErrorResponse {
 	return nil
 }
 
-func (u *AutoUploadable) trigger(payload []byte) *ErrorResponse {
+// trigger triggers the upload. With the 'waitForCompletion' parameter set, waits for the upload of the trigger
+// to complete and returns its final status, or the pending upload, if it does not complete within the configured
+// timeout. The upload of a trigger, delayed by the trigger window, is waited for as well, also if merged into another
+// trigger, and so are the uploads of a trigger, split into batches - the final status of the last batch is returned.
+// Returns no result, if no upload is started for the trigger, e.g. there are no files to upload.
+func (u *AutoUploadable) trigger(payload []byte) (interface{}, *ErrorResponse) {
 	type inputParams struct {
-		CorrelationID string            `json:"correlationId"`
-		Options       map[string]string `json:"options"`
+		CorrelationID     string            `json:"correlationId"`
+		DryRun            bool              `json:"dryRun"`
+		WaitForCompletion bool              `json:"waitForCompletion"`
+		Options           map[string]string `json:"options"`
 	}
 	params := &inputParams{}
 
 	err := json.Unmarshal(payload, params)
 	if err != nil {
 		msg := fmt.Sprintf("invalid 'trigger' operation parameters: %v", string(payload))
-		return &ErrorResponse{http.StatusBadRequest, ErrorCodeParameterInvalid, msg}
+		return nil, &ErrorResponse{http.StatusBadRequest, ErrorCodeParameterInvalid, msg}
 	}
 
 	logger.Infof("trigger called: %+v", params)
 
+	if params.DryRun {
+		if params.Options == nil {
+			params.Options = make(map[string]string)
+		}
+		params.Options[DryRunOption] = "true"
+	}
+
 	correlationID := params.CorrelationID
 	if correlationID == "" {
 		correlationID = u.nextUID()
 	}
 
-	err = u.customizer.DoTrigger(correlationID, params.Options)
+	if !params.WaitForCompletion {
+		if err = u.customizer.DoTrigger(correlationID, params.Options); err != nil {
+			return nil, &ErrorResponse{http.StatusInternalServerError, ErrorCodeExecutionFailed, err.Error()}
+		}
+		return nil, nil
+	}
+
+	completed := u.completions.add(correlationID) // registered in advance, as the upload might complete right away
+	defer u.completions.remove(completed)
+
+	if err = u.customizer.DoTrigger(correlationID, params.Options); err != nil {
+		return nil, &ErrorResponse{http.StatusInternalServerError, ErrorCodeExecutionFailed, err.Error()}
+	}
+
+	if target, ok := u.completions.waitsFor(completed); ok && !u.isTriggerPending(target) {
+		return nil, nil // no upload started
+	}
+
+	select {
+	case status, ok := <-completed:
+		if !ok {
+			return nil, nil // no upload started
+		}
+		return &status, nil
+	case <-time.After(time.Duration(u.cfg.WaitForCompletionTimeout)):
+		logger.Infof("upload %s did not complete within %v", correlationID, time.Duration(u.cfg.WaitForCompletionTimeout))
+		return &pendingUpload{correlationID}, nil
+	}
+}
+
+func (u *AutoUploadable) list(payload []byte) ([]FileInfo, *ErrorResponse) {
+	type inputParams struct {
+		Options map[string]string `json:"options"`
+	}
+	params := &inputParams{}
+
+	err := json.Unmarshal(payload, params)
 	if err != nil {
-		return &ErrorResponse{http.StatusInternalServerError, ErrorCodeExecutionFailed, err.Error()}
+		msg := fmt.Sprintf("invalid 'list' operation parameters: %v", string(payload))
+		return nil, &ErrorResponse{http.StatusBadRequest, ErrorCodeParameterInvalid, msg}
 	}
 
-	return nil
+	logger.Infof("list called: %+v", params)
+
+	return u.customizer.ListFiles(params.Options)
 }
 
 func (u *AutoUploadable) start(payload []byte) *ErrorResponse {
 	type inputParams struct {
 		CorrelationID string            `json:"correlationId"`
+		DryRun        bool              `json:"dryRun"`
 		Options       map[string]string `json:"options"`
 	}
 	params := &inputParams{}
@@ -448,6 +1300,13 @@ func (u *AutoUploadable) start(payload []byte) *ErrorResponse {
 
 	logger.Infof("start called: %+v", params)
 
+	if params.DryRun {
+		if params.Options == nil {
+			params.Options = make(map[string]string)
+		}
+		params.Options[DryRunOption] = "true"
+	}
+
 	up := u.uploads.Get(params.CorrelationID)
 
 	if up == nil {
@@ -465,6 +1324,53 @@ func (u *AutoUploadable) start(payload []byte) *ErrorResponse {
 	return nil
 }
 
+func (u *AutoUploadable) resume(payload []byte) *ErrorResponse {
+	type inputParams struct {
+		CorrelationID string            `json:"correlationId"`
+		Instruction   string            `json:"instruction"`
+		Offset        *int64            `json:"offset"`
+		Options       map[string]string `json:"options"`
+	}
+	params := &inputParams{}
+
+	err := json.Unmarshal(payload, params)
+	if err != nil {
+		msg := fmt.Sprintf("invalid 'resume' operation parameters: %v", string(payload))
+		return &ErrorResponse{http.StatusBadRequest, ErrorCodeParameterInvalid, msg}
+	}
+
+	logger.Infof("resume called: %+v", params)
+
+	if params.Instruction != ResumeContinue && params.Instruction != ResumeRestart && params.Instruction != ResumeAbort {
+		msg := fmt.Sprintf("invalid resume instruction '%s', expected one of '%s', '%s' or '%s'",
+			params.Instruction, ResumeContinue, ResumeRestart, ResumeAbort)
+		return &ErrorResponse{http.StatusBadRequest, ErrorCodeParameterInvalid, msg}
+	}
+
+	up := u.uploads.Get(params.CorrelationID)
+
+	if up == nil {
+		return &ErrorResponse{http.StatusNotFound,
+			ErrorCodeParameterInvalid,
+			fmt.Sprintf("upload with correlation ID '%s' not found", params.CorrelationID)}
+	}
+
+	su, ok := up.(*SingleUpload)
+	if !ok {
+		return &ErrorResponse{http.StatusBadRequest,
+			ErrorCodeParameterInvalid,
+			fmt.Sprintf("upload with correlation ID '%s' is not a file upload", params.CorrelationID)}
+	}
+
+	err = su.resume(params.Instruction, params.Offset, params.Options)
+	if err != nil {
+		logger.Errorf("failed to resume upload %s: %v", params.CorrelationID, err)
+		return &ErrorResponse{http.StatusInternalServerError, ErrorCodeExecutionFailed, err.Error()}
+	}
+
+	return nil
+}
+
 func (u *AutoUploadable) cancel(payload []byte) *ErrorResponse {
 	type inputParams struct {
 		CorrelationID string `json:"correlationId"`
@@ -492,16 +1398,256 @@ func (u *AutoUploadable) cancel(payload []byte) *ErrorResponse {
 	return nil
 }
 
+// cancelAll cancels all running uploads with the given status code and message, e.g. during an incident.
+// The remaining batches of the canceled trigger uploads are not uploaded.
+func (u *AutoUploadable) cancelAll(payload []byte) *ErrorResponse {
+	type inputParams struct {
+		StatusCode string `json:"statusCode"`
+		Message    string `json:"message"`
+	}
+	params := &inputParams{}
+
+	if err := json.Unmarshal(payload, params); err != nil {
+		msg := fmt.Sprintf("invalid 'cancelAll' operation parameters: %v", string(payload))
+		return &ErrorResponse{http.StatusBadRequest, ErrorCodeParameterInvalid, msg}
+	}
+
+	logger.Infof("cancelAll called: %+v", params)
+
+	u.mutex.Lock()
+	if u.batches != nil {
+		u.batches = make(map[string]*uploadBatches)
+	}
+	u.mutex.Unlock()
+
+	canceled := u.uploads.cancelAll(params.StatusCode, params.Message)
+	logger.Infof("%d upload(s) canceled", canceled)
+
+	return nil
+}
+
 // ******* END AutoUploadable Feature operations *******//
 
 // UploadFiles starts the upload of the given files, by sending an upload request with the specified
-// correlation ID and options.
-func (u *AutoUploadable) UploadFiles(correlationID string, files []string, options map[string]string) {
-	childIDs := u.uploads.AddMulti(correlationID, files, u.cfg.Delete, u.cfg.Checksum, u.cfg.ServerCert, u)
+// correlation ID and options. If the 'archive.mode' option is set, the files are bundled into a single
+// temporary archive, named after the 'archive.name' option, which is uploaded instead.
+// With 'skip' in-flight policy, files which are still being uploaded by another upload are skipped.
+func (u *AutoUploadable) UploadFiles(correlationID string, files []string, options map[string]string) error {
+	if u.cfg.InFlightPolicy == InFlightPolicySkip {
+		claimed := u.uploads.claimFiles(correlationID, files)
+		if len(claimed) < len(files) {
+			logger.Infof("skipping %d file(s) of upload %s, which are still being uploaded", len(files)-len(claimed), correlationID)
+		}
+		if len(claimed) == 0 {
+			return nil
+		}
+		files = claimed
+	}
+
+	var archive *fileArchive
+	if mode := options[archiveModeOption]; mode != "" && mode != uploaders.ArchiveNone {
+		if err := checkFreeInodes(os.TempDir(), u.cfg.MinFreeInodes); err != nil {
+			u.uploads.releaseFiles(correlationID)
+			return err
+		}
+
+		var err error
+		if archive, err = newFileArchive(correlationID, files, mode, options[archiveNameOption]); err != nil {
+			u.uploads.releaseFiles(correlationID)
+			return err
+		}
+		files = []string{archive.path}
+	} else if u.cfg.BatchSize > 0 {
+		if err := checkFreeInodes(os.TempDir(), u.cfg.MinFreeInodes); err != nil {
+			logger.Warnf("uploading the files of %s without batching: %v", correlationID, err)
+			u.sendUploadRequests(correlationID, files, nil, options)
+			return nil
+		}
+
+		var err error
+		plain, transformed := u.cfg.Transforms.partition(files) // transformed files are never batched
+		if files, archive, err = batchSmallFiles(correlationID, plain, u.cfg.BatchSize, u.cfg.BatchMinFiles); err != nil {
+			u.uploads.releaseFiles(correlationID)
+			return err
+		}
+		files = append(files, transformed...)
+	}
+
+	u.sendUploadRequests(correlationID, files, archive, options)
+
+	return nil
+}
+
+// triggerDelayer is implemented by upload customizers, which delay triggers, e.g. to merge the triggers within
+// a trigger window
+type triggerDelayer interface {
+	// isDelayed returns true, if the upload of the trigger with the given correlation ID is delayed
+	isDelayed(correlationID string) bool
+}
+
+// isTriggerPending returns true, if the upload of the trigger with the given correlation ID is started, or is
+// to be started - its upload is delayed or its file batches are being uploaded
+func (u *AutoUploadable) isTriggerPending(correlationID string) bool {
+	if u.uploads.Get(correlationID) != nil {
+		return true
+	}
+
+	if delayer, ok := u.customizer.(triggerDelayer); ok && delayer.isDelayed(correlationID) {
+		return true
+	}
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	for _, b := range u.batches {
+		if b.correlationID == correlationID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// triggerFired releases the operations, waiting for the upload of the delayed trigger with the given correlation ID,
+// if no upload is started for it, when it is fired
+func (u *AutoUploadable) triggerFired(correlationID string) {
+	if !u.isTriggerPending(correlationID) {
+		u.completions.release(correlationID)
+	}
+}
+
+// uploadBatches holds the files of a trigger, which are uploaded in sequential batches
+type uploadBatches struct {
+	correlationID string
+	files         [][]string
+	next          int // index of the next batch to upload
+	options       map[string]string
+	last          *UploadStatus // final status of the last uploaded batch
+}
+
+// batchID returns the correlation ID of the batch with the given index
+func (b *uploadBatches) batchID(index int) string {
+	return fmt.Sprintf("%s-batch%d", b.correlationID, index+1)
+}
+
+// UploadBatches starts the upload of the given file batches in sequence - each batch is uploaded like with
+// UploadFiles, with the correlation ID suffixed with the batch number, when the previous batch finishes
+func (u *AutoUploadable) UploadBatches(correlationID string, batches [][]string, options map[string]string) error {
+	logger.Infof("uploading %d batches of files for trigger %s", len(batches), correlationID)
+
+	return u.uploadNextBatch(&uploadBatches{correlationID: correlationID, files: batches, options: options})
+}
+
+// uploadNextBatch uploads the next of the given batches. Batches, from which no files are uploaded, e.g. all files
+// are still being uploaded by other uploads, are skipped. The operations, waiting for the trigger upload, are notified
+// of the final status of the last uploaded batch, when no batches remain.
+func (u *AutoUploadable) uploadNextBatch(b *uploadBatches) error {
+	for b.next < len(b.files) {
+		id, files := b.batchID(b.next), b.files[b.next]
+		b.next++
+
+		u.mutex.Lock()
+		if u.batches == nil { // disconnected
+			u.mutex.Unlock()
+			return nil
+		}
+		u.batches[id] = b // registered in advance, as the batch might finish right away
+		u.mutex.Unlock()
+
+		if err := u.UploadFiles(id, files, b.options); err != nil {
+			u.mutex.Lock()
+			delete(u.batches, id)
+			u.mutex.Unlock()
+
+			return err
+		}
+
+		added := u.uploads.Get(id) != nil
+
+		u.mutex.Lock()
+		pending := !added && u.batches[id] == b // no upload added for the batch
+		if pending {
+			delete(u.batches, id)
+		}
+		u.mutex.Unlock()
+
+		if !pending {
+			return nil // the next batch is uploaded, when this one finishes
+		}
+	}
+
+	if b.last != nil {
+		u.completions.notifyAs(b.correlationID, *b.last)
+	} else {
+		u.completions.release(b.correlationID)
+	}
+
+	return nil
+}
+
+// uploadBatchFinished uploads the next batch, if the upload with the given final status is a batch. The operations,
+// waiting for the trigger upload, are notified of the status, if it is the last batch.
+func (u *AutoUploadable) uploadBatchFinished(status UploadStatus) {
+	u.mutex.Lock()
+	b, ok := u.batches[status.CorrelationID]
+	delete(u.batches, status.CorrelationID)
+	u.mutex.Unlock()
+
+	if ok {
+		b.last = &status
+		go func() {
+			if err := u.uploadNextBatch(b); err != nil {
+				logger.Errorf("failed to upload the next batch of trigger %s: %v", b.correlationID, err)
+			}
+		}()
+	}
+}
+
+// UploadDirectory starts the upload of a consistent snapshot of the given directory, archived with the 'archive.mode'
+// option (tar by default) and named after the 'archive.name' option. Only the files, accepted by the include function,
+// are archived. Returns false if there are no files to upload.
+func (u *AutoUploadable) UploadDirectory(correlationID string, dir string, include func(path string) bool,
+	options map[string]string) (bool, error) {
+	mode := options[archiveModeOption]
+	if mode == "" {
+		mode = uploaders.ArchiveTar
+	}
+
+	if err := checkFreeInodes(os.TempDir(), u.cfg.MinFreeInodes); err != nil {
+		return false, err
+	}
+
+	archive, err := newDirectoryArchive(correlationID, dir, include, mode, options[archiveNameOption])
+	if err != nil || archive == nil {
+		return false, err
+	}
+
+	u.sendUploadRequests(correlationID, []string{archive.path}, archive, options)
+
+	return true, nil
+}
+
+// sendUploadRequests adds a multi-file upload for the given files and sends an upload request for each of them.
+// With the 'dryRun' option, the requests are marked as such and the files are not uploaded, when started.
+// The 'tag.' prefixed options are included in the requests and applied to the uploaded objects.
+func (u *AutoUploadable) sendUploadRequests(correlationID string, files []string, archive *fileArchive,
+	options map[string]string) {
+	dryRun := options[DryRunOption] == "true"
+	tags := uploaders.ObjectTags(options)
+	childIDs := u.uploads.addMulti(correlationID, files, archive, dryRun, tags, u.cfg, u)
 	for i, childID := range childIDs {
 		options := uploaders.ExtractDictionary(options, optionsPrefix)
-		options["storage.providers"] = "aws, azure, generic"
+		options["storage.providers"] = strings.Join(advertisedProviders(u.cfg), ", ")
+		if dryRun {
+			options[DryRunOption] = "true"
+		}
+		for k, v := range tags {
+			options[uploaders.TagPrefix+k] = v
+		}
 		options[filePathOption] = files[i]
+		if archive != nil && files[i] == archive.path {
+			options[filePathOption] = archive.name
+		}
 
 		go u.sendUploadRequest(childID, options, files[i])
 	}
@@ -515,9 +1661,31 @@ func (u *AutoUploadable) startExecutor() {
 		u.executor.Stop()
 	}
 
-	u.executor = NewPeriodicExecutor(u.state.StartTime, u.state.EndTime, time.Duration(u.cfg.Period), func() {
+	task := func() {
 		u.customizer.OnTick()
-	})
+	}
+
+	if u.cfg.Cron != "" {
+		schedule, err := ParseCron(u.cfg.Cron)
+		if err != nil { // validated on startup
+			logger.Errorf("invalid cron expression '%s': %v", u.cfg.Cron, err)
+			u.executor = nil
+			return
+		}
+
+		u.executor = NewCronExecutor(u.state.StartTime, u.state.EndTime, schedule, task)
+	} else {
+		u.executor = NewJitteredPeriodicExecutor(u.state.StartTime, u.state.EndTime, time.Duration(u.cfg.Period),
+			time.Duration(u.cfg.PeriodJitter), task)
+	}
+
+	u.stopEndTimer()
+	if u.cfg.ActiveEndPolicy == ActiveEndPolicyGraceful && u.state.EndTime != nil && u.state.EndTime.After(time.Now()) {
+		u.endTimer = time.AfterFunc(time.Until(*u.state.EndTime), func() {
+			logger.Infof("active time frame ended, waiting up to %v for the running file uploads", time.Duration(u.cfg.ActiveEndGracePeriod))
+			u.uploads.endActive(time.Duration(u.cfg.ActiveEndGracePeriod))
+		})
+	}
 }
 
 func (u *AutoUploadable) stopExecutor() {
@@ -528,6 +1696,16 @@ func (u *AutoUploadable) stopExecutor() {
 		u.executor.Stop()
 		u.executor = nil
 	}
+
+	u.stopEndTimer()
+}
+
+// stopEndTimer stops the active time frame end timer, if any. Should be called with the mutex locked.
+func (u *AutoUploadable) stopEndTimer() {
+	if u.endTimer != nil {
+		u.endTimer.Stop()
+		u.endTimer = nil
+	}
 }
 
 func (u *AutoUploadable) nextUID() string {
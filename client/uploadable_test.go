@@ -0,0 +1,563 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eclipse-kanto/file-upload/uploaders"
+	"github.com/eclipse/ditto-clients-golang/protocol"
+)
+
+const (
+	startOperation  = "start"
+	resumeOperation = "resume"
+)
+
+func TestErrorReply(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	sendOperation(f, startOperation, map[string]interface{}{"correlationId": "unknown"}, "requestCorrelationID")
+
+	reply := client.liveMsg(t, startOperation)
+	assertEquals(t, float64(http.StatusNotFound), reply["status"])
+	assertEquals(t, string(ErrorCodeParameterInvalid), reply["error"])
+	assertEquals(t, "upload with correlation ID 'unknown' not found", reply["message"])
+	assertEquals(t, 3, len(reply))
+}
+
+func TestStructuredErrorReply(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	testCfg.StructuredErrors = true
+
+	// the correlation ID from the operation payload takes precedence
+	sendOperation(f, startOperation, map[string]interface{}{"correlationId": "unknown"}, "requestCorrelationID")
+
+	reply := client.liveMsg(t, startOperation)
+	assertEquals(t, string(ErrorCodeParameterInvalid), reply["code"])
+	assertEquals(t, ErrorCategoryClient, reply["category"])
+	assertEquals(t, "upload with correlation ID 'unknown' not found", reply["message"])
+	assertEquals(t, float64(http.StatusNotFound), reply["status"])
+	assertEquals(t, "unknown", reply["correlationId"])
+	assertEquals(t, 5, len(reply))
+
+	// otherwise, the correlation ID of the request message is used
+	sendOperation(f, "trigger", map[string]interface{}{"options": "invalid"}, "requestCorrelationID")
+
+	reply = client.liveMsg(t, "trigger")
+	assertEquals(t, string(ErrorCodeParameterInvalid), reply["code"])
+	assertEquals(t, ErrorCategoryClient, reply["category"])
+	assertEquals(t, float64(http.StatusBadRequest), reply["status"])
+	assertEquals(t, "requestCorrelationID", reply["correlationId"])
+}
+
+func TestListReply(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a := addTestFile(t, "a.txt")
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	sendOperation(f, "list", map[string]interface{}{}, "requestCorrelationID")
+
+	select {
+	case env := <-client.live:
+		assertEquals(t, http.StatusOK, env.Status)
+		assertEquals(t, "requestCorrelationID", env.Headers.CorrelationID())
+		assertEquals(t, []interface{}{map[string]interface{}{"path": a, "size": float64(len(a))}}, env.Value)
+	case <-time.After(5 * time.Second):
+		t.Fatal("list reply not received")
+	}
+}
+
+func TestNewStructuredErrorResponse(t *testing.T) {
+	headers := protocol.NewHeaders(protocol.WithCorrelationID("requestCorrelationID"))
+
+	e := newStructuredErrorResponse(&ErrorResponse{http.StatusInternalServerError, ErrorCodeExecutionFailed, "failed"},
+		[]byte(`{"correlationId":"testCorrelationID"}`), headers)
+	assertEquals(t, &StructuredErrorResponse{ErrorCodeExecutionFailed, ErrorCategoryServer, "failed",
+		http.StatusInternalServerError, "testCorrelationID"}, e)
+
+	e = newStructuredErrorResponse(&ErrorResponse{http.StatusBadRequest, ErrorCodeParameterInvalid, "invalid"},
+		[]byte(`not a JSON`), headers)
+	assertEquals(t, &StructuredErrorResponse{ErrorCodeParameterInvalid, ErrorCategoryClient, "invalid",
+		http.StatusBadRequest, "requestCorrelationID"}, e)
+}
+
+func TestConnectionProperty(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	f.ConnectionChanged(false)
+	status := client.twinMsg(t, modify)
+	assertEquals(t, false, status["connected"])
+	lost := status["since"]
+
+	f.ConnectionChanged(true)
+	status = client.twinMsg(t, modify)
+	assertEquals(t, true, status["connected"])
+	if status["since"] == lost {
+		t.Errorf("connection restore time not updated: %v", status["since"])
+	}
+}
+
+func TestResumeContinue(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	f, client, received, id, content := startInterruptedUpload(t)
+	defer f.Disconnect()
+
+	sendOperation(f, resumeOperation, map[string]interface{}{
+		"correlationId": id, "instruction": ResumeContinue, "offset": 10}, "requestCorrelationID")
+	assertResumeReply(t, client, http.StatusNoContent)
+	waitUploadState(t, client, StateSuccess)
+
+	requests := received.get()
+	assertEquals(t, 2, len(requests))
+	assertEquals(t, fmt.Sprintf("bytes 10-%d/%d", len(content)-1, len(content)), requests[1].headers.Get("Content-Range"))
+	assertEquals(t, content[10:], string(requests[1].body))
+}
+
+func TestResumeRestart(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	f, client, received, id, content := startInterruptedUpload(t)
+	defer f.Disconnect()
+
+	sendOperation(f, resumeOperation, map[string]interface{}{
+		"correlationId": id, "instruction": ResumeRestart, "offset": 10}, "requestCorrelationID")
+	assertResumeReply(t, client, http.StatusNoContent)
+	waitUploadState(t, client, StateSuccess)
+
+	requests := received.get()
+	assertEquals(t, 2, len(requests))
+	assertEquals(t, "", requests[1].headers.Get("Content-Range"))
+	assertEquals(t, content, string(requests[1].body))
+}
+
+func TestResumeAbort(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	f, client, received, id, _ := startInterruptedUpload(t)
+	defer f.Disconnect()
+
+	sendOperation(f, resumeOperation, map[string]interface{}{
+		"correlationId": id, "instruction": ResumeAbort}, "requestCorrelationID")
+	assertResumeReply(t, client, http.StatusNoContent)
+	waitUploadState(t, client, StateFailed)
+	assertEquals(t, 1, len(received.get()))
+
+	sendOperation(f, resumeOperation, map[string]interface{}{
+		"correlationId": id, "instruction": ResumeRestart}, "requestCorrelationID")
+	assertResumeReply(t, client, http.StatusNotFound)
+}
+
+func TestResumeInvalid(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	f, client, _, id, _ := startInterruptedUpload(t)
+	defer f.Disconnect()
+
+	sendOperation(f, resumeOperation, map[string]interface{}{
+		"correlationId": id, "instruction": "skip"}, "requestCorrelationID")
+	assertResumeReply(t, client, http.StatusBadRequest)
+
+	sendOperation(f, resumeOperation, map[string]interface{}{
+		"correlationId": "unknown", "instruction": ResumeContinue}, "requestCorrelationID")
+	assertResumeReply(t, client, http.StatusNotFound)
+
+	sendOperation(f, resumeOperation, map[string]interface{}{
+		"correlationId": id, "instruction": ResumeContinue, "offset": 1 << 20}, "requestCorrelationID")
+	assertResumeReply(t, client, http.StatusNoContent) // the upload fails with invalid offset and is interrupted again
+
+	resume := client.liveMsg(t, resumeOperation)
+	assertEquals(t, id, resume["correlationId"])
+}
+
+func TestResumeTimeout(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	f, client, received, _, _ := startInterruptedUpload(t)
+	defer f.Disconnect()
+
+	waitUploadState(t, client, StateFailed)
+	assertEquals(t, 1, len(received.get()))
+}
+
+// startInterruptedUpload triggers an upload of a single file, starts it with a server, which fails the first
+// request, and waits for the resume request. Returns the received requests, the file upload correlation ID and
+// the file content.
+func startInterruptedUpload(t *testing.T) (*FileUpload, *mockedClient, *receivedRequests, string, string) {
+	t.Helper()
+
+	content := addTestFile(t, "a.txt")
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+
+	testCfg.ResumeUploads = true
+	testCfg.ResumeTimeout = Duration(time.Second)
+
+	received := &receivedRequests{}
+	failed := uint32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		received.mutex.Lock()
+		received.requests = append(received.requests, receivedRequest{r.Header, body})
+		received.mutex.Unlock()
+
+		if atomic.CompareAndSwapUint32(&failed, 0, 1) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	assertNoError(t, f.DoTrigger("testCorrelationID", nil))
+
+	id := client.liveMsg(t, request)["correlationId"].(string)
+	assertNoError(t, f.uploadable.uploads.Get(id).start(map[string]string{uploaders.URLProp: server.URL}))
+
+	resume := client.liveMsg(t, resumeOperation)
+	assertEquals(t, id, resume["correlationId"])
+	assertEquals(t, float64(0), resume["offset"])
+	assertEquals(t, float64(len(content)), resume["size"])
+	assertEquals(t, "upload failed - code: 500, status: 500 Internal Server Error", resume["message"])
+
+	return f, client, received, id, content
+}
+
+func assertResumeReply(t *testing.T, client *mockedClient, status int) {
+	t.Helper()
+
+	select {
+	case env := <-client.live:
+		assertEquals(t, resumeOperation, string(env.Topic.Action))
+		assertEquals(t, status, env.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("resume reply not received")
+	}
+}
+
+func TestStartDryRun(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a := addTestFile(t, "a.txt")
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	result, responseError := f.uploadable.trigger([]byte(`{"correlationId":"dryRunTrigger"}`))
+	assertEquals(t, nil, result)
+	assertEquals(t, (*ErrorResponse)(nil), responseError)
+
+	msg := client.liveMsg(t, request)
+	assertEquals(t, a, getFileFromMsg(t, msg))
+	if _, ok := msg["options"].(map[string]interface{})[DryRunOption]; ok {
+		t.Error("upload request should not be marked as dry run")
+	}
+
+	payload := fmt.Sprintf(`{"correlationId":"%s","dryRun":true,"options":{"%s":"%s"}}`,
+		msg["correlationId"], uploaders.URLProp, server.URL)
+	assertEquals(t, (*ErrorResponse)(nil), f.uploadable.start([]byte(payload)))
+
+	status := waitFinalStatus(t, client)
+	assertEquals(t, StateSuccess, status["state"])
+	assertEquals(t, float64(100), status["progress"])
+	assertEquals(t, 0, len(received.get()))
+}
+
+func TestActiveEndGraceful(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	addTestFile(t, "a.txt")
+	addTestFile(t, "b.txt")
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	slow := startTestServer(t, 300*time.Millisecond, false)
+	defer slow.Close()
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	testCfg.Cron = "0 0 1 1 *" // no ticks within the active time frame
+	testCfg.ActiveEndPolicy = ActiveEndPolicyGraceful
+	testCfg.ActiveEndGracePeriod = Duration(time.Minute)
+
+	assertNoError(t, f.DoTrigger("testCorrelationID", nil))
+
+	started := client.liveMsg(t, request)["correlationId"].(string)
+	queued := f.uploadable.uploads.Get(client.liveMsg(t, request)["correlationId"].(string))
+
+	assertNoError(t, f.uploadable.uploads.Get(started).start(map[string]string{uploaders.URLProp: slow.URL}))
+
+	from := time.Now()
+	to := from.Add(100 * time.Millisecond)
+	payload, _ := json.Marshal(map[string]interface{}{"from": from, "to": to})
+	assertEquals(t, (*ErrorResponse)(nil), f.uploadable.activate(payload))
+
+	status := waitFinalStatus(t, client)
+	assertEquals(t, StateSuccess, status["state"])
+	assertEquals(t, "active time frame ended - 1 queued file(s) not uploaded", status["message"])
+
+	assertError(t, queued.start(map[string]string{uploaders.URLProp: server.URL}))
+	assertEquals(t, 0, len(received.get()))
+}
+
+func TestCancelAll(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	addTestFile(t, "a.txt")
+
+	slow := startTestServer(t, time.Second, false)
+	defer slow.Close()
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	assertCancelAllReply := func() {
+		t.Helper()
+
+		select {
+		case env := <-client.live:
+			assertEquals(t, http.StatusNoContent, env.Status)
+			assertEquals(t, "requestCorrelationID", env.Headers.CorrelationID())
+		case <-time.After(5 * time.Second):
+			t.Fatal("cancelAll reply not received")
+		}
+	}
+
+	// nothing to cancel
+	sendOperation(f, "cancelAll", map[string]interface{}{}, "requestCorrelationID")
+	assertCancelAllReply()
+
+	for _, id := range []string{"first", "second", "third"} {
+		assertNoError(t, f.DoTrigger(id, nil))
+	}
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		ids = append(ids, client.liveMsg(t, request)["correlationId"].(string))
+	}
+
+	// the first upload is running, the others are not yet started
+	assertNoError(t, f.uploadable.uploads.Get(ids[0]).start(map[string]string{uploaders.URLProp: slow.URL}))
+	assertEquals(t, StateUploading, client.twinMsg(t, modify)["state"])
+
+	sendOperation(f, "cancelAll", map[string]interface{}{"statusCode": "incident", "message": "all uploads canceled"},
+		"requestCorrelationID")
+	assertCancelAllReply()
+
+	canceled := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		status := waitFinalStatus(t, client)
+		assertEquals(t, StateCanceled, status["state"])
+		assertEquals(t, "incident", status["statusCode"])
+		assertEquals(t, "all uploads canceled", status["message"])
+		canceled[status["correlationId"].(string)] = true
+	}
+	assertEquals(t, map[string]bool{"first": true, "second": true, "third": true}, canceled)
+
+	for _, id := range ids {
+		assertEquals(t, nil, f.uploadable.uploads.Get(id))
+	}
+}
+
+func TestTriggerWaitForCompletion(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	addTestFile(t, "a.txt")
+
+	ok := startTestServer(t, 0, false)
+	defer ok.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+
+	trigger := func(correlationID string) {
+		triggerWaitingForCompletion(f, correlationID)
+	}
+	assertTriggerReply := func(status int) map[string]interface{} {
+		t.Helper()
+
+		return assertTriggerReply(t, client, status)
+	}
+
+	f.uploadable.cfg.WaitForCompletionTimeout = Duration(5 * time.Second)
+
+	for _, test := range []struct {
+		url   string
+		state string
+	}{{ok.URL, StateSuccess}, {failing.URL, StateFailed}} {
+		trigger("wait-" + test.state)
+
+		id := client.liveMsg(t, request)["correlationId"].(string)
+		assertNoError(t, f.uploadable.uploads.Get(id).start(map[string]string{uploaders.URLProp: test.url}))
+
+		status := assertTriggerReply(http.StatusOK)
+		assertEquals(t, "wait-"+test.state, status["correlationId"])
+		assertEquals(t, test.state, status["state"])
+		waitFinalStatus(t, client)
+	}
+
+	// not started by the backend in time
+	f.uploadable.cfg.WaitForCompletionTimeout = Duration(200 * time.Millisecond)
+	trigger("wait-timeout")
+	client.liveMsg(t, request)
+	assertEquals(t, map[string]interface{}{"correlationId": "wait-timeout"}, assertTriggerReply(http.StatusAccepted))
+
+	// nothing to upload
+	assertNoError(t, os.Remove(filepath.Join(basedir, "a.txt")))
+	trigger("wait-none")
+	assertEquals(t, map[string]interface{}(nil), assertTriggerReply(http.StatusNoContent))
+}
+
+func TestTriggerWaitForCompletionDelayedAndBatched(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	addTestFile(t, "a.txt")
+	addTestFile(t, "b.txt")
+
+	ok := startTestServer(t, 0, false)
+	defer ok.Close()
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.txt"), ModeStrict)
+	defer f.Disconnect()
+	f.uploadable.cfg.WaitForCompletionTimeout = Duration(5 * time.Second)
+
+	startRequested := func(expected string) {
+		t.Helper()
+
+		msg := client.liveMsg(t, request)
+		assertCorrelationID(t, expected, msg)
+		id := msg["correlationId"].(string)
+		assertNoError(t, f.uploadable.uploads.Get(id).start(map[string]string{uploaders.URLProp: ok.URL}))
+	}
+
+	// the merged trigger waits for the upload of the trigger, into which it is merged
+	f.uploadable.cfg.TriggerWindow = Duration(testTriggerWindow)
+	triggerWaitingForCompletion(f, "wait-delayed")
+	time.Sleep(testTriggerWindow / 4)
+	triggerWaitingForCompletion(f, "wait-merged")
+
+	startRequested("wait-delayed")
+	startRequested("wait-delayed")
+	for i := 0; i < 2; i++ {
+		status := assertTriggerReply(t, client, http.StatusOK)
+		assertEquals(t, "wait-delayed", status["correlationId"])
+		assertEquals(t, StateSuccess, status["state"])
+	}
+	waitFinalStatus(t, client)
+
+	// the trigger waits for the last of its batches
+	f.uploadable.cfg.TriggerWindow = 0
+	f.uploadable.cfg.MaxFilesPerUpload = 1
+	triggerWaitingForCompletion(f, "wait-batches")
+
+	startRequested("wait-batches-batch1")
+	waitFinalStatus(t, client)
+	startRequested("wait-batches-batch2") // no reply before the last batch
+	status := assertTriggerReply(t, client, http.StatusOK)
+	assertEquals(t, "wait-batches-batch2", status["correlationId"])
+	assertEquals(t, StateSuccess, status["state"])
+	waitFinalStatus(t, client)
+
+	// nothing to upload, when the delayed trigger fires
+	f.uploadable.cfg.TriggerWindow = Duration(testTriggerWindow)
+	assertNoError(t, os.Remove(filepath.Join(basedir, "a.txt")))
+	assertNoError(t, os.Remove(filepath.Join(basedir, "b.txt")))
+	triggerWaitingForCompletion(f, "wait-none")
+	assertEquals(t, map[string]interface{}(nil), assertTriggerReply(t, client, http.StatusNoContent))
+}
+
+// triggerWaitingForCompletion sends a 'trigger' operation, waiting for the upload completion, with the given
+// correlation ID, without waiting for the reply
+func triggerWaitingForCompletion(f *FileUpload, correlationID string) {
+	go sendOperation(f, "trigger", map[string]interface{}{"correlationId": correlationID, "waitForCompletion": true},
+		"requestCorrelationID")
+}
+
+// assertTriggerReply asserts that the reply of a 'trigger' operation has the given status and returns its value
+func assertTriggerReply(t *testing.T, client *mockedClient, status int) map[string]interface{} {
+	t.Helper()
+
+	select {
+	case env := <-client.live:
+		assertEquals(t, status, env.Status)
+		assertEquals(t, "requestCorrelationID", env.Headers.CorrelationID())
+		value, _ := env.Value.(map[string]interface{})
+		return value
+	case <-time.After(5 * time.Second):
+		t.Fatal("trigger reply not received")
+	}
+	return nil
+}
+
+func sendOperation(f *FileUpload, operation string, value map[string]interface{}, correlationID string) {
+	topic := (&protocol.Topic{}).WithNamespace(namespace).WithEntityName(deviceID).
+		WithGroup(protocol.GroupThings).WithChannel(protocol.ChannelLive).
+		WithCriterion(protocol.CriterionMessages).WithAction(protocol.TopicAction(operation))
+
+	msg := &protocol.Envelope{
+		Topic:   topic,
+		Headers: protocol.NewHeaders(protocol.WithCorrelationID(correlationID), protocol.WithResponseRequired(true)),
+		Path:    "/features/" + featureID + "/inbox/messages/" + operation,
+		Value:   value,
+	}
+
+	f.uploadable.messageHandler("testRequestID", msg)
+}
@@ -13,9 +13,15 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -42,9 +48,25 @@ const InfoPrefix = "info."
 // StorageProvider hold the name of the storage provider 'start' operation option
 const StorageProvider = "storage.provider"
 
+// ObjectKey holds the name of the 'start' operation option, which specifies the exact key of the uploaded object
+const ObjectKey = "object.key"
+
+// DryRunOption holds the name of the 'trigger' and 'start' operations option, which if set to 'true' selects and
+// requests the files for upload, without uploading them
+const DryRunOption = "dryRun"
+
+// dryRunMessage is the message of the successful dry run upload status
+const dryRunMessage = "dry run - no files were uploaded"
+
 // fineGrainedUploadProgressNotSupported indicates, that at least file size cannot be determined and upload progress will be based on file count only
 const fineGrainedUploadProgressNotSupported = -1
 
+// transferSpeedWindow is the period, over which the transfer speed moving average is computed
+const transferSpeedWindow = 5 * time.Second
+
+// stopPollInterval is the period, in which the pending uploads are checked, when waiting for them to complete on stop
+const stopPollInterval = 2 * time.Second
+
 // Upload represents single or multi-file upload
 type Upload interface {
 	start(options map[string]string) error
@@ -58,10 +80,12 @@ type MultiUpload struct {
 	children   map[string]*SingleUpload
 	totalCount int
 
-	deleteUploaded bool
-	useChecksum    bool
-
-	serverCert string
+	cfg         *UploadableConfig
+	credentials *credentialsStore
+	health      *healthCache
+	circuits    *circuitBreaker
+	deletes     *deleteThrottle
+	retries     *retryState
 
 	uploads *Uploads
 
@@ -72,6 +96,26 @@ type MultiUpload struct {
 
 	totalBytesTransferred int64
 	totalSizeBytes        int64 // -1(fineGrainedUploadProgressNotSupported) if there is an error, retrieving at least one file size(file count progress report will be used in such case)
+
+	transferSamples []transferSample // used for computing the transfer speed as a moving average
+
+	archive *fileArchive // temporary archive of (some of) the uploaded files, if such is uploaded
+
+	fileStatuses []*FileStatus // statuses of the individual files, ordered as added - nil if detailed status is disabled
+
+	dryRun bool // the files are only selected and requested, but not uploaded
+
+	tags map[string]string // object tags, specified with the trigger
+
+	objectKeys  map[string]string // object keys, resolved from the object key template, by file path
+	claimedKeys map[string]bool   // object keys, resolved from the object key template for any of the files
+	keysTime    time.Time         // time of the object keys, resolved from the object key template
+}
+
+// transferSample is the total number of transferred bytes at a given moment
+type transferSample struct {
+	time  time.Time
+	bytes int64
 }
 
 // SingleUpload represents a single file upload
@@ -80,12 +124,24 @@ type SingleUpload struct {
 	filePath      string
 	parent        *MultiUpload
 
-	started uint32
-	file    *os.File
-	mutex   sync.RWMutex
+	started      uint32
+	file         *os.File
+	cancelUpload context.CancelFunc // aborts uploaders, waiting for the storage response
+	mutex        sync.RWMutex
 
 	bytesTransferred int64 //always 0 if uploader does not call back listener for number of uploaded bytes
 	totalSizeBytes   int64
+	sentBytes        int64 // bytes of the uploaded content, reported by the uploader, accessed atomically
+
+	options     map[string]string // 'start' operation options, reused when the upload is resumed
+	provider    string            // storage provider, to which the file was uploaded
+	interrupted uint32            // 1 while the failed upload waits for the backend to resume it
+	stale       uint32            // 1 if the file was modified or replaced during upload
+	uploadErr   error             // the failure, which interrupted the upload
+	resumeTimer *time.Timer       // fails the interrupted upload, if not resumed in time
+	attempted   time.Time         // first upload attempt, from which the maximum retry duration is measured
+
+	fileStatus *FileStatus // guarded by the parent mutex, nil if detailed status is disabled
 }
 
 // Uploads maps correlation IDs to Upload instances
@@ -93,6 +149,19 @@ type Uploads struct {
 	mutex sync.RWMutex
 
 	uploads map[string]Upload
+
+	credentials *credentialsStore
+	health      *healthCache
+	circuits    *circuitBreaker
+	deletes     *deleteThrottle
+	retries     *retryState
+
+	bandwidth   *bandwidthMeter
+	transferred int64 // total number of uploaded bytes, accessed atomically
+
+	inFlight map[string]string // paths of the claimed files, mapped to the correlation IDs of the uploads claiming them
+
+	deviceID string // resolves the device ID placeholder of object key templates
 }
 
 // UploadStatus is used for serializing the 'status' property of the AutoUploadable feature
@@ -107,11 +176,33 @@ type UploadStatus struct {
 
 	Progress int `json:"progress"`
 
+	BytesPerSecond int64 `json:"bytesPerSecond,omitempty"`
+	ETASeconds     int   `json:"etaSeconds,omitempty"`
+
 	Info map[string]string `json:"info"`
+
+	Provider string `json:"provider,omitempty"` // reported if fallback providers are configured, comma-separated if several were used
+
+	Files []FileStatus `json:"files,omitempty"`
+
+	Stale []string `json:"stale,omitempty"` // uploaded files, which were modified or replaced during upload, if detection is enabled
+
+	Logs []string `json:"logs,omitempty"` // last log lines of a failed upload, if enabled
+}
+
+// FileStatus is the status of a single file of a multi-file upload, reported if the detailed status is enabled
+type FileStatus struct {
+	Path     string `json:"path"`
+	State    string `json:"state"`
+	Progress int    `json:"progress"`
 }
 
 func (s *UploadStatus) finished() bool {
-	return s.State == StateSuccess || s.State == StateCanceled || s.State == StateFailed
+	return isFinalState(s.State)
+}
+
+func isFinalState(state string) bool {
+	return state == StateSuccess || state == StateCanceled || state == StateFailed
 }
 
 // UploadStatusListener is notified on changes in uploads status
@@ -119,6 +210,19 @@ type UploadStatusListener interface {
 	uploadStatusUpdated(status *UploadStatus) // should not block
 }
 
+// uploadResumeRequester is implemented by upload status listeners, which can request the backend to resume
+// interrupted uploads
+type uploadResumeRequester interface {
+	uploadResumeRequested(correlationID string, offset int64, size int64, err error) // should not block
+}
+
+// Instructions for resuming interrupted uploads
+const (
+	ResumeContinue = "continue"
+	ResumeRestart  = "restart"
+	ResumeAbort    = "abort"
+)
+
 //******* Uploads methods *******//
 
 // NewUploads constructs new Uploads instance
@@ -126,25 +230,91 @@ func NewUploads() *Uploads {
 	r := &Uploads{}
 
 	r.uploads = make(map[string]Upload)
+	r.inFlight = make(map[string]string)
+	r.bandwidth = newBandwidthMeter(bandwidthWindow)
 
 	return r
 }
 
+// setDeviceID sets the ID of the device, from which the files are uploaded
+func (us *Uploads) setDeviceID(deviceID string) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	us.deviceID = deviceID
+}
+
+func (us *Uploads) getDeviceID() string {
+	us.mutex.RLock()
+	defer us.mutex.RUnlock()
+
+	return us.deviceID
+}
+
+// Bandwidth returns the measured upload bandwidth, as a rolling average of the recent transfers
+func (us *Uploads) Bandwidth() int64 {
+	return us.bandwidth.bandwidth()
+}
+
+// BytesTransferred returns the total number of bytes, uploaded by all uploads
+func (us *Uploads) BytesTransferred() int64 {
+	return atomic.LoadInt64(&us.transferred)
+}
+
+// addTransferred adds the given number of uploaded bytes to the total
+func (us *Uploads) addTransferred(bytes int64) {
+	if us != nil && bytes > 0 {
+		atomic.AddInt64(&us.transferred, bytes)
+	}
+}
+
 // AddMulti is used to add an upload, containing multiple files. The provided listener will be notified on the upload progress.
 // If deleteUploaded is true, files will be deleted after successful upload.
 func (us *Uploads) AddMulti(correlationID string, paths []string, deleteUploaded bool, useChecksum bool,
 	serverCert string, listener UploadStatusListener) []string {
+	return us.AddMultiWithConfig(correlationID, paths,
+		&UploadableConfig{Delete: deleteUploaded, Checksum: useChecksum, ServerCert: serverCert}, listener)
+}
 
+// AddMultiWithConfig is used to add an upload, containing multiple files. The provided listener will be notified on the upload progress.
+// The given configuration specifies how the files are uploaded, e.g. if cfg.Delete is true, files will be deleted after successful upload.
+func (us *Uploads) AddMultiWithConfig(correlationID string, paths []string, cfg *UploadableConfig,
+	listener UploadStatusListener) []string {
+	return us.addMulti(correlationID, paths, nil, false, nil, cfg, listener)
+}
+
+// addMulti adds a multi-file upload, which removes the given temporary archive (if not nil) when finished.
+// The files of a dry run upload are never uploaded - the upload succeeds, once all of them are started.
+// The given trigger tags are applied to the uploaded objects, unless overridden by the 'start' operation options.
+func (us *Uploads) addMulti(correlationID string, paths []string, archive *fileArchive, dryRun bool,
+	tags map[string]string, cfg *UploadableConfig, listener UploadStatusListener) []string {
 	m := &MultiUpload{}
 	m.correlationID = correlationID
+	m.archive = archive
+	m.dryRun = dryRun
+	m.tags = tags
 	m.listener = listener
-	m.deleteUploaded = deleteUploaded
-	m.useChecksum = useChecksum
-	m.serverCert = serverCert
+	m.cfg = cfg
+	m.credentials = us.getCredentialsStore(cfg)
+	m.health = us.getHealthCache(cfg)
+	m.circuits = us.getCircuitBreaker(cfg)
+	m.deletes = us.getDeleteThrottle(cfg)
+	m.retries = us.getRetryState(cfg)
 	m.totalCount = len(paths)
 	m.children = make(map[string]*SingleUpload)
 	m.uploads = us
 
+	if cfg.Compress || cfg.Encrypt {
+		m.totalSizeBytes = fineGrainedUploadProgressNotSupported // size of the uploaded content is not known in advance
+	}
+
+	for _, path := range paths {
+		if cfg.ConvertEncoding.Match(path) || (cfg.Decompress && uploaders.IsGzipFile(path)) || cfg.Transforms.command(path) != "" {
+			m.totalSizeBytes = fineGrainedUploadProgressNotSupported // converted, decompressed and transformed files size differs
+			break
+		}
+	}
+
 	r := make([]string, len(paths))
 	for i, path := range paths {
 		id := fmt.Sprintf("%s#%d", correlationID, i+1)
@@ -175,6 +345,88 @@ func (us *Uploads) AddMulti(correlationID string, paths []string, deleteUploaded
 	return r
 }
 
+// getCredentialsStore returns the store for the credentials file from the given configuration or nil, if such is not configured
+func (us *Uploads) getCredentialsStore(cfg *UploadableConfig) *credentialsStore {
+	if cfg.CredentialsFile == "" {
+		return nil
+	}
+
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	margin := time.Duration(cfg.CredentialsRefreshMargin)
+	if us.credentials == nil || us.credentials.file != cfg.CredentialsFile || us.credentials.margin != margin {
+		us.credentials = newCredentialsStore(cfg.CredentialsFile, time.Duration(cfg.CredentialsRefresh), margin)
+	}
+
+	return us.credentials
+}
+
+// getHealthCache returns the storage endpoints health cache or nil, if endpoints health caching is not configured
+func (us *Uploads) getHealthCache(cfg *UploadableConfig) *healthCache {
+	if cfg.EndpointHealthTTL <= 0 {
+		return nil
+	}
+
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	if us.health == nil || us.health.ttl != time.Duration(cfg.EndpointHealthTTL) {
+		us.health = newHealthCache(time.Duration(cfg.EndpointHealthTTL))
+	}
+
+	return us.health
+}
+
+// getCircuitBreaker returns the storage endpoints circuit breaker or nil, if circuit breaking is not configured
+func (us *Uploads) getCircuitBreaker(cfg *UploadableConfig) *circuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		return nil
+	}
+
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	cooldown := time.Duration(cfg.CircuitCooldown)
+	if us.circuits == nil || us.circuits.threshold != cfg.FailureThreshold || us.circuits.cooldown != cooldown {
+		us.circuits = newCircuitBreaker(cfg.FailureThreshold, cooldown)
+	}
+
+	return us.circuits
+}
+
+// getDeleteThrottle returns the throttle of the uploaded files deletion or nil, if the deletion rate is not limited
+func (us *Uploads) getDeleteThrottle(cfg *UploadableConfig) *deleteThrottle {
+	if cfg.DeleteRate <= 0 {
+		return nil
+	}
+
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	if us.deletes == nil || us.deletes.rate != cfg.DeleteRate {
+		us.deletes = newDeleteThrottle(cfg.DeleteRate)
+	}
+
+	return us.deletes
+}
+
+// getRetryState returns the persisted pending re-attempts or nil, if the re-attempts are not persisted
+func (us *Uploads) getRetryState(cfg *UploadableConfig) *retryState {
+	if cfg.RetryStateFile == "" || cfg.RetryAfterAttempts <= 0 {
+		return nil
+	}
+
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	if us.retries == nil || us.retries.file != cfg.RetryStateFile {
+		us.retries = newRetryState(cfg.RetryStateFile)
+	}
+
+	return us.retries
+}
+
 // AddSingle adds single file upload to a MultiUpload
 func (us *Uploads) AddSingle(parent *MultiUpload, correlationID string, filePath string) {
 	u := &SingleUpload{}
@@ -222,21 +474,69 @@ func (us *Uploads) Remove(correlationID string) {
 		for _, childID := range childrenIDs {
 			delete(us.uploads, childID)
 		}
+
+		us.releaseClaimed(correlationID)
 	}
 }
 
-// Stop waits for pending uploads to complete in the given timeout. Uploads which are still
-// pending after the timeout are canceled.
-func (us *Uploads) Stop(timeout time.Duration) {
-	logger.Info("waiting for pending uploads...")
-	end := time.Now().Add(timeout)
+// claimFiles claims the given files for the upload with the given correlation ID, until the upload is removed.
+// Returns the claimed files - files, which are already claimed by another upload, are excluded.
+func (us *Uploads) claimFiles(correlationID string, files []string) []string {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	claimed := make([]string, 0, len(files))
+	for _, file := range files {
+		if owner, ok := us.inFlight[file]; ok && owner != correlationID {
+			logger.Debugf("file '%s' is still being uploaded by upload %s", file, owner)
+			continue
+		}
+
+		us.inFlight[file] = correlationID
+		claimed = append(claimed, file)
+	}
+
+	return claimed
+}
+
+// releaseFiles releases the files, claimed by the upload with the given correlation ID
+func (us *Uploads) releaseFiles(correlationID string) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	us.releaseClaimed(correlationID)
+}
 
-	pending := true
-	for pending && time.Now().Before(end) {
-		pending = us.hasPendingUploads()
+// releaseClaimed releases the files, claimed by the upload with the given correlation ID.
+// Should be called with the mutex locked.
+func (us *Uploads) releaseClaimed(correlationID string) {
+	for file, owner := range us.inFlight {
+		if owner == correlationID {
+			delete(us.inFlight, file)
+		}
+	}
+}
 
-		if pending {
-			time.Sleep(2 * time.Second)
+// Stop waits for pending uploads to complete in the given timeout. Uploads which are still
+// pending after the timeout, or when the given cancel channel (if not nil) is closed, are canceled.
+func (us *Uploads) Stop(timeout time.Duration, cancel <-chan struct{}) {
+	logger.Info("waiting for pending uploads...")
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	ticker := time.NewTicker(stopPollInterval)
+	defer ticker.Stop()
+
+	pending := us.hasPendingUploads()
+	for waiting := true; pending && waiting; {
+		select {
+		case <-ticker.C:
+			pending = us.hasPendingUploads()
+		case <-deadline.C:
+			waiting = false
+		case <-cancel:
+			logger.Info("waiting for pending uploads canceled")
+			waiting = false
 		}
 	}
 
@@ -254,6 +554,41 @@ func (us *Uploads) Stop(timeout time.Duration) {
 	}
 }
 
+// endActive ends the running uploads gracefully - their files, which are not yet started, are not uploaded and the
+// files, which are being uploaded, are canceled, if not finished within the given grace period
+func (us *Uploads) endActive(grace time.Duration) {
+	us.mutex.RLock()
+	var running []*MultiUpload
+	for _, u := range us.uploads {
+		if mu, ok := u.(*MultiUpload); ok {
+			running = append(running, mu)
+		}
+	}
+	us.mutex.RUnlock()
+
+	for _, mu := range running {
+		mu.endActive(grace)
+	}
+}
+
+// cancelAll cancels all running uploads with the given status code and message and returns their number
+func (us *Uploads) cancelAll(code string, message string) int {
+	us.mutex.RLock()
+	var running []*MultiUpload
+	for _, u := range us.uploads {
+		if mu, ok := u.(*MultiUpload); ok {
+			running = append(running, mu)
+		}
+	}
+	us.mutex.RUnlock()
+
+	for _, mu := range running {
+		mu.cancel(code, message)
+	}
+
+	return len(running)
+}
+
 func (us *Uploads) hasPendingUploads() bool {
 	us.mutex.RLock()
 	defer us.mutex.RUnlock()
@@ -261,7 +596,7 @@ func (us *Uploads) hasPendingUploads() bool {
 	for _, u := range us.uploads {
 		mu, ok := u.(*MultiUpload)
 
-		if ok && mu.status != nil && mu.status.State == StateUploading {
+		if ok && mu.isUploading() {
 			return true
 		}
 	}
@@ -278,6 +613,74 @@ func (u *MultiUpload) addChild(su *SingleUpload) {
 	defer u.mutex.Unlock()
 
 	u.children[su.correlationID] = su
+
+	if u.cfg != nil && u.cfg.DetailedStatus {
+		path := su.filePath
+		if su.isArchive() {
+			path = u.archive.name
+		}
+
+		su.fileStatus = &FileStatus{Path: path, State: StatePending}
+		u.fileStatuses = append(u.fileStatuses, su.fileStatus)
+	}
+}
+
+// notify notifies the listener on upload status change. If the detailed status is enabled,
+// a snapshot of the files statuses is included. Should be called with the mutex locked.
+func (u *MultiUpload) notify() {
+	if u.fileStatuses != nil {
+		files := make([]FileStatus, len(u.fileStatuses))
+		for i, f := range u.fileStatuses {
+			files[i] = *f
+		}
+		u.status.Files = files
+	}
+
+	u.listener.uploadStatusUpdated(u.status)
+}
+
+// updateFileStatus updates the status of the given file, unless it is already finished. The file progress
+// never decreases. Should be called with the mutex locked.
+func (u *MultiUpload) updateFileStatus(su *SingleUpload, state string, progress int) {
+	if f := su.fileStatus; f != nil && !isFinalState(f.State) {
+		f.State = state
+		if progress > f.Progress {
+			f.Progress = progress
+		}
+	}
+}
+
+// addProvider adds the storage provider, to which the given file was uploaded, to the reported providers.
+// Should be called with the mutex locked.
+func (u *MultiUpload) addProvider(su *SingleUpload) {
+	su.mutex.RLock()
+	provider := su.provider
+	su.mutex.RUnlock()
+
+	if provider == "" {
+		return
+	}
+
+	for _, p := range strings.Split(u.status.Provider, ",") {
+		if p == provider {
+			return
+		}
+	}
+
+	if u.status.Provider == "" {
+		u.status.Provider = provider
+	} else {
+		u.status.Provider += "," + provider
+	}
+}
+
+// cancelFileStatuses sets the state of all unfinished files to canceled. Should be called with the mutex locked.
+func (u *MultiUpload) cancelFileStatuses() {
+	for _, f := range u.fileStatuses {
+		if !isFinalState(f.State) {
+			f.State = StateCanceled
+		}
+	}
 }
 
 func (u *MultiUpload) removeChild(su *SingleUpload) {
@@ -301,7 +704,9 @@ func (u *MultiUpload) getChildrenIDs() []string {
 	return ids
 }
 
-func (u *MultiUpload) changeProgress(newBytesTransferred int64) {
+func (u *MultiUpload) changeProgress(su *SingleUpload, newBytesTransferred int64) {
+	u.uploads.addTransferred(newBytesTransferred)
+
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 	if u.totalSizeBytes == 0 { //an empty file set, nothing to change
@@ -310,16 +715,56 @@ func (u *MultiUpload) changeProgress(newBytesTransferred int64) {
 		}
 	} else if u.totalSizeBytes != fineGrainedUploadProgressNotSupported {
 		u.totalBytesTransferred += newBytesTransferred
+		u.updateTransferSpeed(time.Now())
 		newProgress := int((100 * float64(u.totalBytesTransferred)) / float64(u.totalSizeBytes))
 		notify := newProgress != u.status.Progress
 		u.status.Progress = newProgress
+		if su.totalSizeBytes > 0 {
+			u.updateFileStatus(su, StateUploading, int((100*float64(su.bytesTransferred))/float64(su.totalSizeBytes)))
+		}
 		if notify {
-			u.listener.uploadStatusUpdated(u.status)
+			u.notify()
 		}
 	}
 
 }
 
+// updateTransferSpeed updates the transfer speed and the estimated remaining time of the upload status.
+// The speed is averaged over the transferSpeedWindow, to avoid jitter caused by uneven progress reports.
+func (u *MultiUpload) updateTransferSpeed(now time.Time) {
+	if len(u.transferSamples) == 0 {
+		u.transferSamples = append(u.transferSamples, transferSample{u.status.StartTime, 0})
+	}
+
+	if u.uploads != nil {
+		last := u.transferSamples[len(u.transferSamples)-1]
+		u.uploads.bandwidth.add(u.totalBytesTransferred-last.bytes, now.Sub(last.time))
+	}
+
+	u.transferSamples = append(u.transferSamples, transferSample{now, u.totalBytesTransferred})
+
+	// keep the newest sample, which is older than the window, as a base of the average
+	oldest := 0
+	for oldest < len(u.transferSamples)-2 && now.Sub(u.transferSamples[oldest+1].time) >= transferSpeedWindow {
+		oldest++
+	}
+	u.transferSamples = u.transferSamples[oldest:]
+
+	base := u.transferSamples[0]
+	elapsed := now.Sub(base.time).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	u.status.BytesPerSecond = int64(float64(u.totalBytesTransferred-base.bytes) / elapsed)
+	if u.status.BytesPerSecond > 0 {
+		remaining := u.totalSizeBytes - u.totalBytesTransferred
+		u.status.ETASeconds = int((remaining + u.status.BytesPerSecond - 1) / u.status.BytesPerSecond)
+	} else {
+		u.status.ETASeconds = 0
+	}
+}
+
 func (u *MultiUpload) start(options map[string]string) error {
 	return fmt.Errorf("multi-file upload '%s' cannot be started - start the individual uploads", u.correlationID)
 }
@@ -332,7 +777,7 @@ func (u *MultiUpload) cancel(code string, message string) {
 		defer u.mutex.Unlock()
 
 		if u.status == nil { //not yet started
-			u.status = &UploadStatus{}
+			u.status = &UploadStatus{CorrelationID: u.correlationID}
 		} else if u.status.finished() {
 			return true
 		}
@@ -341,7 +786,8 @@ func (u *MultiUpload) cancel(code string, message string) {
 		u.status.StatusCode = code
 		u.status.Message = message
 		u.status.EndTime = time.Now()
-		u.listener.uploadStatusUpdated(u.status)
+		u.cancelFileStatuses()
+		u.notify()
 
 		return false
 	}()
@@ -350,17 +796,38 @@ func (u *MultiUpload) cancel(code string, message string) {
 		u.cancelUploads()
 
 		u.uploads.Remove(u.correlationID)
-
+		u.removeArchive()
 	}
 }
 
+// isUploading checks if the multi-file upload is started and still in progress
+func (u *MultiUpload) isUploading() bool {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
+	return u.status != nil && u.status.State == StateUploading
+}
+
+// isFinished checks if the multi-file upload is already in a final state, e.g. canceled
+func (u *MultiUpload) isFinished() bool {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
+	return u.status != nil && u.status.finished()
+}
+
 func (u *MultiUpload) uploadStarted(su *SingleUpload, info map[string]string) {
 	logger.Infof("upload %v started", su)
 
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
+	u.updateFileStatus(su, StateUploading, 0)
+
 	if u.status != nil && u.status.State != StatePending {
+		if u.fileStatuses != nil && !u.status.finished() {
+			u.notify()
+		}
 		return // already started
 	}
 	u.status = &UploadStatus{}
@@ -369,14 +836,9 @@ func (u *MultiUpload) uploadStarted(su *SingleUpload, info map[string]string) {
 	u.status.StartTime = time.Now()
 	u.status.Progress = 0
 	u.status.Info = info
-	u.status = &UploadStatus{}
-	u.status.CorrelationID = u.correlationID
-	u.status.State = StateUploading
-	u.status.StartTime = time.Now()
-	u.status.Progress = 0
-	u.status.Info = info
+	u.transferSamples = nil
 
-	u.listener.uploadStatusUpdated(u.status)
+	u.notify()
 }
 
 func (u *MultiUpload) uploadFailed(su *SingleUpload, err error) {
@@ -395,7 +857,12 @@ func (u *MultiUpload) uploadFailed(su *SingleUpload, err error) {
 		u.status.State = StateFailed
 		u.status.EndTime = time.Now()
 		u.status.Message = err.Error()
-		u.listener.uploadStatusUpdated(u.status)
+		if errors.Is(err, errCircuitOpen) {
+			u.status.StatusCode = StatusCodeCircuitOpen
+		}
+		u.updateFileStatus(su, StateFailed, 0)
+		u.cancelFileStatuses()
+		u.notify()
 
 		return false
 	}()
@@ -404,15 +871,27 @@ func (u *MultiUpload) uploadFailed(su *SingleUpload, err error) {
 		u.cancelUploads()
 
 		u.uploads.Remove(u.correlationID)
-
+		u.removeArchive()
 	}
 }
 
 func (u *MultiUpload) uploadFinished(su *SingleUpload) {
 	logger.Infof("upload %v finished'", su)
 
+	u.uploads.addTransferred(su.totalSizeBytes - su.bytesTransferred) // uploaders might not report the progress
+
 	u.removeChild(su)
 
+	if listener, ok := u.listener.(fileUploadListener); ok {
+		size := su.totalSizeBytes
+		if size == 0 { // not known in advance
+			if info, err := os.Stat(su.filePath); err == nil {
+				size = info.Size()
+			}
+		}
+		listener.fileUploaded(su.filePath, size)
+	}
+
 	done := func() bool {
 		u.mutex.Lock()
 		defer u.mutex.Unlock()
@@ -426,8 +905,10 @@ func (u *MultiUpload) uploadFinished(su *SingleUpload) {
 			u.status.Progress = 100
 			u.status.State = StateSuccess
 			u.status.EndTime = time.Now()
+			u.status.ETASeconds = 0
 		} else if u.totalSizeBytes != fineGrainedUploadProgressNotSupported && u.totalSizeBytes != 0 {
 			u.totalBytesTransferred += su.totalSizeBytes - su.bytesTransferred // ensures that the total number of transferred bytes for a single file will be exactly its size
+			u.updateTransferSpeed(time.Now())
 			u.status.Progress = int(100 * (float64(u.totalBytesTransferred) / float64(u.totalSizeBytes)))
 		} else {
 			uploaded := float32(u.totalCount - remaining)
@@ -435,49 +916,254 @@ func (u *MultiUpload) uploadFinished(su *SingleUpload) {
 			u.status.Progress = int(percents)
 		}
 
-		u.listener.uploadStatusUpdated(u.status)
+		if len(u.cfg.FallbackProviders) > 0 {
+			u.addProvider(su)
+		}
+
+		if su.isStale() {
+			u.status.Stale = append(u.status.Stale, su.filePath)
+		}
+
+		u.updateFileStatus(su, StateSuccess, 100)
+		u.notify()
 
 		return remaining == 0
 	}()
 
 	if done {
 		u.uploads.Remove(u.correlationID)
+
+		if u.archive != nil && u.cfg.Delete {
+			u.archive.deleteSources(u.deletes)
+		}
+		u.removeArchive()
 	}
 
 }
 
-func (u *MultiUpload) uploadCancelled(su *SingleUpload, code string, message string) {
-	logger.Infof("upload %v cancelled", su)
+// dryRunFinished completes the dry run of the given file. Once all files are completed, the upload succeeds
+// right away, without going through the uploading state.
+func (u *MultiUpload) dryRunFinished(su *SingleUpload, info map[string]string) {
+	logger.Infof("dry run of upload %v finished", su)
 
 	u.removeChild(su)
 
-	u.cancel(code, message) //cancel all uploads
+	done := func() bool {
+		u.mutex.Lock()
+		defer u.mutex.Unlock()
+
+		if u.status != nil && u.status.finished() {
+			return false
+		}
+
+		u.updateFileStatus(su, StateSuccess, 100)
+
+		if len(u.children) > 0 {
+			if u.status != nil && u.status.State != StatePending && u.fileStatuses != nil {
+				u.notify()
+			}
+			return false
+		}
+
+		if u.status == nil {
+			u.status = &UploadStatus{CorrelationID: u.correlationID, StartTime: time.Now(), Info: info}
+		}
+		u.status.State = StateSuccess
+		u.status.Progress = 100
+		u.status.EndTime = time.Now()
+		u.status.ETASeconds = 0
+		u.status.Message = dryRunMessage
+		u.notify()
+
+		return true
+	}()
+
+	if done {
+		u.uploads.Remove(u.correlationID)
+		u.removeArchive()
+	}
 }
 
-func (u *MultiUpload) cancelUploads() {
+// endActive drops the files of the upload, which are not yet started. The upload is canceled, if none of its files is
+// started, or if the started files are not finished within the given grace period.
+func (u *MultiUpload) endActive(grace time.Duration) {
 	u.mutex.Lock()
-	uploads := make([]*SingleUpload, 0, len(u.children))
+	if u.status != nil && u.status.finished() {
+		u.mutex.Unlock()
+		return
+	}
+
+	var queued []*SingleUpload
 	for _, su := range u.children {
-		uploads = append(uploads, su)
+		if atomic.CompareAndSwapUint32(&su.started, 0, 1) { // prevents starting the file
+			queued = append(queued, su)
+		}
 	}
 	u.mutex.Unlock()
 
-	for _, su := range uploads {
-		su.internalCancel()
-		logger.Infof("upload %v cancelled", su)
+	for _, su := range queued {
+		u.removeChild(su)
 	}
-}
-
-//******* END MultiUpload methods *******//
 
-//******* SingleUpload methods *******//
+	message := fmt.Sprintf("active time frame ended - %d queued file(s) not uploaded", len(queued))
+
+	done := func() bool {
+		u.mutex.Lock()
+		defer u.mutex.Unlock()
+
+		if u.status != nil && u.status.finished() {
+			return false
+		}
+
+		for _, su := range queued {
+			u.updateFileStatus(su, StateCanceled, 0)
+		}
+
+		if u.status == nil { // none of the files reported its start yet
+			u.status = &UploadStatus{CorrelationID: u.correlationID, State: StatePending, StartTime: time.Now()}
+		}
+
+		if len(u.children) > 0 {
+			if len(queued) > 0 {
+				u.status.Message = message
+				u.notify()
+			}
+			return false
+		}
+
+		u.status.State = StateCanceled
+		u.status.Message = message
+		u.status.EndTime = time.Now()
+		u.notify()
+
+		return true
+	}()
+
+	if done {
+		logger.Infof("upload %s canceled - %s", u.correlationID, message)
+
+		u.uploads.Remove(u.correlationID)
+		u.removeArchive()
+		return
+	}
+
+	time.AfterFunc(grace, func() {
+		if !u.isFinished() {
+			u.cancel("", "active time frame grace period expired")
+		}
+	})
+}
+
+// removeArchive removes the temporary archive of the upload, if any
+func (u *MultiUpload) removeArchive() {
+	if u.archive != nil {
+		u.archive.remove()
+	}
+}
+
+func (u *MultiUpload) uploadCancelled(su *SingleUpload, code string, message string) {
+	logger.Infof("upload %v cancelled", su)
+
+	u.removeChild(su)
+
+	u.cancel(code, message) //cancel all uploads
+}
+
+func (u *MultiUpload) cancelUploads() {
+	u.mutex.Lock()
+	uploads := make([]*SingleUpload, 0, len(u.children))
+	for _, su := range u.children {
+		uploads = append(uploads, su)
+	}
+	u.mutex.Unlock()
+
+	for _, su := range uploads {
+		su.internalCancel()
+		logger.Infof("upload %v cancelled", su)
+	}
+}
+
+// templateKey returns the key of the object, to which the given file is uploaded, resolved from the object key
+// template in the given options for the given object name, with the compression and encryption extensions appended.
+// Keys, which collide with the key of another file of this upload, get a numeric suffix, e.g. 'app-2.log'. The file
+// always gets the same key, e.g. when its upload is resumed, and all files get the same time.
+func (u *MultiUpload) templateKey(options map[string]string, filePath string, name string) (string, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if key, ok := u.objectKeys[filePath]; ok {
+		return key, nil
+	}
+
+	if u.objectKeys == nil {
+		u.objectKeys = make(map[string]string)
+		u.claimedKeys = make(map[string]bool)
+		u.keysTime = time.Now()
+	}
+
+	deviceID := ""
+	if u.uploads != nil {
+		deviceID = u.uploads.getDeviceID()
+	}
+
+	keys, err := uploaders.NewObjectKeyNamer(options, &uploaders.ObjectKeyValues{
+		DeviceID: deviceID, CorrelationID: u.correlationID, Time: u.keysTime,
+	})
+	if err != nil {
+		return "", err
+	}
+	key := keys.Key(name) + objectExtension(u.cfg)
+
+	unique := key
+	for i := 2; u.claimedKeys[unique]; i++ {
+		dir, base := path.Split(key)
+		ext := ""
+		if dot := strings.Index(base, "."); dot > 0 { // all extensions, e.g. '.log.gz', but not a leading dot
+			base, ext = base[:dot], base[dot:]
+		}
+		unique = fmt.Sprintf("%s%s-%d%s", dir, base, i, ext)
+	}
+
+	u.objectKeys[filePath] = unique
+	u.claimedKeys[unique] = true
+
+	return unique, nil
+}
+
+//******* END MultiUpload methods *******//
+
+//******* SingleUpload methods *******//
+
+// isArchive returns true if the uploaded file is the temporary archive of the parent upload
+func (u *SingleUpload) isArchive() bool {
+	return u.parent.archive != nil && u.filePath == u.parent.archive.path
+}
+
+// transformCommand returns the command of the pre-upload transform of the uploaded file, or empty string,
+// if the file is not transformed
+func (u *SingleUpload) transformCommand() string {
+	if u.isArchive() {
+		return ""
+	}
+
+	return u.parent.cfg.Transforms.command(u.filePath)
+}
+
+// isDecompressed returns true if the uploaded file is gzip compressed and should be decompressed before upload
+func (u *SingleUpload) isDecompressed() bool {
+	return u.parent.cfg.Decompress && !u.isArchive() && uploaders.IsGzipFile(u.filePath)
+}
 
 func (u *SingleUpload) String() string {
 	return fmt.Sprintf("[correlationID: %s, file: %s]", u.correlationID, u.filePath)
 }
 
 func (u *SingleUpload) start(options map[string]string) error {
-	uploader, err := getUploader(options, u.parent.serverCert)
+	if u.parent.dryRun || options[DryRunOption] == "true" {
+		return u.startDryRun(options)
+	}
+
+	uploader, key, err := u.getUploader(options)
 
 	if err != nil {
 		return err
@@ -492,80 +1178,894 @@ func (u *SingleUpload) start(options map[string]string) error {
 	info := uploaders.ExtractDictionary(options, InfoPrefix)
 	u.parent.uploadStarted(u, info)
 
-	progressFunc := func(bytesTransferred int64) {
-		if u.parent.totalSizeBytes == fineGrainedUploadProgressNotSupported {
-			return // unsupported
+	u.mutex.Lock()
+	u.options = options
+	u.provider = providerOf(options)
+	u.mutex.Unlock()
+
+	go u.run(uploader, key, 0, false)
+
+	return nil
+}
+
+// startDryRun completes the upload without connecting to the storage provider or transferring any bytes
+func (u *SingleUpload) startDryRun(options map[string]string) error {
+	if !atomic.CompareAndSwapUint32(&u.started, 0, 1) {
+		return fmt.Errorf("upload '%s' already started", u.correlationID)
+	}
+
+	logger.Infof("dry run of upload %v - skipping the file transfer", u)
+
+	u.mutex.Lock()
+	u.options = options
+	u.mutex.Unlock()
+
+	u.parent.dryRunFinished(u, uploaders.ExtractDictionary(options, InfoPrefix))
+
+	return nil
+}
+
+// progress is called back by the uploaders with the number of transferred bytes
+func (u *SingleUpload) progress(bytesTransferred int64) {
+	atomic.StoreInt64(&u.sentBytes, bytesTransferred)
+
+	if u.parent.totalSizeBytes == fineGrainedUploadProgressNotSupported {
+		return // unsupported
+	}
+	if u.totalSizeBytes == 0 && bytesTransferred != 0 {
+		logger.Warnf("reporting non-zero transferred bytes(%d) on an empty file(%v)", bytesTransferred, u.file)
+		return
+	}
+	if bytesTransferred > u.bytesTransferred { // a re-attempted upload reports from the beginning
+		change := bytesTransferred - u.bytesTransferred
+		u.bytesTransferred = bytesTransferred
+		u.parent.changeProgress(u, change)
+	}
+}
+
+// run uploads the file from the given offset and notifies the parent upload on completion. The pre-upload hook
+// and the remote object check are skipped, when a previously interrupted upload is resumed.
+func (u *SingleUpload) run(uploader uploaders.Uploader, key []byte, offset int64, resumed bool) {
+	u.mutex.RLock()
+	endpoint := endpointOf(u.options)
+	u.mutex.RUnlock()
+
+	u.mutex.Lock()
+	if u.attempted.IsZero() {
+		u.attempted = time.Now()
+	}
+	u.mutex.Unlock()
+
+	if resumed && !u.retryAllowed(0) {
+		u.finish(fmt.Errorf("upload not resumed, since the maximum retry duration of %v is exceeded",
+			time.Duration(u.parent.cfg.MaxRetryDuration)), false)
+		return
+	}
+
+	var unavailable error // the endpoint is cached as unavailable or its circuit is open
+	if !resumed {
+		unavailable = u.checkEndpoint(endpoint)
+		if unavailable != nil && len(u.parent.cfg.FallbackProviders) == 0 {
+			u.finish(unavailable, false)
+			return
 		}
-		if u.totalSizeBytes == 0 && bytesTransferred != 0 {
-			logger.Warnf("reporting non-zero transferred bytes(%d) on an empty file(%v)", bytesTransferred, u.file)
+
+		if err := runPreUploadHook(u.parent.cfg, u.parent.correlationID, u.filePath); err != nil {
+			logger.Errorf("skipping upload %v: %v", u, err)
+			u.finish(err, false)
+			return
+		}
+
+		if unavailable == nil && u.parent.cfg.SkipIfRemoteNewer && u.isRemoteNewer(uploader) {
+			u.finish(nil, true)
 			return
 		}
-		if u.bytesTransferred != bytesTransferred {
-			change := bytesTransferred - u.bytesTransferred
-			u.bytesTransferred = bytesTransferred
-			if change != 0 {
-				u.parent.changeProgress(change)
+	}
+
+	err := unavailable
+	if err == nil {
+		attempt, ok := u.restoreRetry()
+		if !ok {
+			u.finish(context.Canceled, false)
+			return
+		}
+
+		err = u.upload(uploader, key, offset)
+
+		if err != nil && u.parent.credentials != nil && uploaders.IsAuthorizationError(err) && u.retryAllowed(0) {
+			logger.Warnf("credentials for upload %v rejected, retrying with reloaded credentials: %v", u, err)
+
+			u.parent.credentials.invalidate()
+
+			u.mutex.RLock()
+			options := u.options
+			u.mutex.RUnlock()
+
+			if uploader, key, err = u.getUploader(options); err == nil {
+				err = u.upload(uploader, key, offset)
+			}
+		}
+
+		for ; err != nil && attempt < u.parent.cfg.RetryAfterAttempts; attempt++ {
+			delay := uploaders.RetryAfter(err)
+			if delay <= 0 {
+				break
+			}
+			if max := time.Duration(u.parent.cfg.MaxRetryAfter); delay > max {
+				delay = max
+			}
+			if !u.retryAllowed(delay) {
+				logger.Warnf("upload %v throttled by the storage, not retried, since the maximum retry duration of %v would be exceeded: %v",
+					u, time.Duration(u.parent.cfg.MaxRetryDuration), err)
+				break
+			}
+
+			logger.Warnf("upload %v throttled by the storage, retrying in %v: %v", u, delay, err)
+			u.mutex.RLock()
+			attempted := u.attempted
+			u.mutex.RUnlock()
+			u.parent.retries.add(u.filePath, pendingRetry{u.correlationID, attempt + 1, time.Now().Add(delay), attempted})
+			if !u.waitRetry(delay) {
+				break // canceled
 			}
+
+			err = u.upload(uploader, key, offset)
 		}
+		u.parent.retries.remove(u.filePath)
+
+		u.updateEndpointHealth(endpoint, err)
+	}
+
+	if err != nil && (unavailable != nil || uploaders.IsUnavailableError(err)) {
+		err = u.uploadFallback(err)
+	}
+
+	if err != nil && u.parent.cfg.ResumeUploads && u.retryAllowed(0) && u.interrupt(err) {
+		return // waiting for the backend to resume the upload
+	}
+
+	u.finish(err, false)
+}
+
+// retryAllowed returns true, if the file can be re-attempted after the given delay, without exceeding the maximum
+// retry duration, measured from the first attempt of the file. Applies to all re-attempts - with reloaded
+// credentials, after the requested delay, to the fallback providers and when resumed.
+func (u *SingleUpload) retryAllowed(delay time.Duration) bool {
+	max := time.Duration(u.parent.cfg.MaxRetryDuration)
+	if max <= 0 {
+		return true
+	}
+
+	u.mutex.RLock()
+	attempted := u.attempted
+	u.mutex.RUnlock()
+
+	return time.Since(attempted)+delay <= max
+}
+
+// restoreRetry restores the re-attempt of the file, which was pending when the agent stopped, and waits until it is due.
+// Returns the number of re-attempts made, including the restored one, and false if the upload is canceled meanwhile.
+func (u *SingleUpload) restoreRetry() (int, bool) {
+	retry, ok := u.parent.retries.get(u.filePath)
+	if !ok {
+		return 0, true
+	}
+
+	u.mutex.Lock()
+	u.attempted = retry.Attempted
+	u.mutex.Unlock()
+
+	delay := time.Until(retry.Next)
+	if delay < 0 {
+		delay = 0
 	}
+	logger.Infof("upload %v continues re-attempt %d of upload %s, retrying in %v", u, retry.Attempt, retry.CorrelationID, delay)
+
+	if delay > 0 && !u.waitRetry(delay) {
+		return retry.Attempt, false
+	}
+
+	return retry.Attempt, true
+}
 
-	go func() {
-		file, err := os.Open(u.filePath)
-		var useChecksum bool
+// waitRetry waits for the given delay before re-attempting the upload. Returns false, if the upload is canceled.
+func (u *SingleUpload) waitRetry(delay time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), delay)
+	defer cancel()
 
-		if err == nil && u.parent.useChecksum {
-			useChecksum = true
+	u.mutex.Lock()
+	u.cancelUpload = cancel
+	u.mutex.Unlock()
+
+	<-ctx.Done()
+
+	return errors.Is(ctx.Err(), context.DeadlineExceeded) && !u.parent.isFinished()
+}
+
+// uploadFallback uploads the file to the configured fallback providers in order, until the upload to one of them
+// succeeds. Returns the given error of the requested provider upload, if the uploads to all fallback providers fail.
+func (u *SingleUpload) uploadFallback(err error) error {
+	u.mutex.RLock()
+	options, primary := u.options, u.provider
+	u.mutex.RUnlock()
+
+	for _, provider := range u.parent.cfg.FallbackProviders {
+		if u.parent.isFinished() {
+			break // canceled
+		}
+		if provider == primary {
+			continue
+		}
+		if !u.retryAllowed(0) {
+			logger.Warnf("upload %v not retried with fallback provider '%s', since the maximum retry duration of %v is exceeded",
+				u, provider, time.Duration(u.parent.cfg.MaxRetryDuration))
+			break
 		}
 
-		if err == nil {
-			defer func() {
-				file.Close()
-			}()
+		logger.Warnf("upload %v to '%s' failed, falling back to '%s': %v", u, primary, provider, err)
 
+		fallback := withProvider(options, provider)
+		endpoint := endpointOf(fallback)
+		if e := u.checkEndpoint(endpoint); e != nil {
+			logger.Warnf("skipping fallback provider '%s' for upload %v: %v", provider, u, e)
+			continue
+		}
+
+		uploader, key, e := u.getUploader(fallback)
+		if e == nil {
+			e = u.upload(uploader, key, 0)
+			u.updateEndpointHealth(endpoint, e)
+		}
+
+		if e == nil {
 			u.mutex.Lock()
-			u.file = file
+			u.provider = provider
 			u.mutex.Unlock()
 
-			err = uploader.UploadFile(file, useChecksum, progressFunc)
+			return nil
+		}
+		logger.Errorf("upload %v to fallback provider '%s' failed: %v", u, provider, e)
+	}
+
+	return err
+}
+
+// checkEndpoint returns error, if the given endpoint is cached as unavailable or its circuit is open
+func (u *SingleUpload) checkEndpoint(endpoint string) error {
+	if u.parent.circuits != nil {
+		if err := u.parent.circuits.check(endpoint); err != nil {
+			return err
 		}
+	}
+	if u.parent.health != nil {
+		return u.parent.health.check(endpoint)
+	}
+
+	return nil
+}
+
+// updateEndpointHealth records the endpoint health from the upload result, if endpoint health caching or circuit
+// breaking is enabled, and notifies the listener on health changes
+func (u *SingleUpload) updateEndpointHealth(endpoint string, err error) {
+	if u.parent.circuits != nil {
+		u.parent.circuits.update(endpoint, err)
+	}
+	if u.parent.health == nil {
+		return
+	}
+
+	health, changed := u.parent.health.update(endpoint, err)
+	if listener, ok := u.parent.listener.(endpointHealthListener); ok && changed {
+		listener.endpointHealthUpdated(health)
+	}
+}
+
+// finish runs the post-upload hook and notifies the parent upload for the upload result.
+// Successfully uploaded files are deleted, if configured, unless skipped.
+func (u *SingleUpload) finish(err error, skipped bool) {
+	runPostUploadHook(u.parent.cfg, u.parent.correlationID, u.filePath, err)
+
+	if err != nil {
+		u.parent.uploadFailed(u, err)
+		return
+	}
+
+	u.parent.uploadFinished(u)
+
+	if u.isStale() {
+		logger.Warnf("uploaded file '%s' was modified during upload, it is not deleted", u.filePath)
+	} else if u.parent.cfg.Delete && !u.isArchive() && !skipped { // archived files are deleted by the parent
+		u.parent.deletes.wait()
+		err := os.Remove(u.filePath)
 
 		if err != nil {
-			u.parent.uploadFailed(u, err)
+			logger.Errorf("failed to delete uploaded file '%s': %v", u.filePath, err)
 		} else {
-			u.parent.uploadFinished(u)
+			logger.Infof("uploaded file '%s' deleted", u.filePath)
+		}
+	}
+}
+
+// interrupt suspends the failed upload and requests the backend to resume it, reporting the number of transferred
+// bytes. The upload fails if not resumed within the configured timeout. Returns false if resumption cannot be
+// requested, e.g. the upload is cancelled or the file is transformed, since the transformed copy differs on each run,
+// in which case the upload should fail right away.
+func (u *SingleUpload) interrupt(err error) bool {
+	requester, ok := u.parent.listener.(uploadResumeRequester)
+	if !ok || errors.Is(err, context.Canceled) || errors.Is(err, os.ErrClosed) || u.parent.isFinished() ||
+		u.transformCommand() != "" {
+		return false
+	}
 
-			if u.parent.deleteUploaded {
-				file.Close()
-				err := os.Remove(u.filePath)
+	timeout := time.Duration(u.parent.cfg.ResumeTimeout)
 
-				if err != nil {
-					logger.Errorf("failed to delete uploaded file '%s': %v", u.filePath, err)
-				} else {
-					logger.Infof("uploaded file '%s' deleted", u.filePath)
-				}
-			}
+	u.mutex.Lock()
+	u.uploadErr = err
+	atomic.StoreUint32(&u.interrupted, 1)
+	u.resumeTimer = time.AfterFunc(timeout, func() {
+		if atomic.CompareAndSwapUint32(&u.interrupted, 1, 0) {
+			u.finish(fmt.Errorf("upload not resumed in %v after failure: %w", timeout, err), false)
 		}
-	}()
+	})
+	u.mutex.Unlock()
+
+	logger.Warnf("upload %v interrupted, requesting resumption from offset %d: %v", u, u.bytesTransferred, err)
+	requester.uploadResumeRequested(u.correlationID, u.bytesTransferred, u.totalSizeBytes, err)
+
+	return true
+}
+
+// resume executes the backend instruction for the interrupted upload - continue it from the given offset
+// (or the number of transferred bytes, if nil), restart it from the beginning or abort it. Continued and restarted
+// uploads use the given 'start' operation options, if any, or the options of the interrupted upload.
+func (u *SingleUpload) resume(instruction string, offset *int64, options map[string]string) error {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if atomic.LoadUint32(&u.interrupted) == 0 {
+		return fmt.Errorf("upload '%s' is not interrupted", u.correlationID)
+	}
+
+	if instruction == ResumeAbort {
+		if !atomic.CompareAndSwapUint32(&u.interrupted, 1, 0) {
+			return fmt.Errorf("upload '%s' is not interrupted", u.correlationID)
+		}
+		u.resumeTimer.Stop()
+
+		go u.finish(fmt.Errorf("upload aborted by the backend after failure: %w", u.uploadErr), false)
+		return nil
+	}
+
+	from := int64(0)
+	if instruction == ResumeContinue {
+		if u.parent.cfg.Encrypt {
+			return fmt.Errorf("encrypted upload '%s' cannot be continued", u.correlationID)
+		}
+
+		from = u.bytesTransferred
+		if offset != nil {
+			from = *offset
+		}
+	}
+
+	if len(options) == 0 {
+		options = u.options
+	}
+
+	uploader, key, err := u.getUploader(options)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := uploader.(uploaders.ResumableUploader); from > 0 && !ok {
+		return fmt.Errorf("upload '%s' cannot be continued - not supported by the storage provider", u.correlationID)
+	}
+
+	if !atomic.CompareAndSwapUint32(&u.interrupted, 1, 0) {
+		return fmt.Errorf("upload '%s' is not interrupted", u.correlationID)
+	}
+	u.resumeTimer.Stop()
+	u.options = options
+
+	logger.Infof("resuming upload %v from offset %d", u, from)
+	go u.run(uploader, key, from, true)
 
 	return nil
 }
 
+// isRemoteNewer checks if the object, to which the file is uploaded, was modified after the file.
+// If the uploader cannot retrieve the object modification time, the file is considered newer.
+func (u *SingleUpload) isRemoteNewer(uploader uploaders.Uploader) bool {
+	statUploader, ok := uploader.(uploaders.StatUploader)
+	if !ok {
+		logger.Warnf("cannot check if the uploaded object of %v is newer - not supported by the storage provider", u)
+		return false
+	}
+
+	file, err := os.Open(u.filePath)
+	if err != nil {
+		return false // reported on upload
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	lastModified, exists, err := statUploader.LastModified(context.Background(), file)
+	if err != nil {
+		logger.Warnf("cannot check if the uploaded object of %v is newer: %v", u, err)
+		return false
+	}
+
+	if exists && lastModified.After(info.ModTime()) {
+		logger.Infof("skipping upload %v - the uploaded object was modified at %v, after the file", u, lastModified)
+		return true
+	}
+
+	return false
+}
+
+// createsTempFiles returns true, if temporary files are created for uploading the file, e.g. with the given transform
+// command or when compressed, encrypted or split into parts
+func (u *SingleUpload) createsTempFiles(command string) bool {
+	cfg := u.parent.cfg
+
+	return command != "" || u.isDecompressed() || cfg.ConvertEncoding.Match(u.filePath) || cfg.Compress || cfg.Encrypt ||
+		cfg.SplitSize > 0
+}
+
+// streamsCompressed returns true, if the file can be compressed on the fly, while it is uploaded, instead of into
+// a temporary copy - the uploader can upload content of unknown size and the whole compressed content is not needed
+// in advance, e.g. to compute its checksum, to encrypt it, to upload it from an offset or to verify it after upload
+func (u *SingleUpload) streamsCompressed(uploader uploaders.Uploader, offset int64) bool {
+	cfg := u.parent.cfg
+	if offset > 0 || cfg.Checksum || cfg.Encrypt || cfg.VerifyAfterUpload {
+		return false
+	}
+
+	streamer, ok := uploader.(uploaders.StreamUploader)
+
+	return ok && streamer.CanStream()
+}
+
+// upload opens the file and transfers it with the given uploader from the given offset. Files matching a pre-upload
+// transform pattern are replaced by the transform output. Text files matching the convert encoding patterns are
+// converted to UTF-8 first. If compression is enabled, the file is compressed on the fly, if the uploader supports
+// it, or into a temporary copy otherwise. If encryption is enabled, the file is encrypted with the given key.
+func (u *SingleUpload) upload(uploader uploaders.Uploader, key []byte, offset int64) error {
+	var file *os.File
+	var err error
+
+	command := u.transformCommand()
+	if u.createsTempFiles(command) {
+		if err = checkFreeInodes(os.TempDir(), u.parent.cfg.MinFreeInodes); err != nil {
+			return err
+		}
+	}
+
+	if command != "" {
+		if file, err = runTransform(u.parent.cfg, u.parent.correlationID, command, u.filePath); err != nil {
+			return err
+		}
+		defer uploaders.RemoveTempFile(file)
+	} else {
+		if file, err = os.Open(u.filePath); err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+
+	var snapshot os.FileInfo
+	if u.parent.cfg.DetectModification && !u.isArchive() && command == "" { // the transform output is a consistent copy
+		if snapshot, err = file.Stat(); err != nil {
+			return err
+		}
+	}
+
+	upload := file
+	if u.isDecompressed() {
+		if upload, err = uploaders.DecompressFile(file); err != nil {
+			return err
+		}
+		defer uploaders.RemoveTempFile(upload)
+	}
+
+	if u.parent.cfg.ConvertEncoding.Match(u.filePath) {
+		converted, err := uploaders.ConvertToUTF8(upload)
+		if err != nil {
+			return err
+		}
+		if converted != nil {
+			defer uploaders.RemoveTempFile(converted)
+			upload = converted
+		}
+	}
+
+	var stream io.ReadCloser // the file content, compressed on the fly while uploaded
+	if u.parent.cfg.Compress && u.streamsCompressed(uploader, offset) {
+		if stream, err = uploaders.CompressReader(upload, u.parent.cfg.CompressFormat); err != nil {
+			return err
+		}
+		defer stream.Close()
+	} else if u.parent.cfg.Compress {
+		if upload, err = uploaders.CompressFile(upload, u.parent.cfg.CompressFormat); err != nil {
+			return err
+		}
+		defer uploaders.RemoveTempFile(upload)
+	}
+
+	if u.parent.cfg.Encrypt {
+		if upload, err = uploaders.EncryptFile(upload, key); err != nil {
+			return err
+		}
+		defer uploaders.RemoveTempFile(upload)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stopRefresh := u.refreshCredentials(uploader)
+	defer stopRefresh()
+
+	u.mutex.Lock()
+	u.file = upload
+	u.cancelUpload = cancel
+	u.mutex.Unlock()
+
+	atomic.StoreInt64(&u.sentBytes, 0)
+	stopWatching := u.watchProgress(upload, offset, cancel)
+
+	if stream != nil {
+		name := filepath.Base(upload.Name()) + uploaders.CompressionExtension(u.parent.cfg.CompressFormat)
+		err = uploader.(uploaders.StreamUploader).UploadStream(ctx, name, stream, u.progress)
+	} else if offset > 0 {
+		err = uploader.(uploaders.ResumableUploader).UploadFileFrom(ctx, upload, offset, u.parent.cfg.Checksum, u.progress)
+	} else if contextUploader, ok := uploader.(uploaders.ContextUploader); ok {
+		err = contextUploader.UploadFileContext(ctx, upload, u.parent.cfg.Checksum, u.progress)
+	} else {
+		err = uploader.UploadFile(upload, u.parent.cfg.Checksum, u.progress)
+	}
+
+	if aborted := stopWatching(); aborted != nil {
+		return aborted
+	}
+
+	if err == nil && u.parent.cfg.VerifyAfterUpload {
+		err = u.verify(ctx, uploader, upload)
+	}
+
+	if err == nil && snapshot != nil && isModified(u.filePath, snapshot) {
+		logger.Warnf("file of upload %v was modified or replaced during upload, the uploaded content may be stale", u)
+		atomic.StoreUint32(&u.stale, 1)
+	}
+
+	return err
+}
+
+// isStale returns true if the file was modified or replaced during its upload
+func (u *SingleUpload) isStale() bool {
+	return atomic.LoadUint32(&u.stale) == 1
+}
+
+// isModified returns true if the file with the given path is no longer the same file (e.g. it was rotated),
+// or its size or modification time differ from the given snapshot
+func isModified(path string, snapshot os.FileInfo) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return true
+	}
+
+	return !os.SameFile(snapshot, info) || info.Size() != snapshot.Size() || !info.ModTime().Equal(snapshot.ModTime())
+}
+
+// verify downloads the uploaded object and compares it to the uploaded content, if supported by the uploader
+func (u *SingleUpload) verify(ctx context.Context, uploader uploaders.Uploader, upload *os.File) error {
+	verifier, ok := uploader.(uploaders.VerifyingUploader)
+	if !ok {
+		logger.Warnf("cannot verify the uploaded object of %v - not supported by the storage provider", u)
+		return nil
+	}
+
+	if err := verifier.VerifyFile(ctx, upload); err != nil {
+		return fmt.Errorf("failed to verify the uploaded object: %w", err)
+	}
+	logger.Infof("uploaded object of %v verified", u)
+
+	return nil
+}
+
+// refreshCredentials proactively refreshes the credentials of the given uploader from the credentials file, while
+// the file is uploaded, if the uploader supports it and the credentials expire. Returns function, which stops it.
+func (u *SingleUpload) refreshCredentials(uploader uploaders.Uploader) func() {
+	refresher, ok := uploader.(uploaders.CredentialsRefresher)
+	if !ok || u.parent.credentials == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go u.parent.credentials.keepFresh(refresher, done)
+
+	return func() {
+		close(done)
+	}
+}
+
+// getUploader creates an uploader from the given 'start' operation options, applying locally provisioned
+// credentials, the trigger tags and the upload configuration over them. If encryption is enabled, the encryption key is returned as well.
+func (u *SingleUpload) getUploader(options map[string]string) (uploaders.Uploader, []byte, error) {
+	if provider := providerOf(options); !isProviderSupported(u.parent.cfg, provider) {
+		return nil, nil, fmt.Errorf("storage provider '%s' is not supported - supported are '%s'", provider,
+			strings.Join(u.parent.cfg.SupportedProviders, "', '"))
+	}
+
+	if u.parent.credentials != nil {
+		credentials, err := u.parent.credentials.get()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		merged := make(map[string]string, len(options)+len(credentials))
+		for k, v := range options {
+			merged[k] = v
+		}
+		for k, v := range credentials {
+			merged[k] = v
+		}
+		options = merged
+	}
+
+	if len(u.parent.tags) > 0 {
+		merged := make(map[string]string, len(options)+len(u.parent.tags))
+		for k, v := range u.parent.tags {
+			merged[uploaders.TagPrefix+k] = v
+		}
+		for k, v := range options { // the 'start' operation tags override the trigger tags
+			merged[k] = v
+		}
+		options = merged
+	}
+
+	options = withTLSOptions(options, u.parent.cfg)
+	if policy := u.parent.cfg.MultipartPolicy; policy != "" && policy != MultipartPolicyDefault {
+		options = withMultipartOptions(options, u.parent.cfg, u.parent.uploads.Bandwidth())
+	}
+	options = withPartialUploadOptions(options, u.parent.cfg)
+
+	var key []byte
+	if u.parent.cfg.Encrypt {
+		var err error
+		if key, err = uploaders.ParseEncryptionKey(options[uploaders.EncryptionKeyProp]); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	name := u.filePath
+	if u.isArchive() {
+		name = u.parent.archive.name
+	} else if u.isDecompressed() {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	if u.parent.cfg.Compress || u.parent.cfg.Encrypt || u.parent.cfg.ContentAddressed || u.isArchive() || u.isDecompressed() {
+		var err error
+		if options, err = objectOptions(options, u.filePath, name, u.parent.cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	objectKey, ok := options[ObjectKey]
+	if !ok && options[uploaders.ObjectKeyTemplate] != "" {
+		var err error
+		if objectKey, err = u.parent.templateKey(options, u.filePath, name); err != nil {
+			return nil, nil, err
+		}
+		ok = true
+	}
+
+	if ok {
+		var err error
+		if options, err = objectKeyOptions(options, objectKey, u.parent.cfg.ObjectKeyRoot); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if u.parent.cfg.SplitSize > 0 {
+		uploader, err := getSplitUploader(options, name, u.parent.cfg)
+		return uploader, key, err
+	}
+
+	uploader, err := getUploader(options, u.parent.cfg.ServerCert)
+
+	return uploader, key, err
+}
+
+// getSplitUploader returns an uploader, which splits files exceeding the configured split size into parts, uploaded as
+// separate objects named after the given one. Splitting applies only to storage providers, supporting object names.
+func getSplitUploader(options map[string]string, name string, cfg *UploadableConfig) (uploaders.Uploader, error) {
+	var prop string
+	switch strings.ToLower(options[StorageProvider]) {
+	case uploaders.StorageProviderAWS:
+		prop = uploaders.AWSObjectKey
+	case uploaders.StorageProviderAzure:
+		prop = uploaders.AzureBlobName
+		name = filepath.Base(name)
+	case uploaders.StorageProviderFile:
+		prop = uploaders.FileName
+		name = sinkName(options, uploaders.FilePreservePaths, name)
+	case uploaders.StorageProviderLocal:
+		prop = uploaders.LocalObjectKey
+		name = sinkName(options, uploaders.LocalPreservePaths, name)
+	case uploaders.StorageProviderWebDAV:
+		prop = uploaders.WebDAVPath
+		name = filepath.Base(name)
+	default:
+		return getUploader(options, cfg.ServerCert)
+	}
+
+	newUploader := func(object string) (uploaders.Uploader, error) {
+		partOptions := make(map[string]string, len(options))
+		for k, v := range options {
+			partOptions[k] = v
+		}
+		partOptions[prop] = object
+
+		return getUploader(partOptions, cfg.ServerCert)
+	}
+
+	name = objectName(options[prop], name, "")
+	uploader, err := newUploader(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploaders.NewSplitUploader(uploader, name, int64(cfg.SplitSize), newUploader), nil
+}
+
 func getUploader(options map[string]string, serverCert string) (uploaders.Uploader, error) {
 	storage, ok := options[StorageProvider]
 
 	storage = strings.ToLower(storage)
 
-	if !ok || storage == uploaders.StorageProviderHTTP {
-		return uploaders.NewHTTPUploader(options, serverCert)
-	} else if storage == uploaders.StorageProviderAWS {
-		return uploaders.NewAWSUploader(options)
-	} else if storage == uploaders.StorageProviderAzure {
-		return uploaders.NewAzureUploader(options)
+	if !ok {
+		storage = uploaders.StorageProviderHTTP
+	}
+
+	if factory := uploaderFactoryOf(storage); factory != nil {
+		return factory(options, serverCert)
 	}
 
 	return nil, fmt.Errorf("unknown storage provider '%s'", storage)
 }
 
+// objectOptions returns a copy of the 'start' operation options, adjusted with the name of the uploaded object,
+// which depends on the upload configuration - compressed and encrypted files get the corresponding extensions,
+// content addressed objects are named after the SHA-256 hash of the file content. The given name is used
+// as default object name, e.g. the file path or the name of an archive.
+func objectOptions(options map[string]string, filePath string, name string, cfg *UploadableConfig) (map[string]string, error) {
+	result := make(map[string]string, len(options)+1)
+	for k, v := range options {
+		result[k] = v
+	}
+
+	contentName := ""
+	if cfg.ContentAddressed {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		if contentName, err = uploaders.ComputeSHA256(file); err != nil {
+			return nil, err
+		}
+	}
+
+	extension := objectExtension(cfg)
+	if cfg.Compress && !cfg.Encrypt {
+		result[uploaders.ContentEncodingProp] = uploaders.ContentEncoding(cfg.CompressFormat)
+	}
+
+	switch strings.ToLower(options[StorageProvider]) {
+	case uploaders.StorageProviderAWS:
+		result[uploaders.AWSObjectKey] = objectName(options[uploaders.AWSObjectKey], name, contentName) + extension
+	case uploaders.StorageProviderAzure:
+		result[uploaders.AzureBlobName] = objectName(options[uploaders.AzureBlobName], filepath.Base(name), contentName) + extension
+	case uploaders.StorageProviderFile:
+		result[uploaders.FileName] = objectName(options[uploaders.FileName],
+			sinkName(options, uploaders.FilePreservePaths, name), contentName) + extension
+	case uploaders.StorageProviderLocal:
+		result[uploaders.LocalObjectKey] = objectName(options[uploaders.LocalObjectKey],
+			sinkName(options, uploaders.LocalPreservePaths, name), contentName) + extension
+	case uploaders.StorageProviderWebDAV:
+		result[uploaders.WebDAVPath] = objectName(options[uploaders.WebDAVPath], filepath.Base(name), contentName) + extension
+	}
+
+	return result, nil
+}
+
+// sinkName returns the default name of the given file in the target directory of a file sink - its full path,
+// if paths are preserved according to the given option, or its base name otherwise
+func sinkName(options map[string]string, preservePathsOption string, name string) string {
+	if preserve, _ := strconv.ParseBool(options[preservePathsOption]); preserve {
+		return uploaders.PreservedPath(name)
+	}
+
+	return filepath.Base(name)
+}
+
+// objectExtension returns the extension, appended to the names of the uploaded objects,
+// which are compressed and/or encrypted according to the upload configuration
+func objectExtension(cfg *UploadableConfig) string {
+	extension := ""
+	if cfg.Compress {
+		extension = uploaders.CompressionExtension(cfg.CompressFormat)
+	}
+	if cfg.Encrypt {
+		extension += uploaders.EncryptionExtension
+	}
+
+	return extension
+}
+
+// objectKeyOptions returns a copy of the 'start' operation options, adjusted to upload the object with the given key,
+// regardless of the upload configuration. The key should be a relative path, which does not escape the given root
+// (if not empty). For HTTP uploads, the key is appended to the path of the upload URL.
+func objectKeyOptions(options map[string]string, key string, root string) (map[string]string, error) {
+	cleaned := path.Clean(key)
+	if key == "" || path.IsAbs(key) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return nil, fmt.Errorf("invalid object key '%s'", key)
+	}
+
+	if root = strings.Trim(path.Clean(root), "/"); root != "" && root != "." &&
+		cleaned != root && !strings.HasPrefix(cleaned, root+"/") {
+		return nil, fmt.Errorf("object key '%s' is outside of the object key root '%s'", key, root)
+	}
+
+	result := make(map[string]string, len(options))
+	for k, v := range options {
+		result[k] = v
+	}
+
+	switch strings.ToLower(options[StorageProvider]) {
+	case uploaders.StorageProviderAWS:
+		result[uploaders.AWSObjectKey] = cleaned
+	case uploaders.StorageProviderAzure:
+		result[uploaders.AzureBlobName] = cleaned
+	case uploaders.StorageProviderFile:
+		result[uploaders.FileName] = cleaned
+	case uploaders.StorageProviderLocal:
+		result[uploaders.LocalObjectKey] = cleaned
+	case uploaders.StorageProviderWebDAV:
+		result[uploaders.WebDAVPath] = cleaned
+	default:
+		uploadURL, err := url.Parse(options[uploaders.URLProp])
+		if err != nil {
+			return nil, err
+		}
+		uploadURL.Path = path.Join("/", uploadURL.Path, cleaned)
+		uploadURL.RawPath = ""
+		result[uploaders.URLProp] = uploadURL.String()
+	}
+
+	return result, nil
+}
+
+// objectName returns the content name (if not empty), preserving the path of the provided object name,
+// otherwise the provided name (if not empty) or the default one.
+func objectName(name string, defaultName string, contentName string) string {
+	if contentName != "" {
+		return name[:strings.LastIndex(name, "/")+1] + contentName
+	}
+
+	if name == "" {
+		return defaultName
+	}
+
+	return name
+}
+
 func (u *SingleUpload) cancel(code string, message string) {
 	u.internalCancel()
 
@@ -574,11 +2074,20 @@ func (u *SingleUpload) cancel(code string, message string) {
 
 func (u *SingleUpload) internalCancel() {
 	var file *os.File
+	var cancelUpload context.CancelFunc
 
 	u.mutex.RLock()
 	file = u.file
+	cancelUpload = u.cancelUpload
+	if atomic.CompareAndSwapUint32(&u.interrupted, 1, 0) {
+		u.resumeTimer.Stop()
+	}
 	u.mutex.RUnlock()
 
+	if cancelUpload != nil {
+		cancelUpload()
+	}
+
 	if file != nil {
 		err := file.Close()
 
@@ -15,21 +15,37 @@
 package client
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unicode/utf16"
 
+	"github.com/andybalholm/brotli"
 	"github.com/eclipse-kanto/file-upload/uploaders"
+	"github.com/klauspost/compress/zstd"
 )
 
 const (
@@ -126,11 +142,21 @@ func (l *TestStatusListener) assertStatusState(expected string) {
 	}
 }
 
+func TestAddMulti(t *testing.T) {
+	us := NewUploads()
+
+	ids := us.AddMulti("testUID", []string{"t1.txt", "t2.txt"}, true, true, "test.crt", NewTestStatusListener(t))
+	assertEquals(t, []string{"testUID#1", "testUID#2"}, ids)
+
+	cfg := us.Get("testUID").(*MultiUpload).cfg
+	assertEquals(t, UploadableConfig{Delete: true, Checksum: true, ServerCert: "test.crt"}, *cfg)
+}
+
 func TestRemoveChild(t *testing.T) {
 	us := NewUploads()
 
 	paths := []string{"t1.txt", "t2.txt"}
-	ids := us.AddMulti("testUID", paths, false, false, "", NewTestStatusListener(t))
+	ids := us.AddMultiWithConfig("testUID", paths, &UploadableConfig{}, NewTestStatusListener(t))
 
 	for i, id := range ids {
 		u := us.Get(id)
@@ -152,7 +178,7 @@ func TestRemoveParent(t *testing.T) {
 	paths := []string{"t1.txt", "t2.txt"}
 
 	const parentID = "testUID"
-	ids := us.AddMulti(parentID, paths, false, false, "", NewTestStatusListener(t))
+	ids := us.AddMultiWithConfig(parentID, paths, &UploadableConfig{}, NewTestStatusListener(t))
 
 	us.Remove(parentID)
 
@@ -195,7 +221,7 @@ func testSuccessfulUpload(t *testing.T, secure bool) {
 	paths := getPaths(files)
 
 	l := NewTestStatusListener(t)
-	ids := us.AddMulti("testUID", paths, true, false, serverCert, l)
+	ids := us.AddMultiWithConfig("testUID", paths, &UploadableConfig{Delete: true, ServerCert: serverCert}, l)
 
 	startUploads(t, us, ids, server.URL)
 
@@ -251,7 +277,7 @@ func TestUploadStatusOrder(t *testing.T) {
 
 	us := NewUploads()
 	paths := getPaths(files)
-	ids := us.AddMulti("testUID", paths, true, false, "", &u)
+	ids := us.AddMultiWithConfig("testUID", paths, &UploadableConfig{Delete: true}, &u)
 	startUploads(t, us, ids, server.URL)
 
 	cond.L.Lock()
@@ -277,7 +303,7 @@ func TestFailure(t *testing.T) {
 	defer server.Close()
 
 	l := NewTestStatusListener(t)
-	ids := us.AddMulti("testUID", paths, true, false, "", l)
+	ids := us.AddMultiWithConfig("testUID", paths, &UploadableConfig{Delete: true}, l)
 
 	startUploads(t, us, ids, server.URL)
 
@@ -287,6 +313,35 @@ func TestFailure(t *testing.T) {
 	l.assertStatusState(StateFailed)
 }
 
+func TestConfirmHeadFailure(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		ioutil.ReadAll(r.Body)
+
+		if r.Method == http.MethodHead { // upload accepted, but not stored
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{Delete: true}, l)
+
+	options := map[string]string{uploaders.URLProp: server.URL, uploaders.ConfirmHeadProp: "true"}
+	assertNoError(t, us.Get(ids[0]).start(options))
+
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+
+	if _, err := os.Stat(files[0].Name()); err != nil {
+		t.Errorf("file with unconfirmed upload should not be deleted: %v", err)
+	}
+}
+
 func TestCancel(t *testing.T) {
 	const filesCount = 5
 	files := createTestFiles(t, filesCount, false, false)
@@ -297,7 +352,7 @@ func TestCancel(t *testing.T) {
 
 	us := NewUploads()
 	l := NewTestStatusListener(t)
-	ids := us.AddMulti("testUID", getPaths(files), true, false, "", l)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{Delete: true}, l)
 
 	startUploads(t, us, ids, server.URL)
 
@@ -322,6 +377,140 @@ func TestCancel(t *testing.T) {
 	time.Sleep(2 * time.Second) //wait for uploads in progress
 }
 
+func TestCancelStalledUpload(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	received := make(chan struct{})
+	aborted := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		close(received)
+
+		<-r.Context().Done() // never responds
+		close(aborted)
+	}))
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{}, l)
+
+	startUploads(t, us, ids, server.URL)
+
+	<-received // the file is sent, closing it does not abort the upload
+	us.Get("testUID").cancel("tc", "test message")
+
+	select {
+	case <-aborted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stalled upload request not aborted")
+	}
+
+	l.waitFinish()
+	l.assertStatusState(StateCanceled)
+}
+
+func TestEndActiveQueuedFiles(t *testing.T) {
+	files := createTestFiles(t, 3, false, false)
+	defer cleanFiles(files)
+
+	server := startTestServer(t, 200*time.Millisecond, false)
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{}, l)
+	queued := us.Get(ids[1])
+
+	startUploads(t, us, ids[:1], server.URL)
+	time.Sleep(20 * time.Millisecond)
+
+	us.endActive(time.Minute)
+	assertError(t, queued.start(map[string]string{uploaders.URLProp: server.URL}))
+	if us.Get(ids[2]) != nil {
+		t.Errorf("queued upload %s should be removed", ids[2])
+	}
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+	assertEquals(t, "active time frame ended - 2 queued file(s) not uploaded", l.getStatus().Message)
+}
+
+func TestEndActiveGracePeriodExpired(t *testing.T) {
+	files := createTestFiles(t, 2, false, false)
+	defer cleanFiles(files)
+
+	received := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		close(received)
+
+		<-r.Context().Done() // never responds
+	}))
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{}, l)
+
+	startUploads(t, us, ids[:1], server.URL)
+	<-received
+
+	us.endActive(50 * time.Millisecond)
+
+	l.waitFinish()
+	l.assertStatusState(StateCanceled)
+	assertEquals(t, "active time frame grace period expired", l.getStatus().Message)
+}
+
+func TestEndActiveNotStarted(t *testing.T) {
+	files := createTestFiles(t, 2, false, false)
+	defer cleanFiles(files)
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{}, l)
+
+	us.endActive(time.Minute)
+
+	l.waitFinish()
+	l.assertStatusState(StateCanceled)
+	assertEquals(t, "testUID", l.getStatus().CorrelationID)
+	for _, id := range append(ids, "testUID") {
+		if us.Get(id) != nil {
+			t.Errorf("upload %s should be removed", id)
+		}
+	}
+}
+
+func TestEndActiveStartingFile(t *testing.T) {
+	files := createTestFiles(t, 2, false, false)
+	defer cleanFiles(files)
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{}, l)
+
+	// the file is started, but its start is not reported yet
+	starting := us.Get(ids[0]).(*SingleUpload)
+	atomic.StoreUint32(&starting.started, 1)
+
+	us.endActive(time.Minute)
+
+	mu := us.Get("testUID").(*MultiUpload)
+	mu.uploadStarted(starting, nil)
+
+	assertEquals(t, true, mu.isUploading())
+	if us.Get(ids[1]) != nil {
+		t.Errorf("queued upload %s should be removed", ids[1])
+	}
+
+	mu.cancel("", "test message")
+	l.waitFinish()
+	l.assertStatusState(StateCanceled)
+}
+
 func TestGracefulShutdown(t *testing.T) {
 	files := createTestFiles(t, 1, false, false)
 	defer cleanFiles(files)
@@ -336,7 +525,7 @@ func TestGracefulShutdown(t *testing.T) {
 
 	const parentID = "testUID"
 	l := NewTestStatusListener(t)
-	ids := us.AddMulti(parentID, paths, false, false, "", l)
+	ids := us.AddMultiWithConfig(parentID, paths, &UploadableConfig{}, l)
 
 	startUploads(t, us, ids, server.URL)
 
@@ -344,16 +533,925 @@ func TestGracefulShutdown(t *testing.T) {
 		t.Fatal("pending upload expected, but none found")
 	}
 
-	us.Stop(delay * 2)
+	us.Stop(delay*2, nil)
 
 	if !l.isFinished() {
 		t.Fatal("all uploads should have finished")
 	}
 }
 
+func TestStopCanceled(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	server := startTestServer(t, 3*time.Second, false)
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{}, l)
+
+	startUploads(t, us, ids, server.URL)
+
+	cancel := make(chan struct{})
+	time.AfterFunc(100*time.Millisecond, func() {
+		close(cancel)
+	})
+
+	start := time.Now()
+	us.Stop(time.Minute, cancel)
+	if elapsed := time.Since(start); elapsed > stopPollInterval {
+		t.Fatalf("stop should return right after it is canceled, but took %v", elapsed)
+	}
+
+	l.waitFinish()
+	if state := l.getStatus().State; state == StateSuccess {
+		t.Fatal("the pending upload should be canceled")
+	}
+}
+
+func TestDetectModification(t *testing.T) {
+	dir := t.TempDir()
+	rotated, unchanged := filepath.Join(dir, "rotated.log"), filepath.Join(dir, "unchanged.log")
+	assertNoError(t, os.WriteFile(rotated, []byte("rotated content"), 0600))
+	assertNoError(t, os.WriteFile(unchanged, []byte("unchanged content"), 0600))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) == "rotated content" { // rotate the file, while it is being uploaded
+			replacement := rotated + ".new"
+			assertNoError(t, os.WriteFile(replacement, []byte("new content"), 0600))
+			assertNoError(t, os.Rename(replacement, rotated))
+		}
+	}))
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", []string{rotated, unchanged}, &UploadableConfig{DetectModification: true, Delete: true}, l)
+	startUploads(t, us, ids, server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+	assertEquals(t, []string{rotated}, l.getStatus().Stale)
+
+	content, err := os.ReadFile(rotated)
+	assertNoError(t, err)
+	assertEquals(t, "new content", string(content)) // stale files are not deleted
+	assertNotExists(t, unchanged)
+}
+
+func TestDetectModificationDisabled(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "modified.log")
+	assertNoError(t, os.WriteFile(path, []byte("content"), 0600))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		assertNoError(t, os.WriteFile(path, []byte("modified content"), 0600))
+	}))
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	startUploads(t, us, us.AddMultiWithConfig("testUID", []string{path}, &UploadableConfig{}, l), server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+	if stale := l.getStatus().Stale; len(stale) > 0 {
+		t.Errorf("no stale files expected, but were %v", stale)
+	}
+}
+
+func TestIsModified(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	assertNoError(t, os.WriteFile(path, []byte("content"), 0600))
+
+	snapshot, err := os.Stat(path)
+	assertNoError(t, err)
+	assertEquals(t, false, isModified(path, snapshot))
+
+	modified := snapshot.ModTime().Add(time.Second)
+	assertNoError(t, os.Chtimes(path, modified, modified))
+	assertEquals(t, true, isModified(path, snapshot))
+
+	snapshot, err = os.Stat(path)
+	assertNoError(t, err)
+	assertNoError(t, os.WriteFile(path, []byte("other content"), 0600))
+	assertNoError(t, os.Chtimes(path, snapshot.ModTime(), snapshot.ModTime()))
+	assertEquals(t, true, isModified(path, snapshot)) // size changed
+
+	assertNoError(t, os.Remove(path))
+	assertEquals(t, true, isModified(path, snapshot))
+}
+
+func TestTransferSpeed(t *testing.T) {
+	start := time.Now()
+	u := &MultiUpload{totalSizeBytes: 10000, status: &UploadStatus{StartTime: start}}
+
+	u.totalBytesTransferred = 1000
+	u.updateTransferSpeed(start.Add(time.Second))
+	assertEquals(t, int64(1000), u.status.BytesPerSecond)
+	assertEquals(t, 9, u.status.ETASeconds)
+
+	// a burst of progress within the window does not change the speed drastically
+	u.totalBytesTransferred = 4000
+	u.updateTransferSpeed(start.Add(1100 * time.Millisecond))
+	assertEquals(t, int64(3636), u.status.BytesPerSecond)
+	assertEquals(t, 2, u.status.ETASeconds)
+
+	// samples older than the window are not taken into account
+	u.totalBytesTransferred = 5000
+	u.updateTransferSpeed(start.Add(7 * time.Second))
+	u.totalBytesTransferred = 6000
+	u.updateTransferSpeed(start.Add(12 * time.Second))
+	assertEquals(t, int64(200), u.status.BytesPerSecond)
+	assertEquals(t, 20, u.status.ETASeconds)
+
+	// no progress
+	u.updateTransferSpeed(start.Add(20 * time.Second))
+	assertEquals(t, int64(0), u.status.BytesPerSecond)
+	assertEquals(t, 0, u.status.ETASeconds)
+}
+
+func TestTransferSpeedNotSupported(t *testing.T) {
+	files := createTestFiles(t, 2, true, false)
+	defer cleanFiles(files)
+
+	server := startTestServer(t, 0, false)
+	defer server.Close()
+
+	for _, cfg := range []*UploadableConfig{{}, {Compress: true, CompressFormat: uploaders.CompressionGzip}} {
+		us := NewUploads()
+		l := NewTestStatusListener(t)
+		ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+
+		startUploads(t, us, ids, server.URL)
+
+		l.waitFinish()
+		l.assertStatusState(StateSuccess)
+
+		status := l.getStatus()
+		if cfg.Compress {
+			assertEquals(t, int64(0), status.BytesPerSecond)
+		} else if status.BytesPerSecond <= 0 {
+			t.Errorf("positive transfer speed expected, got %d", status.BytesPerSecond)
+		}
+		assertEquals(t, 0, status.ETASeconds)
+	}
+
+	data, err := json.Marshal(UploadStatus{})
+	assertNoError(t, err)
+	if strings.Contains(string(data), "bytesPerSecond") || strings.Contains(string(data), "etaSeconds") {
+		t.Errorf("transfer speed and ETA should be omitted when not set: %s", data)
+	}
+}
+
+func TestCompressGzip(t *testing.T) {
+	testCompressedUpload(t, uploaders.CompressionGzip, func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	})
+}
+
+func TestCompressZstd(t *testing.T) {
+	testCompressedUpload(t, uploaders.CompressionZstd, func(r io.Reader) (io.Reader, error) {
+		return zstd.NewReader(r)
+	})
+}
+
+func TestCompressBrotli(t *testing.T) {
+	testCompressedUpload(t, uploaders.CompressionBrotli, func(r io.Reader) (io.Reader, error) {
+		return brotli.NewReader(r), nil
+	})
+}
+
+func testCompressedUpload(t *testing.T, format string, decompressor func(r io.Reader) (io.Reader, error)) {
+	for _, chunked := range []bool{false, true} {
+		testCompressedUploadChunked(t, format, chunked, decompressor)
+	}
+}
+
+// testCompressedUploadChunked uploads compressed files and verifies the uploaded content round-trips. If chunked
+// transfer encoding is allowed, the files are compressed on the fly and sent without content length.
+func testCompressedUploadChunked(t *testing.T, format string, chunked bool, decompressor func(r io.Reader) (io.Reader, error)) {
+	files := createTestFiles(t, 3, true, false)
+	defer cleanFiles(files)
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	us := NewUploads()
+	paths := getPaths(files)
+
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", paths, &UploadableConfig{Compress: true, CompressFormat: format}, l)
+
+	for _, id := range ids {
+		options := map[string]string{uploaders.URLProp: server.URL, uploaders.ChunkedProp: strconv.FormatBool(chunked)}
+		assertNoError(t, us.Get(id).start(options))
+	}
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	expected := make([]string, len(paths))
+	for i, path := range paths {
+		content, err := os.ReadFile(path)
+		assertNoError(t, err)
+		expected[i] = string(content)
+	}
+
+	actual := make([]string, 0, len(paths))
+	for _, r := range received.get() {
+		assertEquals(t, uploaders.ContentEncoding(format), r.headers.Get("Content-Encoding"))
+		assertEquals(t, chunked, r.headers.Get("Content-Length") == "") // streamed without a temporary copy
+
+		reader, err := decompressor(bytes.NewReader(r.body))
+		assertNoError(t, err)
+		content, err := ioutil.ReadAll(reader)
+		assertNoError(t, err)
+
+		actual = append(actual, string(content))
+	}
+
+	sort.Strings(expected)
+	sort.Strings(actual)
+	assertEquals(t, expected, actual)
+}
+
+func TestDecompressUpload(t *testing.T) {
+	dir := t.TempDir()
+	const content = "rotated log line\n"
+	rotated := writeGzipFile(t, filepath.Join(dir, "test.log.1.gz"), content)
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", []string{rotated}, &UploadableConfig{Decompress: true}, l)
+	startUploads(t, us, ids, server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	requests := received.get()
+	assertEquals(t, 1, len(requests))
+	assertEquals(t, content, string(requests[0].body))
+	assertEquals(t, "", requests[0].headers.Get("Content-Encoding"))
+}
+
+func TestDecompressFileSinkUpload(t *testing.T) {
+	dir := t.TempDir()
+	const content = "rotated log line\n"
+	rotated := writeGzipFile(t, filepath.Join(dir, "test.log.1.gz"), content)
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", []string{rotated}, &UploadableConfig{Decompress: true}, l)
+
+	sink := t.TempDir()
+	options := map[string]string{StorageProvider: uploaders.StorageProviderFile, uploaders.FileDirectory: sink}
+	assertNoError(t, us.Get(ids[0]).start(options))
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	actual, err := os.ReadFile(filepath.Join(sink, "test.log.1"))
+	assertNoError(t, err)
+	assertEquals(t, content, string(actual))
+}
+
+func TestDecompressInvalidGzip(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "test.log.gz")
+	assertNoError(t, os.WriteFile(invalid, []byte("not compressed"), 0644))
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", []string{invalid}, &UploadableConfig{Decompress: true}, l)
+	startUploads(t, us, ids, server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+	assertEquals(t, 0, len(received.get()))
+}
+
+func TestVerifyAfterUpload(t *testing.T) {
+	testVerifyAfterUpload(t, false, StateSuccess)
+}
+
+func TestVerifyAfterUploadMismatch(t *testing.T) {
+	testVerifyAfterUpload(t, true, StateFailed)
+}
+
+func testVerifyAfterUpload(t *testing.T, corrupt bool, state string) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	var mutex sync.Mutex
+	stored := make(map[string][]byte)
+	downloads := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&downloads, 1)
+			if corrupt {
+				w.Write([]byte("corrupted"))
+			} else {
+				w.Write(stored[r.URL.Path])
+			}
+			return
+		}
+		stored[r.URL.Path], _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{VerifyAfterUpload: true, Compress: true, CompressFormat: uploaders.CompressionGzip}, l)
+	startUploads(t, us, ids, server.URL+"/upload")
+
+	l.waitFinish()
+	l.assertStatusState(state)
+	assertEquals(t, int32(1), atomic.LoadInt32(&downloads))
+
+	if corrupt && !strings.Contains(l.getStatus().Message, uploaders.ErrVerificationFailed.Error()) {
+		t.Errorf("verification error expected, but was '%s'", l.getStatus().Message)
+	}
+}
+
+// writeGzipFile writes the given content gzip compressed to the given path
+func writeGzipFile(t *testing.T, path string, content string) string {
+	t.Helper()
+
+	file, err := os.Create(path)
+	assertNoError(t, err)
+	defer file.Close()
+
+	w := gzip.NewWriter(file)
+	_, err = w.Write([]byte(content))
+	assertNoError(t, err)
+	assertNoError(t, w.Close())
+
+	return path
+}
+
+func TestObjectOptions(t *testing.T) {
+	options := map[string]string{StorageProvider: uploaders.StorageProviderAWS}
+	cfg := &UploadableConfig{Compress: true, CompressFormat: uploaders.CompressionZstd}
+
+	actual := objectOptionsNoError(t, options, "/var/log/test.log", cfg)
+	assertEquals(t, "/var/log/test.log.zst", actual[uploaders.AWSObjectKey])
+	assertEquals(t, uploaders.CompressionZstd, actual[uploaders.ContentEncodingProp])
+
+	options[uploaders.AWSObjectKey] = "logs/test"
+	cfg.CompressFormat = uploaders.CompressionGzip
+	actual = objectOptionsNoError(t, options, "/var/log/test.log", cfg)
+	assertEquals(t, "logs/test.gz", actual[uploaders.AWSObjectKey])
+	assertEquals(t, "logs/test", options[uploaders.AWSObjectKey])
+
+	cfg.CompressFormat = uploaders.CompressionBrotli
+	actual = objectOptionsNoError(t, options, "/var/log/test.log", cfg)
+	assertEquals(t, "logs/test.br", actual[uploaders.AWSObjectKey])
+	assertEquals(t, "br", actual[uploaders.ContentEncodingProp])
+
+	cfg.CompressFormat = uploaders.CompressionGzip
+	cfg.Encrypt = true
+	actual = objectOptionsNoError(t, options, "/var/log/test.log", cfg)
+	assertEquals(t, "logs/test.gz.enc", actual[uploaders.AWSObjectKey])
+	assertEquals(t, "", actual[uploaders.ContentEncodingProp])
+
+	cfg.Compress = false
+	actual = objectOptionsNoError(t, options, "/var/log/test.log", cfg)
+	assertEquals(t, "logs/test.enc", actual[uploaders.AWSObjectKey])
+
+	options = map[string]string{StorageProvider: uploaders.StorageProviderAzure}
+	actual = objectOptionsNoError(t, options, "/var/log/test.log", cfg)
+	assertEquals(t, "test.log.enc", actual[uploaders.AzureBlobName])
+
+	options = map[string]string{StorageProvider: uploaders.StorageProviderFile}
+	actual = objectOptionsNoError(t, options, "/var/log/test.log", cfg)
+	assertEquals(t, "test.log.enc", actual[uploaders.FileName])
+
+	options[uploaders.FilePreservePaths] = "true"
+	actual = objectOptionsNoError(t, options, "/var/log/test.log", cfg)
+	assertEquals(t, filepath.Join("var", "log", "test.log.enc"), actual[uploaders.FileName])
+
+	options = map[string]string{StorageProvider: uploaders.StorageProviderLocal, uploaders.LocalPreservePaths: "true"}
+	actual = objectOptionsNoError(t, options, "/var/log/test.log", cfg)
+	assertEquals(t, filepath.Join("var", "log", "test.log.enc"), actual[uploaders.LocalObjectKey])
+}
+
+func TestContentAddressedOptions(t *testing.T) {
+	dir, err := os.MkdirTemp(".", "content")
+	assertNoError(t, err)
+	defer os.RemoveAll(dir)
+
+	paths := []string{filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log"), filepath.Join(dir, "third.log")}
+	assertNoError(t, os.WriteFile(paths[0], []byte("same content"), 0600))
+	assertNoError(t, os.WriteFile(paths[1], []byte("same content"), 0600))
+	assertNoError(t, os.WriteFile(paths[2], []byte("other content"), 0600))
+
+	sum := sha256.Sum256([]byte("same content"))
+	hash := hex.EncodeToString(sum[:])
+
+	cfg := &UploadableConfig{ContentAddressed: true}
+	for _, provider := range []string{uploaders.StorageProviderAWS, uploaders.StorageProviderAzure} {
+		prop := uploaders.AWSObjectKey
+		if provider == uploaders.StorageProviderAzure {
+			prop = uploaders.AzureBlobName
+		}
+
+		options := map[string]string{StorageProvider: provider}
+		keys := make([]string, len(paths))
+		for i, path := range paths {
+			keys[i] = objectOptionsNoError(t, options, path, cfg)[prop]
+		}
+
+		assertEquals(t, hash, keys[0])
+		assertEquals(t, keys[0], keys[1])
+		if keys[0] == keys[2] {
+			t.Errorf("different keys expected for files with different content, got %s", keys[2])
+		}
+
+		options[prop] = "device/logs/test.log"
+		assertEquals(t, "device/logs/"+hash, objectOptionsNoError(t, options, paths[0], cfg)[prop])
+	}
+
+	cfg.Compress = true
+	cfg.CompressFormat = uploaders.CompressionGzip
+	options := map[string]string{StorageProvider: uploaders.StorageProviderAWS}
+	assertEquals(t, hash+".gz", objectOptionsNoError(t, options, paths[1], cfg)[uploaders.AWSObjectKey])
+
+	if _, err := objectOptions(options, filepath.Join(dir, "missing.log"), "missing.log", cfg); err == nil {
+		t.Error("error for missing file expected")
+	}
+}
+
+func objectOptionsNoError(t *testing.T, options map[string]string, path string, cfg *UploadableConfig) map[string]string {
+	t.Helper()
+
+	result, err := objectOptions(options, path, path, cfg)
+	assertNoError(t, err)
+
+	return result
+}
+
+func TestEncryptedUpload(t *testing.T) {
+	files := createTestFiles(t, 3, true, false)
+	defer cleanFiles(files)
+
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	key := make([]byte, 32)
+	rand.Read(key)
+
+	us := NewUploads()
+	paths := getPaths(files)
+
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", paths, &UploadableConfig{Encrypt: true, Checksum: true}, l)
+
+	for _, id := range ids {
+		options := map[string]string{uploaders.URLProp: server.URL, uploaders.EncryptionKeyProp: hex.EncodeToString(key)}
+		assertNoError(t, us.Get(id).start(options))
+	}
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	expected := make([]string, len(paths))
+	for i, path := range paths {
+		content, err := os.ReadFile(path)
+		assertNoError(t, err)
+		expected[i] = string(content)
+	}
+
+	actual := make([]string, 0, len(paths))
+	for i, r := range received.get() {
+		md5 := md5.Sum(r.body) // checksum is computed over the uploaded ciphertext
+		assertEquals(t, base64.StdEncoding.EncodeToString(md5[:]), r.headers.Get(uploaders.ContentMD5))
+
+		encrypted := fmt.Sprintf("received%d.enc", i)
+		decrypted := fmt.Sprintf("received%d", i)
+		assertNoError(t, os.WriteFile(encrypted, r.body, 0600))
+		defer os.Remove(encrypted)
+
+		assertNoError(t, uploaders.DecryptFile(encrypted, decrypted, key))
+		defer os.Remove(decrypted)
+
+		content, err := os.ReadFile(decrypted)
+		assertNoError(t, err)
+		actual = append(actual, string(content))
+	}
+
+	sort.Strings(expected)
+	sort.Strings(actual)
+	assertEquals(t, expected, actual)
+}
+
+func TestConvertEncodingUpload(t *testing.T) {
+	server, received := startRecordingServer(t)
+	defer server.Close()
+
+	text := "Grüße, 世界\n"
+	units := append([]uint16{0xFEFF}, utf16.Encode([]rune(text))...)
+	utf16Content := make([]byte, 2*len(units))
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(utf16Content[2*i:], unit)
+	}
+
+	dir := t.TempDir()
+	converted := filepath.Join(dir, "converted.txt")
+	unmatched := filepath.Join(dir, "unmatched.log")
+	for _, path := range []string{converted, unmatched} {
+		assertNoError(t, os.WriteFile(path, utf16Content, 0600))
+	}
+
+	us := NewUploads()
+	cfg := &UploadableConfig{ConvertEncoding: Globs{"*.txt"}, Checksum: true}
+	for i, path := range []string{converted, unmatched} { // uploaded one by one, to preserve the requests order
+		l := NewTestStatusListener(t)
+		ids := us.AddMultiWithConfig(fmt.Sprintf("testUID%d", i), []string{path}, cfg, l)
+
+		startUploads(t, us, ids, server.URL)
+		l.waitFinish()
+		l.assertStatusState(StateSuccess)
+	}
+
+	requests := received.get()
+	assertEquals(t, 2, len(requests))
+	assertEquals(t, text, string(requests[0].body))
+	assertEquals(t, utf16Content, requests[1].body)
+
+	for _, r := range requests {
+		md5 := md5.Sum(r.body) // checksum is computed over the converted content
+		assertEquals(t, base64.StdEncoding.EncodeToString(md5[:]), r.headers.Get(uploaders.ContentMD5))
+	}
+
+	for _, path := range []string{converted, unmatched} {
+		content, err := os.ReadFile(path)
+		assertNoError(t, err)
+		assertEquals(t, utf16Content, content) // source files are not modified
+	}
+}
+
+func TestEncryptedUploadInvalidKey(t *testing.T) {
+	us := NewUploads()
+	ids := us.AddMultiWithConfig("testUID", []string{"test.txt"}, &UploadableConfig{Encrypt: true}, nil)
+
+	options := map[string]string{uploaders.URLProp: "http://localhost", uploaders.EncryptionKeyProp: "invalid"}
+	if err := us.Get(ids[0]).start(options); err == nil {
+		t.Error("error for invalid encryption key expected")
+	}
+}
+
+func TestSplitUploader(t *testing.T) {
+	cfg := &UploadableConfig{SplitSize: 5 * Gigabyte}
+
+	options := map[string]string{
+		StorageProvider:              uploaders.StorageProviderAWS,
+		uploaders.AWSRegion:          "us-east-1",
+		uploaders.AWSAccessKeyID:     "testKeyID",
+		uploaders.AWSSecretAccessKey: "testSecret",
+		uploaders.AWSBucket:          "testBucket",
+	}
+
+	u, err := getSplitUploader(options, "/var/log/test.log", cfg)
+	assertNoError(t, err)
+	if _, ok := u.(*uploaders.SplitUploader); !ok {
+		t.Errorf("split uploader expected for AWS storage, but was %T", u)
+	}
+
+	delete(options, uploaders.AWSBucket)
+	_, err = getSplitUploader(options, "/var/log/test.log", cfg)
+	assertError(t, err)
+
+	u, err = getSplitUploader(map[string]string{uploaders.URLProp: "http://localhost/up"}, "/var/log/test.log", cfg)
+	assertNoError(t, err)
+	if _, ok := u.(*uploaders.HTTPUploader); !ok {
+		t.Errorf("HTTP uploader expected for generic storage, but was %T", u)
+	}
+}
+
+func TestSkipIfRemoteNewer(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+	path := files[0].Name()
+
+	info, err := os.Stat(path)
+	assertNoError(t, err)
+
+	tests := []struct {
+		name         string
+		lastModified time.Time
+		uploaded     bool
+	}{
+		{"remote newer", info.ModTime().Add(time.Hour), false},
+		{"remote older", info.ModTime().Add(-time.Hour), true},
+		{"remote not found", time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var puts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodHead {
+					ioutil.ReadAll(r.Body)
+					atomic.AddInt32(&puts, 1)
+				} else if test.lastModified.IsZero() {
+					w.WriteHeader(http.StatusNotFound)
+				} else {
+					w.Header().Set("Last-Modified", test.lastModified.UTC().Format(http.TimeFormat))
+				}
+			}))
+			defer server.Close()
+
+			us := NewUploads()
+			l := NewTestStatusListener(t)
+			ids := us.AddMultiWithConfig("testUID", []string{path}, &UploadableConfig{SkipIfRemoteNewer: true}, l)
+			startUploads(t, us, ids, server.URL)
+
+			l.waitFinish()
+			l.assertStatusState(StateSuccess)
+			assertEquals(t, test.uploaded, atomic.LoadInt32(&puts) == 1)
+		})
+	}
+}
+
+func TestSkipIfRemoteNewerNoDelete(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+	path := files[0].Name()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("unexpected %s request", r.Method)
+		}
+		w.Header().Set("Last-Modified", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	}))
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", []string{path}, &UploadableConfig{SkipIfRemoteNewer: true, Delete: true}, l)
+	startUploads(t, us, ids, server.URL)
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("skipped file should not be deleted: %v", err)
+	}
+}
+
+// recordingStatusListener records the files statuses of the upload status updates
+type recordingStatusListener struct {
+	*TestStatusListener
+
+	mutex sync.Mutex
+	files [][]FileStatus
+}
+
+func (l *recordingStatusListener) uploadStatusUpdated(s *UploadStatus) {
+	l.mutex.Lock()
+	l.files = append(l.files, s.Files)
+	l.mutex.Unlock()
+
+	l.TestStatusListener.uploadStatusUpdated(s)
+}
+
+func TestDetailedStatusPartialFailure(t *testing.T) {
+	files := createTestFiles(t, 3, true, false)
+	defer cleanFiles(files)
+
+	server, _ := startRecordingServer(t)
+	defer server.Close()
+
+	paths := getPaths(files)
+	paths[1] += ".missing" // fails to open
+
+	us := NewUploads()
+	l := &recordingStatusListener{TestStatusListener: NewTestStatusListener(t)}
+	ids := us.AddMultiWithConfig("testUID", paths, &UploadableConfig{DetailedStatus: true}, l)
+
+	startUploads(t, us, ids[:1], server.URL)
+	for !fileStateReached(l, 0, StateSuccess) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	startUploads(t, us, ids[1:2], server.URL)
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+
+	assertEquals(t, []FileStatus{
+		{Path: paths[0], State: StateSuccess, Progress: 100},
+		{Path: paths[1], State: StateFailed},
+		{Path: paths[2], State: StateCanceled},
+	}, l.getStatus().Files)
+
+	// file states are never reverted and progress never decreases
+	order := map[string]int{StatePending: 0, StateUploading: 1, StateSuccess: 2, StateFailed: 2, StateCanceled: 2}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for i := 1; i < len(l.files); i++ {
+		for j, f := range l.files[i] {
+			prev := l.files[i-1][j]
+			if order[f.State] < order[prev.State] || f.Progress < prev.Progress ||
+				(isFinalState(prev.State) && f.State != prev.State) {
+				t.Errorf("file status of '%s' changed from %+v to %+v", f.Path, prev, f)
+			}
+		}
+	}
+}
+
+func TestDetailedStatusDisabled(t *testing.T) {
+	files := createTestFiles(t, 2, false, false)
+	defer cleanFiles(files)
+
+	server, _ := startRecordingServer(t)
+	defer server.Close()
+
+	us := NewUploads()
+	l := &recordingStatusListener{TestStatusListener: NewTestStatusListener(t)}
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{}, l)
+
+	startUploads(t, us, ids, server.URL)
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, files := range l.files {
+		if files != nil {
+			t.Errorf("no files statuses expected, but were %+v", files)
+		}
+	}
+}
+
+func fileStateReached(l *recordingStatusListener, index int, state string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return len(l.files) > 0 && l.files[len(l.files)-1][index].State == state
+}
+
+func TestFileSinkUpload(t *testing.T) {
+	files := createTestFiles(t, 2, true, false)
+	defer cleanFiles(files)
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{}, l)
+
+	dir := t.TempDir()
+	for _, id := range ids {
+		options := map[string]string{StorageProvider: uploaders.StorageProviderFile, uploaders.FileDirectory: dir}
+		assertNoError(t, us.Get(id).start(options))
+	}
+
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	for _, file := range files {
+		expected, err := os.ReadFile(file.Name())
+		assertNoError(t, err)
+
+		actual, err := os.ReadFile(filepath.Join(dir, filepath.Base(file.Name())))
+		assertNoError(t, err)
+		assertEquals(t, expected, actual)
+	}
+}
+
+func TestObjectKeyOptions(t *testing.T) {
+	options := map[string]string{StorageProvider: uploaders.StorageProviderAWS, uploaders.AWSObjectKey: "ignored"}
+
+	actual, err := objectKeyOptions(options, "devices/test/logs/a.log", "devices/test")
+	assertNoError(t, err)
+	assertEquals(t, "devices/test/logs/a.log", actual[uploaders.AWSObjectKey])
+	assertEquals(t, "ignored", options[uploaders.AWSObjectKey])
+
+	options = map[string]string{StorageProvider: uploaders.StorageProviderAzure}
+	actual, err = objectKeyOptions(options, "logs/./a.log", "")
+	assertNoError(t, err)
+	assertEquals(t, "logs/a.log", actual[uploaders.AzureBlobName])
+
+	options = map[string]string{uploaders.URLProp: "https://localhost/uploads?sig=abc"}
+	actual, err = objectKeyOptions(options, "devices/test/a.log", "/devices/test/")
+	assertNoError(t, err)
+	assertEquals(t, "https://localhost/uploads/devices/test/a.log?sig=abc", actual[uploaders.URLProp])
+
+	violations := []string{"", "/etc/passwd", "..", "../a.log", "logs/../../a.log", "devices/other/a.log", "devices/test/../a.log"}
+	for _, key := range violations {
+		if _, err := objectKeyOptions(options, key, "devices/test"); err == nil {
+			t.Errorf("object key '%s' should be rejected", key)
+		}
+	}
+}
+
+func TestObjectKeyUpload(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	paths := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		paths <- r.URL.Path
+	}))
+	defer server.Close()
+
+	us := NewUploads()
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{ObjectKeyRoot: "devices"}, l)
+
+	u := us.Get(ids[0])
+	assertError(t, u.start(map[string]string{uploaders.URLProp: server.URL, ObjectKey: "other/test.log"}))
+
+	assertNoError(t, u.start(map[string]string{uploaders.URLProp: server.URL + "/up", ObjectKey: "devices/test.log"}))
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	assertEquals(t, "/up/devices/test.log", <-paths)
+}
+
+func TestObjectKeyTemplateUpload(t *testing.T) {
+	files := createTestFiles(t, 2, false, false)
+	defer cleanFiles(files)
+
+	paths := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		paths <- r.URL.Path
+	}))
+	defer server.Close()
+
+	us := NewUploads()
+	us.setDeviceID("test:device")
+	l := NewTestStatusListener(t)
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), &UploadableConfig{}, l)
+
+	for _, id := range ids {
+		assertNoError(t, us.Get(id).start(map[string]string{
+			uploaders.URLProp: server.URL + "/up", uploaders.ObjectKeyTemplate: "{deviceId}/{correlationId}/{filename}",
+		}))
+	}
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+
+	received := []string{<-paths, <-paths}
+	sort.Strings(received)
+
+	expected := []string{
+		"/up/test:device/testUID/" + filepath.Base(files[0].Name()),
+		"/up/test:device/testUID/" + filepath.Base(files[1].Name()),
+	}
+	sort.Strings(expected)
+	assertEquals(t, expected, received)
+}
+
+func TestObjectKeyTemplateCollisions(t *testing.T) {
+	us := NewUploads()
+	us.setDeviceID("device-123")
+	paths := []string{"/var/log/first/app.log", "/var/log/second/app.log", "/var/log/third/app.log", "/var/log/other.log"}
+	us.AddMultiWithConfig("testUID", paths, &UploadableConfig{Compress: true, CompressFormat: uploaders.CompressionGzip}, nil)
+
+	mu := us.Get("testUID").(*MultiUpload)
+	date := time.Now().UTC().Format("2006-01-02")
+
+	options := map[string]string{uploaders.ObjectKeyTemplate: "{deviceId}/{date}/logs/{filename}"}
+	keys := make([]string, len(paths))
+	for i, path := range paths {
+		var err error
+		keys[i], err = mu.templateKey(options, path, path)
+		assertNoError(t, err)
+	}
+	assertEquals(t, []string{
+		"device-123/" + date + "/logs/app.log.gz",
+		"device-123/" + date + "/logs/app-2.log.gz",
+		"device-123/" + date + "/logs/app-3.log.gz",
+		"device-123/" + date + "/logs/other.log.gz",
+	}, keys)
+
+	// the same file always gets the same key, e.g. when resumed
+	key, err := mu.templateKey(options, paths[1], paths[1])
+	assertNoError(t, err)
+	assertEquals(t, keys[1], key)
+
+	_, err = mu.templateKey(map[string]string{uploaders.ObjectKeyTemplate: "{unknown}/{filename}"},
+		"/var/log/new.log", "/var/log/new.log")
+	assertError(t, err)
+}
+
 func TestProvidersErrors(t *testing.T) {
 	us := NewUploads()
-	ids := us.AddMulti("testUID", []string{"test.txt"}, false, false, "", nil)
+	ids := us.AddMultiWithConfig("testUID", []string{"test.txt"}, &UploadableConfig{}, nil)
 
 	u := us.Get(ids[0])
 
@@ -438,6 +1536,42 @@ func startTestServer(t *testing.T, delay time.Duration, secure bool) *httptest.S
 	return httptest.NewServer(handler)
 }
 
+type receivedRequest struct {
+	headers http.Header
+	body    []byte
+}
+
+type receivedRequests struct {
+	mutex    sync.Mutex
+	requests []receivedRequest
+}
+
+func (r *receivedRequests) get() []receivedRequest {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]receivedRequest{}, r.requests...)
+}
+
+func startRecordingServer(t *testing.T) (*httptest.Server, *receivedRequests) {
+	received := &receivedRequests{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Log(err)
+		}
+
+		received.mutex.Lock()
+		defer received.mutex.Unlock()
+		received.requests = append(received.requests, receivedRequest{r.Header, body})
+	}))
+
+	return server, received
+}
+
 func startUploads(t *testing.T, us *Uploads, ids []string, url string) {
 	for _, id := range ids {
 
@@ -510,3 +1644,94 @@ func report(err error) {
 		log.Println(err)
 	}
 }
+
+func TestRetryAfter(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	requests := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		if atomic.AddInt32(&requests, 1)%2 == 1 { // every other request is throttled
+			w.Header().Set("Retry-After", "10")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	upload := func(cfg *UploadableConfig) *TestStatusListener {
+		l := NewTestStatusListener(t)
+		us := NewUploads()
+		ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+		startUploads(t, us, ids, server.URL)
+		l.waitFinish()
+
+		return l
+	}
+
+	start := time.Now()
+	upload(&UploadableConfig{RetryAfterAttempts: 1, MaxRetryAfter: Duration(300 * time.Millisecond)}).assertStatusState(StateSuccess)
+	assertEquals(t, int32(2), atomic.LoadInt32(&requests))
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond || elapsed > 5*time.Second {
+		t.Errorf("retry expected after the maximum delay of 300ms, but the upload took %v", elapsed)
+	}
+
+	upload(&UploadableConfig{}).assertStatusState(StateFailed) // re-attempts disabled
+	assertEquals(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestMaxRetryDuration(t *testing.T) {
+	files := createTestFiles(t, 1, false, false)
+	defer cleanFiles(files)
+
+	requests := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "10") // always throttled
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	l := NewTestStatusListener(t)
+	us := NewUploads()
+	cfg := &UploadableConfig{RetryAfterAttempts: 100, MaxRetryAfter: Duration(100 * time.Millisecond),
+		MaxRetryDuration: Duration(350 * time.Millisecond)}
+	ids := us.AddMultiWithConfig("testUID", getPaths(files), cfg, l)
+
+	start := time.Now()
+	startUploads(t, us, ids, server.URL)
+	l.waitFinish()
+
+	l.assertStatusState(StateFailed)
+	if n := atomic.LoadInt32(&requests); n < 2 || n > 4 { // the retries stop, although attempts remain
+		t.Errorf("retries expected to stop after the maximum retry duration, but got %d requests", n)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("retries expected to stop after the maximum retry duration of 350ms, but the upload took %v", elapsed)
+	}
+
+	// the fallback providers are not attempted, once the maximum retry duration is exceeded
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer slow.Close()
+
+	sink := t.TempDir()
+	cfg = &UploadableConfig{FallbackProviders: StorageProviders{uploaders.StorageProviderFile},
+		MaxRetryDuration: Duration(100 * time.Millisecond)}
+	l = startFallbackUpload(t, NewUploads(), files, cfg, slow.URL, sink)
+	l.waitFinish()
+	l.assertStatusState(StateFailed)
+
+	if _, err := os.Stat(filepath.Join(sink, filepath.Base(files[0].Name()))); !os.IsNotExist(err) {
+		t.Errorf("file not expected to be uploaded to the fallback provider: %v", err)
+	}
+
+	cfg.MaxRetryDuration = 0
+	l = startFallbackUpload(t, NewUploads(), files, cfg, slow.URL, sink)
+	l.waitFinish()
+	l.assertStatusState(StateSuccess)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errXattrUnsupported = errors.New("extended file attributes are not supported on this platform")
+
+// fileAttribute selects the files with an extended attribute, optionally with a specific value
+type fileAttribute struct {
+	name  string
+	value []byte // nil matches any value
+}
+
+// parseFileAttribute parses the attribute selector in 'name=value' or 'name' format, nil if empty
+func parseFileAttribute(selector string) (*fileAttribute, error) {
+	if selector == "" {
+		return nil, nil
+	}
+
+	if !xattrSupported {
+		return nil, errXattrUnsupported
+	}
+
+	parts := strings.SplitN(selector, "=", 2)
+	name := strings.TrimSpace(parts[0])
+	if name == "" {
+		return nil, fmt.Errorf("invalid file attribute '%s' - attribute name expected", selector)
+	}
+
+	attribute := &fileAttribute{name: name}
+	if len(parts) == 2 {
+		attribute.value = []byte(parts[1])
+	}
+
+	return attribute, nil
+}
+
+// matches returns true, if the file with the given path has the attribute with the expected value
+func (a *fileAttribute) matches(path string) (bool, error) {
+	value, ok, err := getXattr(path, a.name)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return a.value == nil || bytes.Equal(value, a.value), nil
+}
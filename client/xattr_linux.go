@@ -0,0 +1,47 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package client
+
+import (
+	"errors"
+	"syscall"
+)
+
+const xattrSupported = true
+
+// getXattr returns the value of the extended attribute of the file with the given path and false, if not present
+func getXattr(path string, name string) ([]byte, bool, error) {
+	for {
+		size, err := syscall.Getxattr(path, name, nil)
+		if errors.Is(err, syscall.ENODATA) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		value := make([]byte, size)
+		n, err := syscall.Getxattr(path, name, value)
+		if errors.Is(err, syscall.ERANGE) {
+			continue // the value grew in between
+		}
+		if errors.Is(err, syscall.ENODATA) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		return value[:n], true, nil
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package client
+
+import (
+	"errors"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func setXattr(t *testing.T, path string, name string, value string) {
+	t.Helper()
+
+	if err := syscall.Setxattr(path, name, []byte(value), 0); err != nil {
+		if errors.Is(err, syscall.ENOTSUP) || errors.Is(err, syscall.EPERM) {
+			t.Skipf("extended attributes not supported by the test file system: %v", err)
+		}
+		t.Fatal(err)
+	}
+}
+
+func TestFileAttributeSelection(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	a, b, c, d := getTestFiles(t)
+	setXattr(t, a, "user.upload", "yes")
+	setXattr(t, b, "user.upload", "no")
+	setXattr(t, c, "user.other", "yes")
+
+	f, client := newConnectedFileUpload(t, filepath.Join(basedir, "*.*"), ModeStrict)
+	defer f.Disconnect()
+
+	f.uploadable.cfg.FileAttribute = "user.upload=yes"
+	assertNoError(t, f.DoTrigger("taggedCorrelationID", nil))
+	assertEquals(t, a, getFileFromMsg(t, client.liveMsg(t, request)))
+	client.assertLiveEmpty(t)
+
+	f.uploadable.cfg.FileAttribute = "user.upload"
+	assertEquals(t, []string{a, b}, f.selectFiles([]string{a, b, c, d}))
+
+	f.uploadable.cfg.FileAttribute = "user.upload="
+	assertEquals(t, []string{}, f.selectFiles([]string{a, b, c, d}))
+}
+
+func TestParseFileAttribute(t *testing.T) {
+	attribute, err := parseFileAttribute("")
+	assertNoError(t, err)
+	assertEquals(t, (*fileAttribute)(nil), attribute)
+
+	attribute, err = parseFileAttribute("user.upload=yes=no")
+	assertNoError(t, err)
+	assertEquals(t, &fileAttribute{"user.upload", []byte("yes=no")}, attribute)
+
+	attribute, err = parseFileAttribute("user.upload")
+	assertNoError(t, err)
+	assertEquals(t, &fileAttribute{name: "user.upload"}, attribute)
+
+	_, err = parseFileAttribute("=yes")
+	assertError(t, err)
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/eclipse-kanto/file-upload/client"
 	"github.com/eclipse-kanto/file-upload/logger"
+	"github.com/eclipse-kanto/file-upload/uploaders"
 )
 
 // Flag names and default values
@@ -36,13 +37,16 @@ const (
 	Files = "files"
 )
 
+// EnvPrefix is the prefix of the environment variables, which override the configuration values
+const EnvPrefix = "FILE_UPLOAD_"
+
 // UploadConfig describes config of uploadable feature
 type UploadConfig struct {
 	client.BrokerConfig
 	client.UploadableConfig
 	logger.LogConfig
 
-	Files string            `json:"files,omitempty" descr:"Glob pattern for the files to upload"`
+	Files string            `json:"files,omitempty" descr:"Glob pattern for the files to upload. Use '**' to match files in all nested directories, e.g. '/var/log/**/*.log'. Multiple patterns can be specified, separated by the OS-specific path list separator (':' on Linux, ';' on Windows)"`
 	Mode  client.AccessMode `json:"mode,omitempty" def:"strict" descr:"{mode}"`
 }
 
@@ -65,21 +69,36 @@ type ConfigFileMissing error
 func (cfg *UploadConfig) Validate() {
 	if cfg.Files == "" {
 		if cfg.Mode != client.ModeLax {
-			log.Fatalln("Files glob not specified. To permit unrestricted file upload set 'mode' property to 'lax'.")
+			log.Fatalln("Files glob not specified. To permit unrestricted file upload (or upload of any files from the files list) set 'mode' property to 'lax'.")
 		}
 	} else {
-		_, err := filepath.Glob(cfg.Files)
-		if err != nil {
-			log.Fatalln(err)
+		for _, glob := range filepath.SplitList(cfg.Files) {
+			if err := client.ValidateGlob(glob); err != nil {
+				log.Fatalln(err)
+			}
 		}
 	}
 	if (len(cfg.Cert) == 0) != (len(cfg.Key) == 0) {
 		log.Fatalln("Either both client MQTT certificate and key must be set or none of them.")
 	}
+	if cfg.KeepAlive <= 0 || cfg.ConnectRetryInterval <= 0 || cfg.MaxReconnectInterval <= 0 {
+		log.Fatalln("MQTT 'keepAlive', 'connectRetryInterval' and 'maxReconnectInterval' should be larger than zero")
+	}
+	if cfg.WillQoS < 0 || cfg.WillQoS > 2 {
+		log.Fatalf("Unsupported MQTT last will QoS %d - allowed values are 0, 1 and 2", cfg.WillQoS)
+	}
+	if _, _, err := uploaders.ParseTLSVersions(cfg.BrokerTLSMinVersion, cfg.BrokerTLSMaxVersion); err != nil {
+		log.Fatalf("Invalid 'brokerTlsMinVersion' or 'brokerTlsMaxVersion': %v", err)
+	}
+	if _, err := uploaders.ParseCipherSuites(cfg.BrokerTLSCipherSuites.String()); err != nil {
+		log.Fatalf("Invalid 'brokerTlsCipherSuites': %v", err)
+	}
 	cfg.UploadableConfig.Validate()
 }
 
-// ParseFlags parses the CLI flags and generates an upload file configuration
+// ParseFlags parses the CLI flags and generates an upload file configuration. The configuration values are taken
+// from the CLI flags, the 'FILE_UPLOAD_' prefixed environment variables, the configuration file and the defaults,
+// in this order of precedence. The configuration file can also be specified with FILE_UPLOAD_CONFIG_FILE.
 func ParseFlags(version string) (*UploadConfig, ConfigFileMissing) {
 
 	flagsConfig := &UploadConfig{}
@@ -95,8 +114,16 @@ func ParseFlags(version string) (*UploadConfig, ConfigFileMissing) {
 		os.Exit(0)
 	}
 
+	file := *configFile
+	if file == "" {
+		file = os.Getenv(EnvPrefix + ToEnvName(ConfigFile))
+	}
+
 	config := &UploadConfig{}
-	warn := LoadConfigFromFile(*configFile, config, ConfigNames, nil)
+	warn := LoadConfigFromFile(file, config, ConfigNames, nil)
+	if err := ApplyEnv(config, EnvPrefix, nil); err != nil {
+		log.Fatalf("Error reading environment variables: %v", err)
+	}
 	ApplyFlags(config, *flagsConfig)
 
 	return config, warn
@@ -155,20 +182,7 @@ func LoadConfigFromFile(configFile string, cfg interface{}, names map[string]str
 func initConfigValues(valueOfConfig reflect.Value, names map[string]string, skip map[string]bool, flagIt bool) {
 	r := getReplacer(names)
 
-	typeOfConfig := valueOfConfig.Type()
-	numFields := typeOfConfig.NumField()
-	for i := 0; i < numFields; i++ {
-		fieldType := typeOfConfig.Field(i)
-		argName := ToFlagName(fieldType.Name)
-
-		if skip != nil && skip[argName] {
-			continue
-		}
-
-		if !fieldType.IsExported() {
-			continue
-		}
-
+	walkConfigFields(valueOfConfig, skip, func(fieldType reflect.StructField, fieldValue reflect.Value, argName string) {
 		defaultValue := fieldType.Tag.Get("def")
 		description := fieldType.Tag.Get("descr")
 
@@ -177,7 +191,6 @@ func initConfigValues(valueOfConfig reflect.Value, names map[string]string, skip
 			description = r.Replace(description)
 		}
 
-		fieldValue := valueOfConfig.FieldByName(fieldType.Name)
 		pointer := fieldValue.Addr().Interface()
 
 		switch val := fieldValue.Interface(); val.(type) {
@@ -208,9 +221,7 @@ func initConfigValues(valueOfConfig reflect.Value, names map[string]string, skip
 				fieldValue.SetInt(int64(defaultIntValue))
 			}
 		default:
-			v, ok := pointer.(flag.Value)
-
-			if ok {
+			if v, ok := pointer.(flag.Value); ok {
 				if flagIt {
 					flag.Var(v, argName, description)
 				} else if err := v.Set(defaultValue); err == nil {
@@ -218,13 +229,96 @@ func initConfigValues(valueOfConfig reflect.Value, names map[string]string, skip
 				} else {
 					log.Printf("Error parsing argument %v with value %v - %v", fieldType.Name, defaultValue, err)
 				}
-			} else if fieldType.Type.Kind() == reflect.Struct {
-				initConfigValues(fieldValue, names, skip, flagIt)
 			}
 		}
+	})
+}
+
+// walkConfigFields calls visit for the exported fields of the config structure, which are not skipped, along with
+// their flag names. Nested structures, which are not flag values, are walked recursively.
+func walkConfigFields(valueOfConfig reflect.Value, skip map[string]bool,
+	visit func(fieldType reflect.StructField, fieldValue reflect.Value, argName string)) {
+	typeOfConfig := valueOfConfig.Type()
+	numFields := typeOfConfig.NumField()
+	for i := 0; i < numFields; i++ {
+		fieldType := typeOfConfig.Field(i)
+		argName := ToFlagName(fieldType.Name)
+
+		if skip != nil && skip[argName] {
+			continue
+		}
+
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		fieldValue := valueOfConfig.FieldByName(fieldType.Name)
+		if _, ok := fieldValue.Addr().Interface().(flag.Value); !ok && fieldType.Type.Kind() == reflect.Struct {
+			walkConfigFields(fieldValue, skip, visit)
+			continue
+		}
+
+		visit(fieldType, fieldValue, argName)
 	}
 }
 
+// ApplyEnv applies the values of the environment variables with the given prefix over the config values.
+// The variable names are derived from the configuration property names, e.g. 'FILE_UPLOAD_BROKER' for 'broker'
+// and 'FILE_UPLOAD_MAX_FILE_SIZE' for 'maxFileSize' with 'FILE_UPLOAD_' prefix. The values are specified like
+// the command-line flag values. The 'cfg' parameter should be a pointer to structure.
+// The 'skip' parameter lists the flag names, that should not be parsed from the environment.
+func ApplyEnv(cfg interface{}, prefix string, skip map[string]bool) error {
+	var result error
+	walkConfigFields(reflect.ValueOf(cfg).Elem(), skip, func(fieldType reflect.StructField, fieldValue reflect.Value, argName string) {
+		name := prefix + ToEnvName(configPropertyName(fieldType, argName))
+
+		value, ok := os.LookupEnv(name)
+		if !ok || result != nil {
+			return
+		}
+
+		if err := setConfigValue(fieldValue, value); err != nil {
+			result = fmt.Errorf("invalid value '%s' of environment variable %s: %v", value, name, err)
+		}
+	})
+
+	return result
+}
+
+// configPropertyName returns the name of the field in the configuration file, or its flag name, if not specified
+func configPropertyName(fieldType reflect.StructField, argName string) string {
+	name := strings.Split(fieldType.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return argName
+	}
+
+	return name
+}
+
+// setConfigValue parses the given value like the command-line flag of the config field and sets it to the field
+func setConfigValue(fieldValue reflect.Value, value string) error {
+	switch pointer := fieldValue.Addr().Interface().(type) {
+	case *string:
+		*pointer = value
+	case *bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*pointer = v
+	case *int:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*pointer = v
+	case flag.Value:
+		return pointer.Set(value)
+	}
+
+	return nil
+}
+
 func getReplacer(names map[string]string) *strings.Replacer {
 	if names == nil {
 		return nil
@@ -262,6 +356,21 @@ func ToFieldName(s string) string {
 	return string(rn)
 }
 
+// ToEnvName converts configuration property name to environment variable name without prefix,
+// e.g. 'MAX_FILE_SIZE' for 'maxFileSize'
+func ToEnvName(s string) string {
+	rn := []rune(s)
+	var b strings.Builder
+	for i, r := range rn {
+		if i > 0 && unicode.IsUpper(r) &&
+			(!unicode.IsUpper(rn[i-1]) || (i+1 < len(rn) && unicode.IsLower(rn[i+1]))) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
 // ToFlagName converts config structure field name to command-line flag name
 func ToFlagName(s string) string {
 	s = replaceSuffix(s, "ID", "Id")
@@ -15,7 +15,9 @@ package flags_test
 import (
 	"os"
 	"testing"
+	"time"
 
+	"github.com/eclipse-kanto/file-upload/client"
 	flags "github.com/eclipse-kanto/file-upload/flagparse"
 	. "github.com/eclipse-kanto/file-upload/flagparsetest"
 )
@@ -88,6 +90,78 @@ func TestConfigFileNotExist(t *testing.T) {
 	parseAndVerify(expected, t, true)
 }
 
+func TestEnv(t *testing.T) {
+	ResetFlags()
+
+	t.Setenv("FILE_UPLOAD_CONFIG_FILE", testConfigFile)
+	t.Setenv("FILE_UPLOAD_BROKER", "envBroker")
+	t.Setenv("FILE_UPLOAD_USERNAME", "envUsername")
+	t.Setenv("FILE_UPLOAD_FEATURE_ID", "envId")
+	t.Setenv("FILE_UPLOAD_DELETE", "false")
+	t.Setenv("FILE_UPLOAD_WILL_QOS", "1")
+	t.Setenv("FILE_UPLOAD_EXCLUDE_FILES", "*.bak, *.old")
+	t.Setenv("FILE_UPLOAD_STOP_TIMEOUT", "1m")
+
+	PassArgs(Arg{Name: "username", Value: "cliUsername"})
+
+	expected := testConfig
+	expected.Broker = "envBroker"
+	expected.Username = "cliUsername" // CLI flags take precedence over the environment
+	expected.FeatureID = "envId"
+	expected.Delete = false
+	expected.WillQoS = 1
+	expected.ExcludeFiles = client.Globs{"*.bak", "*.old"}
+	expected.StopTimeout = client.Duration(time.Minute)
+
+	parseAndVerify(&expected, t, false)
+}
+
+func TestEnvDefaults(t *testing.T) {
+	ResetFlags()
+
+	t.Setenv("FILE_UPLOAD_FILES", "test")
+	t.Setenv("FILE_UPLOAD_LOG_LEVEL", "DEBUG")
+
+	PassArgs()
+
+	expected := getDefaultConfig()
+	expected.LogLevel = "DEBUG"
+
+	parseAndVerify(expected, t, false)
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	for name, value := range map[string]string{
+		"FILE_UPLOAD_DELETE":        "maybe",
+		"FILE_UPLOAD_GLOB_WORKERS":  "many",
+		"FILE_UPLOAD_STOP_TIMEOUT":  "soon",
+		"FILE_UPLOAD_EXCLUDE_FILES": "[",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+
+			if err := flags.ApplyEnv(&flags.UploadConfig{}, flags.EnvPrefix, nil); err == nil {
+				t.Errorf("Error expected for %s=%s", name, value)
+			}
+		})
+	}
+}
+
+func TestToEnvName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"broker":              "BROKER",
+		"maxFileSize":         "MAX_FILE_SIZE",
+		"featureId":           "FEATURE_ID",
+		"brokerTlsMinVersion": "BROKER_TLS_MIN_VERSION",
+		"TLSMinVersion":       "TLS_MIN_VERSION",
+		"EndpointHealthTTL":   "ENDPOINT_HEALTH_TTL",
+	} {
+		if actual := flags.ToEnvName(name); actual != expected {
+			t.Errorf("Expected environment variable name %s for %s, but was %s", expected, name, actual)
+		}
+	}
+}
+
 func getDefaultConfig() *flags.UploadConfig {
 	cfg := &flags.UploadConfig{}
 
@@ -4,18 +4,25 @@ go 1.17
 
 require (
 	github.com/Azure/azure-sdk-for-go/sdk/storage/azblob v0.2.0
+	github.com/andybalholm/brotli v1.0.5
 	github.com/aws/aws-sdk-go-v2 v1.5.0
 	github.com/aws/aws-sdk-go-v2/config v1.2.0
 	github.com/aws/aws-sdk-go-v2/credentials v1.2.0
 	github.com/aws/aws-sdk-go-v2/feature/s3/manager v1.2.0
 	github.com/aws/aws-sdk-go-v2/service/s3 v1.7.0
+	github.com/aws/aws-sdk-go-v2/service/sts v1.4.0
 	github.com/aws/smithy-go v1.4.0
 	github.com/caarlos0/env/v6 v6.10.1
 	github.com/eclipse-kanto/kanto/integration/util v0.0.0-20221202134037-d46d274df5c4
 	github.com/eclipse/ditto-clients-golang v0.0.0-20220225085802-cf3b306280d3
 	github.com/eclipse/paho.mqtt.golang v1.4.1
+	github.com/fxamacker/cbor/v2 v2.5.0
 	github.com/google/uuid v1.3.0
+	github.com/klauspost/compress v1.15.15
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/stretchr/testify v1.8.1
+	golang.org/x/net v0.25.0
+	golang.org/x/text v0.15.0
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
 )
 
@@ -23,9 +30,9 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pkg/browser v0.0.0-20180916011732-0a3d74bf9ce4 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/crypto v0.23.0 // indirect
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
-	golang.org/x/text v0.15.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
@@ -39,9 +46,7 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/internal/presigned-url v1.1.0 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/s3shared v1.3.0 // indirect
 	github.com/aws/aws-sdk-go-v2/service/sso v1.2.0 // indirect
-	github.com/aws/aws-sdk-go-v2/service/sts v1.4.0 // indirect
 	github.com/google/go-cmp v0.5.6 // indirect
 	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
-	golang.org/x/net v0.25.0 // indirect
 )
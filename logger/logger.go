@@ -13,12 +13,15 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 )
@@ -30,6 +33,10 @@ type LogConfig struct {
 	LogFileSize   int    `json:"logFileSize,omitempty" def:"2" descr:"Log file size in MB before it gets rotated"`
 	LogFileCount  int    `json:"logFileCount,omitempty" def:"5" descr:"Log file max rotations count"`
 	LogFileMaxAge int    `json:"logFileMaxAge,omitempty" def:"28" descr:"Log file rotations max age in days"`
+	LogFormat     string `json:"logFormat,omitempty" def:"text" descr:"Log output format. Allowed values are:\n'text' - human readable lines\n'json' - JSON objects, one per line, with 'level', 'ts', 'msg' and 'component' fields"`
+
+	LogToStdout     bool `json:"logToStdout,omitempty" def:"false" descr:"Log to the standard output instead of the standard error, when no log file is specified"`
+	LogFileCompress bool `json:"logFileCompress" def:"true" descr:"Compress the rotated log files"`
 }
 
 // LogLevel - Error(1), Warn(2), Info(3), Debug(4) or Trace(5)
@@ -44,9 +51,17 @@ const (
 	TRACE
 )
 
+// Log output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 const (
 	logFlags int = log.Ldate | log.Ltime | log.Lmicroseconds | log.Lmsgprefix
 
+	jsonTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
 	ePrefix = "ERROR  "
 	wPrefix = "WARN   "
 	iPrefix = "INFO   "
@@ -59,11 +74,67 @@ const (
 var (
 	logger *log.Logger
 	level  LogLevel
+
+	hooks      []*hookEntry
+	hooksMutex sync.RWMutex
 )
 
+// Hook receives the logged messages, without the level prefix and the timestamp, e.g. to capture them.
+// Hooks receive the error, warning and info messages regardless of the log level and the debug and trace messages,
+// if enabled by the log level.
+type Hook func(level LogLevel, msg string)
+
+type hookEntry struct {
+	hook Hook
+}
+
+// AddHook registers the given hook and returns a function, which removes it
+func AddHook(hook Hook) func() {
+	entry := &hookEntry{hook}
+
+	hooksMutex.Lock()
+	hooks = append(hooks, entry)
+	hooksMutex.Unlock()
+
+	return func() {
+		hooksMutex.Lock()
+		defer hooksMutex.Unlock()
+
+		for i, e := range hooks {
+			if e == entry {
+				hooks = append(hooks[:i:i], hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notify passes the message, formatted on demand, to the registered hooks
+func notify(level LogLevel, msg func() string) {
+	hooksMutex.RLock()
+	defer hooksMutex.RUnlock()
+
+	if len(hooks) == 0 {
+		return
+	}
+
+	formatted := msg()
+	for _, e := range hooks {
+		e.hook(level, formatted)
+	}
+}
+
 // SetupLogger initializes logger with the provided configuration
 func SetupLogger(logConfig *LogConfig, componentPrefix string) (io.WriteCloser, error) {
+	format := strings.ToLower(logConfig.LogFormat)
+	if format != "" && format != FormatText && format != FormatJSON {
+		return nil, fmt.Errorf("unsupported log format '%s' - allowed values are '%s' and '%s'", logConfig.LogFormat, FormatText, FormatJSON)
+	}
+
 	loggerOut := io.WriteCloser(&nopWriterCloser{out: os.Stderr})
+	if logConfig.LogToStdout {
+		loggerOut = &nopWriterCloser{out: os.Stdout}
+	}
 	if len(logConfig.LogFile) > 0 {
 		err := os.MkdirAll(filepath.Dir(logConfig.LogFile), 0755)
 
@@ -77,14 +148,18 @@ func SetupLogger(logConfig *LogConfig, componentPrefix string) (io.WriteCloser,
 			MaxBackups: logConfig.LogFileCount,
 			MaxAge:     logConfig.LogFileMaxAge,
 			LocalTime:  true,
-			Compress:   true,
+			Compress:   logConfig.LogFileCompress,
 		}
 	}
 
 	log.SetOutput(loggerOut)
 	log.SetFlags(logFlags)
 
-	logger = log.New(loggerOut, fmt.Sprintf(prefix, componentPrefix), logFlags)
+	if format == FormatJSON {
+		logger = log.New(&jsonWriter{out: loggerOut, component: componentPrefix}, "", 0)
+	} else {
+		logger = log.New(loggerOut, fmt.Sprintf(prefix, componentPrefix), logFlags)
+	}
 
 	// Parse log level
 	switch strings.ToUpper(logConfig.LogLevel) {
@@ -108,6 +183,7 @@ func Error(v interface{}) {
 	if level >= ERROR {
 		logger.Println(ePrefix, v)
 	}
+	notify(ERROR, func() string { return fmt.Sprint(v) })
 }
 
 // Errorf logs the given formatted message, if level is >= ERROR
@@ -115,6 +191,7 @@ func Errorf(format string, v ...interface{}) {
 	if level >= ERROR {
 		logger.Println(fmt.Errorf(fmt.Sprint(ePrefix, " ", format), v...))
 	}
+	notify(ERROR, func() string { return fmt.Errorf(format, v...).Error() })
 }
 
 // Warn logs the given value, if level is >= WARN
@@ -122,6 +199,7 @@ func Warn(v interface{}) {
 	if level >= WARN {
 		logger.Println(wPrefix, v)
 	}
+	notify(WARN, func() string { return fmt.Sprint(v) })
 }
 
 // Warnf logs the given formatted message, if level is >= WARN
@@ -129,6 +207,7 @@ func Warnf(format string, v ...interface{}) {
 	if level >= WARN {
 		logger.Printf(fmt.Sprint(wPrefix, " ", format), v...)
 	}
+	notify(WARN, func() string { return fmt.Sprintf(format, v...) })
 }
 
 // Info logs the given value, if level is >= INFO
@@ -136,6 +215,7 @@ func Info(v interface{}) {
 	if level >= INFO {
 		logger.Println(iPrefix, v)
 	}
+	notify(INFO, func() string { return fmt.Sprint(v) })
 }
 
 // Infof logs the given formatted message, if level is >= INFO
@@ -143,12 +223,14 @@ func Infof(format string, v ...interface{}) {
 	if level >= INFO {
 		logger.Printf(fmt.Sprint(iPrefix, " ", format), v...)
 	}
+	notify(INFO, func() string { return fmt.Sprintf(format, v...) })
 }
 
 // Debug logs the given value, if level is >= DEBUG
 func Debug(v interface{}) {
 	if IsDebugEnabled() {
 		logger.Println(dPrefix, v)
+		notify(DEBUG, func() string { return fmt.Sprint(v) })
 	}
 }
 
@@ -156,6 +238,7 @@ func Debug(v interface{}) {
 func Debugf(format string, v ...interface{}) {
 	if IsDebugEnabled() {
 		logger.Printf(fmt.Sprint(dPrefix, " ", format), v...)
+		notify(DEBUG, func() string { return fmt.Sprintf(format, v...) })
 	}
 }
 
@@ -163,6 +246,7 @@ func Debugf(format string, v ...interface{}) {
 func Trace(v ...interface{}) {
 	if IsTraceEnabled() {
 		logger.Println(tPrefix, fmt.Sprint(v...))
+		notify(TRACE, func() string { return fmt.Sprint(v...) })
 	}
 }
 
@@ -170,6 +254,7 @@ func Trace(v ...interface{}) {
 func Tracef(format string, v ...interface{}) {
 	if IsTraceEnabled() {
 		logger.Printf(fmt.Sprint(tPrefix, " ", format), v...)
+		notify(TRACE, func() string { return fmt.Sprintf(format, v...) })
 	}
 }
 
@@ -183,6 +268,45 @@ func IsTraceEnabled() bool {
 	return level >= TRACE
 }
 
+// jsonWriter writes each log entry as a JSON object line. The level is taken from the level prefix of the entry.
+type jsonWriter struct {
+	out       io.Writer
+	component string
+}
+
+type jsonEntry struct {
+	Level     string `json:"level"`
+	Timestamp string `json:"ts"`
+	Message   string `json:"msg"`
+	Component string `json:"component,omitempty"`
+}
+
+// Write converts the log entry to JSON and writes it to the log output
+func (w *jsonWriter) Write(p []byte) (n int, err error) {
+	entry := jsonEntry{Timestamp: time.Now().Format(jsonTimeLayout), Component: w.component}
+
+	msg := strings.TrimSuffix(string(p), "\n")
+	for _, levelPrefix := range []string{ePrefix, wPrefix, iPrefix, dPrefix, tPrefix} {
+		if strings.HasPrefix(msg, levelPrefix) {
+			entry.Level = strings.TrimSpace(levelPrefix)
+			msg = strings.TrimLeft(msg[len(levelPrefix):], " ")
+			break
+		}
+	}
+	entry.Message = msg
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = w.out.Write(append(data, '\n')); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
 type nopWriterCloser struct {
 	out io.Writer
 }
@@ -14,11 +14,15 @@ package logger
 
 import (
 	"bufio"
+	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // TestLogLevelError tests logger functions with log level set to ERROR.
@@ -72,6 +76,131 @@ func TestNopWriter(t *testing.T) {
 	}
 }
 
+// TestJSONFormat tests logger functions with JSON log format.
+func TestJSONFormat(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "json.log")
+	loggerOut, err := SetupLogger(&LogConfig{LogFile: log, LogLevel: "DEBUG", LogFileSize: 2, LogFileCount: 5, LogFormat: FormatJSON}, "[FILE UPLOAD]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loggerOut.Close()
+
+	Error("error log")
+	Warnf("warn log [%v,%s]", "param1", "param2")
+	Info("info log")
+	Debugf("debug log [%d]", 1)
+	Trace("trace log") // not enabled
+
+	file, err := os.Open(log)
+	if err != nil {
+		t.Fatalf("fail to open log file: %v", err)
+	}
+	defer file.Close()
+
+	var entries []map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := make(map[string]string)
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid JSON log line '%s': %v", scanner.Text(), err)
+		}
+		if _, err := time.Parse(jsonTimeLayout, entry["ts"]); err != nil {
+			t.Errorf("invalid log timestamp '%s': %v", entry["ts"], err)
+		}
+		delete(entry, "ts")
+		entries = append(entries, entry)
+	}
+
+	expected := []map[string]string{
+		{"level": "ERROR", "msg": "error log", "component": "[FILE UPLOAD]"},
+		{"level": "WARN", "msg": "warn log [param1,param2]", "component": "[FILE UPLOAD]"},
+		{"level": "INFO", "msg": "info log", "component": "[FILE UPLOAD]"},
+		{"level": "DEBUG", "msg": "debug log [1]", "component": "[FILE UPLOAD]"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d log entries, but were %v", len(expected), entries)
+	}
+	for i := range expected {
+		for k, v := range expected[i] {
+			if entries[i][k] != v {
+				t.Errorf("expected '%s' to be '%s' in log entry %d, but was '%s'", k, v, i, entries[i][k])
+			}
+		}
+	}
+}
+
+// TestUnsupportedFormat tests logger setup with unsupported log format.
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := SetupLogger(&LogConfig{LogLevel: "INFO", LogFormat: "xml"}, "[FILE UPLOAD]"); err == nil {
+		t.Error("unsupported log format error expected")
+	}
+}
+
+// TestLogOutput tests logger setup with all combinations of log file, standard output and log file compression.
+func TestLogOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, tc := range []struct {
+		logFile  bool
+		stdout   bool
+		compress bool
+	}{
+		{false, false, false},
+		{false, true, false},
+		{true, false, true},
+		{true, false, false},
+		{true, true, true},
+		{true, true, false},
+	} {
+		stderr, stdout := os.Stderr, os.Stdout
+		os.Stderr = createOutput(t, filepath.Join(dir, "stderr"))
+		os.Stdout = createOutput(t, filepath.Join(dir, "stdout"))
+
+		cfg := &LogConfig{LogLevel: "INFO", LogToStdout: tc.stdout, LogFileCompress: tc.compress}
+		if tc.logFile {
+			cfg.LogFile = filepath.Join(dir, "upload.log")
+		}
+		loggerOut, err := SetupLogger(cfg, "[FILE UPLOAD]")
+		os.Stderr, os.Stdout = stderr, stdout
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		Info("output log")
+		loggerOut.Close()
+
+		expected := filepath.Join(dir, "stderr")
+		if tc.logFile {
+			expected = cfg.LogFile
+			if rotated, ok := loggerOut.(*lumberjack.Logger); !ok || rotated.Compress != tc.compress {
+				t.Errorf("expected rotating log file with compress %v, but was %v", tc.compress, loggerOut)
+			}
+		} else if tc.stdout {
+			expected = filepath.Join(dir, "stdout")
+		}
+
+		for _, name := range []string{"stderr", "stdout", "upload.log"} {
+			path := filepath.Join(dir, name)
+			data, _ := os.ReadFile(path)
+			if logged := strings.Contains(string(data), "output log"); logged != (path == expected) {
+				t.Errorf("%+v: unexpected log output in %s: '%s'", tc, name, data)
+			}
+			os.Remove(path)
+		}
+	}
+}
+
+func createOutput(t *testing.T, path string) *os.File {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	return f
+}
+
 func validate(lvl string, hasError bool, hasWarn bool, hasInfo bool, hasDebug bool, hasTrace bool, t *testing.T) {
 	// Prepare
 	dir := "_tmp-logger"
@@ -204,3 +333,37 @@ func has(s string, substrs ...string) bool {
 	}
 	return true
 }
+
+// TestHooks tests that hooks receive the logged messages.
+func TestHooks(t *testing.T) {
+	dir := "_tmp-logger"
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	loggerOut, err := SetupLogger(&LogConfig{LogFile: filepath.Join(dir, "hooks.log"), LogLevel: "WARN", LogFileSize: 2, LogFileCount: 5}, "[FILE UPLOAD]")
+	if err != nil {
+		t.Fatalf("cannot initialize logger: %v", err)
+	}
+	defer loggerOut.Close()
+
+	var received []string
+	remove := AddHook(func(level LogLevel, msg string) {
+		received = append(received, msg)
+	})
+
+	Errorf("error %s", "message")
+	Warn("warning message")
+	Infof("info %d", 1) // info messages are passed to hooks regardless of the log level
+	Debug("debug message")
+	Trace("trace message")
+
+	remove()
+	Error("removed")
+
+	expected := []string{"error message", "warning message", "info 1"}
+	if strings.Join(received, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected hook messages %v, but were %v", expected, received)
+	}
+}
@@ -46,9 +46,11 @@ func main() {
 	logger.Infof("files glob: '%s', mode: '%s'", config.Files, config.Mode)
 
 	if logger.IsDebugEnabled() && config.Files != "" {
-		//no err expected it's already validated
-		files, _ := filepath.Glob(config.Files)
-		logger.Debugf("Files matching glob filter '%s': %v\n", config.Files, files)
+		for _, glob := range filepath.SplitList(config.Files) {
+			//no err expected it's already validated
+			files, _ := client.Glob(glob)
+			logger.Debugf("Files matching glob filter '%s': %v\n", glob, files)
+		}
 	}
 
 	chstop := make(chan os.Signal, 1)
@@ -66,7 +68,20 @@ func main() {
 		panic(err)
 	}
 
-	defer p.Close()
-
 	<-chstop
+
+	logger.Info("stopping - waiting for the running uploads to complete, signal again to cancel them")
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-chstop:
+		logger.Info("canceling the running uploads")
+		uploadable.ForceStop()
+		<-closed
+	}
 }
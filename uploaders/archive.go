@@ -0,0 +1,273 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Supported archive modes
+const (
+	ArchiveNone  = "none"
+	ArchiveTar   = "tar"
+	ArchiveTarGz = "tar.gz"
+	ArchiveZip   = "zip"
+)
+
+var archiveExtensions = map[string]string{
+	ArchiveTar:   ".tar",
+	ArchiveTarGz: ".tar.gz",
+	ArchiveZip:   ".zip",
+}
+
+// ValidateArchiveMode returns error if the given archive mode is not supported
+func ValidateArchiveMode(mode string) error {
+	if _, ok := archiveExtensions[mode]; !ok && mode != ArchiveNone {
+		return fmt.Errorf("unsupported archive mode '%s'", mode)
+	}
+
+	return nil
+}
+
+// ArchiveExtension returns the file name extension for the given archive mode
+func ArchiveExtension(mode string) string {
+	return archiveExtensions[mode]
+}
+
+// ArchiveFiles bundles the given files with the specified mode into a temporary archive. The name of the archive
+// is the base of the given name. Each file is stored under its cleaned path, made relative to the file system root.
+// Callers are responsible for removing the directory of the returned archive path.
+func ArchiveFiles(files []string, mode string, name string) (string, error) {
+	entries := make([]archiveEntry, len(files))
+	for i, file := range files {
+		entries[i] = archiveEntry{path: file, name: ArchiveEntryName(file), size: -1}
+	}
+
+	return createArchive(entries, mode, name)
+}
+
+// ArchiveDirectory bundles the regular files from the directory tree, accepted by the given include function,
+// with the specified mode into a temporary archive, as a consistent snapshot of the directory. The files are listed,
+// with their sizes, before archiving - files added later are not included, content appended later is not archived
+// and files removed in the meantime are skipped. Archiving fails if a file is truncated in the meantime.
+// Each file is stored under its slash-separated path, relative to the directory. Returns the archive path and the
+// archived files. Callers are responsible for removing the directory of the returned archive path.
+func ArchiveDirectory(dir string, include func(path string) bool, mode string, name string) (string, []string, error) {
+	var entries []archiveEntry
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !include(path) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, archiveEntry{path: path, name: filepath.ToSlash(rel), size: info.Size(), snapshot: true})
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	archive, err := createArchive(entries, mode, name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.skipped {
+			files = append(files, entry.path)
+		}
+	}
+
+	return archive, files, nil
+}
+
+// archiveEntry is a file, stored in an archive under the given name. Snapshot entries have the size of the file
+// at the time of listing, while for other entries (with negative size) the size is taken when the file is opened.
+type archiveEntry struct {
+	path     string
+	name     string
+	size     int64
+	snapshot bool
+	skipped  bool // set if a snapshot entry is removed before being archived
+}
+
+func createArchive(entries []archiveEntry, mode string, name string) (string, error) {
+	if _, ok := archiveExtensions[mode]; !ok {
+		return "", fmt.Errorf("unsupported archive mode '%s'", mode)
+	}
+
+	dir, err := os.MkdirTemp("", "file-upload-")
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := os.Create(filepath.Join(dir, filepath.Base(name)))
+	if err == nil {
+		err = writeArchive(archive, entries, mode)
+
+		if closeErr := archive.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		os.RemoveAll(dir)
+
+		return "", err
+	}
+
+	return archive.Name(), nil
+}
+
+func writeArchive(dst io.Writer, entries []archiveEntry, mode string) error {
+	if mode == ArchiveZip {
+		return writeZip(dst, entries)
+	}
+
+	if mode == ArchiveTarGz {
+		gw := gzip.NewWriter(dst)
+		if err := writeTar(gw, entries); err != nil {
+			gw.Close()
+			return err
+		}
+		return gw.Close()
+	}
+
+	return writeTar(dst, entries)
+}
+
+func writeTar(dst io.Writer, entries []archiveEntry) error {
+	tw := tar.NewWriter(dst)
+
+	for i := range entries {
+		err := addArchiveEntry(&entries[i], func(info os.FileInfo, size int64) (io.Writer, error) {
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return nil, err
+			}
+			header.Name = entries[i].name
+			header.Size = size
+
+			return tw, tw.WriteHeader(header)
+		})
+
+		if err != nil {
+			tw.Close()
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+func writeZip(dst io.Writer, entries []archiveEntry) error {
+	zw := zip.NewWriter(dst)
+
+	for i := range entries {
+		err := addArchiveEntry(&entries[i], func(info os.FileInfo, size int64) (io.Writer, error) {
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return nil, err
+			}
+			header.Name = entries[i].name
+			header.Method = zip.Deflate
+
+			return zw.CreateHeader(header)
+		})
+
+		if err != nil {
+			zw.Close()
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// addArchiveEntry copies the file content to the writer, created for the file info and the archived size by the given
+// function. Only the content up to the entry size is archived, so data appended meanwhile does not corrupt the archive.
+func addArchiveEntry(entry *archiveEntry, create func(info os.FileInfo, size int64) (io.Writer, error)) error {
+	f, err := os.Open(entry.path)
+	if os.IsNotExist(err) && entry.snapshot {
+		entry.skipped = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("cannot archive '%s' - not a regular file", entry.path)
+	}
+
+	size := entry.size
+	if size < 0 {
+		size = info.Size()
+	}
+
+	w, err := create(info, size)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.CopyN(w, f, size); err == io.EOF {
+		return fmt.Errorf("cannot archive '%s' - file truncated while archiving", entry.path)
+	}
+
+	return err
+}
+
+// ArchiveEntryName returns the name, under which the given file is stored in an archive -
+// the cleaned slash-separated path, without volume name, leading separators and parent directory elements
+func ArchiveEntryName(file string) string {
+	name := filepath.ToSlash(filepath.Clean(strings.TrimPrefix(file, filepath.VolumeName(file))))
+	name = path.Clean("/" + name) // resolves leading parent directory elements
+
+	return strings.TrimPrefix(name, "/")
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package uploaders
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	contents := map[string]string{
+		"a.log":         "first file",
+		"logs/b.log":    "second file",
+		"logs/empty.db": "",
+	}
+
+	files := make([]string, 0, len(contents))
+	expected := make(map[string]string, len(contents))
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		assertNoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assertNoError(t, os.WriteFile(path, []byte(content), 0600))
+
+		files = append(files, path)
+		expected[ArchiveEntryName(path)] = content
+	}
+
+	for _, mode := range []string{ArchiveTar, ArchiveTarGz, ArchiveZip} {
+		name := "logs" + ArchiveExtension(mode)
+
+		path, err := ArchiveFiles(files, mode, "device/"+name)
+		assertNoError(t, err)
+		assertStringsSame(t, "archive name", name, filepath.Base(path))
+
+		actual := extractArchive(t, path, mode)
+		if len(actual) != len(expected) {
+			t.Fatalf("expected %d archived files, but were %d", len(expected), len(actual))
+		}
+		for entry, content := range expected {
+			assertStringsSame(t, entry, content, actual[entry])
+		}
+
+		assertNoError(t, os.RemoveAll(filepath.Dir(path)))
+	}
+}
+
+func TestArchiveFilesErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "a.log")
+	assertNoError(t, os.WriteFile(file, []byte("content"), 0600))
+
+	_, err := ArchiveFiles([]string{file}, ArchiveNone, "test")
+	assertError(t, err)
+
+	_, err = ArchiveFiles([]string{file}, "rar", "test.rar")
+	assertError(t, err)
+
+	_, err = ArchiveFiles([]string{file, filepath.Join(dir, "missing.log")}, ArchiveZip, "test.zip")
+	assertError(t, err)
+
+	_, err = ArchiveFiles([]string{dir}, ArchiveTar, "test.tar")
+	assertError(t, err)
+}
+
+func TestValidateArchiveMode(t *testing.T) {
+	for _, mode := range []string{ArchiveNone, ArchiveTar, ArchiveTarGz, ArchiveZip} {
+		assertNoError(t, ValidateArchiveMode(mode))
+	}
+
+	assertError(t, ValidateArchiveMode("tgz"))
+}
+
+func TestArchiveEntryName(t *testing.T) {
+	tests := map[string]string{
+		"/var/log/a.log":    "var/log/a.log",
+		"var/log/a.log":     "var/log/a.log",
+		"./var/log/a.log":   "var/log/a.log",
+		"../../var/a.log":   "var/a.log",
+		"/var/log/../a.log": "var/a.log",
+	}
+
+	for file, expected := range tests {
+		assertStringsSame(t, file, expected, ArchiveEntryName(filepath.FromSlash(file)))
+	}
+}
+
+func TestArchiveDirectorySnapshot(t *testing.T) {
+	for _, mode := range []string{ArchiveTar, ArchiveTarGz, ArchiveZip} {
+		t.Run(mode, func(t *testing.T) {
+			dir := t.TempDir()
+
+			appended := writeArchiveTestFile(t, dir, "a.log", "a content")
+			removed := writeArchiveTestFile(t, dir, "b.log", "b content")
+			writeArchiveTestFile(t, dir, "c.tmp", "c content")
+			nested := writeArchiveTestFile(t, dir, "sub/c.log", "nested content")
+
+			// modify the directory after all files are listed, but before they are archived
+			include := func(path string) bool {
+				if path == nested {
+					appendArchiveTestFile(t, appended, " appended")
+					assertNoError(t, os.Remove(removed))
+					writeArchiveTestFile(t, dir, "late.log", "late content")
+				}
+				return filepath.Ext(path) == ".log"
+			}
+
+			path, files, err := ArchiveDirectory(dir, include, mode, "snapshot"+ArchiveExtension(mode))
+			assertNoError(t, err)
+			defer os.RemoveAll(filepath.Dir(path))
+
+			assertDeepEquals(t, []string{appended, nested}, files)
+			assertDeepEquals(t, map[string]string{
+				"a.log":     "a content",
+				"sub/c.log": "nested content",
+			}, extractArchive(t, path, mode))
+		})
+	}
+}
+
+func TestArchiveDirectoryTruncated(t *testing.T) {
+	dir := t.TempDir()
+
+	file := writeArchiveTestFile(t, dir, "a.log", "a content")
+	include := func(path string) bool {
+		assertNoError(t, os.Truncate(file, 1))
+		return true
+	}
+
+	_, _, err := ArchiveDirectory(dir, include, ArchiveTar, "snapshot.tar")
+	assertError(t, err)
+}
+
+func TestArchiveDirectoryErrors(t *testing.T) {
+	include := func(path string) bool { return true }
+
+	_, _, err := ArchiveDirectory(filepath.Join(t.TempDir(), "missing"), include, ArchiveTar, "snapshot.tar")
+	assertError(t, err)
+
+	_, _, err = ArchiveDirectory(t.TempDir(), include, ArchiveNone, "snapshot")
+	assertError(t, err)
+}
+
+func writeArchiveTestFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	assertNoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	assertNoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	return path
+}
+
+func appendArchiveTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	assertNoError(t, err)
+	defer f.Close()
+
+	_, err = f.WriteString(content)
+	assertNoError(t, err)
+}
+
+// extractArchive returns the content of the archive files, mapped by their names
+func extractArchive(t *testing.T, path string, mode string) map[string]string {
+	t.Helper()
+
+	result := make(map[string]string)
+
+	if mode == ArchiveZip {
+		zr, err := zip.OpenReader(path)
+		assertNoError(t, err)
+		defer zr.Close()
+
+		for _, f := range zr.File {
+			r, err := f.Open()
+			assertNoError(t, err)
+			content, err := io.ReadAll(r)
+			assertNoError(t, err)
+			r.Close()
+
+			result[f.Name] = string(content)
+		}
+
+		return result
+	}
+
+	file, err := os.Open(path)
+	assertNoError(t, err)
+	defer file.Close()
+
+	var r io.Reader = file
+	if mode == ArchiveTarGz {
+		gr, err := gzip.NewReader(file)
+		assertNoError(t, err)
+		r = gr
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assertNoError(t, err)
+
+		content, err := io.ReadAll(tr)
+		assertNoError(t, err)
+
+		result[header.Name] = string(content)
+	}
+
+	return result
+}
@@ -13,15 +13,29 @@
 package uploaders
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/smithy-go/logging"
 	"github.com/eclipse-kanto/file-upload/logger"
 )
@@ -36,14 +50,60 @@ const (
 	AWSSessionToken    = "aws.session.token"
 	AWSBucket          = "aws.s3.bucket"
 	AWSObjectKey       = "aws.object.key"
+
+	AWSRoleARN         = "aws.role.arn"
+	AWSRoleExternalID  = "aws.role.external.id"
+	AWSRoleSessionName = "aws.role.session.name"
+
+	AWSObjectLockMode        = "aws.object.lock.mode"
+	AWSObjectLockRetainUntil = "aws.object.lock.retain.until"
+
+	AWSChecksumLocation = "aws.checksum.location"
+
+	AWSMultipartPartSize    = "aws.multipart.part.size"
+	AWSMultipartConcurrency = "aws.multipart.concurrency"
+	AWSMultipartLeaveParts  = "aws.multipart.leave.parts"
 )
 
+// Part sizes of AWS multipart uploads. Files, which fit in a single part, are uploaded with a single request,
+// so the maximum part size, which is also the maximum size of a single request upload, disables multipart uploads.
+const (
+	AWSMinPartSize = manager.MinUploadPartSize
+	AWSMaxPartSize = 5 << 30
+)
+
+// awsAbortTimeout limits the time for aborting an incomplete multipart upload, which is aborted regardless
+// of the cancellation of the upload context
+const awsAbortTimeout = 30 * time.Second
+
+// awsCredentialsExpiryWindow is the period before expiration, in which temporary credentials are refreshed
+const awsCredentialsExpiryWindow = time.Minute
+
 // AWSUploader handles upload to AWS S3 storage
 type AWSUploader struct {
 	bucket    string
 	objectKey string
+	keys      *ObjectKeyNamer // names the objects after the files, if no object key is specified
+
+	lockMode        types.ObjectLockMode
+	lockRetainUntil *time.Time
+
+	checksumLocation string
 
-	uploader *manager.Uploader
+	tags string // URL query encoded
+
+	client     *s3.Client
+	uploader   *manager.Uploader
+	leaveParts bool // the parts of incomplete multipart uploads are left in the bucket
+
+	credentials      *awsStaticCredentials
+	credentialsCache *aws.CredentialsCache
+}
+
+// awsStaticCredentials provides the credentials from the 'start' operation options, which can be replaced
+// while the uploader is in use
+type awsStaticCredentials struct {
+	value atomic.Value // aws.Credentials
 }
 
 type awsCredentials struct {
@@ -52,6 +112,10 @@ type awsCredentials struct {
 	token  string
 	region string
 	bucket string
+
+	roleARN     string
+	externalID  string
+	sessionName string
 }
 
 type awsLogger struct{}
@@ -66,7 +130,15 @@ func (l *awsLogger) Logf(classification logging.Classification, format string, v
 	}
 }
 
-// NewAWSUploader construct new AWSUploader from the provided 'start' operation options
+// NewAWSUploader construct new AWSUploader from the provided 'start' operation options. Object lock retention
+// is applied to the uploaded objects, if both the lock mode (GOVERNANCE or COMPLIANCE) and the RFC 3339 retain
+// until date are specified. The bucket should have object lock enabled. When checksums are enabled, the checksum
+// is sent in the Content-MD5 header (default), in the object metadata or in a checksum manifest object.
+// Files larger than the multipart part size (5 MiB by default) are uploaded in parts, with the configured
+// number of parts (5 by default) uploaded in parallel. The tags from the 'tag.' prefixed options are applied
+// to the uploaded objects. The TLS connections are restricted by the 'tls.' options, if specified.
+// Incomplete multipart uploads of failed or canceled uploads are aborted, so their parts are removed, unless
+// the parts should be left for manual recovery.
 func NewAWSUploader(options map[string]string) (Uploader, error) {
 	cred, err := getAWSCredentials(options)
 
@@ -74,53 +146,422 @@ func NewAWSUploader(options map[string]string) (Uploader, error) {
 		return nil, err
 	}
 
+	lockMode, lockRetainUntil, err := parseRetention(options, AWSObjectLockMode, AWSObjectLockRetainUntil,
+		string(types.ObjectLockModeGovernance), string(types.ObjectLockModeCompliance))
+	if err != nil {
+		return nil, err
+	}
+
+	checksumLocation, err := parseChecksumLocation(options, AWSChecksumLocation,
+		ChecksumLocationHeader, ChecksumLocationMetadata, ChecksumLocationManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	partSize, concurrency, err := parseMultipart(options)
+	if err != nil {
+		return nil, err
+	}
+
+	leaveParts := false
+	if value, ok := options[AWSMultipartLeaveParts]; ok {
+		if leaveParts, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for parameter '%s'", value, AWSMultipartLeaveParts)
+		}
+	}
+
+	policy, restricted, err := parseTLSPolicy(options)
+	if err != nil {
+		return nil, err
+	}
+
 	var logMode aws.ClientLogMode
 	if logger.IsDebugEnabled() {
 		logMode = aws.LogRequest | aws.LogResponse | aws.LogRetries
 	}
 
-	provider := credentials.NewStaticCredentialsProvider(cred.key, cred.secret, cred.token)
-	cfg, err := config.LoadDefaultConfig(context.Background(),
+	static := &awsStaticCredentials{}
+	static.set(cred.key, cred.secret, cred.token)
+	provider := aws.NewCredentialsCache(static)
+	loadOptions := []func(*config.LoadOptions) error{
 		config.WithCredentialsProvider(provider),
 		config.WithRegion(cred.region),
 		config.WithLogger(&awsLogger{}),
 		config.WithClientLogMode(logMode),
-	)
+	}
+	if restricted {
+		// the SDK configuration requires a buildable client, e.g. to apply a custom CA bundle
+		loadOptions = append(loadOptions, config.WithHTTPClient(awshttp.NewBuildableClient().WithTransportOptions(
+			func(transport *http.Transport) {
+				transport.TLSClientConfig = policy.config()
+			})))
+	}
+	cfg, err := config.LoadDefaultConfig(context.Background(), loadOptions...)
 
 	if err != nil {
 		return nil, err
 	}
 
-	uploader := manager.NewUploader(s3.NewFromConfig(cfg))
-	objectKey := options[AWSObjectKey]
+	if cred.roleARN != "" {
+		cfg.Credentials = newAssumeRoleProvider(sts.NewFromConfig(cfg), cred)
+	}
 
-	return &AWSUploader{cred.bucket, objectKey, uploader}, nil
+	keys, err := NewObjectKeyNamer(options, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3.NewFromConfig(cfg)
+
+	return &AWSUploader{
+		bucket:           cred.bucket,
+		objectKey:        options[AWSObjectKey],
+		keys:             keys,
+		lockMode:         types.ObjectLockMode(lockMode),
+		lockRetainUntil:  lockRetainUntil,
+		checksumLocation: checksumLocation,
+		tags:             encodeTags(ObjectTags(options)),
+		client:           client,
+		uploader: manager.NewUploader(client, func(u *manager.Uploader) {
+			u.PartSize = partSize
+			u.Concurrency = concurrency
+			u.LeavePartsOnError = true // aborted by the uploader, also when the upload context is canceled
+		}),
+		leaveParts:       leaveParts,
+		credentials:      static,
+		credentialsCache: provider,
+	}, nil
+}
+
+// RefreshCredentials replaces the access key, the secret access key and the session token of the uploader with
+// the ones from the given options. With role assumption, the role is assumed with the new credentials, when
+// the temporary role credentials expire.
+func (u *AWSUploader) RefreshCredentials(options map[string]string) error {
+	key, secret := options[AWSAccessKeyID], options[AWSSecretAccessKey]
+	if key == "" {
+		return fmt.Errorf(missingParameterErrMsg, AWSAccessKeyID)
+	}
+	if secret == "" {
+		return fmt.Errorf(missingParameterErrMsg, AWSSecretAccessKey)
+	}
+
+	u.credentials.set(key, secret, options[AWSSessionToken])
+	u.credentialsCache.Invalidate()
+
+	return nil
+}
+
+func (c *awsStaticCredentials) set(key string, secret string, token string) {
+	c.value.Store(aws.Credentials{AccessKeyID: key, SecretAccessKey: secret, SessionToken: token, Source: "StaticCredentials"})
+}
+
+// Retrieve implements aws.CredentialsProvider Retrieve method
+func (c *awsStaticCredentials) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	return c.value.Load().(aws.Credentials), nil
+}
+
+// parseMultipart returns the multipart part size and concurrency from the given options,
+// or the upload manager defaults, if not specified
+func parseMultipart(options map[string]string) (int64, int, error) {
+	partSize := manager.DefaultUploadPartSize
+	if value := options[AWSMultipartPartSize]; value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < manager.MinUploadPartSize {
+			return 0, 0, fmt.Errorf("invalid value '%s' for parameter '%s', minimum part size is %d bytes",
+				value, AWSMultipartPartSize, manager.MinUploadPartSize)
+		}
+		partSize = parsed
+	}
+
+	concurrency := manager.DefaultUploadConcurrency
+	if value := options[AWSMultipartConcurrency]; value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return 0, 0, fmt.Errorf("invalid value '%s' for parameter '%s'", value, AWSMultipartConcurrency)
+		}
+		concurrency = parsed
+	}
+
+	return partSize, concurrency, nil
 }
 
 // UploadFile performs AWS S3 file upload
 func (u *AWSUploader) UploadFile(file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
-	name := u.objectKey
-	if u.objectKey == "" {
-		name = file.Name()
-	}
+	return u.UploadFileContext(context.Background(), file, useChecksum, listener)
+}
+
+// UploadFileContext performs AWS S3 file upload, which is aborted when the given context is done
+func (u *AWSUploader) UploadFileContext(ctx context.Context, file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
+	name := u.name(file)
 
-	var md5 string
+	var md5 []byte
 	if useChecksum {
-		hash, err := ComputeMD5(file, true)
+		hash, err := ComputeMD5(file, false)
+		if err != nil {
+			return err
+		}
+		md5 = []byte(hash)
+	}
+
+	input := u.putObjectInput(file, name, md5)
+	if listener != nil {
+		body, err := newPartProgressReader(file, u.uploader.PartSize, listener)
+		if err != nil {
+			return err
+		}
+		input.Body = body
+	}
+
+	if err := u.upload(ctx, input); err != nil {
+		return err
+	}
+
+	if md5 != nil && u.checksumLocation == ChecksumLocationManifest {
+		data, err := checksumManifest(name, md5)
 		if err != nil {
 			return err
 		}
-		md5 = hash
+
+		_, err = u.uploader.Upload(ctx, &s3.PutObjectInput{
+			Bucket:      &u.bucket,
+			Key:         aws.String(name + ChecksumManifestExtension),
+			Body:        bytes.NewReader(data),
+			ContentType: aws.String("application/json"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upload checksum manifest: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// name returns the key of the S3 object, to which the file is uploaded
+func (u *AWSUploader) name(file *os.File) string {
+	if u.objectKey != "" {
+		return u.objectKey
+	}
+	if key := u.keys.Key(file.Name()); key != "" {
+		return key
+	}
+
+	return file.Name()
+}
+
+// CanStream returns true, since the upload manager uploads content of unknown size in parts, buffered in memory
+func (u *AWSUploader) CanStream() bool {
+	return true
+}
+
+// UploadStream performs AWS S3 upload of content of unknown size, which is aborted when the given context is done.
+// The given name is the object key, unless the key is specified with the options. Each part is buffered in memory,
+// up to the part size times the upload concurrency.
+func (u *AWSUploader) UploadStream(ctx context.Context, name string, content io.Reader, listener func(bytesTransferred int64)) error {
+	if u.objectKey != "" {
+		name = u.objectKey
+	} else if key := u.keys.Key(name); key != "" {
+		name = key
+	}
+
+	if listener != nil {
+		content = &progressReader{r: content, listener: listener}
+	}
+
+	return u.upload(ctx, u.putObjectInput(content, name, nil))
+}
+
+// upload uploads the object with the given input, aborting the incomplete multipart upload on failure,
+// unless the uploaded parts should be left
+func (u *AWSUploader) upload(ctx context.Context, input *s3.PutObjectInput) error {
+	if _, err := u.uploader.Upload(ctx, input); err != nil {
+		var failure manager.MultiUploadFailure
+		if errors.As(err, &failure) && !u.leaveParts {
+			u.abortMultipartUpload(*input.Key, failure.UploadID())
+		}
+		return err
+	}
+
+	return nil
+}
+
+// abortMultipartUpload aborts the incomplete multipart upload with the given ID, removing its uploaded parts
+func (u *AWSUploader) abortMultipartUpload(name string, uploadID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), awsAbortTimeout)
+	defer cancel()
+
+	if _, err := u.uploader.S3.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   &u.bucket,
+		Key:      aws.String(name),
+		UploadId: aws.String(uploadID),
+	}); err != nil {
+		logger.Warnf("failed to abort incomplete multipart upload of '%s', its parts remain in the bucket: %v", name, err)
+		return
 	}
 
-	_, err := u.uploader.Upload(context.Background(), &s3.PutObjectInput{
-		Bucket:     &u.bucket,
-		Key:        aws.String(name),
-		Body:       file,
-		ContentMD5: &md5,
+	logger.Debugf("incomplete multipart upload of '%s' aborted", name)
+}
+
+// putObjectInput returns the input for uploading the given content as S3 object with the given name, with object tags
+// and object lock retention, if configured. The given MD5 checksum (if not nil) is set in the configured checksum location.
+func (u *AWSUploader) putObjectInput(content io.Reader, name string, md5 []byte) *s3.PutObjectInput {
+	input := &s3.PutObjectInput{
+		Bucket: &u.bucket,
+		Key:    aws.String(name),
+		Body:   content,
+	}
+
+	if md5 != nil {
+		switch u.checksumLocation {
+		case ChecksumLocationHeader:
+			input.ContentMD5 = aws.String(base64.StdEncoding.EncodeToString(md5))
+		case ChecksumLocationMetadata:
+			input.Metadata = map[string]string{ChecksumMetadataKey: hex.EncodeToString(md5)}
+		}
+	}
+
+	if u.tags != "" {
+		input.Tagging = aws.String(u.tags)
+	}
+
+	if u.lockMode != "" {
+		input.ObjectLockMode = u.lockMode
+		input.ObjectLockRetainUntilDate = u.lockRetainUntil
+	}
+
+	return input
+}
+
+// partProgressReader reports the number of bytes of the file read by the upload manager so far to the listener.
+// The manager reads the parts concurrently through io.ReaderAt, and the same part may be read more than once,
+// e.g. to sign the request or to retry it, so only the furthest position read in each part is counted.
+type partProgressReader struct {
+	total int64 // first, for 64-bit alignment of the atomic operations on 32-bit platforms
+
+	*os.File
+
+	partSize int64
+	read     []int64 // the number of bytes read from each part
+
+	mutex    sync.Mutex
+	reported int64
+	listener func(bytesTransferred int64)
+}
+
+func newPartProgressReader(file *os.File, partSize int64, listener func(bytesTransferred int64)) (*partProgressReader, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if partSize <= 0 {
+		partSize = manager.DefaultUploadPartSize
+	}
+	size := info.Size()
+	if size/partSize >= int64(manager.MaxUploadParts) { // adjusted the same way by the upload manager
+		partSize = size/int64(manager.MaxUploadParts) + 1
+	}
+
+	return &partProgressReader{
+		File:     file,
+		partSize: partSize,
+		read:     make([]int64, size/partSize+1),
+		listener: listener,
+	}, nil
+}
+
+// ReadAt reads from the file, counting the bytes read past the furthest position read so far in the part
+func (r *partProgressReader) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.File.ReadAt(p, off)
+
+	if n > 0 {
+		part := off / r.partSize
+		end := off + int64(n) - part*r.partSize
+		if part < int64(len(r.read)) {
+			for {
+				read := atomic.LoadInt64(&r.read[part])
+				if end <= read {
+					break
+				}
+				if atomic.CompareAndSwapInt64(&r.read[part], read, end) {
+					r.report(atomic.AddInt64(&r.total, end-read))
+					break
+				}
+			}
+		}
+	}
+
+	return n, err
+}
+
+// report notifies the listener, if the total number of bytes read has increased since the last notification
+func (r *partProgressReader) report(total int64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if total > r.reported {
+		r.reported = total
+		r.listener(total)
+	}
+}
+
+// encodeTags returns the given S3 object tags, encoded as URL query parameters, sorted by key
+func encodeTags(tags map[string]string) string {
+	values := url.Values{}
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+
+	return values.Encode()
+}
+
+// LastModified returns the last modification time of the S3 object, to which the file is uploaded
+func (u *AWSUploader) LastModified(ctx context.Context, file *os.File) (time.Time, bool, error) {
+	name := u.name(file)
+
+	output, err := u.client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: &u.bucket, Key: aws.String(name)})
+	if err != nil {
+		if errorStatusCode(err) == http.StatusNotFound {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, err
+	}
+
+	if output.LastModified == nil {
+		return time.Time{}, true, nil
+	}
+
+	return *output.LastModified, true, nil
+}
+
+// VerifyFile downloads the S3 object, to which the file is uploaded, and compares it to the file content
+func (u *AWSUploader) VerifyFile(ctx context.Context, file *os.File) error {
+	name := u.name(file)
+
+	output, err := u.client.GetObject(ctx, &s3.GetObjectInput{Bucket: &u.bucket, Key: aws.String(name)})
+	if err != nil {
+		return err
+	}
+	defer output.Body.Close()
+
+	return verifyContent(file, output.Body)
+}
+
+// newAssumeRoleProvider returns a provider of temporary credentials, obtained by assuming the configured role
+// with the static credentials of the given STS client. The temporary credentials are cached and refreshed
+// automatically shortly before they expire.
+func newAssumeRoleProvider(client stscreds.AssumeRoleAPIClient, cred *awsCredentials) aws.CredentialsProvider {
+	provider := stscreds.NewAssumeRoleProvider(client, cred.roleARN, func(o *stscreds.AssumeRoleOptions) {
+		if cred.externalID != "" {
+			o.ExternalID = aws.String(cred.externalID)
+		}
+		if cred.sessionName != "" {
+			o.RoleSessionName = cred.sessionName
+		}
 	})
 
-	return err
+	return aws.NewCredentialsCache(provider, func(o *aws.CredentialsCacheOptions) {
+		o.ExpiryWindow = awsCredentialsExpiryWindow
+	})
 }
 
 func getAWSCredentials(options map[string]string) (*awsCredentials, error) {
@@ -132,6 +573,10 @@ func getAWSCredentials(options map[string]string) (*awsCredentials, error) {
 	r.secret = options[AWSSecretAccessKey]
 	r.token = options[AWSSessionToken]
 
+	r.roleARN = options[AWSRoleARN]
+	r.externalID = options[AWSRoleExternalID]
+	r.sessionName = options[AWSRoleSessionName]
+
 	if r.bucket == "" {
 		return nil, fmt.Errorf(missingParameterErrMsg, AWSBucket)
 	}
@@ -148,7 +593,7 @@ func getAWSCredentials(options map[string]string) (*awsCredentials, error) {
 		return nil, fmt.Errorf(missingParameterErrMsg, AWSSecretAccessKey)
 	}
 
-	//token is optional
+	//token and role are optional
 
 	return r, nil
 }
@@ -15,15 +15,24 @@
 package uploaders
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
 )
 
 func TestAWSUploadWithoutChecksum(t *testing.T) {
@@ -76,6 +85,417 @@ func TestNewAWSUploaderErrors(t *testing.T) {
 
 }
 
+type mockedSTSClient struct {
+	calls    int
+	input    *sts.AssumeRoleInput
+	duration time.Duration
+}
+
+func (c *mockedSTSClient) AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	c.calls++
+	c.input = params
+
+	return &sts.AssumeRoleOutput{
+		Credentials: &types.Credentials{
+			AccessKeyId:     aws.String(fmt.Sprintf("tempKey%d", c.calls)),
+			SecretAccessKey: aws.String("tempSecret"),
+			SessionToken:    aws.String("tempToken"),
+			Expiration:      aws.Time(time.Now().Add(c.duration)),
+		},
+	}, nil
+}
+
+func TestAWSAssumeRoleProvider(t *testing.T) {
+	client := &mockedSTSClient{duration: time.Hour}
+	cred := &awsCredentials{roleARN: "arn:aws:iam::123456789012:role/test", externalID: "testExternalID", sessionName: "testSession"}
+
+	provider := newAssumeRoleProvider(client, cred)
+
+	creds, err := provider.Retrieve(context.Background())
+	assertNoError(t, err)
+	assertStringsSame(t, "access key", "tempKey1", creds.AccessKeyID)
+	assertStringsSame(t, "session token", "tempToken", creds.SessionToken)
+
+	assertStringsSame(t, "role ARN", cred.roleARN, *client.input.RoleArn)
+	assertStringsSame(t, "external ID", cred.externalID, *client.input.ExternalId)
+	assertStringsSame(t, "session name", cred.sessionName, *client.input.RoleSessionName)
+
+	// valid credentials are cached
+	creds, err = provider.Retrieve(context.Background())
+	assertNoError(t, err)
+	assertStringsSame(t, "access key", "tempKey1", creds.AccessKeyID)
+	if client.calls != 1 {
+		t.Errorf("expected single role assumption, got %d", client.calls)
+	}
+}
+
+func TestAWSAssumeRoleProviderRefresh(t *testing.T) {
+	client := &mockedSTSClient{duration: awsCredentialsExpiryWindow / 2} // expire within the refresh window
+	cred := &awsCredentials{roleARN: "arn:aws:iam::123456789012:role/test"}
+
+	provider := newAssumeRoleProvider(client, cred)
+
+	creds, err := provider.Retrieve(context.Background())
+	assertNoError(t, err)
+	assertStringsSame(t, "access key", "tempKey1", creds.AccessKeyID)
+
+	creds, err = provider.Retrieve(context.Background())
+	assertNoError(t, err)
+	assertStringsSame(t, "access key", "tempKey2", creds.AccessKeyID)
+
+	if client.input.ExternalId != nil {
+		t.Errorf("no external ID expected, got %s", *client.input.ExternalId)
+	}
+}
+
+func TestNewAWSUploaderAssumeRole(t *testing.T) {
+	options := map[string]string{
+		AWSBucket:          "testBucket",
+		AWSRegion:          "eu-central-1",
+		AWSAccessKeyID:     "testKey",
+		AWSSecretAccessKey: "testSecret",
+		AWSRoleARN:         "arn:aws:iam::123456789012:role/test",
+		AWSRoleExternalID:  "testExternalID",
+	}
+
+	u, err := NewAWSUploader(options)
+	assertNoError(t, err)
+	if u == nil {
+		t.Error("uploader expected")
+	}
+}
+
+func TestAWSObjectLock(t *testing.T) {
+	retainUntil := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	options := map[string]string{
+		AWSBucket:                "testBucket",
+		AWSRegion:                "eu-central-1",
+		AWSAccessKeyID:           "testKey",
+		AWSSecretAccessKey:       "testSecret",
+		AWSObjectLockMode:        "compliance",
+		AWSObjectLockRetainUntil: retainUntil.Format(time.RFC3339),
+	}
+
+	u, err := NewAWSUploader(options)
+	assertNoError(t, err)
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	input := u.(*AWSUploader).putObjectInput(f, "testObject", nil)
+	assertStringsSame(t, "object lock mode", string(s3types.ObjectLockModeCompliance), string(input.ObjectLockMode))
+	if input.ObjectLockRetainUntilDate == nil || !input.ObjectLockRetainUntilDate.Equal(retainUntil) {
+		t.Errorf("object lock retain until date %v expected, got %v", retainUntil, input.ObjectLockRetainUntilDate)
+	}
+
+	delete(options, AWSObjectLockMode)
+	delete(options, AWSObjectLockRetainUntil)
+	u, err = NewAWSUploader(options)
+	assertNoError(t, err)
+
+	input = u.(*AWSUploader).putObjectInput(f, "testObject", nil)
+	assertStringsSame(t, "object lock mode", "", string(input.ObjectLockMode))
+	if input.ObjectLockRetainUntilDate != nil {
+		t.Errorf("no object lock retain until date expected, got %v", input.ObjectLockRetainUntilDate)
+	}
+}
+
+func TestAWSObjectLockErrors(t *testing.T) {
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	invalid := []map[string]string{
+		{AWSObjectLockMode: "GOVERNANCE"},
+		{AWSObjectLockRetainUntil: future},
+		{AWSObjectLockMode: "LEGAL", AWSObjectLockRetainUntil: future},
+		{AWSObjectLockMode: "GOVERNANCE", AWSObjectLockRetainUntil: "tomorrow"},
+		{AWSObjectLockMode: "GOVERNANCE", AWSObjectLockRetainUntil: past},
+	}
+	for _, lock := range invalid {
+		options := map[string]string{
+			AWSBucket:          "testBucket",
+			AWSRegion:          "eu-central-1",
+			AWSAccessKeyID:     "testKey",
+			AWSSecretAccessKey: "testSecret",
+		}
+		addAll(options, lock)
+
+		u, err := NewAWSUploader(options)
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}
+
+// mockedMultipartClient collects the uploaded parts, reading each part body twice, like when signing the request
+type mockedMultipartClient struct {
+	mutex sync.Mutex
+	parts map[int32][]byte
+
+	started chan int32 // if not nil, the part uploads are reported and block until canceled
+	aborted []string   // IDs of the aborted multipart uploads
+}
+
+func (c *mockedMultipartClient) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	_, err := c.read(1, params.Body)
+	return &s3.PutObjectOutput{}, err
+}
+
+func (c *mockedMultipartClient) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	if c.started != nil {
+		c.started <- params.PartNumber
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	_, err := c.read(params.PartNumber, params.Body)
+	return &s3.UploadPartOutput{ETag: aws.String(fmt.Sprintf("etag%d", params.PartNumber))}, err
+}
+
+func (c *mockedMultipartClient) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String("testUpload")}, nil
+}
+
+func (c *mockedMultipartClient) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (c *mockedMultipartClient) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.aborted = append(c.aborted, *params.UploadId)
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func (c *mockedMultipartClient) read(part int32, body io.Reader) ([]byte, error) {
+	if _, err := io.ReadAll(body); err != nil {
+		return nil, err
+	}
+	if _, err := body.(io.Seeker).Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.parts[part] = data
+	return data, nil
+}
+
+func TestAWSMultipartUploadProgress(t *testing.T) {
+	partSize := manager.MinUploadPartSize
+	content := bytes.Repeat([]byte("0123456789abcdef"), int(3*partSize+partSize/2)/16)
+	file := writeSplitTestFile(t, content)
+	defer file.Close()
+
+	client := &mockedMultipartClient{parts: make(map[int32][]byte)}
+	u := &AWSUploader{
+		bucket: "testBucket",
+		uploader: manager.NewUploader(client, func(u *manager.Uploader) {
+			u.PartSize = partSize
+			u.Concurrency = 3
+		}),
+	}
+
+	var progress []int64
+	assertNoError(t, u.UploadFile(file, false, func(bytesTransferred int64) {
+		progress = append(progress, bytesTransferred) // reported under lock
+	}))
+
+	assertEquals(t, "uploaded parts", 4, int64(len(client.parts)))
+	var uploaded []byte
+	for part := int32(1); part <= 4; part++ {
+		uploaded = append(uploaded, client.parts[part]...)
+	}
+	if !bytes.Equal(content, uploaded) {
+		t.Error("uploaded parts do not match the file content")
+	}
+
+	if len(progress) == 0 {
+		t.Fatal("no progress reported")
+	}
+	for i := 1; i < len(progress); i++ {
+		if progress[i] <= progress[i-1] {
+			t.Fatalf("progress not monotonic: %d reported after %d", progress[i], progress[i-1])
+		}
+	}
+	assertEquals(t, "final progress", int64(len(content)), progress[len(progress)-1])
+}
+
+func TestAWSMultipartUploadStream(t *testing.T) {
+	partSize := manager.MinUploadPartSize
+	content := bytes.Repeat([]byte("0123456789abcdef"), int(2*partSize+partSize/2)/16)
+
+	client := &mockedMultipartClient{parts: make(map[int32][]byte)}
+	u := &AWSUploader{
+		bucket: "testBucket",
+		uploader: manager.NewUploader(client, func(u *manager.Uploader) {
+			u.PartSize = partSize
+			u.Concurrency = 2
+		}),
+	}
+
+	var mutex sync.Mutex
+	var progress int64
+	stream := io.MultiReader(bytes.NewReader(content)) // of unknown size
+	assertNoError(t, u.UploadStream(context.Background(), "test.gz", stream, func(bytesTransferred int64) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		progress = bytesTransferred
+	}))
+
+	assertEquals(t, "uploaded parts", 3, int64(len(client.parts)))
+	var uploaded []byte
+	for part := int32(1); part <= 3; part++ {
+		uploaded = append(uploaded, client.parts[part]...)
+	}
+	if !bytes.Equal(content, uploaded) {
+		t.Error("uploaded parts do not match the streamed content")
+	}
+	assertEquals(t, "final progress", int64(len(content)), progress)
+}
+
+func TestAWSMultipartUploadCanceled(t *testing.T) {
+	partSize := manager.MinUploadPartSize
+	content := make([]byte, 2*partSize)
+
+	for _, leaveParts := range []bool{false, true} {
+		file := writeSplitTestFile(t, content)
+		defer file.Close()
+
+		client := &mockedMultipartClient{parts: make(map[int32][]byte), started: make(chan int32, 2)}
+		u := &AWSUploader{
+			bucket: "testBucket",
+			uploader: manager.NewUploader(client, func(u *manager.Uploader) {
+				u.PartSize = partSize
+				u.Concurrency = 1
+				u.LeavePartsOnError = true
+			}),
+			leaveParts: leaveParts,
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-client.started
+			cancel()
+		}()
+
+		err := u.UploadFileContext(ctx, file, false, nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("canceled upload expected, got: %v", err)
+		}
+
+		if leaveParts {
+			assertDeepEquals(t, []string(nil), client.aborted)
+		} else {
+			assertDeepEquals(t, []string{"testUpload"}, client.aborted)
+		}
+	}
+}
+
+func TestAWSMultipartOptions(t *testing.T) {
+	options := map[string]string{
+		AWSBucket:          "testBucket",
+		AWSRegion:          "eu-central-1",
+		AWSAccessKeyID:     "testKey",
+		AWSSecretAccessKey: "testSecret",
+	}
+
+	u, err := NewAWSUploader(options)
+	assertNoError(t, err)
+	assertEquals(t, "default part size", manager.DefaultUploadPartSize, u.(*AWSUploader).uploader.PartSize)
+	assertEquals(t, "default concurrency", manager.DefaultUploadConcurrency, int64(u.(*AWSUploader).uploader.Concurrency))
+
+	options[AWSMultipartPartSize] = "10485760"
+	options[AWSMultipartConcurrency] = "8"
+	u, err = NewAWSUploader(options)
+	assertNoError(t, err)
+	assertEquals(t, "part size", 10485760, u.(*AWSUploader).uploader.PartSize)
+	assertEquals(t, "concurrency", 8, int64(u.(*AWSUploader).uploader.Concurrency))
+
+	for _, multipart := range []map[string]string{
+		{AWSMultipartPartSize: "1024"},
+		{AWSMultipartPartSize: "5MB"},
+		{AWSMultipartConcurrency: "0"},
+		{AWSMultipartConcurrency: "many"},
+	} {
+		options := map[string]string{
+			AWSBucket:          "testBucket",
+			AWSRegion:          "eu-central-1",
+			AWSAccessKeyID:     "testKey",
+			AWSSecretAccessKey: "testSecret",
+		}
+		addAll(options, multipart)
+
+		u, err := NewAWSUploader(options)
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}
+
+func TestAWSObjectTags(t *testing.T) {
+	options := map[string]string{
+		AWSBucket:          "testBucket",
+		AWSRegion:          "eu-central-1",
+		AWSAccessKeyID:     "testKey",
+		AWSSecretAccessKey: "testSecret",
+	}
+
+	u, err := NewAWSUploader(options)
+	assertNoError(t, err)
+	if tagging := u.(*AWSUploader).putObjectInput(nil, "testObject", nil).Tagging; tagging != nil {
+		t.Errorf("no object tags expected, but were %s", *tagging)
+	}
+
+	options[TagPrefix+"source"] = "kanto"
+	options[TagPrefix+"device type"] = "gateway & edge"
+	u, err = NewAWSUploader(options)
+	assertNoError(t, err)
+
+	tagging := u.(*AWSUploader).putObjectInput(nil, "testObject", nil).Tagging
+	if tagging == nil {
+		t.Fatal("object tags expected")
+	}
+	assertStringsSame(t, "object tags", "device+type=gateway+%26+edge&source=kanto", *tagging)
+}
+
+func TestAWSObjectKeyTemplate(t *testing.T) {
+	options := map[string]string{
+		AWSBucket:          "testBucket",
+		AWSRegion:          "eu-central-1",
+		AWSAccessKeyID:     "testKey",
+		AWSSecretAccessKey: "testSecret",
+		ObjectKeyTemplate:  "{deviceId}/{date}/{filename}",
+		ObjectKeyDeviceID:  "device-123",
+		ObjectKeyTime:      "2024-06-17T10:30:00Z",
+	}
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	u, err := NewAWSUploader(options)
+	assertNoError(t, err)
+	assertStringsSame(t, "object key", "device-123/2024-06-17/"+filepath.Base(testFile), u.(*AWSUploader).name(f))
+
+	options[AWSObjectKey] = "explicit.txt"
+	u, err = NewAWSUploader(options)
+	assertNoError(t, err)
+	assertStringsSame(t, "object key", "explicit.txt", u.(*AWSUploader).name(f))
+
+	options[ObjectKeyTemplate] = "{unknown}"
+	_, err = NewAWSUploader(options)
+	assertError(t, err)
+}
+
 func deleteAWSObject(client *s3.Client, key string, bucket string) {
 	di := s3.DeleteObjectInput{
 		Bucket: aws.String(bucket),
@@ -13,13 +13,28 @@
 package uploaders
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/eclipse-kanto/file-upload/logger"
+	"github.com/google/uuid"
 )
 
 // Constants for Azure upload 'start' operation options
@@ -28,63 +43,533 @@ const (
 
 	AzureEndpoint      = "azure.storage.endpoint"
 	AzureSAS           = "azure.shared.access.signature"
+	AzureAccountKey    = "azure.account.key"
+	AzureConnection    = "azure.connection.string"
 	AzureContainerName = "azure.blob.container"
+	AzureBlobName      = "azure.blob.name"
+
+	AzureBlobTier       = "azure.blob.tier"
+	AzureBlobMetaPrefix = "azure.blob.meta."
+
+	AzureImmutabilityMode  = "azure.immutability.mode"
+	AzureImmutabilityUntil = "azure.immutability.until"
+
+	AzureChecksumLocation = "azure.checksum.location"
+
+	AzureBlockSize        = "azure.block.size"
+	AzureBlockParallelism = "azure.block.parallelism"
+)
+
+// Constants for Azure blob immutability policy modes
+const (
+	AzureImmutabilityUnlocked = "Unlocked"
+	AzureImmutabilityLocked   = "Locked"
+)
+
+// Defaults for the staged block uploads
+const (
+	AzureDefaultBlockSize        = 8 << 20
+	AzureDefaultBlockParallelism = 5
 )
 
+// azureImmutabilityVersion is the minimal storage service version, supporting blob immutability policies
+const azureImmutabilityVersion = "2020-10-02"
+
 // AzureUploader handles upload to Azure Blob storage
 type AzureUploader struct {
-	endpoint  string
-	sas       string
-	container string
+	endpoint   string
+	sas        string
+	sharedKey  *azblob.SharedKeyCredential
+	connection *azblob.ContainerClient
+	container  string
+	blobName   string
+	keys       *ObjectKeyNamer // names the blobs after the files, if no blob name is specified
+
+	checksumLocation string
+
+	tags map[string]string // blob index tags
+
+	tier     *azblob.AccessTier
+	metadata map[string]string
+
+	sasPolicy *azureSASPolicy
+
+	blockSize   int64
+	parallelism int
+
+	clientOptions azblob.ClientOptions
 }
 
-// NewAzureUploader constructs new AzureUploader from provided 'start' operation options
+// azureSASPolicy replaces the shared access signature of the requests with the current one, so the signature
+// can be refreshed while uploading
+type azureSASPolicy struct {
+	sas   atomic.Value // url.Values
+	names sync.Map     // the names of all signature parameters, ever set
+}
+
+// azureImmutabilityPolicy sets the immutability policy of the uploaded blob. The azblob version in use predates
+// blob immutability, so the policy headers are set directly on the upload requests.
+type azureImmutabilityPolicy struct {
+	mode  string
+	until time.Time
+}
+
+// NewAzureUploader constructs new AzureUploader from provided 'start' operation options. Exactly one of the shared
+// access signature, the account key or the connection string options should be specified. With account key,
+// the storage account name is taken from the endpoint host, e.g. 'https://<account>.blob.core.windows.net/'.
+// Immutability policy is set on the uploaded blobs, if both the policy mode (Unlocked or Locked) and the RFC 3339
+// expiry date are specified. The container should have version-level immutability support enabled.
+// When checksums are enabled, the checksum is sent in the Content-MD5 header (default), in the blob metadata
+// or in a checksum manifest blob. The tags from the 'tag.' prefixed options are set as blob index tags.
+// The blobs are uploaded to the access tier (Hot, Cool or Archive) from the tier option, if specified, with
+// the metadata from the 'azure.blob.meta.' prefixed options. Checksum manifest blobs have the default tier.
+// The TLS connections are restricted by the 'tls.' options, if specified. Files larger than the block size
+// are uploaded in blocks, staged in parallel and committed at the end, reporting the progress per block.
 func NewAzureUploader(options map[string]string) (Uploader, error) {
 	uploader := &AzureUploader{
 		endpoint:  options[AzureEndpoint],
 		sas:       options[AzureSAS],
 		container: options[AzureContainerName],
+		blobName:  options[AzureBlobName],
+		tags:      ObjectTags(options),
+		metadata:  ExtractDictionary(options, AzureBlobMetaPrefix),
+	}
+
+	accountKey := options[AzureAccountKey]
+	connection := options[AzureConnection]
+	authCount := 0
+	for _, auth := range []string{uploader.sas, accountKey, connection} {
+		if auth != "" {
+			authCount++
+		}
+	}
+	if authCount > 1 {
+		return nil, fmt.Errorf("parameters '%s', '%s' and '%s' are mutually exclusive", AzureSAS, AzureAccountKey, AzureConnection)
 	}
-	if uploader.endpoint == "" {
+
+	if uploader.endpoint == "" && connection == "" {
 		return nil, fmt.Errorf(missingParameterErrMsg, AzureEndpoint)
 	}
-	if uploader.sas == "" {
+	if authCount == 0 {
 		return nil, fmt.Errorf(missingParameterErrMsg, AzureSAS)
 	}
 	if uploader.container == "" {
 		return nil, fmt.Errorf(missingParameterErrMsg, AzureContainerName)
 	}
+
+	mode, until, err := parseRetention(options, AzureImmutabilityMode, AzureImmutabilityUntil,
+		AzureImmutabilityUnlocked, AzureImmutabilityLocked)
+	if err != nil {
+		return nil, err
+	}
+	if mode != "" {
+		uploader.clientOptions.PerCallOptions = append(uploader.clientOptions.PerCallOptions, &azureImmutabilityPolicy{mode, *until})
+	}
+
+	if uploader.sas != "" {
+		uploader.sasPolicy = &azureSASPolicy{}
+		if err := uploader.sasPolicy.set(uploader.sas); err != nil {
+			return nil, err
+		}
+		uploader.clientOptions.PerCallOptions = append(uploader.clientOptions.PerCallOptions, uploader.sasPolicy)
+	}
+
+	tlsPolicy, restricted, err := parseTLSPolicy(options)
+	if err != nil {
+		return nil, err
+	}
+	if restricted {
+		uploader.clientOptions.Transporter = tlsPolicy.httpClient()
+	}
+
+	if uploader.tier, err = parseAzureBlobTier(options[AzureBlobTier]); err != nil {
+		return nil, err
+	}
+
+	for key := range uploader.metadata {
+		if key == "" {
+			return nil, fmt.Errorf("empty metadata name in parameter '%s'", AzureBlobMetaPrefix)
+		}
+	}
+
+	if uploader.checksumLocation, err = parseChecksumLocation(options, AzureChecksumLocation,
+		ChecksumLocationHeader, ChecksumLocationMetadata, ChecksumLocationManifest); err != nil {
+		return nil, err
+	}
+
+	if uploader.blockSize, uploader.parallelism, err = parseAzureBlocks(options); err != nil {
+		return nil, err
+	}
+
+	if uploader.keys, err = NewObjectKeyNamer(options, nil); err != nil {
+		return nil, err
+	}
+
+	if accountKey != "" {
+		endpointURL, err := url.Parse(uploader.endpoint)
+		if err != nil || endpointURL.Hostname() == "" {
+			return nil, fmt.Errorf("invalid value '%s' for parameter '%s'", uploader.endpoint, AzureEndpoint)
+		}
+
+		accountName := strings.Split(endpointURL.Hostname(), ".")[0]
+		if uploader.sharedKey, err = azblob.NewSharedKeyCredential(accountName, accountKey); err != nil {
+			return nil, fmt.Errorf("invalid value for parameter '%s': %w", AzureAccountKey, err)
+		}
+	}
+
+	if connection != "" {
+		containerClient, err := azblob.NewContainerClientFromConnectionString(connection, uploader.container, &uploader.clientOptions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for parameter '%s': %w", AzureConnection, err)
+		}
+		uploader.connection = &containerClient
+	}
+
 	return uploader, nil
 }
 
+// name returns the name of the blob, to which the file is uploaded
+func (u *AzureUploader) name(file *os.File) string {
+	if u.blobName != "" {
+		return u.blobName
+	}
+	if key := u.keys.Key(file.Name()); key != "" {
+		return key
+	}
+
+	return filepath.Base(file.Name())
+}
+
+// getBlockBlobClient creates a client for the blob with the given name, authenticated with the configured credentials
+func (u *AzureUploader) getBlockBlobClient(name string) (azblob.BlockBlobClient, error) {
+	clientOptions := u.clientOptions
+
+	if u.connection != nil {
+		return u.connection.NewBlockBlobClient(name), nil
+	}
+
+	if u.sharedKey != nil {
+		return azblob.NewBlockBlobClientWithSharedKey(fmt.Sprint(u.endpoint, u.container, "/", name), u.sharedKey, &clientOptions)
+	}
+
+	return azblob.NewBlockBlobClientWithNoCredential(fmt.Sprint(u.endpoint, u.container, "/", name, "?", u.sas), &clientOptions)
+}
+
+// LastModified returns the last modification time of the blob, to which the file is uploaded
+func (u *AzureUploader) LastModified(ctx context.Context, file *os.File) (time.Time, bool, error) {
+	name := u.name(file)
+
+	blockBlobClient, err := u.getBlockBlobClient(name)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	properties, err := blockBlobClient.GetProperties(ctx, nil)
+	if err != nil {
+		if errorStatusCode(err) == http.StatusNotFound {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, err
+	}
+
+	if properties.LastModified == nil {
+		return time.Time{}, true, nil
+	}
+
+	return *properties.LastModified, true, nil
+}
+
+// VerifyFile downloads the blob, to which the file is uploaded, and compares it to the file content
+func (u *AzureUploader) VerifyFile(ctx context.Context, file *os.File) error {
+	name := u.name(file)
+
+	blockBlobClient, err := u.getBlockBlobClient(name)
+	if err != nil {
+		return err
+	}
+
+	response, err := blockBlobClient.Download(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	body := response.Body(azblob.RetryReaderOptions{MaxRetryRequests: 3})
+	defer body.Close()
+
+	return verifyContent(file, body)
+}
+
 // UploadFile performs Azure file upload
 func (u *AzureUploader) UploadFile(file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
-	clientOptions := azblob.ClientOptions{}
-	blockBlobClient, err := azblob.NewBlockBlobClientWithNoCredential(fmt.Sprint(u.endpoint, u.container, "/", filepath.Base(file.Name()), "?", u.sas), &clientOptions)
+	name := u.name(file)
+
+	blockBlobClient, err := u.getBlockBlobClient(name)
 	if err != nil {
 		return err
 	}
 
-	blobHTTPHeaders := &azblob.BlobHTTPHeaders{}
+	var md5 []byte
 	if useChecksum {
-		md5, err := ComputeMD5(file, false)
+		hash, err := ComputeMD5(file, false)
 		if err != nil {
 			return err
 		}
-		blobHTTPHeaders.BlobContentMD5 = []byte(md5)
+		md5 = []byte(hash)
 	}
+
+	blobHTTPHeaders := &azblob.BlobHTTPHeaders{}
 	options := azblob.HighLevelUploadToBlockBlobOption{
 		HTTPHeaders:             blobHTTPHeaders,
 		Progress:                listener,
 		TransactionalContentMD5: &blobHTTPHeaders.BlobContentMD5,
+		TagsMap:                 u.tags,
+		AccessTier:              u.tier,
+	}
+	if len(u.metadata) > 0 {
+		options.Metadata = make(map[string]string, len(u.metadata)+1)
+		for k, v := range u.metadata {
+			options.Metadata[k] = v
+		}
+	}
+	if md5 != nil {
+		switch u.checksumLocation {
+		case ChecksumLocationHeader:
+			blobHTTPHeaders.BlobContentMD5 = md5
+		case ChecksumLocationMetadata:
+			if options.Metadata == nil {
+				options.Metadata = make(map[string]string, 1)
+			}
+			options.Metadata[ChecksumMetadataKey] = hex.EncodeToString(md5)
+		}
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	var response *http.Response
+	if info.Size() > u.blockSize {
+		response, err = u.uploadBlocks(blockBlobClient, file, info.Size(), options)
+	} else {
+		response, err = blockBlobClient.UploadFileToBlockBlob(context.Background(), file, options) // perform upload
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("azure blob upload response status code - %v", response.StatusCode)
+	if response.StatusCode != 201 {
+		return fmt.Errorf("unsuccessful upload, response status code - %v", response.StatusCode)
+	}
+
+	if md5 != nil && u.checksumLocation == ChecksumLocationManifest {
+		return u.uploadManifest(name, md5)
+	}
+
+	return nil
+}
+
+// uploadBlocks stages the file content in blocks, uploading up to the configured number of blocks in parallel,
+// and commits the block list with the blob properties from the given options. The number of bytes staged so far
+// is reported to the options progress listener after each block.
+func (u *AzureUploader) uploadBlocks(blockBlobClient azblob.BlockBlobClient, file *os.File, size int64,
+	options azblob.HighLevelUploadToBlockBlobOption) (*http.Response, error) {
+	blockSize := u.blockSize
+	if size/blockSize >= azblob.BlockBlobMaxBlocks {
+		blockSize = size/azblob.BlockBlobMaxBlocks + 1
+	}
+	count := int((size-1)/blockSize + 1)
+
+	// block IDs are unique per upload, so concurrent uploads of the same blob do not mix their blocks
+	prefix := uuid.New().String()
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s-%05d", prefix, i)))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mutex    sync.Mutex
+		staged   int64
+		stageErr error
+		wg       sync.WaitGroup
+	)
+	blocks := make(chan int)
+	for i := 0; i < u.parallelism && i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for block := range blocks {
+				offset := int64(block) * blockSize
+				length := blockSize
+				if offset+length > size {
+					length = size - offset
+				}
+
+				body := io.NewSectionReader(file, offset, length)
+				_, err := blockBlobClient.StageBlock(ctx, ids[block], streaming.NopCloser(body), nil)
+
+				mutex.Lock()
+				if err != nil {
+					if stageErr == nil {
+						stageErr = err
+						cancel()
+					}
+				} else if stageErr == nil {
+					staged += length
+					if options.Progress != nil {
+						options.Progress(staged)
+					}
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for block := 0; block < count && ctx.Err() == nil; block++ {
+		blocks <- block
+	}
+	close(blocks)
+	wg.Wait()
+
+	if stageErr != nil {
+		return nil, stageErr
+	}
+
+	response, err := blockBlobClient.CommitBlockList(context.Background(), ids, &azblob.CommitBlockListOptions{
+		BlobTagsMap:     options.TagsMap,
+		Metadata:        options.Metadata,
+		Tier:            options.AccessTier,
+		BlobHTTPHeaders: options.HTTPHeaders,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response.RawResponse, nil
+}
+
+// parseAzureBlocks returns the block size and the number of blocks uploaded in parallel from the given options,
+// or the defaults, if not specified
+func parseAzureBlocks(options map[string]string) (int64, int, error) {
+	blockSize := int64(AzureDefaultBlockSize)
+	if value := options[AzureBlockSize]; value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 || parsed > azblob.BlockBlobMaxStageBlockBytes {
+			return 0, 0, fmt.Errorf("invalid value '%s' for parameter '%s', maximum block size is %d bytes",
+				value, AzureBlockSize, int64(azblob.BlockBlobMaxStageBlockBytes))
+		}
+		blockSize = parsed
+	}
+
+	parallelism := AzureDefaultBlockParallelism
+	if value := options[AzureBlockParallelism]; value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return 0, 0, fmt.Errorf("invalid value '%s' for parameter '%s'", value, AzureBlockParallelism)
+		}
+		parallelism = parsed
 	}
 
-	response, err := blockBlobClient.UploadFileToBlockBlob(context.Background(), file, options) // perform upload
-	if err == nil {
-		logger.Debugf("azure blob upload response status code - %v", response.StatusCode)
-		if response.StatusCode != 201 {
-			return fmt.Errorf("unsuccessful upload, response status code - %v", response.StatusCode)
+	return blockSize, parallelism, nil
+}
+
+// parseAzureBlobTier returns the access tier with the given case-insensitive name, or nil if the name is empty
+func parseAzureBlobTier(value string) (*azblob.AccessTier, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	tiers := []azblob.AccessTier{azblob.AccessTierHot, azblob.AccessTierCool, azblob.AccessTierArchive}
+	for _, tier := range tiers {
+		if strings.EqualFold(value, string(tier)) {
+			return tier.ToPtr(), nil
 		}
 	}
-	return err
+
+	return nil, fmt.Errorf("invalid value '%s' for parameter '%s', supported values are '%s', '%s' and '%s'",
+		value, AzureBlobTier, tiers[0], tiers[1], tiers[2])
+}
+
+// uploadManifest uploads the checksum manifest of the blob with the given name and MD5 checksum
+func (u *AzureUploader) uploadManifest(name string, md5 []byte) error {
+	data, err := checksumManifest(name, md5)
+	if err != nil {
+		return err
+	}
+
+	blockBlobClient, err := u.getBlockBlobClient(name + ChecksumManifestExtension)
+	if err != nil {
+		return err
+	}
+
+	options := &azblob.UploadBlockBlobOptions{HTTPHeaders: &azblob.BlobHTTPHeaders{BlobContentType: to.StringPtr("application/json")}}
+	if _, err := blockBlobClient.Upload(context.Background(), streaming.NopCloser(bytes.NewReader(data)), options); err != nil {
+		return fmt.Errorf("failed to upload checksum manifest: %w", err)
+	}
+
+	return nil
+}
+
+// Do sets the immutability policy headers on the requests, which create the blob, i.e. 'Put Blob' and
+// 'Put Block List'
+func (p *azureImmutabilityPolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	if comp := raw.URL.Query().Get("comp"); raw.Method == http.MethodPut && (comp == "" || comp == "blocklist") {
+		raw.Header.Set("x-ms-version", azureImmutabilityVersion)
+		raw.Header.Set("x-ms-immutability-policy-mode", p.mode)
+		raw.Header.Set("x-ms-immutability-policy-until-date", p.until.UTC().Format(http.TimeFormat))
+	}
+
+	return req.Next()
+}
+
+// RefreshCredentials replaces the shared access signature of the uploader with the one from the given options.
+// Refreshing is supported only for uploaders, authenticated with a shared access signature.
+func (u *AzureUploader) RefreshCredentials(options map[string]string) error {
+	if u.sasPolicy == nil {
+		return fmt.Errorf("credentials refresh is supported only with parameter '%s'", AzureSAS)
+	}
+
+	sas := options[AzureSAS]
+	if sas == "" {
+		return fmt.Errorf(missingParameterErrMsg, AzureSAS)
+	}
+
+	return u.sasPolicy.set(sas)
+}
+
+func (p *azureSASPolicy) set(sas string) error {
+	values, err := url.ParseQuery(sas)
+	if err != nil {
+		return fmt.Errorf("invalid value for parameter '%s': %w", AzureSAS, err)
+	}
+
+	for name := range values {
+		p.names.Store(name, true)
+	}
+	p.sas.Store(values)
+
+	return nil
+}
+
+// Do implements policy.Policy Do method
+func (p *azureSASPolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+
+	query := raw.URL.Query()
+	p.names.Range(func(name, _ interface{}) bool {
+		query.Del(name.(string))
+		return true
+	})
+	for name, values := range p.sas.Load().(url.Values) {
+		query[name] = values
+	}
+	raw.URL.RawQuery = query.Encode()
+
+	return req.Next()
 }
@@ -17,9 +17,18 @@ package uploaders
 import (
 	"bytes"
 	"context"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 )
@@ -76,6 +85,411 @@ func TestNewAzureUploaderErrors(t *testing.T) {
 
 }
 
+func TestAzureUploadWithAccountKey(t *testing.T) {
+	options := RetrieveAzureTestOptions(t)
+
+	accountKey := os.Getenv("AZURE_ACCOUNT_KEY")
+	if accountKey == "" {
+		t.Skip("Please set AZURE_ACCOUNT_KEY environment variable.")
+	}
+
+	options = partialCopy(options, AzureSAS)
+	options[AzureAccountKey] = accountKey
+
+	testAzureUploadCredentials(t, options)
+}
+
+func TestAzureUploadWithConnectionString(t *testing.T) {
+	options := RetrieveAzureTestOptions(t)
+
+	connection := os.Getenv("AZURE_CONNECTION_STRING")
+	if connection == "" {
+		t.Skip("Please set AZURE_CONNECTION_STRING environment variable.")
+	}
+
+	options = partialCopy(partialCopy(options, AzureSAS), AzureEndpoint)
+	options[AzureConnection] = connection
+
+	testAzureUploadCredentials(t, options)
+}
+
+func testAzureUploadCredentials(t *testing.T, options map[string]string) {
+	t.Helper()
+
+	u, err := NewAzureUploader(options)
+	assertNoError(t, err)
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	assertNoError(t, u.UploadFile(f, true, nil))
+
+	blockBlobClient, err := u.(*AzureUploader).getBlockBlobClient(testFile)
+	assertNoError(t, err)
+	defer deleteBlob(t, blockBlobClient)
+
+	response, err := blockBlobClient.Download(context.Background(), &azblob.DownloadBlobOptions{})
+	assertNoError(t, err)
+
+	downloadedData := bytes.Buffer{}
+	_, err = downloadedData.ReadFrom(response.Body(azblob.RetryReaderOptions{MaxRetryRequests: 3}))
+	assertNoError(t, err)
+	assertStringsSame(t, "Test file content", testBody, downloadedData.String())
+}
+
+func TestNewAzureUploaderCredentials(t *testing.T) {
+	const (
+		endpoint   = "https://testaccount.blob.core.windows.net/"
+		accountKey = "dGVzdEtleQ==" // base64 encoded
+		connection = "DefaultEndpointsProtocol=https;AccountName=testaccount;AccountKey=dGVzdEtleQ==;EndpointSuffix=core.windows.net"
+	)
+
+	valid := []map[string]string{
+		{AzureEndpoint: endpoint, AzureContainerName: "test", AzureAccountKey: accountKey},
+		{AzureContainerName: "test", AzureConnection: connection},
+	}
+	for _, options := range valid {
+		_, err := NewAzureUploader(options)
+		assertNoError(t, err)
+	}
+
+	u, err := NewAzureUploader(valid[0])
+	assertNoError(t, err)
+	assertStringsSame(t, "account name", "testaccount", u.(*AzureUploader).sharedKey.AccountName())
+
+	invalid := []map[string]string{
+		{AzureEndpoint: endpoint, AzureContainerName: "test", AzureAccountKey: accountKey, AzureSAS: "sig=test"},
+		{AzureContainerName: "test", AzureConnection: connection, AzureAccountKey: accountKey},
+		{AzureEndpoint: endpoint, AzureContainerName: "test", AzureSAS: "sig=test", AzureConnection: connection},
+		{AzureEndpoint: endpoint, AzureContainerName: "test", AzureAccountKey: "not base64"},
+		{AzureEndpoint: "/relative/", AzureContainerName: "test", AzureAccountKey: accountKey},
+		{AzureContainerName: "test", AzureConnection: "AccountName=testaccount"},
+		{AzureContainerName: "test", AzureAccountKey: accountKey},
+		{AzureEndpoint: endpoint, AzureContainerName: "test"},
+	}
+	for _, options := range invalid {
+		u, err := NewAzureUploader(options)
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}
+
+func TestAzureImmutabilityPolicy(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			headers <- r.Header.Clone()
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	until := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	u, err := NewAzureUploader(map[string]string{
+		AzureEndpoint:          server.URL + "/",
+		AzureContainerName:     "test",
+		AzureSAS:               "sig=test",
+		AzureImmutabilityMode:  "locked",
+		AzureImmutabilityUntil: until.Format(time.RFC3339),
+	})
+	assertNoError(t, err)
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	assertNoError(t, u.UploadFile(f, false, nil))
+
+	header := <-headers
+	assertStringsSame(t, "immutability policy mode", AzureImmutabilityLocked, header.Get("x-ms-immutability-policy-mode"))
+	assertStringsSame(t, "immutability policy until date", until.Format(http.TimeFormat),
+		header.Get("x-ms-immutability-policy-until-date"))
+	assertStringsSame(t, "service version", azureImmutabilityVersion, header.Get("x-ms-version"))
+}
+
+func TestAzureObjectTags(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			headers <- r.Header.Clone()
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	u, err := NewAzureUploader(map[string]string{
+		AzureEndpoint:             server.URL + "/",
+		AzureContainerName:        "test",
+		AzureSAS:                  "sig=test",
+		TagPrefix + "source":      "kanto",
+		TagPrefix + "device type": "gateway & edge",
+	})
+	assertNoError(t, err)
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	assertNoError(t, u.UploadFile(f, false, nil))
+
+	tags, err := url.ParseQuery((<-headers).Get("x-ms-tags"))
+	assertNoError(t, err)
+	assertDeepEquals(t, url.Values{"source": {"kanto"}, "device type": {"gateway & edge"}}, tags)
+}
+
+func TestAzureObjectKeyTemplate(t *testing.T) {
+	paths := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			paths <- r.URL.Path
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	options := map[string]string{
+		AzureEndpoint:          server.URL + "/",
+		AzureContainerName:     "test",
+		AzureSAS:               "sig=test",
+		ObjectKeyTemplate:      "{deviceId}/{correlationId}/{filename}",
+		ObjectKeyDeviceID:      "device-123",
+		ObjectKeyCorrelationID: "upload-1",
+	}
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	u, err := NewAzureUploader(options)
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(f, false, nil))
+	assertStringsSame(t, "blob path", "/test/device-123/upload-1/"+filepath.Base(testFile), <-paths)
+
+	options[AzureBlobName] = "explicit.txt"
+	u, err = NewAzureUploader(options)
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(f, false, nil))
+	assertStringsSame(t, "blob path", "/test/explicit.txt", <-paths)
+
+	options[ObjectKeyTemplate] = "{unknown}"
+	_, err = NewAzureUploader(options)
+	assertError(t, err)
+}
+
+func TestAzureBlobTierAndMetadata(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			headers <- r.Header.Clone()
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	u, err := NewAzureUploader(map[string]string{
+		AzureEndpoint:                 server.URL + "/",
+		AzureContainerName:            "test",
+		AzureSAS:                      "sig=test",
+		AzureBlobTier:                 "archive",
+		AzureBlobMetaPrefix + "owner": "kanto",
+		AzureChecksumLocation:         ChecksumLocationMetadata,
+	})
+	assertNoError(t, err)
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	assertNoError(t, u.UploadFile(f, true, nil))
+
+	header := <-headers
+	assertStringsSame(t, "access tier", string(azblob.AccessTierArchive), header.Get("x-ms-access-tier"))
+	assertStringsSame(t, "owner metadata", "kanto", header.Get("x-ms-meta-owner"))
+	if header.Get("x-ms-meta-"+ChecksumMetadataKey) == "" {
+		t.Error("checksum metadata expected along with the blob metadata")
+	}
+
+	u, err = NewAzureUploader(map[string]string{
+		AzureEndpoint:      server.URL + "/",
+		AzureContainerName: "test",
+		AzureSAS:           "sig=test",
+	})
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(f, false, nil))
+
+	header = <-headers
+	assertStringsSame(t, "access tier", "", header.Get("x-ms-access-tier"))
+	assertStringsSame(t, "owner metadata", "", header.Get("x-ms-meta-owner"))
+}
+
+func TestAzureBlobTierAndMetadataErrors(t *testing.T) {
+	for _, invalid := range []map[string]string{{AzureBlobTier: "Premium"}, {AzureBlobMetaPrefix: "value"}} {
+		options := map[string]string{
+			AzureEndpoint:      "https://testaccount.blob.core.windows.net/",
+			AzureContainerName: "test",
+			AzureSAS:           "sig=test",
+		}
+		for k, v := range invalid {
+			options[k] = v
+		}
+
+		u, err := NewAzureUploader(options)
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}
+
+func TestAzureUploadBlobTier(t *testing.T) {
+	options := RetrieveAzureTestOptions(t)
+	options[AzureBlobTier] = string(azblob.AccessTierCool)
+	options[AzureBlobMetaPrefix+"owner"] = "kanto"
+
+	u, err := NewAzureUploader(options)
+	assertNoError(t, err)
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	assertNoError(t, u.UploadFile(f, false, nil))
+
+	urlStr := fmt.Sprint(options[AzureEndpoint], options[AzureContainerName], "/", testFile, "?", options[AzureSAS])
+	blockBlobClient, err := azblob.NewBlockBlobClientWithNoCredential(urlStr, &azblob.ClientOptions{})
+	assertNoError(t, err)
+	defer deleteBlob(t, blockBlobClient)
+
+	properties, err := blockBlobClient.GetProperties(context.Background(), nil)
+	assertNoError(t, err)
+	if properties.AccessTier == nil {
+		t.Fatal("access tier not set")
+	}
+	assertStringsSame(t, "access tier", string(azblob.AccessTierCool), *properties.AccessTier)
+	assertStringsSame(t, "owner metadata", "kanto", properties.Metadata["owner"])
+}
+
+func TestAzureBlockUploadProgress(t *testing.T) {
+	var (
+		mutex  sync.Mutex
+		blocks = make(map[string][]byte)
+		blob   []byte
+		puts   int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assertNoError(t, err)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		query := r.URL.Query()
+		switch query.Get("comp") {
+		case "block":
+			blocks[query.Get("blockid")] = body
+		case "blocklist":
+			var list struct {
+				Latest []string `xml:"Latest"`
+			}
+			assertNoError(t, xml.Unmarshal(body, &list))
+			for _, id := range list.Latest {
+				blob = append(blob, blocks[id]...)
+			}
+		default:
+			puts++
+			blob = body
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	blockSize := int64(64 << 10)
+	u, err := NewAzureUploader(map[string]string{
+		AzureEndpoint:         server.URL + "/",
+		AzureContainerName:    "test",
+		AzureSAS:              "sig=test",
+		AzureBlockSize:        strconv.FormatInt(blockSize, 10),
+		AzureBlockParallelism: "3",
+	})
+	assertNoError(t, err)
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), int(10*blockSize+blockSize/2)/16)
+	file := writeSplitTestFile(t, content)
+	defer file.Close()
+
+	var progress []int64
+	assertNoError(t, u.UploadFile(file, true, func(bytesTransferred int64) {
+		progress = append(progress, bytesTransferred) // reported under lock
+	}))
+
+	assertEquals(t, "staged blocks", 11, int64(len(blocks)))
+	assertEquals(t, "single request uploads", 0, int64(puts))
+	if !bytes.Equal(content, blob) {
+		t.Error("committed blocks do not match the file content")
+	}
+
+	assertEquals(t, "progress reports", 11, int64(len(progress)))
+	for i := 1; i < len(progress); i++ {
+		if progress[i] <= progress[i-1] {
+			t.Fatalf("progress does not advance: %v", progress)
+		}
+	}
+	assertEquals(t, "final progress", int64(len(content)), progress[len(progress)-1])
+
+	small := writeSplitTestFile(t, content[:blockSize])
+	defer small.Close()
+
+	assertNoError(t, u.UploadFile(small, false, nil))
+	assertEquals(t, "single request uploads", 1, int64(puts))
+	if !bytes.Equal(content[:blockSize], blob) {
+		t.Error("uploaded blob does not match the file content")
+	}
+}
+
+func TestAzureBlockOptionsErrors(t *testing.T) {
+	invalid := []map[string]string{
+		{AzureBlockSize: "0"},
+		{AzureBlockSize: "4GB"},
+		{AzureBlockSize: strconv.FormatInt(azblob.BlockBlobMaxStageBlockBytes+1, 10)},
+		{AzureBlockParallelism: "0"},
+		{AzureBlockParallelism: "all"},
+	}
+	for _, blocks := range invalid {
+		options := map[string]string{
+			AzureEndpoint:      "https://testaccount.blob.core.windows.net/",
+			AzureContainerName: "test",
+			AzureSAS:           "sig=test",
+		}
+		addAll(options, blocks)
+
+		u, err := NewAzureUploader(options)
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}
+
+func TestAzureImmutabilityPolicyErrors(t *testing.T) {
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	invalid := []map[string]string{
+		{AzureImmutabilityMode: AzureImmutabilityLocked},
+		{AzureImmutabilityUntil: future},
+		{AzureImmutabilityMode: "Compliance", AzureImmutabilityUntil: future},
+		{AzureImmutabilityMode: AzureImmutabilityUnlocked, AzureImmutabilityUntil: "2020-01-01T00:00:00Z"},
+	}
+	for _, immutability := range invalid {
+		options := map[string]string{
+			AzureEndpoint:      "https://testaccount.blob.core.windows.net/",
+			AzureContainerName: "test",
+			AzureSAS:           "sig=test",
+		}
+		addAll(options, immutability)
+
+		u, err := NewAzureUploader(options)
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}
+
 func deleteBlob(t *testing.T, blockBlobClient azblob.BlockBlobClient) {
 	t.Helper()
 
@@ -0,0 +1,81 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Locations, in which the MD5 checksum of the uploaded files is reported, when checksums are enabled
+const (
+	ChecksumLocationHeader   = "header"
+	ChecksumLocationMetadata = "metadata"
+	ChecksumLocationManifest = "manifest"
+)
+
+// Algorithms of the checksum in the custom checksum header of generic HTTP uploads
+const (
+	ChecksumAlgorithmMD5    = "md5"
+	ChecksumAlgorithmSHA256 = "sha256"
+)
+
+// ChecksumMetadataKey is the name of the object metadata, holding the hex encoded MD5 checksum of the object,
+// when reported in the object metadata
+const ChecksumMetadataKey = "md5"
+
+// ChecksumManifestExtension is appended to the object name, to form the name of its checksum manifest
+const ChecksumManifestExtension = ".md5.json"
+
+// ChecksumManifest holds the hex encoded MD5 checksum of an uploaded object, when reported in a manifest,
+// uploaded next to the object
+type ChecksumManifest struct {
+	Name string `json:"name"`
+	MD5  string `json:"md5"`
+}
+
+// parseChecksumLocation returns the checksum location from the given option, which should be one of the given
+// supported locations. The checksum is reported in a header by default.
+func parseChecksumLocation(options map[string]string, name string, locations ...string) (string, error) {
+	return parseChecksumOption(options, name, ChecksumLocationHeader, locations...)
+}
+
+// parseChecksumAlgorithm returns the checksum algorithm from the given option, MD5 by default
+func parseChecksumAlgorithm(options map[string]string, name string) (string, error) {
+	return parseChecksumOption(options, name, ChecksumAlgorithmMD5, ChecksumAlgorithmMD5, ChecksumAlgorithmSHA256)
+}
+
+// parseChecksumOption returns the value of the given option, which should be one of the given supported values,
+// matched case-insensitively, or the default value, if the option is not specified
+func parseChecksumOption(options map[string]string, name string, def string, values ...string) (string, error) {
+	value := options[name]
+	if value == "" {
+		return def, nil
+	}
+
+	for _, supported := range values {
+		if strings.EqualFold(value, supported) {
+			return supported, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid value '%s' for parameter '%s', supported values are '%s'",
+		value, name, strings.Join(values, "', '"))
+}
+
+// checksumManifest returns the checksum manifest of the object with the given name and raw MD5 checksum
+func checksumManifest(name string, md5 []byte) ([]byte, error) {
+	return json.MarshalIndent(&ChecksumManifest{name, hex.EncodeToString(md5)}, "", "  ")
+}
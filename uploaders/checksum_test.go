@@ -0,0 +1,228 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package uploaders
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+type checksumRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// startChecksumServer starts a server, which records the PUT requests by path
+func startChecksumServer(t *testing.T) (*httptest.Server, func(path string) *checksumRequest) {
+	var mutex sync.Mutex
+	requests := make(map[string]*checksumRequest)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			body, err := io.ReadAll(r.Body)
+			assertNoError(t, err)
+
+			mutex.Lock()
+			requests[r.URL.Path] = &checksumRequest{r.Header.Clone(), body}
+			mutex.Unlock()
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	return server, func(path string) *checksumRequest {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return requests[path]
+	}
+}
+
+func testFileMD5(t *testing.T) []byte {
+	data, err := os.ReadFile(testFile)
+	assertNoError(t, err)
+
+	sum := md5.Sum(data)
+	return sum[:]
+}
+
+func assertChecksumManifest(t *testing.T, request *checksumRequest, name string, md5 []byte) {
+	t.Helper()
+
+	if request == nil {
+		t.Fatal("checksum manifest not uploaded")
+	}
+
+	manifest := &ChecksumManifest{}
+	assertNoError(t, json.Unmarshal(request.body, manifest))
+	assertStringsSame(t, "manifest object name", name, manifest.Name)
+	assertStringsSame(t, "manifest checksum", hex.EncodeToString(md5), manifest.MD5)
+}
+
+func TestHTTPChecksumLocation(t *testing.T) {
+	server, get := startChecksumServer(t)
+	defer server.Close()
+
+	sum := testFileMD5(t)
+
+	for _, location := range []string{"", ChecksumLocationHeader, ChecksumLocationManifest} {
+		options := map[string]string{
+			URLProp:                 server.URL + "/" + location + "/test.txt",
+			ChecksumLocationProp:    location,
+			ChecksumManifestURLProp: server.URL + "/" + location + "/test.txt" + ChecksumManifestExtension,
+		}
+
+		u, err := NewHTTPUploader(options, "")
+		assertNoError(t, err)
+
+		f, err := os.Open(testFile)
+		assertNoError(t, err)
+		assertNoError(t, u.UploadFile(f, true, nil))
+		f.Close()
+
+		upload := get("/" + location + "/test.txt")
+		manifest := get("/" + location + "/test.txt" + ChecksumManifestExtension)
+		if location == ChecksumLocationManifest {
+			assertStringsSame(t, "Content-MD5 header", "", upload.header.Get(ContentMD5))
+			assertChecksumManifest(t, manifest, "test.txt", sum)
+		} else {
+			assertStringsSame(t, "Content-MD5 header", base64.StdEncoding.EncodeToString(sum), upload.header.Get(ContentMD5))
+			if manifest != nil {
+				t.Errorf("unexpected checksum manifest for location '%s'", location)
+			}
+		}
+	}
+}
+
+func TestAWSChecksumLocation(t *testing.T) {
+	options := map[string]string{
+		AWSBucket:          "testBucket",
+		AWSRegion:          "eu-central-1",
+		AWSAccessKeyID:     "testKey",
+		AWSSecretAccessKey: "testSecret",
+	}
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	sum := testFileMD5(t)
+
+	for _, location := range []string{ChecksumLocationHeader, ChecksumLocationMetadata, ChecksumLocationManifest} {
+		options[AWSChecksumLocation] = location
+		u, err := NewAWSUploader(options)
+		assertNoError(t, err)
+
+		input := u.(*AWSUploader).putObjectInput(f, "testObject", sum)
+
+		md5, metadata := "", ""
+		if input.ContentMD5 != nil {
+			md5 = *input.ContentMD5
+		}
+		if input.Metadata != nil {
+			metadata = input.Metadata[ChecksumMetadataKey]
+		}
+
+		switch location {
+		case ChecksumLocationHeader:
+			assertStringsSame(t, "Content-MD5", base64.StdEncoding.EncodeToString(sum), md5)
+			assertStringsSame(t, "checksum metadata", "", metadata)
+		case ChecksumLocationMetadata:
+			assertStringsSame(t, "Content-MD5", "", md5)
+			assertStringsSame(t, "checksum metadata", hex.EncodeToString(sum), metadata)
+		default:
+			assertStringsSame(t, "Content-MD5", "", md5)
+			assertStringsSame(t, "checksum metadata", "", metadata)
+		}
+	}
+}
+
+func TestAzureChecksumLocation(t *testing.T) {
+	server, get := startChecksumServer(t)
+	defer server.Close()
+
+	sum := testFileMD5(t)
+
+	for _, location := range []string{ChecksumLocationHeader, ChecksumLocationMetadata, ChecksumLocationManifest} {
+		u, err := NewAzureUploader(map[string]string{
+			AzureEndpoint:         server.URL + "/",
+			AzureContainerName:    location,
+			AzureBlobName:         "test.txt",
+			AzureSAS:              "sig=test",
+			AzureChecksumLocation: location,
+		})
+		assertNoError(t, err)
+
+		f, err := os.Open(testFile)
+		assertNoError(t, err)
+		assertNoError(t, u.UploadFile(f, true, nil))
+		f.Close()
+
+		upload := get("/" + location + "/test.txt")
+		manifest := get("/" + location + "/test.txt" + ChecksumManifestExtension)
+
+		switch location {
+		case ChecksumLocationHeader:
+			assertStringsSame(t, "blob MD5 header", base64.StdEncoding.EncodeToString(sum), upload.header.Get("x-ms-blob-content-md5"))
+			assertStringsSame(t, "checksum metadata", "", upload.header.Get("x-ms-meta-"+ChecksumMetadataKey))
+		case ChecksumLocationMetadata:
+			assertStringsSame(t, "blob MD5 header", "", upload.header.Get("x-ms-blob-content-md5"))
+			assertStringsSame(t, "checksum metadata", hex.EncodeToString(sum), upload.header.Get("x-ms-meta-"+ChecksumMetadataKey))
+		default:
+			assertStringsSame(t, "blob MD5 header", "", upload.header.Get("x-ms-blob-content-md5"))
+			assertChecksumManifest(t, manifest, "test.txt", sum)
+		}
+
+		if location != ChecksumLocationManifest && manifest != nil {
+			t.Errorf("unexpected checksum manifest for location '%s'", location)
+		}
+	}
+}
+
+func TestChecksumLocationErrors(t *testing.T) {
+	u, err := NewHTTPUploader(map[string]string{URLProp: "http://localhost:1234/up", ChecksumLocationProp: ChecksumLocationMetadata}, "")
+	assertNil(t, u)
+	assertError(t, err)
+
+	u, err = NewHTTPUploader(map[string]string{URLProp: "http://localhost:1234/up", ChecksumLocationProp: ChecksumLocationManifest}, "")
+	assertNil(t, u)
+	assertError(t, err)
+
+	u, err = NewAWSUploader(map[string]string{
+		AWSBucket:           "testBucket",
+		AWSRegion:           "eu-central-1",
+		AWSAccessKeyID:      "testKey",
+		AWSSecretAccessKey:  "testSecret",
+		AWSChecksumLocation: "body",
+	})
+	assertNil(t, u)
+	assertError(t, err)
+
+	u, err = NewAzureUploader(map[string]string{
+		AzureEndpoint:         "https://testaccount.blob.core.windows.net/",
+		AzureContainerName:    "test",
+		AzureSAS:              "sig=test",
+		AzureChecksumLocation: "body",
+	})
+	assertNil(t, u)
+	assertError(t, err)
+}
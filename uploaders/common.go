@@ -13,51 +13,258 @@
 package uploaders
 
 import (
+	"bytes"
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/eclipse-kanto/file-upload/logger"
+	"golang.org/x/net/http/httpproxy"
 )
 
 // Constants for HTTP(S) file upload 'start' operation options
 const (
 	StorageProviderHTTP = "generic"
 
-	URLProp       = "https.url"
-	MethodProp    = "https.method"
-	HeadersPrefix = "https.header."
+	URLProp             = "https.url"
+	MethodProp          = "https.method"
+	HeadersPrefix       = "https.header."
+	ContentEncodingProp = "https.content.encoding"
+	ContentTypeProp     = "https.content.type"
+	ConfirmHeadProp     = "https.confirm.head"
+	ProxyProp           = "https.proxy"
+	ExpectBodyRegexProp = "https.expect.body.regex"
+	ChunkedProp         = "https.chunked"
+
+	BodyFormatProp   = "https.body.format"
+	FormFieldProp    = "https.form.field"
+	FormFieldsPrefix = "https.form."
+
+	ChecksumHeaderProp      = "https.checksum.header"
+	ChecksumEncodingProp    = "https.checksum.encoding"
+	ChecksumAlgorithmProp   = "https.checksum.algorithm"
+	ChecksumLocationProp    = "https.checksum.location"
+	ChecksumManifestURLProp = "https.checksum.manifest.url"
+	VerifyURLProp           = "https.verify.url"
+
+	TimeoutProp               = "https.timeout"
+	ResponseHeaderTimeoutProp = "https.response.header.timeout"
+
+	AuthBearerProp        = "https.auth.bearer"
+	AuthBasicUserProp     = "https.auth.basic.user"
+	AuthBasicPasswordProp = "https.auth.basic.password"
+
+	ClientCertProp = "https.client.cert"
+	ClientKeyProp  = "https.client.key"
 )
 
+const authorizationHeader = "Authorization"
+
+// defaultContentType is the content type of the uploaded files, unless specified with the 'https.content.type' option
+const defaultContentType = "application/x-binary"
+
+// maxExpectedBodySize limits the size of the response body, matched against the 'https.expect.body.regex' pattern
+const maxExpectedBodySize = 64 * 1024
+
 // ContentMD5 header name
 const ContentMD5 = "Content-MD5"
 
+// Encodings of the MD5 checksum in the custom checksum header
+const (
+	ChecksumEncodingHex    = "hex"
+	ChecksumEncodingBase64 = "base64"
+)
+
 const missingParameterErrMsg = "required parameter '%s' missing or empty"
 
+// ObjectKeyTemplate holds the name of the 'start' operation option, common for all storage providers, which specifies
+// a template for the keys of the uploaded objects, e.g. '{deviceId}/{date}/logs/{filename}'. Applied to the objects,
+// named after the uploaded files, i.e. unless the object key, blob name or path is specified with the options.
+const ObjectKeyTemplate = "object.key.template"
+
+// Names of the 'start' operation options, common for all storage providers, which specify the values of the object key
+// template placeholders, not derived from the uploaded file. The time is in RFC 3339 format, the current one by default.
+const (
+	ObjectKeyDeviceID      = "object.key.device.id"
+	ObjectKeyCorrelationID = "object.key.correlation.id"
+	ObjectKeyTime          = "object.key.time"
+)
+
+// TagPrefix is used to prefix the 'start' operation options, common for all storage providers, which specify tags
+// of the uploaded objects, e.g. 'tag.source=kanto'. Applied as S3 object tags and Azure blob index tags.
+const TagPrefix = "tag."
+
+// objectKeyPlaceholder matches the placeholders in object key templates
+var objectKeyPlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// ObjectKeyValues holds the values, with which the object key template placeholders are replaced
+type ObjectKeyValues struct {
+	FilePath      string
+	DeviceID      string
+	CorrelationID string
+	Time          time.Time
+}
+
+// ObjectKeyNamer resolves the object key template from the 'start' operation options for each uploaded file
+type ObjectKeyNamer struct {
+	template string
+	values   ObjectKeyValues
+}
+
 // Uploader interface wraps the generic UploadFile method
 type Uploader interface {
 	UploadFile(file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error
 }
 
+// ContextUploader is implemented by uploaders, which support cancelling of the upload with a context.
+// Closing the uploaded file does not interrupt an upload, which waits for the storage response.
+type ContextUploader interface {
+	UploadFileContext(ctx context.Context, file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error
+}
+
+// ResumableUploader is implemented by uploaders, which can upload the file content from a given offset,
+// e.g. to continue an interrupted upload
+type ResumableUploader interface {
+	UploadFileFrom(ctx context.Context, file *os.File, offset int64, useChecksum bool, listener func(bytesTransferred int64)) error
+}
+
+// StreamUploader is implemented by uploaders, which can upload content of unknown size, as it is produced, e.g. a file
+// compressed on the fly, instead of a temporary copy of the whole content
+type StreamUploader interface {
+	// CanStream returns false, if the uploader configuration requires the whole content in advance, e.g. to compute
+	// its checksum or to confirm its size after upload.
+	CanStream() bool
+	// UploadStream uploads the content, read from the given reader until EOF, which is aborted when the given context
+	// is done. The given name replaces the file name, e.g. as default object name.
+	UploadStream(ctx context.Context, name string, content io.Reader, listener func(bytesTransferred int64)) error
+}
+
+// StatUploader is implemented by uploaders, which can retrieve the last modification time of the uploaded object
+type StatUploader interface {
+	// LastModified returns the last modification time of the object, to which the file is uploaded.
+	// The returned flag is false if the object does not exist. Zero time is returned if the storage does not report it.
+	LastModified(ctx context.Context, file *os.File) (time.Time, bool, error)
+}
+
+// VerifyingUploader is implemented by uploaders, which can download the uploaded object to verify its integrity
+type VerifyingUploader interface {
+	// VerifyFile downloads the object, to which the file was uploaded, and returns error if its content differs
+	// from the file content
+	VerifyFile(ctx context.Context, file *os.File) error
+}
+
+// CredentialsRefresher is implemented by uploaders, which can replace their storage credentials while uploading,
+// e.g. to continue a long upload with refreshed credentials, before the initial ones expire
+type CredentialsRefresher interface {
+	// RefreshCredentials replaces the credentials with the ones from the given 'start' operation options.
+	// The requests, which are already sent, are not affected.
+	RefreshCredentials(options map[string]string) error
+}
+
+// ErrVerificationFailed is returned when the content of the uploaded object differs from the uploaded file
+var ErrVerificationFailed = errors.New("uploaded object verification failed - content differs from the uploaded file")
+
+// HTTPError is returned from HTTPUploader when the upload request completes with a non-successful status code
+type HTTPError struct {
+	Code       int
+	Status     string
+	RetryAfter time.Duration // delay requested with the 'Retry-After' header of 429 and 503 responses, zero if none
+}
+
+// newHTTPError returns the error for the given non-successful response
+func newHTTPError(resp *http.Response) *HTTPError {
+	err := &HTTPError{Code: resp.StatusCode, Status: resp.Status}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		err.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+
+	return err
+}
+
+// parseRetryAfter returns the delay, specified with the given 'Retry-After' header value as a number of seconds
+// or an HTTP date, relative to the given time. Returns zero, if the value is missing, invalid or in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("upload failed - code: %d, status: %s", e.Code, e.Status)
+}
+
+// StatusCode returns the response status code of the failed upload request
+func (e *HTTPError) StatusCode() int {
+	return e.Code
+}
+
 // HTTPUploader handles generic HTTP uploads
 type HTTPUploader struct {
-	url          string
-	headers      map[string]string
-	method       string
-	serverCert   string
-	cipherSuites []uint16
+	url             string
+	headers         map[string]string
+	method          string
+	contentEncoding string
+	contentType     string
+	confirmHead     bool
+	proxy           string
+	timeout         time.Duration
+	headerTimeout   time.Duration
+	expectBody      *regexp.Regexp
+	checksumHeader  string
+	checksumBase64  bool
+	checksumSHA256  bool   // SHA-256 instead of MD5 checksum in the checksum header, replacing the Content-MD5 header
+	manifestURL     string // checksum manifest upload URL, if the checksum is reported in a manifest
+	verifyURL       string
+	serverCert      string
+	clientCert      string
+	clientKey       string
+	tlsPolicy       *tlsPolicy
+	form            *multipartForm // nil, unless the body is sent as 'multipart/form-data'
+	chunked         bool           // content of unknown size can be sent with chunked transfer encoding
 }
 
-// NewHTTPUploader construct new HttpUploader from the provided 'start' operation options
+// NewHTTPUploader construct new HttpUploader from the provided 'start' operation options. If a checksum header
+// is specified, the MD5 (default) or SHA-256 checksum of the uploaded content is sent in it, hex (default) or base64
+// encoded, regardless if checksums are enabled, for endpoints validating checksums in a custom header. When checksums
+// are enabled, the checksum is sent in the Content-MD5 header, unless the SHA-256 checksum header replaces it, or, with 'manifest' checksum location, in a checksum
+// manifest, uploaded to the checksum manifest URL after the file. With 'multipart' body format, the file is sent
+// as a 'multipart/form-data' form field, preceded by the extra form fields, and the Content-MD5 checksum covers
+// the whole request body. HTTPS connections use TLS 1.2 or later with secure cipher suites, further restricted
+// by the 'tls.' options, if specified. Content of unknown size, e.g. compressed on the fly, is streamed with chunked
+// transfer encoding only if allowed with the 'https.chunked' option, since some storages, e.g. S3 pre-signed URLs,
+// require the content length.
 func NewHTTPUploader(options map[string]string, serverCert string) (Uploader, error) {
 	url := options[URLProp]
 	if url == "" {
@@ -71,13 +278,223 @@ func NewHTTPUploader(options map[string]string, serverCert string) (Uploader, er
 		method = strings.ToUpper(method)
 	}
 
-	if method != "PUT" && method != "POST" {
+	if method != "PUT" && method != "POST" && method != "PATCH" {
 		return nil, fmt.Errorf("unsupported HTTP method: %s", method)
 	}
 
+	contentType := options[ContentTypeProp]
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+
+	confirmHead := false
+	if value, ok := options[ConfirmHeadProp]; ok {
+		var err error
+		if confirmHead, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for parameter '%s'", value, ConfirmHeadProp)
+		}
+	}
+
+	proxy := options[ProxyProp]
+	if proxy != "" && !isValidProxy(proxy) {
+		return nil, fmt.Errorf("invalid value '%s' for parameter '%s'", proxy, ProxyProp)
+	}
+
+	timeout, err := parseTimeout(options, TimeoutProp)
+	if err != nil {
+		return nil, err
+	}
+
+	headerTimeout, err := parseTimeout(options, ResponseHeaderTimeoutProp)
+	if err != nil {
+		return nil, err
+	}
+
+	clientCert, clientKey := options[ClientCertProp], options[ClientKeyProp]
+	if clientCert != "" && clientKey == "" {
+		return nil, fmt.Errorf(missingParameterErrMsg, ClientKeyProp)
+	}
+	if clientKey != "" && clientCert == "" {
+		return nil, fmt.Errorf(missingParameterErrMsg, ClientCertProp)
+	}
+
+	var expectBody *regexp.Regexp
+	if pattern := options[ExpectBodyRegexProp]; pattern != "" {
+		if expectBody, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for parameter '%s': %w", pattern, ExpectBodyRegexProp, err)
+		}
+	}
+
+	checksumBase64 := false
+	switch strings.ToLower(options[ChecksumEncodingProp]) {
+	case "", ChecksumEncodingHex:
+	case ChecksumEncodingBase64:
+		checksumBase64 = true
+	default:
+		return nil, fmt.Errorf("invalid value '%s' for parameter '%s', supported values are '%s' and '%s'",
+			options[ChecksumEncodingProp], ChecksumEncodingProp, ChecksumEncodingHex, ChecksumEncodingBase64)
+	}
+
+	algorithm, err := parseChecksumAlgorithm(options, ChecksumAlgorithmProp)
+	if err != nil {
+		return nil, err
+	}
+	checksumSHA256 := algorithm == ChecksumAlgorithmSHA256
+	if checksumSHA256 && options[ChecksumHeaderProp] == "" {
+		return nil, fmt.Errorf(missingParameterErrMsg, ChecksumHeaderProp)
+	}
+
+	location, err := parseChecksumLocation(options, ChecksumLocationProp, ChecksumLocationHeader, ChecksumLocationManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestURL := ""
+	if location == ChecksumLocationManifest {
+		if manifestURL = options[ChecksumManifestURLProp]; manifestURL == "" {
+			return nil, fmt.Errorf(missingParameterErrMsg, ChecksumManifestURLProp)
+		}
+	}
+
+	policy, _, err := parseTLSPolicy(options)
+	if err != nil {
+		return nil, err
+	}
+
+	form, err := parseMultipartForm(options)
+	if err != nil {
+		return nil, err
+	}
+
+	chunked := false
+	if value, ok := options[ChunkedProp]; ok {
+		if chunked, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for parameter '%s'", value, ChunkedProp)
+		}
+	}
+
 	headers := ExtractDictionary(options, HeadersPrefix)
 
-	return &HTTPUploader{url, headers, method, serverCert, SupportedCipherSuites()}, nil
+	authorization, err := getAuthorization(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if authorization != "" {
+		for name := range headers {
+			if strings.EqualFold(name, authorizationHeader) {
+				logger.Warnf("'%s%s' option overridden by the authentication options", HeadersPrefix, name)
+				delete(headers, name)
+			}
+		}
+		headers[authorizationHeader] = authorization
+	}
+
+	return &HTTPUploader{url, headers, method, options[ContentEncodingProp], contentType, confirmHead, proxy,
+		timeout, headerTimeout, expectBody, options[ChecksumHeaderProp], checksumBase64, checksumSHA256, manifestURL, options[VerifyURLProp], serverCert, clientCert, clientKey,
+		policy, form, chunked}, nil
+}
+
+// getAuthorization returns the value of the Authorization header for the bearer token or basic authentication options,
+// or empty string if none of them is specified
+func getAuthorization(options map[string]string) (string, error) {
+	token := options[AuthBearerProp]
+	user := options[AuthBasicUserProp]
+
+	if token != "" && user != "" {
+		return "", fmt.Errorf("parameters '%s' and '%s' are mutually exclusive", AuthBearerProp, AuthBasicUserProp)
+	}
+
+	if token != "" {
+		return "Bearer " + token, nil
+	}
+
+	if user != "" {
+		credentials := user + ":" + options[AuthBasicPasswordProp]
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials)), nil
+	}
+
+	if options[AuthBasicPasswordProp] != "" {
+		return "", fmt.Errorf(missingParameterErrMsg, AuthBasicUserProp)
+	}
+
+	return "", nil
+}
+
+// parseTimeout parses the timeout option with the given name. Zero or missing timeout means no timeout.
+func parseTimeout(options map[string]string, name string) (time.Duration, error) {
+	value, ok := options[name]
+	if !ok || value == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("invalid value '%s' for parameter '%s'", value, name)
+	}
+
+	return timeout, nil
+}
+
+// parseRetention parses the retention mode and the RFC 3339 retain until date options with the given names.
+// Both options should be either missing or specified together. The mode is matched case-insensitively against
+// the given supported modes and is returned in their spelling. The retain until date should be in the future.
+func parseRetention(options map[string]string, modeName string, untilName string, modes ...string) (string, *time.Time, error) {
+	value, until := options[modeName], options[untilName]
+	if value == "" && until == "" {
+		return "", nil, nil
+	}
+
+	if value == "" {
+		return "", nil, fmt.Errorf(missingParameterErrMsg, modeName)
+	}
+	if until == "" {
+		return "", nil, fmt.Errorf(missingParameterErrMsg, untilName)
+	}
+
+	mode := ""
+	for _, m := range modes {
+		if strings.EqualFold(m, value) {
+			mode = m
+		}
+	}
+	if mode == "" {
+		return "", nil, fmt.Errorf("invalid value '%s' for parameter '%s', supported values are %v", value, modeName, modes)
+	}
+
+	t, err := time.Parse(time.RFC3339, until)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid value '%s' for parameter '%s': %w", until, untilName, err)
+	}
+	if !t.After(time.Now()) {
+		return "", nil, fmt.Errorf("invalid value '%s' for parameter '%s', date should be in the future", until, untilName)
+	}
+
+	return mode, &t, nil
+}
+
+func isValidProxy(proxy string) bool {
+	proxyURL, err := url.Parse(proxy)
+
+	return err == nil && proxyURL.Scheme != "" && proxyURL.Host != ""
+}
+
+// getProxy returns the proxy selection function for the upload requests. The proxy from the 'https.proxy' option
+// is used for all hosts, except the ones excluded by the NO_PROXY environment variable. If no proxy is specified,
+// it is selected from the environment.
+func (u *HTTPUploader) getProxy() func(*http.Request) (*url.URL, error) {
+	if u.proxy == "" {
+		return http.ProxyFromEnvironment
+	}
+
+	cfg := httpproxy.FromEnvironment()
+	cfg.HTTPProxy = u.proxy
+	cfg.HTTPSProxy = u.proxy
+	proxy := cfg.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
 }
 
 func (u *HTTPUploader) getHTTPTransport() (*http.Transport, error) {
@@ -92,70 +509,569 @@ func (u *HTTPUploader) getHTTPTransport() (*http.Transport, error) {
 		caCertPool.AppendCertsFromPEM(caCert)
 	}
 
-	config := &tls.Config{ // using the system CA pool
-		InsecureSkipVerify: false,
-		RootCAs:            caCertPool,
-		MinVersion:         tls.VersionTLS12,
-		MaxVersion:         tls.VersionTLS13,
-		CipherSuites:       u.cipherSuites,
+	var certificates []tls.Certificate
+	if len(u.clientCert) > 0 {
+		keyPair, err := tls.LoadX509KeyPair(u.clientCert, u.clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error reading x509 key pair files(\"%s, %s\") - %v", u.clientCert, u.clientKey, err)
+		}
+		certificates = []tls.Certificate{keyPair}
 	}
+
+	config := u.tlsPolicy.config() // using the system CA pool, unless a server certificate is specified
+	config.RootCAs = caCertPool
+	config.Certificates = certificates
 	return &http.Transport{
+		Proxy:           u.getProxy(),
 		TLSClientConfig: config,
 	}, nil
 }
 
+// getHTTPClient creates a client for the upload URL, using the configured proxy, TLS settings and timeouts
+func (u *HTTPUploader) getHTTPClient() (*http.Client, error) {
+	parsedURL, _ := url.Parse(u.url) // MUST not return error, since the URL is validated on construction
+	transport := &http.Transport{Proxy: u.getProxy()}
+	if parsedURL.Scheme == "https" {
+		var err error
+		if transport, err = u.getHTTPTransport(); err != nil {
+			return nil, err
+		}
+	}
+	transport.ResponseHeaderTimeout = u.headerTimeout
+
+	return &http.Client{Transport: transport, Timeout: u.timeout}, nil
+}
+
 // UploadFile performs generic HTTP file upload
 func (u *HTTPUploader) UploadFile(file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
+	return u.UploadFileContext(context.Background(), file, useChecksum, listener)
+}
+
+// UploadFileContext performs generic HTTP file upload, which is aborted when the given context is done
+func (u *HTTPUploader) UploadFileContext(ctx context.Context, file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
+	return u.UploadFileFrom(ctx, file, 0, useChecksum, listener)
+}
+
+// UploadFileFrom performs generic HTTP upload of the file content from the given offset, which is aborted when
+// the given context is done. If the offset is positive, the uploaded range is specified with the 'Content-Range'
+// header and the checksum covers only the uploaded range. Uploads in 'multipart' body format cannot be resumed
+// from a positive offset.
+func (u *HTTPUploader) UploadFileFrom(ctx context.Context, file *os.File, offset int64, useChecksum bool, listener func(bytesTransferred int64)) error {
 	stats, err := file.Stat()
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest(u.method, u.url, file)
+	if offset < 0 || (offset > 0 && offset >= stats.Size()) {
+		return fmt.Errorf("invalid upload offset %d for file of size %d", offset, stats.Size())
+	}
+
+	body := io.Reader(file)
+	if offset > 0 {
+		body = io.NewSectionReader(file, offset, stats.Size()-offset)
+	}
+
+	contentType := u.contentType
+	var head, tail []byte // multipart body parts around the file content
+	if u.form != nil {
+		if offset > 0 {
+			return fmt.Errorf("upload from offset %d is not supported with '%s' body format", offset, BodyFormatMultipart)
+		}
+		if head, tail, contentType, err = u.form.envelope(file.Name(), u.contentType, u.contentEncoding); err != nil {
+			return err
+		}
+		body = io.MultiReader(bytes.NewReader(head), file, bytes.NewReader(tail))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, u.method, u.url, body)
 	if err != nil {
 		return err
 	}
 
-	parsedURL, _ := url.Parse(u.url) // MUST not return error, since http(s) request was done to that url
-	transport := &http.Transport{}
-	if parsedURL.Scheme == "https" {
-		transport, err = u.getHTTPTransport()
-		if err != nil {
+	client, err := u.getHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	u.setHeaders(req, contentType)
+
+	if offset > 0 {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, stats.Size()-1, stats.Size()))
+	}
+
+	if useChecksum || u.checksumHeader != "" {
+		h, bodyHash := md5.New(), md5.New()
+		if u.checksumSHA256 {
+			h = sha256.New()
+		}
+		bodyHash.Write(head)
+		if _, err := io.Copy(io.MultiWriter(h, bodyHash), io.NewSectionReader(file, offset, stats.Size()-offset)); err != nil {
 			return err
 		}
+		bodyHash.Write(tail)
+		sum := h.Sum(nil)
+
+		if useChecksum && u.manifestURL == "" && !u.checksumSHA256 {
+			req.Header.Set(ContentMD5, base64.StdEncoding.EncodeToString(bodyHash.Sum(nil)))
+		}
+		if u.checksumHeader != "" {
+			if u.checksumBase64 {
+				req.Header.Set(u.checksumHeader, base64.StdEncoding.EncodeToString(sum))
+			} else {
+				req.Header.Set(u.checksumHeader, hex.EncodeToString(sum))
+			}
+		}
 	}
 
-	req.Header.Set("Content-Type", "application/x-binary")
-	for name, value := range u.headers {
-		req.Header.Set(name, value)
+	var manifest []byte // computed in advance, as the file is closed by the request
+	if useChecksum && u.manifestURL != "" {
+		if manifest, err = u.checksumManifest(file, stats.Size()); err != nil {
+			return err
+		}
+	}
+
+	req.ContentLength = int64(len(head)) + stats.Size() - offset + int64(len(tail))
+	// Send the HTTP(S) request and get its response.
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if err := u.checkResponse(resp); err != nil {
+		return err
 	}
 
-	if useChecksum {
-		md5, err := ComputeMD5(file, true)
+	if u.confirmHead {
+		if err := u.confirmUpload(ctx, client, stats.Size(), resp.Header.Get("ETag")); err != nil {
+			return err
+		}
+	}
+
+	if manifest != nil {
+		return u.uploadManifest(ctx, client, manifest)
+	}
+
+	return nil
+}
+
+// CanStream returns true, if chunked transfer encoding is allowed and no checksum header, checksum manifest
+// or upload confirmation is configured, which need the whole content in advance
+func (u *HTTPUploader) CanStream() bool {
+	return u.chunked && u.checksumHeader == "" && u.manifestURL == "" && !u.confirmHead
+}
+
+// UploadStream performs generic HTTP upload of content of unknown size with chunked transfer encoding, which
+// is aborted when the given context is done. With 'multipart' body format, the given name is the file name
+// of the file form field.
+func (u *HTTPUploader) UploadStream(ctx context.Context, name string, content io.Reader, listener func(bytesTransferred int64)) error {
+	if !u.CanStream() {
+		return errors.New("upload of content with unknown size is not supported by the HTTP upload configuration")
+	}
+
+	if listener != nil {
+		content = &progressReader{r: content, listener: listener}
+	}
+
+	body, contentType := content, u.contentType
+	if u.form != nil {
+		head, tail, formType, err := u.form.envelope(name, u.contentType, u.contentEncoding)
 		if err != nil {
 			return err
 		}
-		req.Header.Set(ContentMD5, md5)
+		body, contentType = io.MultiReader(bytes.NewReader(head), content, bytes.NewReader(tail)), formType
 	}
 
-	req.ContentLength = stats.Size()
-	// Send the HTTP(S) request and get its response.
-	client := &http.Client{Transport: transport}
+	req, err := http.NewRequestWithContext(ctx, u.method, u.url, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = -1 // unknown, sent chunked
+
+	client, err := u.getHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	u.setHeaders(req, contentType)
+
 	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return u.checkResponse(resp)
+}
+
+// setHeaders sets the content type, the content encoding and the configured headers of the upload request
+func (u *HTTPUploader) setHeaders(req *http.Request, contentType string) {
+	req.Header.Set("Content-Type", contentType)
+	if u.contentEncoding != "" && u.form == nil { // set on the file part of multipart body
+		req.Header.Set("Content-Encoding", u.contentEncoding)
+	}
+	for name, value := range u.headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// checkResponse fails if the upload response status is not successful or its body does not match the expected pattern
+func (u *HTTPUploader) checkResponse(resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newHTTPError(resp)
+	}
+
+	if u.expectBody != nil {
+		return u.checkResponseBody(resp.Body)
+	}
+
+	return nil
+}
+
+// checksumManifest returns the checksum manifest of the whole file, named after the last element of the upload URL path
+func (u *HTTPUploader) checksumManifest(file *os.File, size int64) ([]byte, error) {
+	h := md5.New()
+	if _, err := io.Copy(h, io.NewSectionReader(file, 0, size)); err != nil {
+		return nil, err
+	}
+
+	name := u.url
+	if uploadURL, err := url.Parse(u.url); err == nil {
+		name = path.Base(uploadURL.Path)
+	}
+
+	return checksumManifest(name, h.Sum(nil))
+}
+
+// uploadManifest uploads the given checksum manifest to the checksum manifest URL
+func (u *HTTPUploader) uploadManifest(ctx context.Context, client *http.Client, manifest []byte) error {
+	req, err := http.NewRequestWithContext(ctx, u.method, u.manifestURL, bytes.NewReader(manifest))
+	if err != nil {
+		return err
+	}
+
+	for name, value := range u.headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload checksum manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to upload checksum manifest: %w", newHTTPError(resp))
+	}
+
+	return nil
+}
+
+// checkResponseBody fails if the response body does not match the expected pattern. Only the beginning of the body,
+// up to maxExpectedBodySize bytes, is read and matched.
+func (u *HTTPUploader) checkResponseBody(body io.Reader) error {
+	data, err := ioutil.ReadAll(io.LimitReader(body, maxExpectedBodySize))
+	if err != nil {
+		return fmt.Errorf("failed to read upload response body: %w", err)
+	}
+
+	if !u.expectBody.Match(data) {
+		return fmt.Errorf("upload failed - response body does not match pattern '%s'", u.expectBody)
+	}
 
+	return nil
+}
+
+// confirmUpload issues a HEAD request to the upload URL to confirm that the uploaded object exists and its size
+// and ETag (if returned) match the uploaded ones
+func (u *HTTPUploader) confirmUpload(ctx context.Context, client *http.Client, size int64, etag string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.url, nil)
 	if err != nil {
 		return err
 	}
 
+	for name, value := range u.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload confirmation failed: %w", err)
+	}
+
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return fmt.Errorf("upload failed - code: %d, status: %s", resp.StatusCode, resp.Status)
+		return fmt.Errorf("upload confirmation failed: %w", newHTTPError(resp))
+	}
+
+	if resp.ContentLength >= 0 && resp.ContentLength != size {
+		return fmt.Errorf("upload confirmation failed - uploaded size: %d, stored size: %d", size, resp.ContentLength)
+	}
+
+	if confirmedETag := resp.Header.Get("ETag"); etag != "" && confirmedETag != "" && etag != confirmedETag {
+		return fmt.Errorf("upload confirmation failed - uploaded ETag: %s, stored ETag: %s", etag, confirmedETag)
 	}
 
 	return nil
 }
 
+// LastModified issues a HEAD request to the upload URL and returns the time from the 'Last-Modified' response header
+func (u *HTTPUploader) LastModified(ctx context.Context, file *os.File) (time.Time, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.url, nil)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	for name, value := range u.headers {
+		req.Header.Set(name, value)
+	}
+
+	client, err := u.getHTTPClient()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return time.Time{}, false, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return time.Time{}, false, newHTTPError(resp)
+	}
+
+	lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err != nil {
+		return time.Time{}, true, nil
+	}
+
+	return lastModified, true, nil
+}
+
+// VerifyFile downloads the uploaded content with a GET request and compares it to the file content. The content is
+// downloaded from the 'https.verify.url' option, if specified, e.g. when the pre-signed upload URL allows only upload.
+func (u *HTTPUploader) VerifyFile(ctx context.Context, file *os.File) error {
+	url := u.verifyURL
+	if url == "" {
+		url = u.url
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range u.headers {
+		req.Header.Set(name, value)
+	}
+
+	client, err := u.getHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newHTTPError(resp)
+	}
+
+	return verifyContent(file, resp.Body)
+}
+
+// verifyContent returns ErrVerificationFailed, if the MD5 checksum of the given downloaded content differs
+// from the checksum of the file. The file is reopened, as it might be closed by the upload, e.g. HTTP request
+// bodies are closed when sent.
+func verifyContent(file *os.File, content io.Reader) error {
+	uploaded, err := os.Open(file.Name())
+	if err != nil {
+		return err
+	}
+	defer uploaded.Close()
+
+	expected, err := ComputeMD5(uploaded, false)
+	if err != nil {
+		return err
+	}
+
+	h := md5.New()
+	if _, err := io.Copy(h, content); err != nil {
+		return fmt.Errorf("failed to download the uploaded object: %w", err)
+	}
+
+	if string(h.Sum(nil)) != expected {
+		return ErrVerificationFailed
+	}
+
+	return nil
+}
+
+// IsAuthorizationError returns true if the given upload error is caused by rejected credentials, i.e. the storage
+// responded with HTTP status 401 (Unauthorized) or 403 (Forbidden). Errors from all supported providers are recognized.
+func IsAuthorizationError(err error) bool {
+	code := errorStatusCode(err)
+
+	return code == http.StatusUnauthorized || code == http.StatusForbidden
+}
+
+// IsUnavailableError returns true if the given upload error indicates, that the storage endpoint is unavailable,
+// i.e. it responded with HTTP server error status (5xx) or could not be reached at all. Canceled uploads are not
+// considered unavailability errors. Errors from all supported providers are recognized.
+func IsUnavailableError(err error) bool {
+	if code := errorStatusCode(err); code != 0 {
+		return code >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && !errors.Is(err, context.Canceled)
+}
+
+// RetryAfter returns the delay, requested by the storage with the 'Retry-After' header of the 429 (Too Many Requests)
+// or 503 (Service Unavailable) response, which caused the given generic HTTP upload error, or zero if none
+func RetryAfter(err error) time.Duration {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.RetryAfter
+	}
+
+	return 0
+}
+
+// errorStatusCode returns the HTTP status code of the storage response, which caused the given error, or zero
+// if the error is not caused by an unsuccessful response. Errors from all supported providers are recognized.
+func errorStatusCode(err error) int {
+	var statusErr interface{ StatusCode() int } // generic HTTP and Azure errors
+	var awsErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode()
+	} else if errors.As(err, &awsErr) {
+		return awsErr.HTTPStatusCode()
+	}
+
+	return 0
+}
+
+// ResolveObjectKeyTemplate returns the object key, resolved from the given template by replacing the placeholders:
+//   - {filename} - the file name, e.g. 'app.log'
+//   - {basename} - the file name without extension, e.g. 'app'
+//   - {ext} - the file extension without the dot, e.g. 'log'
+//   - {date} - the UTC date, e.g. '2024-06-17'
+//   - {datetime} - the UTC date and time, e.g. '2024-06-17T08-30-00Z'
+//   - {deviceId} - the device ID
+//   - {correlationId} - the upload correlation ID
+//
+// Returns error, if the template contains unknown placeholders.
+func ResolveObjectKeyTemplate(template string, values *ObjectKeyValues) (string, error) {
+	filename := filepath.Base(values.FilePath)
+	ext := filepath.Ext(filename)
+	utc := values.Time.UTC()
+
+	var unknown []string
+	key := objectKeyPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		switch name := placeholder[1 : len(placeholder)-1]; name {
+		case "filename":
+			return filename
+		case "basename":
+			return strings.TrimSuffix(filename, ext)
+		case "ext":
+			return strings.TrimPrefix(ext, ".")
+		case "date":
+			return utc.Format("2006-01-02")
+		case "datetime":
+			return utc.Format("2006-01-02T15-04-05Z")
+		case "deviceId":
+			return values.DeviceID
+		case "correlationId":
+			return values.CorrelationID
+		default:
+			unknown = append(unknown, placeholder)
+			return placeholder
+		}
+	})
+
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown placeholders %s in object key template '%s'", strings.Join(unknown, ", "), template)
+	}
+
+	return key, nil
+}
+
+// NewObjectKeyNamer returns the namer of the objects from the object key template in the given 'start' operation
+// options, or nil if no template is specified. The placeholder values, not specified with the options, are taken from
+// the given defaults (if not nil). Returns error, if the template contains unknown placeholders or the time is invalid.
+func NewObjectKeyNamer(options map[string]string, defaults *ObjectKeyValues) (*ObjectKeyNamer, error) {
+	template := options[ObjectKeyTemplate]
+	if template == "" {
+		return nil, nil
+	}
+
+	namer := &ObjectKeyNamer{template: template}
+	if defaults != nil {
+		namer.values = *defaults
+	}
+
+	if value, ok := options[ObjectKeyDeviceID]; ok {
+		namer.values.DeviceID = value
+	}
+	if value, ok := options[ObjectKeyCorrelationID]; ok {
+		namer.values.CorrelationID = value
+	}
+	if value, ok := options[ObjectKeyTime]; ok {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for parameter '%s'", value, ObjectKeyTime)
+		}
+		namer.values.Time = t
+	} else if namer.values.Time.IsZero() {
+		namer.values.Time = time.Now()
+	}
+
+	if _, err := ResolveObjectKeyTemplate(template, &namer.values); err != nil {
+		return nil, err
+	}
+
+	return namer, nil
+}
+
+// Key returns the key of the object, to which the file with the given path is uploaded, or empty string for nil namer
+func (n *ObjectKeyNamer) Key(filePath string) string {
+	if n == nil {
+		return ""
+	}
+
+	values := n.values
+	values.FilePath = filePath
+	key, _ := ResolveObjectKeyTemplate(n.template, &values) // MUST not return error, since the template is validated on construction
+
+	return key
+}
+
+// ObjectTags returns the tags of the uploaded objects from the given 'start' operation options, or nil if none
+func ObjectTags(options map[string]string) map[string]string {
+	tags := ExtractDictionary(options, TagPrefix)
+	if len(tags) == 0 {
+		return nil
+	}
+
+	return tags
+}
+
 // ExtractDictionary extracts from the given map properties with a specified prefix.
 // In the resulting dictionary, property names have the prefix removed.
 func ExtractDictionary(options map[string]string, prefix string) map[string]string {
@@ -191,6 +1107,18 @@ func ComputeMD5(f *os.File, encodeBase64 bool) (string, error) {
 	return encoded, nil
 }
 
+// ComputeSHA256 returns the hex encoded SHA-256 hash of a file.
+func ComputeSHA256(f *os.File) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	f.Seek(0, 0)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // SupportedCipherSuites returns the ids of secure TLS cipher suites
 func SupportedCipherSuites() []uint16 {
 	cs := tls.CipherSuites()
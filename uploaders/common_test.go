@@ -15,15 +15,31 @@
 package uploaders
 
 import (
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 const (
@@ -253,6 +269,137 @@ func TestHTTPSUploadPOSTWithChecksum(t *testing.T) {
 	testHTTPUploadMethod(t, "POST", true, true, "", "")
 }
 
+func TestHTTPUploadPATCHWithoutChecksum(t *testing.T) {
+	testHTTPUploadMethod(t, "PATCH", false, false, "", "")
+}
+
+func TestHTTPSUploadPATCHWithChecksum(t *testing.T) {
+	setSSLCerts(t)
+	defer unsetSSLCerts(t)
+	testHTTPUploadMethod(t, "PATCH", true, true, "", "")
+}
+
+func TestHTTPUploadContentType(t *testing.T) {
+	testHTTPUploadContentType(t, map[string]string{}, "application/x-binary")
+	testHTTPUploadContentType(t, map[string]string{ContentTypeProp: "application/octet-stream"}, "application/octet-stream")
+}
+
+func testHTTPUploadContentType(t *testing.T, options map[string]string, expected string) {
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+
+	defer f.Close()
+	defer handler.reset()
+
+	options[URLProp] = "http://localhost:1234/up"
+	options[MethodProp] = "patch"
+
+	u, err := NewHTTPUploader(options, "")
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(f, false, nil))
+
+	assertStringsSame(t, "request method", "PATCH", handler.method)
+	assertStringsSame(t, "content type", expected, handler.headers.Get("Content-Type"))
+}
+
+func TestHTTPUploadChecksumHeader(t *testing.T) {
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	h := md5.New()
+	_, err = io.Copy(h, f)
+	assertNoError(t, err)
+	sum := h.Sum(nil)
+
+	testHTTPUploadChecksumHeader(t, map[string]string{ChecksumHeaderProp: "X-Checksum"}, false,
+		"X-Checksum", hex.EncodeToString(sum), "")
+	testHTTPUploadChecksumHeader(t, map[string]string{ChecksumHeaderProp: "X-Checksum", ChecksumEncodingProp: "BASE64"}, false,
+		"X-Checksum", base64.StdEncoding.EncodeToString(sum), "")
+	testHTTPUploadChecksumHeader(t, map[string]string{ChecksumHeaderProp: "X-Content-Digest", ChecksumEncodingProp: ChecksumEncodingHex}, true,
+		"X-Content-Digest", hex.EncodeToString(sum), base64.StdEncoding.EncodeToString(sum))
+	testHTTPUploadChecksumHeader(t, map[string]string{ChecksumHeaderProp: "X-Checksum", ChecksumAlgorithmProp: ChecksumAlgorithmMD5}, true,
+		"X-Checksum", hex.EncodeToString(sum), base64.StdEncoding.EncodeToString(sum))
+
+	_, err = f.Seek(0, io.SeekStart)
+	assertNoError(t, err)
+	h = sha256.New()
+	_, err = io.Copy(h, f)
+	assertNoError(t, err)
+	sum = h.Sum(nil)
+
+	// the SHA-256 checksum header replaces the Content-MD5 header
+	testHTTPUploadChecksumHeader(t, map[string]string{ChecksumHeaderProp: "X-Content-SHA256", ChecksumAlgorithmProp: "SHA256",
+		ChecksumEncodingProp: ChecksumEncodingBase64}, true, "X-Content-SHA256", base64.StdEncoding.EncodeToString(sum), "")
+	testHTTPUploadChecksumHeader(t, map[string]string{ChecksumHeaderProp: "X-Content-SHA256", ChecksumAlgorithmProp: ChecksumAlgorithmSHA256}, false,
+		"X-Content-SHA256", hex.EncodeToString(sum), "")
+}
+
+func testHTTPUploadChecksumHeader(t *testing.T, options map[string]string, useChecksum bool, header string, expected string, expectedMD5 string) {
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+
+	defer f.Close()
+	defer handler.reset()
+
+	options[URLProp] = "http://localhost:1234/up"
+
+	u, err := NewHTTPUploader(options, "")
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(f, useChecksum, nil))
+
+	assertStringsSame(t, "checksum header", expected, handler.headers.Get(header))
+	assertStringsSame(t, "Content-MD5 header", expectedMD5, handler.headers.Get(ContentMD5))
+}
+
+func TestHTTPVerifyFile(t *testing.T) {
+	var stored []byte
+	corrupt := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			stored, _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			if r.URL.Path != "/download" || r.Header.Get("X-Test") != "test" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if corrupt {
+				w.Write([]byte("corrupted"))
+			} else {
+				w.Write(stored)
+			}
+		}
+	}))
+	defer server.Close()
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	u, err := NewHTTPUploader(map[string]string{
+		URLProp:                  server.URL + "/upload",
+		VerifyURLProp:            server.URL + "/download",
+		HeadersPrefix + "X-Test": "test",
+	}, "")
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(f, false, nil))
+
+	verifier := u.(VerifyingUploader)
+	assertNoError(t, verifier.VerifyFile(context.Background(), f))
+
+	corrupt = true
+	if err := verifier.VerifyFile(context.Background(), f); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("verification error expected, got %v", err)
+	}
+
+	u, err = NewHTTPUploader(map[string]string{URLProp: server.URL + "/upload"}, "") // no download from upload URL
+	assertNoError(t, err)
+	if err := u.(VerifyingUploader).VerifyFile(context.Background(), f); errorStatusCode(err) != http.StatusNotFound {
+		t.Errorf("not found error expected, got %v", err)
+	}
+}
+
 func TestNewHttpUploaderErrors(t *testing.T) {
 	options := map[string]string{}
 
@@ -266,6 +413,541 @@ func TestNewHttpUploaderErrors(t *testing.T) {
 	u, err = NewHTTPUploader(options, "")
 	assertNil(t, u)
 	assertError(t, err)
+
+	delete(options, MethodProp)
+	options[ConfirmHeadProp] = "maybe"
+
+	u, err = NewHTTPUploader(options, "")
+	assertNil(t, u)
+	assertError(t, err)
+
+	delete(options, ConfirmHeadProp)
+	options[ChunkedProp] = "maybe"
+
+	u, err = NewHTTPUploader(options, "")
+	assertNil(t, u)
+	assertError(t, err)
+
+	delete(options, ChunkedProp)
+	options[ChecksumEncodingProp] = "base32"
+
+	u, err = NewHTTPUploader(options, "")
+	assertNil(t, u)
+	assertError(t, err)
+
+	delete(options, ChecksumEncodingProp)
+	options[ChecksumAlgorithmProp] = "sha1"
+
+	u, err = NewHTTPUploader(options, "")
+	assertNil(t, u)
+	assertError(t, err)
+
+	options[ChecksumAlgorithmProp] = ChecksumAlgorithmSHA256 // without checksum header
+
+	u, err = NewHTTPUploader(options, "")
+	assertNil(t, u)
+	assertError(t, err)
+
+	delete(options, ChecksumAlgorithmProp)
+	for _, prop := range []string{TimeoutProp, ResponseHeaderTimeoutProp} {
+		for _, value := range []string{"10", "-1s"} {
+			u, err = NewHTTPUploader(map[string]string{URLProp: "http://localhost/up", prop: value}, "")
+			assertNil(t, u)
+			assertError(t, err)
+		}
+	}
+}
+
+func TestHTTPUploadConfirmHead(t *testing.T) {
+	stats, err := os.Stat(testFile)
+	assertNoError(t, err)
+
+	size := strconv.FormatInt(stats.Size(), 10)
+	tests := []struct {
+		name     string
+		status   int
+		length   string
+		etag     string
+		expected bool
+	}{
+		{"confirmed", http.StatusOK, size, "\"test\"", true},
+		{"no size and etag", http.StatusOK, "", "", true},
+		{"not found", http.StatusNotFound, "", "", false},
+		{"size mismatch", http.StatusOK, "1", "", false},
+		{"etag mismatch", http.StatusOK, size, "\"other\"", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					if test.length != "" {
+						w.Header().Set("Content-Length", test.length)
+					}
+					if test.etag != "" {
+						w.Header().Set("ETag", test.etag)
+					}
+					w.WriteHeader(test.status)
+					return
+				}
+
+				ioutil.ReadAll(r.Body)
+				w.Header().Set("ETag", "\"test\"")
+			}))
+			defer server.Close()
+
+			u, err := NewHTTPUploader(map[string]string{URLProp: server.URL, ConfirmHeadProp: "true"}, "")
+			assertNoError(t, err)
+
+			f, err := os.Open(testFile)
+			assertNoError(t, err)
+			defer f.Close()
+
+			err = u.UploadFile(f, false, nil)
+			if test.expected {
+				assertNoError(t, err)
+			} else {
+				assertError(t, err)
+			}
+		})
+	}
+}
+
+func TestHTTPUploadFrom(t *testing.T) {
+	var contentRange, checksum string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentRange = r.Header.Get("Content-Range")
+		checksum = r.Header.Get(ContentMD5)
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	u, err := NewHTTPUploader(map[string]string{URLProp: server.URL}, "")
+	assertNoError(t, err)
+
+	file := writeSplitTestFile(t, []byte("0123456789"))
+	defer file.Close()
+
+	assertNoError(t, u.(ResumableUploader).UploadFileFrom(context.Background(), file, 4, true, nil))
+	assertStringsSame(t, "content range", "bytes 4-9/10", contentRange)
+	assertStringsSame(t, "body", "456789", string(body))
+	assertStringsSame(t, "checksum", "41z3tmRJ31Zfk8YH1agdCQ==", checksum) // MD5 of "456789"
+
+	assertNoError(t, u.(ResumableUploader).UploadFileFrom(context.Background(), file, 0, false, nil))
+	assertStringsSame(t, "content range", "", contentRange)
+	assertStringsSame(t, "body", "0123456789", string(body))
+
+	for _, offset := range []int64{-1, 10, 11} {
+		assertError(t, u.(ResumableUploader).UploadFileFrom(context.Background(), file, offset, false, nil))
+	}
+}
+
+func TestHTTPUploaderLastModified(t *testing.T) {
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name         string
+		status       int
+		lastModified string
+		expected     time.Time
+		exists       bool
+		fails        bool
+	}{
+		{"modified", http.StatusOK, modified.Format(http.TimeFormat), modified, true, false},
+		{"no last modified", http.StatusOK, "", time.Time{}, true, false},
+		{"not found", http.StatusNotFound, "", time.Time{}, false, false},
+		{"error", http.StatusForbidden, "", time.Time{}, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assertStringsSame(t, "request method", http.MethodHead, r.Method)
+				assertStringsSame(t, "header", "test", r.Header.Get("X-Test"))
+				if test.lastModified != "" {
+					w.Header().Set("Last-Modified", test.lastModified)
+				}
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			u, err := NewHTTPUploader(map[string]string{URLProp: server.URL, HeadersPrefix + "X-Test": "test"}, "")
+			assertNoError(t, err)
+
+			f, err := os.Open(testFile)
+			assertNoError(t, err)
+			defer f.Close()
+
+			lastModified, exists, err := u.(StatUploader).LastModified(context.Background(), f)
+			if test.fails {
+				assertError(t, err)
+				return
+			}
+
+			assertNoError(t, err)
+			assertDeepEquals(t, test.exists, exists)
+			if !lastModified.Equal(test.expected) {
+				t.Errorf("expected last modified %v, but was %v", test.expected, lastModified)
+			}
+		})
+	}
+}
+
+func TestHTTPUploadExpectBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected bool
+	}{
+		{"matching", `{"result":"ok"}`, true},
+		{"error body", `{"error":"quota exceeded"}`, false},
+		{"empty body", "", false},
+		{"match beyond limit", strings.Repeat(" ", maxExpectedBodySize) + `{"result":"ok"}`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ioutil.ReadAll(r.Body)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			u, err := NewHTTPUploader(map[string]string{URLProp: server.URL, ExpectBodyRegexProp: `"result":\s*"ok"`}, "")
+			assertNoError(t, err)
+
+			f, err := os.Open(testFile)
+			assertNoError(t, err)
+			defer f.Close()
+
+			err = u.UploadFile(f, false, nil)
+			if test.expected {
+				assertNoError(t, err)
+			} else {
+				assertError(t, err)
+			}
+		})
+	}
+}
+
+func TestHTTPUploadWithoutExpectBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.Write([]byte(`{"error":"quota exceeded"}`))
+	}))
+	defer server.Close()
+
+	u, err := NewHTTPUploader(map[string]string{URLProp: server.URL}, "")
+	assertNoError(t, err)
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	assertNoError(t, u.UploadFile(f, false, nil))
+}
+
+func TestNewHTTPUploaderInvalidExpectBody(t *testing.T) {
+	_, err := NewHTTPUploader(map[string]string{URLProp: "http://localhost:1234/up", ExpectBodyRegexProp: "[a-"}, "")
+	assertError(t, err)
+}
+
+// proxyStub is a forward proxy, recording the proxied requests. CONNECT requests are tunneled to the given address.
+type proxyStub struct {
+	tunnelAddr string
+
+	mutex    sync.Mutex
+	requests []string
+}
+
+func (p *proxyStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mutex.Lock()
+	p.requests = append(p.requests, r.Method+" "+r.RequestURI)
+	p.mutex.Unlock()
+
+	if r.Method != http.MethodConnect {
+		ioutil.ReadAll(r.Body)
+		return
+	}
+
+	target, err := net.Dial("tcp", p.tunnelAddr)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	defer target.Close()
+
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
+
+func (p *proxyStub) getRequests() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]string{}, p.requests...)
+}
+
+func TestHTTPUploadProxy(t *testing.T) {
+	stub := &proxyStub{}
+	proxy := httptest.NewServer(stub)
+	defer proxy.Close()
+
+	uploadThroughProxy(t, "http://upload.test/up", proxy.URL, "")
+
+	assertDeepEquals(t, []string{"PUT http://upload.test/up"}, stub.getRequests())
+}
+
+func TestHTTPSUploadProxy(t *testing.T) {
+	var body []byte
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	stub := &proxyStub{tunnelAddr: server.Listener.Addr().String()}
+	proxy := httptest.NewServer(stub)
+	defer proxy.Close()
+
+	// the TLS configuration, i.e. the trusted server certificate, applies to the tunneled connection
+	uploadThroughProxy(t, "https://example.com/up", proxy.URL, writeServerCert(t, server))
+
+	assertDeepEquals(t, []string{"CONNECT example.com:443"}, stub.getRequests())
+	assertStringsSame(t, "request body", testBody, string(body))
+}
+
+// writeServerCert writes the certificate of the test server to a PEM file, removed when the test ends
+func writeServerCert(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+
+	cert, err := os.CreateTemp(".", "cert")
+	assertNoError(t, err)
+	t.Cleanup(func() { os.Remove(cert.Name()) })
+
+	assertNoError(t, pem.Encode(cert, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	assertNoError(t, cert.Close())
+
+	return cert.Name()
+}
+
+func TestHTTPSUploadClientCert(t *testing.T) {
+	caCert, err := ioutil.ReadFile(validCert)
+	assertNoError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(caCert)
+
+	var body []byte
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCert := writeServerCert(t, server)
+	upload := func(options map[string]string) error {
+		u, err := NewHTTPUploader(options, serverCert)
+		assertNoError(t, err)
+
+		f, err := os.Open(testFile)
+		assertNoError(t, err)
+		defer f.Close()
+
+		return u.UploadFile(f, false, nil)
+	}
+
+	options := map[string]string{URLProp: server.URL, ClientCertProp: validCert, ClientKeyProp: validKey}
+	assertNoError(t, upload(options))
+	assertStringsSame(t, "request body", testBody, string(body))
+
+	// the server rejects connections without client certificate
+	assertError(t, upload(map[string]string{URLProp: server.URL}))
+
+	options[ClientCertProp] = "missing.pem"
+	assertError(t, upload(options))
+}
+
+func TestNewHTTPUploaderClientCertWithoutKey(t *testing.T) {
+	for _, options := range []map[string]string{
+		{URLProp: "https://localhost:2345/up", ClientCertProp: validCert},
+		{URLProp: "https://localhost:2345/up", ClientKeyProp: validKey},
+	} {
+		u, err := NewHTTPUploader(options, "")
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}
+
+func TestHTTPUploadNoProxy(t *testing.T) {
+	t.Setenv("NO_PROXY", "localhost,upload.test")
+
+	stub := &proxyStub{}
+	proxy := httptest.NewServer(stub)
+	defer proxy.Close()
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	u, err := NewHTTPUploader(map[string]string{URLProp: "http://upload.test/up", ProxyProp: proxy.URL}, "")
+	assertNoError(t, err)
+
+	assertError(t, u.UploadFile(f, false, nil)) // not resolved without the proxy
+	assertDeepEquals(t, []string{}, stub.getRequests())
+}
+
+func TestNewHTTPUploaderInvalidProxy(t *testing.T) {
+	for _, proxy := range []string{"localhost:3128", "://proxy", "http://"} {
+		u, err := NewHTTPUploader(map[string]string{URLProp: "http://localhost/up", ProxyProp: proxy}, "")
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}
+
+func uploadThroughProxy(t *testing.T, url string, proxy string, serverCert string) {
+	t.Helper()
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	u, err := NewHTTPUploader(map[string]string{URLProp: url, ProxyProp: proxy}, serverCert)
+	assertNoError(t, err)
+
+	assertNoError(t, u.UploadFile(f, false, nil))
+}
+
+func assertDeepEquals(t *testing.T, expected interface{}, actual interface{}) {
+	t.Helper()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected '%v', but was '%v'", expected, actual)
+	}
+}
+
+func TestHTTPUploadBearerAuth(t *testing.T) {
+	options := map[string]string{AuthBearerProp: "testToken"}
+	testHTTPUploadAuthorization(t, options, "Bearer testToken")
+
+	// the explicit option overrides the raw header
+	options[HeadersPrefix+"authorization"] = "Bearer otherToken"
+	testHTTPUploadAuthorization(t, options, "Bearer testToken")
+}
+
+func TestHTTPUploadBasicAuth(t *testing.T) {
+	options := map[string]string{AuthBasicUserProp: "testUser", AuthBasicPasswordProp: "testPassword"}
+	testHTTPUploadAuthorization(t, options, "Basic dGVzdFVzZXI6dGVzdFBhc3N3b3Jk")
+
+	options[HeadersPrefix+"Authorization"] = "Bearer otherToken"
+	testHTTPUploadAuthorization(t, options, "Basic dGVzdFVzZXI6dGVzdFBhc3N3b3Jk")
+}
+
+func TestHTTPUploadRawAuthorizationHeader(t *testing.T) {
+	testHTTPUploadAuthorization(t, map[string]string{HeadersPrefix + "Authorization": "Custom value"}, "Custom value")
+	testHTTPUploadAuthorization(t, map[string]string{}, "")
+}
+
+func TestHTTPUploadAuthErrors(t *testing.T) {
+	invalid := []map[string]string{
+		{AuthBearerProp: "testToken", AuthBasicUserProp: "testUser"},
+		{AuthBasicPasswordProp: "testPassword"},
+	}
+
+	for _, options := range invalid {
+		options[URLProp] = "http://localhost:1234/up"
+
+		u, err := NewHTTPUploader(options, "")
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}
+
+func testHTTPUploadAuthorization(t *testing.T, options map[string]string, expected string) {
+	t.Helper()
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+	defer handler.reset()
+
+	options[URLProp] = "http://localhost:1234/up"
+
+	u, err := NewHTTPUploader(options, "")
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(f, false, nil))
+
+	assertStringsSame(t, "authorization header", expected, handler.headers.Get("Authorization"))
+}
+
+// startStalledServer starts a server, which reads the request, but never responds until the test ends
+func startStalledServer(t *testing.T) *httptest.Server {
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+
+	t.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+
+	return server
+}
+
+func TestHTTPUploadTimeout(t *testing.T) {
+	for _, prop := range []string{TimeoutProp, ResponseHeaderTimeoutProp} {
+		t.Run(prop, func(t *testing.T) {
+			server := startStalledServer(t)
+
+			u, err := NewHTTPUploader(map[string]string{URLProp: server.URL, prop: "200ms"}, "")
+			assertNoError(t, err)
+
+			f, err := os.Open(testFile)
+			assertNoError(t, err)
+			defer f.Close()
+
+			start := time.Now()
+			assertError(t, u.UploadFile(f, false, nil))
+
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("upload should time out, but took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestHTTPUploadContextCancel(t *testing.T) {
+	server := startStalledServer(t)
+
+	u, err := NewHTTPUploader(map[string]string{URLProp: server.URL}, "")
+	assertNoError(t, err)
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	err = u.(ContextUploader).UploadFileContext(ctx, f, false, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("context deadline error expected, but was %v", err)
+	}
 }
 
 func TestHTTPUploadPortFailure(t *testing.T) {
@@ -358,6 +1040,180 @@ func getChecksum(t *testing.T, f *os.File, useChecksum bool) *string {
 	return nil
 }
 
+type testAWSResponseError struct {
+	code int
+}
+
+func (e *testAWSResponseError) Error() string {
+	return fmt.Sprintf("aws error %d", e.code)
+}
+
+func (e *testAWSResponseError) HTTPStatusCode() int {
+	return e.code
+}
+
+func TestIsAuthorizationError(t *testing.T) {
+	authErrors := []error{
+		&HTTPError{Code: http.StatusUnauthorized, Status: "401 Unauthorized"},
+		fmt.Errorf("wrapped: %w", &HTTPError{Code: http.StatusForbidden, Status: "403 Forbidden"}),
+		&testAWSResponseError{http.StatusForbidden},
+	}
+	for _, err := range authErrors {
+		if !IsAuthorizationError(err) {
+			t.Errorf("'%v' should be authorization error", err)
+		}
+	}
+
+	otherErrors := []error{
+		errors.New("test error"),
+		&HTTPError{Code: http.StatusNotFound, Status: "404 Not Found"},
+		&testAWSResponseError{http.StatusInternalServerError},
+	}
+	for _, err := range otherErrors {
+		if IsAuthorizationError(err) {
+			t.Errorf("'%v' should not be authorization error", err)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	assertDeepEquals(t, 120*time.Second, parseRetryAfter("120", now))
+	assertDeepEquals(t, 30*time.Second, parseRetryAfter(" 30 ", now))
+	assertDeepEquals(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+
+	for _, value := range []string{"", "0", "-5", "soon", now.Add(-time.Minute).Format(http.TimeFormat)} {
+		assertDeepEquals(t, time.Duration(0), parseRetryAfter(value, now))
+	}
+}
+
+func TestHTTPRetryAfter(t *testing.T) {
+	code := http.StatusTooManyRequests
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+
+	u, err := NewHTTPUploader(map[string]string{URLProp: server.URL}, "")
+	assertNoError(t, err)
+
+	upload := func() error {
+		f, err := os.Open(testFile)
+		assertNoError(t, err)
+		defer f.Close()
+
+		return u.UploadFile(f, false, nil)
+	}
+
+	for _, code = range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		err = upload()
+		assertError(t, err)
+		assertDeepEquals(t, 7*time.Second, RetryAfter(err))
+		assertDeepEquals(t, 7*time.Second, RetryAfter(fmt.Errorf("wrapped: %w", err)))
+	}
+
+	code = http.StatusInternalServerError // the header is honored only for 429 and 503 responses
+	err = upload()
+	assertError(t, err)
+	assertDeepEquals(t, time.Duration(0), RetryAfter(err))
+
+	assertDeepEquals(t, time.Duration(0), RetryAfter(errors.New("test error")))
+}
+
+func TestIsUnavailableError(t *testing.T) {
+	unavailableErrors := []error{
+		&HTTPError{Code: http.StatusServiceUnavailable, Status: "503 Service Unavailable"},
+		fmt.Errorf("wrapped: %w", &HTTPError{Code: http.StatusInternalServerError, Status: "500 Internal Server Error"}),
+		&testAWSResponseError{http.StatusBadGateway},
+		&url.Error{Op: "Put", URL: "http://localhost:1", Err: &net.OpError{Op: "dial", Err: errors.New("refused")}},
+	}
+	for _, err := range unavailableErrors {
+		if !IsUnavailableError(err) {
+			t.Errorf("'%v' should be unavailability error", err)
+		}
+	}
+
+	otherErrors := []error{
+		errors.New("test error"),
+		&HTTPError{Code: http.StatusNotFound, Status: "404 Not Found"},
+		&testAWSResponseError{http.StatusForbidden},
+		&url.Error{Op: "Put", URL: "http://localhost:1", Err: context.Canceled},
+	}
+	for _, err := range otherErrors {
+		if IsUnavailableError(err) {
+			t.Errorf("'%v' should not be unavailability error", err)
+		}
+	}
+}
+
+func TestResolveObjectKeyTemplate(t *testing.T) {
+	values := &ObjectKeyValues{
+		FilePath:      "/var/log/app.tar.gz",
+		DeviceID:      "device-123",
+		CorrelationID: "upload#1",
+		Time:          time.Date(2024, 6, 17, 10, 30, 5, 0, time.FixedZone("CEST", 2*60*60)),
+	}
+
+	for template, expected := range map[string]string{
+		"":                             "",
+		"static/key":                   "static/key",
+		"{filename}":                   "app.tar.gz",
+		"{basename}":                   "app.tar",
+		"{ext}":                        "gz",
+		"{date}":                       "2024-06-17",
+		"{datetime}":                   "2024-06-17T08-30-05Z",
+		"{deviceId}":                   "device-123",
+		"{correlationId}":              "upload#1",
+		"{deviceId}/{date}/{filename}": "device-123/2024-06-17/app.tar.gz",
+		"{basename}-{datetime}.{ext}":  "app.tar-2024-06-17T08-30-05Z.gz",
+	} {
+		key, err := ResolveObjectKeyTemplate(template, values)
+		assertNoError(t, err)
+		assertStringsSame(t, "key of template "+template, expected, key)
+	}
+
+	key, err := ResolveObjectKeyTemplate("{basename}.{ext}", &ObjectKeyValues{FilePath: "/var/log/noext"})
+	assertNoError(t, err)
+	assertStringsSame(t, "key without extension", "noext.", key)
+
+	for _, template := range []string{"{unknown}", "{filename}/{}", "{Filename}"} {
+		_, err := ResolveObjectKeyTemplate(template, values)
+		assertError(t, err)
+	}
+}
+
+func TestObjectKeyNamer(t *testing.T) {
+	keys, err := NewObjectKeyNamer(map[string]string{}, nil)
+	assertNoError(t, err)
+	assertStringsSame(t, "key without template", "", keys.Key("/var/log/app.log"))
+
+	options := map[string]string{
+		ObjectKeyTemplate:      "{deviceId}/{correlationId}/{date}/{filename}",
+		ObjectKeyDeviceID:      "device-123",
+		ObjectKeyCorrelationID: "upload#1",
+		ObjectKeyTime:          "2024-06-17T23:30:00-02:00",
+	}
+	keys, err = NewObjectKeyNamer(options, nil)
+	assertNoError(t, err)
+	assertStringsSame(t, "key from options", "device-123/upload#1/2024-06-18/app.log", keys.Key("/var/log/app.log"))
+
+	defaults := &ObjectKeyValues{DeviceID: "default", CorrelationID: "default", Time: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}
+	keys, err = NewObjectKeyNamer(map[string]string{ObjectKeyTemplate: options[ObjectKeyTemplate], ObjectKeyDeviceID: "device-123"}, defaults)
+	assertNoError(t, err)
+	assertStringsSame(t, "key from defaults", "device-123/default/2020-01-02/app.log", keys.Key("/var/log/app.log"))
+
+	for _, invalid := range []map[string]string{
+		{ObjectKeyTemplate: "{unknown}/{filename}"},
+		{ObjectKeyTemplate: "{date}/{filename}", ObjectKeyTime: "yesterday"},
+	} {
+		_, err := NewObjectKeyNamer(invalid, nil)
+		assertError(t, err)
+	}
+}
+
 func TestExtractDictionary(t *testing.T) {
 	info := map[string]string{"name": "John Doe", "age": "37", "addr": "under the bridge"}
 	headers := map[string]string{"content-type": "application/x-binary", "content-length": "42"}
@@ -464,3 +1320,55 @@ func assertFailsWith(t *testing.T, u interface{}, err error, msg string) {
 		t.Fatalf("expected error '%s', but was '%v'", msg, err)
 	}
 }
+
+func TestHTTPUploadStream(t *testing.T) {
+	content := strings.Repeat("streamed content ", 1000)
+
+	var contentLength int64
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		body, err := gzip.NewReader(r.Body)
+		if err == nil {
+			received, err = ioutil.ReadAll(body)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := NewHTTPUploader(map[string]string{URLProp: server.URL, ChunkedProp: "true"}, "")
+	assertNoError(t, err)
+
+	stream, err := CompressReader(strings.NewReader(content), CompressionGzip)
+	assertNoError(t, err)
+	defer stream.Close()
+
+	var progress int64
+	streamer := u.(StreamUploader)
+	assertNoError(t, streamer.UploadStream(context.Background(), "test.gz", stream, func(bytesTransferred int64) {
+		progress = bytesTransferred
+	}))
+
+	assertEquals(t, "content length", -1, contentLength) // sent chunked
+	assertStringsSame(t, "uploaded content", content, string(received))
+	if progress == 0 {
+		t.Error("no progress reported")
+	}
+
+	for _, options := range []map[string]string{
+		{URLProp: server.URL},
+		{URLProp: server.URL, ChunkedProp: "true", ConfirmHeadProp: "true"},
+		{URLProp: server.URL, ChunkedProp: "true", ChecksumHeaderProp: "X-Checksum"},
+	} {
+		u, err := NewHTTPUploader(options, "")
+		assertNoError(t, err)
+
+		streamer := u.(StreamUploader)
+		if streamer.CanStream() {
+			t.Errorf("streaming not expected with options %v", options)
+		}
+		assertError(t, streamer.UploadStream(context.Background(), "test.gz", strings.NewReader(content), nil))
+	}
+}
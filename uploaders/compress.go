@@ -0,0 +1,194 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Supported compression formats
+const (
+	CompressionGzip   = "gzip"
+	CompressionZstd   = "zstd"
+	CompressionBrotli = "brotli"
+)
+
+var compressionExtensions = map[string]string{
+	CompressionGzip:   ".gz",
+	CompressionZstd:   ".zst",
+	CompressionBrotli: ".br",
+}
+
+// HTTP content codings of the compression formats, which differ from the format names
+var contentEncodings = map[string]string{
+	CompressionBrotli: "br",
+}
+
+// ValidateCompressionFormat returns error if the given compression format is not supported
+func ValidateCompressionFormat(format string) error {
+	if _, ok := compressionExtensions[format]; !ok {
+		return fmt.Errorf("unsupported compression format '%s'", format)
+	}
+
+	return nil
+}
+
+// CompressionExtension returns the file name extension for the given compression format
+func CompressionExtension(format string) string {
+	return compressionExtensions[format]
+}
+
+// ContentEncoding returns the HTTP content coding for the given compression format
+func ContentEncoding(format string) string {
+	if encoding, ok := contentEncodings[format]; ok {
+		return encoding
+	}
+
+	return format
+}
+
+// CompressFile compresses the given file with the specified format into a temporary file. The name of the temporary file
+// is the base name of the original file with the format extension appended. The returned file is positioned at its
+// beginning. Callers are responsible for closing it and removing its directory with RemoveTempFile.
+func CompressFile(file *os.File, format string) (*os.File, error) {
+	if err := ValidateCompressionFormat(format); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "file-upload-")
+	if err != nil {
+		return nil, err
+	}
+
+	compressed, err := os.Create(filepath.Join(dir, filepath.Base(file.Name())+CompressionExtension(format)))
+	if err == nil {
+		err = compress(compressed, file, format)
+	}
+
+	if err == nil {
+		_, err = compressed.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		if compressed != nil {
+			compressed.Close()
+		}
+		os.RemoveAll(dir)
+
+		return nil, err
+	}
+
+	return compressed, nil
+}
+
+// CompressReader returns reader of the content of the given reader, compressed with the specified format on the fly,
+// while it is read, without a temporary copy. The compression is stopped, when the returned reader is closed.
+// Reading fails with the error, with which reading the given reader fails.
+func CompressReader(src io.Reader, format string) (io.ReadCloser, error) {
+	if err := ValidateCompressionFormat(format); err != nil {
+		return nil, err
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(compress(w, src, format))
+	}()
+
+	return r, nil
+}
+
+// IsGzipFile returns true if the given file name has the gzip extension ('.gz'), e.g. a file rotated by logrotate
+func IsGzipFile(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), compressionExtensions[CompressionGzip])
+}
+
+// DecompressFile decompresses the given gzip compressed file into a temporary file. The name of the temporary file
+// is the base name of the original file with the gzip extension removed. The returned file is positioned at its
+// beginning. Callers are responsible for closing it and removing its directory with RemoveTempFile.
+func DecompressFile(file *os.File) (*os.File, error) {
+	dir, err := os.MkdirTemp("", "file-upload-")
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(file.Name())
+	decompressed, err := os.Create(filepath.Join(dir, strings.TrimSuffix(name, filepath.Ext(name))))
+	if err == nil {
+		err = decompress(decompressed, file)
+	}
+
+	if err == nil {
+		_, err = decompressed.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		if decompressed != nil {
+			decompressed.Close()
+		}
+		os.RemoveAll(dir)
+
+		return nil, fmt.Errorf("failed to decompress file '%s': %w", file.Name(), err)
+	}
+
+	return decompressed, nil
+}
+
+// RemoveTempFile closes a temporary file, created by this package, and removes its directory
+func RemoveTempFile(file *os.File) error {
+	file.Close()
+
+	return os.RemoveAll(filepath.Dir(file.Name()))
+}
+
+func compress(dst io.Writer, src io.Reader, format string) error {
+	var w io.WriteCloser
+	switch format {
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(dst)
+		if err != nil {
+			return err
+		}
+		w = zw
+	case CompressionBrotli:
+		w = brotli.NewWriter(dst)
+	default:
+		w = gzip.NewWriter(dst)
+	}
+
+	if _, err := io.Copy(w, src); err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+func decompress(dst io.Writer, src io.Reader) error {
+	r, err := gzip.NewReader(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	_, err = io.Copy(dst, r)
+
+	return err
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// CredentialsExpirationProp is the 'start' operation option, common for all storage providers, with the RFC 3339
+// expiration time of temporary storage credentials, e.g. AWS session credentials
+const CredentialsExpirationProp = "credentials.expiration"
+
+// sasExpiry is the shared access signature parameter, holding its expiration time
+const sasExpiry = "se"
+
+// CredentialsExpiration returns the expiration time of the storage credentials in the given 'start' operation
+// options - the credentials expiration option, if specified, or else the expiry of the Azure shared access signature.
+// Returns zero time, if the credentials expiration is not known.
+func CredentialsExpiration(options map[string]string) (time.Time, error) {
+	if value := options[CredentialsExpirationProp]; value != "" {
+		expiration, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid value '%s' for parameter '%s': %w", value, CredentialsExpirationProp, err)
+		}
+		return expiration, nil
+	}
+
+	if sas := options[AzureSAS]; sas != "" {
+		return sasExpiration(sas)
+	}
+
+	return time.Time{}, nil
+}
+
+// sasExpiration returns the expiry of the given shared access signature, specified as an UTC date or time,
+// or zero time, if it has no expiry
+func sasExpiration(sas string) (time.Time, error) {
+	query, err := url.ParseQuery(sas)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid value for parameter '%s': %w", AzureSAS, err)
+	}
+
+	value := query.Get(sasExpiry)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z", "2006-01-02"} {
+		if expiration, err := time.Parse(layout, value); err == nil {
+			return expiration, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid shared access signature expiry '%s' in parameter '%s'", value, AzureSAS)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package uploaders
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCredentialsExpiration(t *testing.T) {
+	expiration, err := CredentialsExpiration(map[string]string{AWSAccessKeyID: "testKey"})
+	assertNoError(t, err)
+	assertDeepEquals(t, time.Time{}, expiration)
+
+	expiration, err = CredentialsExpiration(map[string]string{CredentialsExpirationProp: "2026-10-17T10:00:00Z"})
+	assertNoError(t, err)
+	assertDeepEquals(t, time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC), expiration)
+
+	for sas, expected := range map[string]time.Time{
+		"sv=2020-08-04&se=2026-10-17T10%3A30%3A00Z&sig=test": time.Date(2026, 10, 17, 10, 30, 0, 0, time.UTC),
+		"se=2026-10-17T10:30Z&sig=test":                      time.Date(2026, 10, 17, 10, 30, 0, 0, time.UTC),
+		"se=2026-10-18&sig=test":                             time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		"sig=test":                                           {},
+	} {
+		expiration, err = CredentialsExpiration(map[string]string{AzureSAS: sas})
+		assertNoError(t, err)
+		assertDeepEquals(t, expected, expiration)
+	}
+
+	for _, invalid := range []map[string]string{
+		{CredentialsExpirationProp: "tomorrow"},
+		{AzureSAS: "se=tomorrow&sig=test"},
+		{AzureSAS: "se=%zz"},
+	} {
+		_, err = CredentialsExpiration(invalid)
+		assertError(t, err)
+	}
+}
+
+func TestAzureRefreshCredentials(t *testing.T) {
+	var mutex sync.Mutex
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		signatures = append(signatures, r.URL.Query()["sig"]...)
+		mutex.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	u, err := NewAzureUploader(map[string]string{
+		AzureEndpoint:      server.URL + "/",
+		AzureContainerName: "test",
+		AzureSAS:           "se=2026-10-17T10%3A00%3A00Z&sig=first",
+	})
+	assertNoError(t, err)
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	assertNoError(t, u.UploadFile(f, false, nil))
+	assertNoError(t, u.(CredentialsRefresher).RefreshCredentials(map[string]string{AzureSAS: "sig=second&sp=w"}))
+	assertNoError(t, u.UploadFile(f, false, nil))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assertDeepEquals(t, []string{"first", "second"}, signatures)
+}
+
+func TestAWSRefreshCredentials(t *testing.T) {
+	u, err := NewAWSUploader(map[string]string{
+		AWSBucket:          "testBucket",
+		AWSRegion:          "eu-central-1",
+		AWSAccessKeyID:     "firstKey",
+		AWSSecretAccessKey: "firstSecret",
+		AWSSessionToken:    "firstToken",
+	})
+	assertNoError(t, err)
+
+	uploader := u.(*AWSUploader)
+	credentials, err := uploader.credentialsCache.Retrieve(context.Background())
+	assertNoError(t, err)
+	assertStringsSame(t, "access key", "firstKey", credentials.AccessKeyID)
+
+	assertNoError(t, uploader.RefreshCredentials(map[string]string{
+		AWSAccessKeyID:     "secondKey",
+		AWSSecretAccessKey: "secondSecret",
+		AWSSessionToken:    "secondToken",
+	}))
+
+	credentials, err = uploader.credentialsCache.Retrieve(context.Background())
+	assertNoError(t, err)
+	assertStringsSame(t, "access key", "secondKey", credentials.AccessKeyID)
+	assertStringsSame(t, "secret access key", "secondSecret", credentials.SecretAccessKey)
+	assertStringsSame(t, "session token", "secondToken", credentials.SessionToken)
+}
+
+func TestRefreshCredentialsErrors(t *testing.T) {
+	u, err := NewAWSUploader(map[string]string{
+		AWSBucket:          "testBucket",
+		AWSRegion:          "eu-central-1",
+		AWSAccessKeyID:     "testKey",
+		AWSSecretAccessKey: "testSecret",
+	})
+	assertNoError(t, err)
+	assertError(t, u.(CredentialsRefresher).RefreshCredentials(map[string]string{AWSAccessKeyID: "testKey"}))
+	assertError(t, u.(CredentialsRefresher).RefreshCredentials(map[string]string{AWSSecretAccessKey: "testSecret"}))
+
+	u, err = NewAzureUploader(map[string]string{
+		AzureEndpoint:      "https://testaccount.blob.core.windows.net/",
+		AzureContainerName: "test",
+		AzureSAS:           "sig=test",
+	})
+	assertNoError(t, err)
+	assertError(t, u.(CredentialsRefresher).RefreshCredentials(map[string]string{}))
+
+	u, err = NewAzureUploader(map[string]string{
+		AzureEndpoint:      "https://testaccount.blob.core.windows.net/",
+		AzureContainerName: "test",
+		AzureAccountKey:    "dGVzdEtleQ==",
+	})
+	assertNoError(t, err)
+	assertError(t, u.(CredentialsRefresher).RefreshCredentials(map[string]string{AzureSAS: "sig=test"}))
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Byte order marks, recognized by ConvertToUTF8
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+)
+
+// ConvertToUTF8 converts the given text file to UTF-8 without byte order mark into a temporary file, if the file
+// starts with UTF-16 (big or little endian) or UTF-8 byte order mark. Returns nil if the file has no byte order mark,
+// i.e. no conversion is needed. The temporary file has the same base name as the original file and is positioned at
+// its beginning. Callers are responsible for closing it and removing its directory with RemoveTempFile.
+func ConvertToUTF8(file *os.File) (*os.File, error) {
+	bom := make([]byte, len(bomUTF8))
+	n, err := io.ReadFull(file, bom)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	bom = bom[:n]
+	if !bytes.HasPrefix(bom, bomUTF8) && !bytes.HasPrefix(bom, bomUTF16BE) && !bytes.HasPrefix(bom, bomUTF16LE) {
+		return nil, nil
+	}
+
+	dir, err := os.MkdirTemp("", "file-upload-")
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := os.Create(filepath.Join(dir, filepath.Base(file.Name())))
+	if err == nil {
+		decoder := unicode.BOMOverride(unicode.UTF8.NewDecoder())
+		_, err = io.Copy(converted, transform.NewReader(file, decoder))
+	}
+
+	if err == nil {
+		_, err = converted.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		if converted != nil {
+			converted.Close()
+		}
+		os.RemoveAll(dir)
+
+		return nil, err
+	}
+
+	return converted, nil
+}
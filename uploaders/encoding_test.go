@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package uploaders
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+const encodingTestText = "Grüße, 世界 😀\n"
+
+func TestConvertToUTF8(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"UTF-16LE", encodeUTF16(encodingTestText, binary.LittleEndian)},
+		{"UTF-16BE", encodeUTF16(encodingTestText, binary.BigEndian)},
+		{"UTF-8 with BOM", append([]byte{0xEF, 0xBB, 0xBF}, encodingTestText...)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeSplitTestFile(t, test.content)
+			defer file.Close()
+
+			converted, err := ConvertToUTF8(file)
+			assertNoError(t, err)
+			if converted == nil {
+				t.Fatal("converted file expected")
+			}
+			defer RemoveTempFile(converted)
+
+			assertStringsSame(t, "name", filepath.Base(file.Name()), filepath.Base(converted.Name()))
+
+			data, err := ioutil.ReadAll(converted)
+			assertNoError(t, err)
+			assertStringsSame(t, "converted content", encodingTestText, string(data))
+
+			source, err := os.ReadFile(file.Name())
+			assertNoError(t, err)
+			assertStringsSame(t, "source content", string(test.content), string(source))
+		})
+	}
+}
+
+func TestConvertToUTF8NotNeeded(t *testing.T) {
+	for _, content := range []string{encodingTestText, "a", ""} {
+		file := writeSplitTestFile(t, []byte(content))
+		defer file.Close()
+
+		converted, err := ConvertToUTF8(file)
+		assertNoError(t, err)
+		if converted != nil {
+			RemoveTempFile(converted)
+			t.Fatalf("no conversion expected for '%s'", content)
+		}
+
+		data, err := ioutil.ReadAll(file)
+		assertNoError(t, err)
+		assertStringsSame(t, "content", content, string(data))
+	}
+}
+
+// encodeUTF16 encodes the text as UTF-16 with the given byte order, prefixed with byte order mark
+func encodeUTF16(text string, order binary.ByteOrder) []byte {
+	units := append([]uint16{0xFEFF}, utf16.Encode([]rune(text))...)
+
+	data := make([]byte, 2*len(units))
+	for i, unit := range units {
+		order.PutUint16(data[2*i:], unit)
+	}
+
+	return data
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// EncryptionKeyProp is the 'start' operation option, containing the hex or base64 encoded 32-byte AES-256 key
+const EncryptionKeyProp = "encryption.key"
+
+// EncryptionExtension is appended to the names of encrypted objects
+const EncryptionExtension = ".enc"
+
+// Encrypted stream format.
+//
+// The stream starts with a random 12-byte nonce, followed by the file content split in chunks of EncryptionChunkSize
+// bytes, each sealed with AES-256-GCM (i.e. followed by its 16-byte authentication tag). The last chunk is always
+// shorter than EncryptionChunkSize (possibly empty), so a stream cannot be truncated at a chunk boundary unnoticed.
+// The nonce of the chunk with index i (starting from 0) is the stream nonce with its last 8 bytes XOR-ed with i
+// (big-endian). The additional authenticated data of each chunk is a single byte - 1 for the last chunk, 0 otherwise.
+const (
+	EncryptionChunkSize = 64 * 1024
+
+	encryptionKeySize   = 32
+	encryptionNonceSize = 12
+	encryptionTagSize   = 16
+)
+
+// ParseEncryptionKey decodes a hex or base64 encoded AES-256 key
+func ParseEncryptionKey(encoded string) ([]byte, error) {
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == encryptionKeySize {
+		return key, nil
+	}
+
+	if key, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(key) == encryptionKeySize {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("invalid encryption key - hex or base64 encoded %d-byte key expected", encryptionKeySize)
+}
+
+// EncryptFile encrypts the given file with the specified key into a temporary file. The name of the temporary file
+// is the base name of the original file with EncryptionExtension appended. The returned file is positioned at its
+// beginning. Callers are responsible for closing it and removing its directory with RemoveTempFile.
+func EncryptFile(file *os.File, key []byte) (*os.File, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "file-upload-")
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := os.Create(filepath.Join(dir, filepath.Base(file.Name())+EncryptionExtension))
+	if err == nil {
+		err = encrypt(encrypted, file, aead)
+	}
+
+	if err == nil {
+		_, err = encrypted.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		if encrypted != nil {
+			encrypted.Close()
+		}
+		os.RemoveAll(dir)
+
+		return nil, err
+	}
+
+	return encrypted, nil
+}
+
+// DecryptFile decrypts a file, encrypted by EncryptFile, with the specified key and writes the plain content
+// to the given destination file.
+func DecryptFile(encryptedPath string, decryptedPath string, key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(encryptedPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(decryptedPath)
+	if err != nil {
+		return err
+	}
+
+	if err = decrypt(dst, src, aead); err != nil {
+		dst.Close()
+		os.Remove(decryptedPath)
+
+		return err
+	}
+
+	return dst.Close()
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("invalid encryption key size %d - %d-byte key expected", len(key), encryptionKeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(nonce []byte, index uint64) []byte {
+	result := make([]byte, len(nonce))
+	copy(result, nonce)
+
+	counter := binary.BigEndian.Uint64(result[len(result)-8:]) ^ index
+	binary.BigEndian.PutUint64(result[len(result)-8:], counter)
+
+	return result
+}
+
+func chunkAdditionalData(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func encrypt(dst io.Writer, src io.Reader, aead cipher.AEAD) error {
+	nonce := make([]byte, encryptionNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	if _, err := dst.Write(nonce); err != nil {
+		return err
+	}
+
+	plain := make([]byte, EncryptionChunkSize)
+	sealed := make([]byte, 0, EncryptionChunkSize+encryptionTagSize)
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(src, plain)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		last := n < EncryptionChunkSize
+		sealed = aead.Seal(sealed[:0], chunkNonce(nonce, index), plain[:n], chunkAdditionalData(last))
+		if _, err := dst.Write(sealed); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+	}
+}
+
+func decrypt(dst io.Writer, src io.Reader, aead cipher.AEAD) error {
+	nonce := make([]byte, encryptionNonceSize)
+	if _, err := io.ReadFull(src, nonce); err != nil {
+		return fmt.Errorf("failed to read encryption nonce: %v", err)
+	}
+
+	sealed := make([]byte, EncryptionChunkSize+encryptionTagSize)
+	plain := make([]byte, 0, EncryptionChunkSize)
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(src, sealed)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		last := n < len(sealed)
+		if plain, err = aead.Open(plain[:0], chunkNonce(nonce, index), sealed[:n], chunkAdditionalData(last)); err != nil {
+			return errors.New("failed to decrypt - invalid key or corrupted content")
+		}
+
+		if _, err := dst.Write(plain); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+	}
+}
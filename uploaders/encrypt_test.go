@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package uploaders
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	key := newTestEncryptionKey(t)
+
+	sizes := []int{0, 1, EncryptionChunkSize - 1, EncryptionChunkSize, EncryptionChunkSize + 1, 3 * EncryptionChunkSize}
+	for _, size := range sizes {
+		content := make([]byte, size)
+		rand.Read(content)
+
+		encrypted := encryptTestContent(t, content, key)
+
+		if filepath.Ext(encrypted.Name()) != EncryptionExtension {
+			t.Errorf("encrypted file '%s' should have extension '%s'", encrypted.Name(), EncryptionExtension)
+		}
+
+		decrypted := encrypted.Name() + ".dec"
+		assertNoError(t, DecryptFile(encrypted.Name(), decrypted, key))
+
+		actual, err := os.ReadFile(decrypted)
+		assertNoError(t, err)
+		if !bytes.Equal(content, actual) {
+			t.Errorf("decrypted content of size %d does not match the original", size)
+		}
+
+		assertNoError(t, RemoveTempFile(encrypted))
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	key := newTestEncryptionKey(t)
+
+	content := make([]byte, 2*EncryptionChunkSize+10)
+	rand.Read(content)
+
+	encrypted := encryptTestContent(t, content, key)
+	defer RemoveTempFile(encrypted)
+
+	decrypted := encrypted.Name() + ".dec"
+
+	assertError(t, DecryptFile(encrypted.Name(), decrypted, newTestEncryptionKey(t)))
+
+	data, err := os.ReadFile(encrypted.Name())
+	assertNoError(t, err)
+
+	truncated := encrypted.Name() + ".truncated"
+	assertNoError(t, os.WriteFile(truncated, data[:encryptionNonceSize+EncryptionChunkSize+encryptionTagSize], 0600))
+	assertError(t, DecryptFile(truncated, decrypted, key))
+
+	data[len(data)-1]++
+	assertNoError(t, os.WriteFile(truncated, data, 0600))
+	assertError(t, DecryptFile(truncated, decrypted, key))
+
+	if _, err := os.Stat(decrypted); !os.IsNotExist(err) {
+		t.Errorf("partially decrypted file '%s' should be removed", decrypted)
+	}
+}
+
+func TestParseEncryptionKey(t *testing.T) {
+	key := newTestEncryptionKey(t)
+
+	for _, encoded := range []string{hex.EncodeToString(key), base64.StdEncoding.EncodeToString(key)} {
+		parsed, err := ParseEncryptionKey(encoded)
+		assertNoError(t, err)
+
+		if !bytes.Equal(key, parsed) {
+			t.Errorf("parsed key of '%s' does not match", encoded)
+		}
+	}
+
+	for _, encoded := range []string{"", "invalid", hex.EncodeToString(key[:16])} {
+		_, err := ParseEncryptionKey(encoded)
+		assertError(t, err)
+	}
+}
+
+func newTestEncryptionKey(t *testing.T) []byte {
+	key := make([]byte, encryptionKeySize)
+	_, err := rand.Read(key)
+	assertNoError(t, err)
+
+	return key
+}
+
+func encryptTestContent(t *testing.T, content []byte, key []byte) *os.File {
+	t.Helper()
+
+	f, err := os.CreateTemp("", "plain")
+	assertNoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	_, err = f.Write(content)
+	assertNoError(t, err)
+	_, err = f.Seek(0, 0)
+	assertNoError(t, err)
+
+	encrypted, err := EncryptFile(f, key)
+	assertNoError(t, err)
+
+	return encrypted
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constants for file sink upload 'start' operation options
+const (
+	StorageProviderFile = "file"
+
+	FileDirectory     = "file.directory"
+	FileName          = "file.name"
+	FilePreservePaths = "file.preserve.paths"
+	FileDirectoryMode = "file.directory.mode"
+)
+
+// defaultDirectoryMode is the permissions of the directories, created by the file sinks, unless configured
+const defaultDirectoryMode os.FileMode = 0755
+
+// FileSinkUploader handles upload to a local directory, e.g. a mounted network share
+type FileSinkUploader struct {
+	directory     string
+	name          string
+	keys          *ObjectKeyNamer // names the copies after the files, if no name is specified
+	preservePaths bool
+	directoryMode os.FileMode
+}
+
+// NewFileSinkUploader constructs new FileSinkUploader from the provided 'start' operation options. If paths are
+// preserved, the files are copied under their full path in the target directory, instead of their base name.
+// The missing directories are created with the configured octal permissions, e.g. '0750' (0755 by default).
+func NewFileSinkUploader(options map[string]string) (Uploader, error) {
+	directory := options[FileDirectory]
+	if directory == "" {
+		return nil, fmt.Errorf(missingParameterErrMsg, FileDirectory)
+	}
+
+	uploader, err := newFileSinkUploader(options, directory, FileName, FilePreservePaths, FileDirectoryMode)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploader, nil
+}
+
+// newFileSinkUploader constructs new FileSinkUploader for the given directory from the options with the given names
+func newFileSinkUploader(options map[string]string, directory string, nameParam string, preservePathsParam string,
+	modeParam string) (*FileSinkUploader, error) {
+	name, err := relativeName(options, nameParam)
+	if err != nil {
+		return nil, err
+	}
+
+	preservePaths := false
+	if value, ok := options[preservePathsParam]; ok {
+		if preservePaths, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for parameter '%s'", value, preservePathsParam)
+		}
+	}
+
+	mode := defaultDirectoryMode
+	if value := options[modeParam]; value != "" {
+		parsed, err := strconv.ParseUint(value, 8, 32)
+		if err != nil || parsed > uint64(os.ModePerm) {
+			return nil, fmt.Errorf("invalid value '%s' for parameter '%s', octal permissions expected", value, modeParam)
+		}
+		mode = os.FileMode(parsed)
+	}
+
+	keys, err := NewObjectKeyNamer(options, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSinkUploader{directory: directory, name: name, keys: keys, preservePaths: preservePaths, directoryMode: mode}, nil
+}
+
+// PreservedPath returns the given path, relative to the file system root, under which it is copied to the target
+// directory of a file sink, which preserves paths
+func PreservedPath(path string) string {
+	path = filepath.Clean(path)
+	path = strings.TrimPrefix(path, filepath.VolumeName(path))
+
+	return strings.TrimLeft(path, string(filepath.Separator))
+}
+
+// relativeName returns the cleaned relative path from the given option, which should not escape the target directory
+func relativeName(options map[string]string, param string) (string, error) {
+	name := options[param]
+	if name == "" {
+		return "", nil
+	}
+
+	cleaned := filepath.Clean(name)
+	if filepath.IsAbs(name) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid value '%s' for parameter '%s'", name, param)
+	}
+
+	return cleaned, nil
+}
+
+// UploadFile copies the file to the target directory
+func (u *FileSinkUploader) UploadFile(file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
+	return u.UploadFileContext(context.Background(), file, useChecksum, listener)
+}
+
+// UploadFileContext copies the file to the target directory, aborting when the given context is done.
+// The file is copied under a temporary name and renamed when complete, so partial copies are never visible.
+func (u *FileSinkUploader) UploadFileContext(ctx context.Context, file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
+	target := u.target(file)
+
+	var checksum []byte
+	if useChecksum {
+		md5, err := ComputeMD5(file, false)
+		if err != nil {
+			return err
+		}
+		checksum = []byte(md5)
+	}
+
+	if err := mkdirAll(filepath.Dir(target), u.directoryMode); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after successful rename
+
+	h := md5.New()
+	w := &progressWriter{ctx: ctx, w: io.MultiWriter(tmp, h), listener: listener}
+	_, err = io.Copy(w, file)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if checksum != nil && !bytes.Equal(checksum, h.Sum(nil)) {
+		return fmt.Errorf("checksum mismatch of the file copied to '%s'", target)
+	}
+
+	return os.Rename(tmp.Name(), target)
+}
+
+// VerifyFile compares the file in the target directory, to which the file is copied, to the file content
+func (u *FileSinkUploader) VerifyFile(ctx context.Context, file *os.File) error {
+	copied, err := os.Open(u.target(file))
+	if err != nil {
+		return err
+	}
+	defer copied.Close()
+
+	return verifyContent(file, copied)
+}
+
+// LastModified returns the modification time of the file in the target directory, to which the file is copied
+func (u *FileSinkUploader) LastModified(ctx context.Context, file *os.File) (time.Time, bool, error) {
+	info, err := os.Stat(u.target(file))
+	if os.IsNotExist(err) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return info.ModTime(), true, nil
+}
+
+// target returns the path, to which the file is copied
+func (u *FileSinkUploader) target(file *os.File) string {
+	name := u.name
+	if key := u.keys.Key(file.Name()); name == "" && key != "" {
+		name = PreservedPath(string(filepath.Separator) + filepath.FromSlash(key)) // cannot escape the directory
+	} else if name == "" {
+		if u.preservePaths {
+			name = PreservedPath(file.Name())
+		} else {
+			name = filepath.Base(file.Name())
+		}
+	}
+
+	return filepath.Join(u.directory, name)
+}
+
+// mkdirAll creates the given directory along with any missing parents, with the given permissions, regardless of
+// the umask. Existing directories are left unchanged. Safe for concurrent creation of the same directories.
+func mkdirAll(dir string, mode os.FileMode) error {
+	info, err := os.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("'%s' is not a directory", dir)
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	if parent := filepath.Dir(dir); parent != dir {
+		if err := mkdirAll(parent, mode); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Mkdir(dir, mode); err != nil {
+		if os.IsExist(err) { // created concurrently
+			return mkdirAll(dir, mode)
+		}
+		return err
+	}
+
+	return os.Chmod(dir, mode) // the umask is applied on creation
+}
+
+// progressWriter reports the number of bytes written so far to the listener (if not nil),
+// failing when the context is done
+type progressWriter struct {
+	ctx      context.Context
+	w        io.Writer
+	listener func(bytesTransferred int64)
+	written  int64
+}
+
+func (p *progressWriter) Write(data []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := p.w.Write(data)
+	p.written += int64(n)
+	if p.listener != nil && n > 0 {
+		p.listener(p.written)
+	}
+
+	return n, err
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package uploaders
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileSinkUpload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000) // 100000 bytes
+	file := writeSplitTestFile(t, content)
+	defer file.Close()
+
+	dir := t.TempDir()
+	u, err := NewFileSinkUploader(map[string]string{FileDirectory: dir})
+	assertNoError(t, err)
+
+	var progress []int64
+	assertNoError(t, u.UploadFile(file, true, func(bytesTransferred int64) {
+		progress = append(progress, bytesTransferred)
+	}))
+
+	copied, err := os.ReadFile(filepath.Join(dir, "test.bin"))
+	assertNoError(t, err)
+	assertStringsSame(t, "content", string(content), string(copied))
+
+	if len(progress) == 0 {
+		t.Fatal("progress not reported")
+	}
+	for i := 1; i < len(progress); i++ {
+		if progress[i] <= progress[i-1] {
+			t.Errorf("progress should increase, but was %v", progress)
+		}
+	}
+	assertEquals(t, "transferred bytes", int64(len(content)), progress[len(progress)-1])
+
+	entries, err := os.ReadDir(dir)
+	assertNoError(t, err)
+	assertEquals(t, "files in directory", 1, int64(len(entries))) // no temporary files left
+}
+
+func TestFileSinkUploadWithName(t *testing.T) {
+	file := writeSplitTestFile(t, []byte("test content"))
+	defer file.Close()
+
+	dir := t.TempDir()
+	u, err := NewFileSinkUploader(map[string]string{FileDirectory: dir, FileName: "device/logs/test.log"})
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(file, false, nil))
+
+	copied, err := os.ReadFile(filepath.Join(dir, "device", "logs", "test.log"))
+	assertNoError(t, err)
+	assertStringsSame(t, "content", "test content", string(copied))
+}
+
+func TestFileSinkUploadObjectKeyTemplate(t *testing.T) {
+	file := writeSplitTestFile(t, []byte("test content"))
+	defer file.Close()
+
+	dir := t.TempDir()
+	u, err := NewFileSinkUploader(map[string]string{
+		FileDirectory:          dir,
+		FilePreservePaths:      "true",
+		ObjectKeyTemplate:      "../{deviceId}/{correlationId}/{filename}",
+		ObjectKeyDeviceID:      "device-123",
+		ObjectKeyCorrelationID: "upload-1",
+	})
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(file, false, nil))
+
+	copied, err := os.ReadFile(filepath.Join(dir, "device-123", "upload-1", filepath.Base(file.Name())))
+	assertNoError(t, err)
+	assertStringsSame(t, "content", "test content", string(copied))
+}
+
+func TestFileSinkUploadPreservePaths(t *testing.T) {
+	file := writeSplitTestFile(t, []byte("test content"))
+	defer file.Close()
+
+	dir := t.TempDir()
+	u, err := NewFileSinkUploader(map[string]string{
+		FileDirectory: dir, FilePreservePaths: "true", FileDirectoryMode: "0750",
+	})
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(file, false, nil))
+
+	target := filepath.Join(dir, PreservedPath(file.Name()))
+	copied, err := os.ReadFile(target)
+	assertNoError(t, err)
+	assertStringsSame(t, "content", "test content", string(copied))
+
+	for parent := filepath.Dir(target); parent != dir; parent = filepath.Dir(parent) {
+		info, err := os.Stat(parent)
+		assertNoError(t, err)
+		assertEquals(t, "mode of "+parent, int64(0750), int64(info.Mode().Perm()))
+	}
+}
+
+func TestFileSinkUploadConcurrentDirectories(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "device")
+	assertNoError(t, os.Mkdir(existing, 0700))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < cap(errs); i++ {
+		file := writeSplitTestFile(t, []byte("test content"))
+		defer file.Close()
+
+		wg.Add(1)
+		go func(i int, file *os.File) {
+			defer wg.Done()
+
+			u, err := NewFileSinkUploader(map[string]string{
+				FileDirectory: dir, FileName: fmt.Sprintf("device/logs/%d/test.log", i%2), FileDirectoryMode: "700",
+			})
+			if err == nil {
+				err = u.UploadFile(file, false, nil)
+			}
+			errs <- err
+		}(i, file)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assertNoError(t, err)
+	}
+
+	for _, sub := range []string{"device", "device/logs", "device/logs/0", "device/logs/1"} {
+		info, err := os.Stat(filepath.Join(dir, sub))
+		assertNoError(t, err)
+		assertEquals(t, "mode of "+sub, int64(0700), int64(info.Mode().Perm()))
+	}
+}
+
+func TestPreservedPath(t *testing.T) {
+	assertStringsSame(t, "absolute path", filepath.Join("var", "log", "test.log"),
+		PreservedPath(filepath.Join(string(filepath.Separator), "var", "log", "..", "log", "test.log")))
+	assertStringsSame(t, "relative path", filepath.Join("log", "test.log"), PreservedPath(filepath.Join("log", "test.log")))
+}
+
+func TestFileSinkUploadContextCancel(t *testing.T) {
+	file := writeSplitTestFile(t, []byte("test content"))
+	defer file.Close()
+
+	dir := t.TempDir()
+	u, err := NewFileSinkUploader(map[string]string{FileDirectory: dir})
+	assertNoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = u.(ContextUploader).UploadFileContext(ctx, file, false, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("context canceled error expected, but was %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	assertNoError(t, err)
+	assertEquals(t, "files in directory", 0, int64(len(entries)))
+}
+
+func TestNewFileSinkUploaderErrors(t *testing.T) {
+	for _, options := range []map[string]string{
+		{},
+		{FileDirectory: "dir", FileName: "/etc/test"},
+		{FileDirectory: "dir", FileName: "../test"},
+		{FileDirectory: "dir", FileName: "a/../../test"},
+		{FileDirectory: "dir", FilePreservePaths: "yes"},
+		{FileDirectory: "dir", FileDirectoryMode: "0789"},
+		{FileDirectory: "dir", FileDirectoryMode: "1777"},
+	} {
+		u, err := NewFileSinkUploader(options)
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}
+
+func TestFileSinkLastModified(t *testing.T) {
+	file := writeSplitTestFile(t, []byte("test content"))
+	defer file.Close()
+
+	dir := t.TempDir()
+	u, err := NewFileSinkUploader(map[string]string{FileDirectory: dir})
+	assertNoError(t, err)
+
+	_, exists, err := u.(StatUploader).LastModified(context.Background(), file)
+	assertNoError(t, err)
+	assertDeepEquals(t, false, exists)
+
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	target := filepath.Join(dir, filepath.Base(file.Name()))
+	assertNoError(t, os.WriteFile(target, []byte("uploaded"), 0644))
+	assertNoError(t, os.Chtimes(target, modified, modified))
+
+	lastModified, exists, err := u.(StatUploader).LastModified(context.Background(), file)
+	assertNoError(t, err)
+	assertDeepEquals(t, true, exists)
+	if !lastModified.Equal(modified) {
+		t.Errorf("expected last modified %v, but was %v", modified, lastModified)
+	}
+}
+
+func TestFileSinkVerifyFile(t *testing.T) {
+	file := writeSplitTestFile(t, []byte("verified content"))
+	defer file.Close()
+
+	dir := t.TempDir()
+	u, err := NewFileSinkUploader(map[string]string{FileDirectory: dir})
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(file, false, nil))
+
+	verifier := u.(VerifyingUploader)
+	assertNoError(t, verifier.VerifyFile(context.Background(), file))
+
+	assertNoError(t, os.WriteFile(filepath.Join(dir, filepath.Base(file.Name())), []byte("corrupted content"), 0644))
+	if err := verifier.VerifyFile(context.Background(), file); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("verification error expected, got %v", err)
+	}
+}
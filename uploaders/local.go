@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"fmt"
+	"os"
+)
+
+// Constants for local copy upload 'start' operation options
+const (
+	StorageProviderLocal = "local"
+
+	LocalDirectory     = "local.dir"
+	LocalObjectKey     = "local.object.key"
+	LocalPreservePaths = "local.preserve.paths"
+	LocalDirectoryMode = "local.directory.mode"
+)
+
+// NewLocalUploader constructs new uploader from the provided 'start' operation options, which copies the files
+// to an existing, writable local directory, e.g. an attached USB drive or a mounted NFS share. Unlike the file sink,
+// the target directory is never created, so nothing is written, if the drive or the share is not mounted.
+// The files are copied like with the file sink - under a temporary name, renamed when complete, preserving
+// their paths and creating the missing sub-directories with the configured permissions, if specified.
+func NewLocalUploader(options map[string]string) (Uploader, error) {
+	directory := options[LocalDirectory]
+	if directory == "" {
+		return nil, fmt.Errorf(missingParameterErrMsg, LocalDirectory)
+	}
+
+	uploader, err := newFileSinkUploader(options, directory, LocalObjectKey, LocalPreservePaths, LocalDirectoryMode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkWritable(directory); err != nil {
+		return nil, err
+	}
+
+	return uploader, nil
+}
+
+// checkWritable returns error, if the given path is not an existing directory, in which files can be created
+func checkWritable(directory string) error {
+	info, err := os.Stat(directory)
+	if err != nil {
+		return fmt.Errorf("local directory '%s' is not accessible: %w", directory, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("local path '%s' is not a directory", directory)
+	}
+
+	probe, err := os.CreateTemp(directory, ".file-upload-")
+	if err != nil {
+		return fmt.Errorf("local directory '%s' is not writable: %w", directory, err)
+	}
+	probe.Close()
+
+	return os.Remove(probe.Name())
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package uploaders
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalUpload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000) // 100000 bytes
+	file := writeSplitTestFile(t, content)
+	defer file.Close()
+
+	dir := t.TempDir()
+	u, err := NewLocalUploader(map[string]string{LocalDirectory: dir})
+	assertNoError(t, err)
+
+	var transferred int64
+	assertNoError(t, u.UploadFile(file, true, func(bytesTransferred int64) {
+		transferred = bytesTransferred
+	}))
+	assertEquals(t, "transferred bytes", int64(len(content)), transferred)
+
+	copied, err := os.ReadFile(filepath.Join(dir, "test.bin"))
+	assertNoError(t, err)
+	assertStringsSame(t, "content", string(content), string(copied))
+
+	entries, err := os.ReadDir(dir)
+	assertNoError(t, err)
+	assertEquals(t, "files in directory", 1, int64(len(entries))) // no temporary or probe files left
+}
+
+func TestLocalUploadWithObjectKey(t *testing.T) {
+	file := writeSplitTestFile(t, []byte("test content"))
+	defer file.Close()
+
+	dir := t.TempDir()
+	u, err := NewLocalUploader(map[string]string{LocalDirectory: dir, LocalObjectKey: "device/logs/test.log"})
+	assertNoError(t, err)
+	assertNoError(t, u.UploadFile(file, false, nil))
+
+	copied, err := os.ReadFile(filepath.Join(dir, "device", "logs", "test.log"))
+	assertNoError(t, err)
+	assertStringsSame(t, "content", "test content", string(copied))
+}
+
+func TestNewLocalUploaderErrors(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	assertNoError(t, os.WriteFile(file, []byte("test"), 0600))
+
+	for _, options := range []map[string]string{
+		{},
+		{LocalDirectory: filepath.Join(dir, "missing")},
+		{LocalDirectory: file},
+		{LocalDirectory: dir, LocalObjectKey: "../test"},
+	} {
+		u, err := NewLocalUploader(options)
+		assertNil(t, u)
+		assertError(t, err)
+	}
+
+	if os.Geteuid() == 0 {
+		t.Skip("directory permissions are not enforced for root")
+	}
+
+	readOnly := filepath.Join(dir, "readonly")
+	assertNoError(t, os.Mkdir(readOnly, 0500))
+
+	u, err := NewLocalUploader(map[string]string{LocalDirectory: readOnly})
+	assertNil(t, u)
+	assertError(t, err)
+}
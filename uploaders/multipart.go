@@ -0,0 +1,104 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Formats of the generic HTTP upload request body
+const (
+	BodyFormatRaw       = "raw"
+	BodyFormatMultipart = "multipart"
+)
+
+// defaultFormField is the name of the form field, holding the uploaded file in 'multipart' body format,
+// unless specified with the 'https.form.field' option
+const defaultFormField = "file"
+
+// multipartForm holds the form of the 'multipart/form-data' upload requests
+type multipartForm struct {
+	field  string
+	fields map[string]string // extra form fields, sent before the file
+}
+
+// parseMultipartForm returns the multipart form from the 'start' operation options, or nil if the raw body format
+// is selected. The extra form fields are taken from the options, prefixed with 'https.form.', except
+// the 'https.form.field' option itself.
+func parseMultipartForm(options map[string]string) (*multipartForm, error) {
+	switch format := options[BodyFormatProp]; strings.ToLower(format) {
+	case "", BodyFormatRaw:
+		return nil, nil
+	case BodyFormatMultipart:
+	default:
+		return nil, fmt.Errorf("invalid value '%s' for parameter '%s', supported values are '%s' and '%s'",
+			format, BodyFormatProp, BodyFormatRaw, BodyFormatMultipart)
+	}
+
+	form := &multipartForm{field: options[FormFieldProp], fields: ExtractDictionary(options, FormFieldsPrefix)}
+	if form.field == "" {
+		form.field = defaultFormField
+	}
+	delete(form.fields, strings.TrimPrefix(FormFieldProp, FormFieldsPrefix))
+
+	return form, nil
+}
+
+// envelope returns the parts of the 'multipart/form-data' request body, which precede and follow the content
+// of the file with the given name, sent as the file form field, together with the request content type. The extra
+// form fields precede the file. The file content is streamed in between, so it is never buffered in memory.
+func (f *multipartForm) envelope(name string, contentType string, contentEncoding string) ([]byte, []byte, string, error) {
+	head := &bytes.Buffer{}
+	w := multipart.NewWriter(head)
+
+	names := make([]string, 0, len(f.fields))
+	for field := range f.fields {
+		names = append(names, field)
+	}
+	sort.Strings(names) // deterministic order of the extra fields
+
+	for _, field := range names {
+		if err := w.WriteField(field, f.fields[field]); err != nil {
+			return nil, nil, "", err
+		}
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(f.field), escapeQuotes(filepath.Base(name))))
+	header.Set("Content-Type", contentType)
+	if contentEncoding != "" {
+		header.Set("Content-Encoding", contentEncoding)
+	}
+	if _, err := w.CreatePart(header); err != nil {
+		return nil, nil, "", err
+	}
+
+	// the closing boundary, as written by the multipart writer on close
+	tail := []byte("\r\n--" + w.Boundary() + "--\r\n")
+
+	return head.Bytes(), tail, w.FormDataContentType(), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// escapeQuotes escapes the quoted form field and file names the way the multipart writer does
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
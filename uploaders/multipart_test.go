@@ -0,0 +1,168 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package uploaders
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type multipartRequest struct {
+	contentType   string
+	contentLength int64
+	contentMD5    string
+	fields        map[string]string
+	field         string
+	filename      string
+	fileType      string
+	content       string
+}
+
+// startMultipartServer starts a server, which parses the last received multipart/form-data request
+func startMultipartServer(t *testing.T) (*httptest.Server, *multipartRequest) {
+	received := &multipartRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assertNoError(t, err)
+
+		*received = multipartRequest{contentType: r.Header.Get("Content-Type"), contentLength: r.ContentLength,
+			contentMD5: r.Header.Get(ContentMD5), fields: make(map[string]string)}
+		sum := md5.Sum(body)
+		if received.contentMD5 != "" && received.contentMD5 != base64.StdEncoding.EncodeToString(sum[:]) {
+			t.Errorf("Content-MD5 header %s does not match the request body", received.contentMD5)
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		reader, err := r.MultipartReader()
+		assertNoError(t, err)
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			assertNoError(t, err)
+
+			data, err := ioutil.ReadAll(part)
+			assertNoError(t, err)
+			if part.FileName() == "" {
+				received.fields[part.FormName()] = string(data)
+			} else {
+				received.field, received.filename = part.FormName(), part.FileName()
+				received.fileType, received.content = part.Header.Get("Content-Type"), string(data)
+			}
+		}
+	}))
+
+	return server, received
+}
+
+func TestHTTPUploadMultipart(t *testing.T) {
+	server, received := startMultipartServer(t)
+	defer server.Close()
+
+	u, err := NewHTTPUploader(map[string]string{
+		URLProp:                        server.URL,
+		MethodProp:                     "POST",
+		BodyFormatProp:                 "Multipart",
+		FormFieldsPrefix + "deviceId":  "test-device",
+		FormFieldsPrefix + "timestamp": "1718612345",
+	}, "")
+	assertNoError(t, err)
+
+	file := writeSplitTestFile(t, []byte("multipart content"))
+	defer file.Close()
+
+	assertNoError(t, u.UploadFile(file, true, nil))
+
+	mediaType, params, err := mime.ParseMediaType(received.contentType)
+	assertNoError(t, err)
+	assertStringsSame(t, "content type", "multipart/form-data", mediaType)
+	if params["boundary"] == "" {
+		t.Error("multipart boundary expected in the content type")
+	}
+	if received.contentLength < 0 {
+		t.Error("multipart body expected to be sent with content length")
+	}
+	if received.contentMD5 == "" {
+		t.Error("Content-MD5 header of the multipart body expected")
+	}
+
+	assertStringsSame(t, "file field", defaultFormField, received.field)
+	assertStringsSame(t, "file name", "test.bin", received.filename)
+	assertStringsSame(t, "file content type", defaultContentType, received.fileType)
+	assertStringsSame(t, "file content", "multipart content", received.content)
+	assertDeepEquals(t, map[string]string{"deviceId": "test-device", "timestamp": "1718612345"}, received.fields)
+}
+
+func TestHTTPUploadMultipartField(t *testing.T) {
+	server, received := startMultipartServer(t)
+	defer server.Close()
+
+	u, err := NewHTTPUploader(map[string]string{
+		URLProp:         server.URL,
+		MethodProp:      "POST",
+		BodyFormatProp:  BodyFormatMultipart,
+		FormFieldProp:   "logFile",
+		ContentTypeProp: "text/plain",
+	}, "")
+	assertNoError(t, err)
+
+	file := writeSplitTestFile(t, []byte("log line"))
+	defer file.Close()
+
+	assertNoError(t, u.UploadFile(file, false, nil))
+
+	assertStringsSame(t, "file field", "logFile", received.field)
+	assertStringsSame(t, "file content type", "text/plain", received.fileType)
+	assertStringsSame(t, "file content", "log line", received.content)
+	assertStringsSame(t, "Content-MD5 header", "", received.contentMD5)
+	assertDeepEquals(t, map[string]string{}, received.fields)
+
+	assertError(t, u.(ResumableUploader).UploadFileFrom(context.Background(), file, 4, false, nil))
+}
+
+func TestHTTPUploadRawBodyFormat(t *testing.T) {
+	var contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		contentType, body = r.Header.Get("Content-Type"), string(data)
+	}))
+	defer server.Close()
+
+	u, err := NewHTTPUploader(map[string]string{URLProp: server.URL, BodyFormatProp: BodyFormatRaw, FormFieldProp: "ignored"}, "")
+	assertNoError(t, err)
+
+	file := writeSplitTestFile(t, []byte("raw content"))
+	defer file.Close()
+
+	assertNoError(t, u.UploadFile(file, false, nil))
+	assertStringsSame(t, "content type", defaultContentType, contentType)
+	assertStringsSame(t, "body", "raw content", body)
+}
+
+func TestNewHTTPUploaderInvalidBodyFormat(t *testing.T) {
+	u, err := NewHTTPUploader(map[string]string{URLProp: "http://localhost:1234/up", BodyFormatProp: "form"}, "")
+	assertNil(t, u)
+	assertError(t, err)
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ManifestExtension is appended to the object name of a split file, to form the name of its manifest
+const ManifestExtension = ".manifest.json"
+
+// SplitManifest lists the parts of a split file, for reassembly by concatenating them in order
+type SplitManifest struct {
+	Name   string      `json:"name"`
+	Size   int64       `json:"size"`
+	SHA256 string      `json:"sha256"`
+	Parts  []SplitPart `json:"parts"`
+}
+
+// SplitPart is a single part of a split file
+type SplitPart struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// SplitUploader uploads files exceeding the part size as multiple numbered objects, followed by a manifest, listing
+// them. Smaller files are uploaded as a single object.
+type SplitUploader struct {
+	uploader    Uploader
+	name        string
+	partSize    int64
+	newUploader func(name string) (Uploader, error)
+}
+
+// NewSplitUploader constructs new SplitUploader for the object with the given name, uploaded with the given uploader
+// when not exceeding the part size. The given function creates the uploaders for the parts and the manifest.
+func NewSplitUploader(uploader Uploader, name string, partSize int64, newUploader func(name string) (Uploader, error)) *SplitUploader {
+	return &SplitUploader{uploader, name, partSize, newUploader}
+}
+
+// PartName returns the object name of the part with the given index (starting from 1)
+func PartName(name string, index int) string {
+	return fmt.Sprintf("%s.part%03d", name, index)
+}
+
+// UploadFile uploads the file as a single object, or split in parts if it exceeds the part size
+func (u *SplitUploader) UploadFile(file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
+	stats, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if stats.Size() <= u.partSize {
+		return u.uploader.UploadFile(file, useChecksum, listener)
+	}
+
+	dir, err := os.MkdirTemp("", "file-upload-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := &SplitManifest{Name: u.name, Size: stats.Size()}
+	hash := sha256.New()
+
+	for offset := int64(0); offset < stats.Size(); offset += u.partSize {
+		part := SplitPart{Name: PartName(u.name, len(manifest.Parts)+1)}
+
+		partFile, err := u.writePart(dir, io.NewSectionReader(file, offset, u.partSize), hash, &part)
+		if err != nil {
+			return err
+		}
+
+		partListener := listener
+		if listener != nil {
+			transferred := offset
+			partListener = func(bytesTransferred int64) {
+				listener(transferred + bytesTransferred)
+			}
+		}
+
+		err = u.upload(part.Name, partFile, useChecksum, partListener)
+		RemoveTempFile(partFile)
+		if err != nil {
+			return fmt.Errorf("failed to upload part '%s': %w", part.Name, err)
+		}
+
+		manifest.Parts = append(manifest.Parts, part)
+	}
+	manifest.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	return u.uploadManifest(dir, manifest, useChecksum)
+}
+
+// writePart copies the part content to a temporary file in its own sub-directory of the given one,
+// updating the hash of the whole file and the size and hash of the part
+func (u *SplitUploader) writePart(dir string, r io.Reader, hash io.Writer, part *SplitPart) (*os.File, error) {
+	partDir, err := os.MkdirTemp(dir, "part-")
+	if err != nil {
+		return nil, err
+	}
+
+	partFile, err := os.Create(filepath.Join(partDir, filepath.Base(part.Name)))
+	if err != nil {
+		return nil, err
+	}
+
+	partHash := sha256.New()
+	if part.Size, err = io.Copy(io.MultiWriter(partFile, hash, partHash), r); err == nil {
+		_, err = partFile.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		RemoveTempFile(partFile)
+		return nil, err
+	}
+
+	part.SHA256 = hex.EncodeToString(partHash.Sum(nil))
+
+	return partFile, nil
+}
+
+func (u *SplitUploader) uploadManifest(dir string, manifest *SplitManifest, useChecksum bool) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	name := u.name + ManifestExtension
+	path := filepath.Join(dir, filepath.Base(name))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+
+	manifestFile, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer manifestFile.Close()
+
+	if err := u.upload(name, manifestFile, useChecksum, nil); err != nil {
+		return fmt.Errorf("failed to upload manifest '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+func (u *SplitUploader) upload(name string, file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
+	uploader, err := u.newUploader(name)
+	if err != nil {
+		return err
+	}
+
+	return uploader.UploadFile(file, useChecksum, listener)
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package uploaders
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordingUploader stores the content of the uploaded objects
+type recordingUploader struct {
+	name    string
+	objects map[string][]byte
+	err     error
+}
+
+func (u *recordingUploader) UploadFile(file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
+	if u.err != nil {
+		return u.err
+	}
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	u.objects[u.name] = data
+	if listener != nil {
+		listener(int64(len(data)) / 2)
+		listener(int64(len(data)))
+	}
+
+	return nil
+}
+
+func newSplitTestUploader(partSize int64, objects map[string][]byte, err error) *SplitUploader {
+	newUploader := func(name string) (Uploader, error) {
+		return &recordingUploader{name, objects, err}, nil
+	}
+
+	uploader, _ := newUploader("test.bin")
+
+	return NewSplitUploader(uploader, "test.bin", partSize, newUploader)
+}
+
+func TestSplitUpload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 250) // 2500 bytes
+	file := writeSplitTestFile(t, content)
+	defer file.Close()
+
+	objects := make(map[string][]byte)
+	u := newSplitTestUploader(1000, objects, nil)
+
+	var progress []int64
+	assertNoError(t, u.UploadFile(file, false, func(bytesTransferred int64) {
+		progress = append(progress, bytesTransferred)
+	}))
+
+	assertDeepEquals(t, []int64{500, 1000, 1500, 2000, 2250, 2500}, progress)
+
+	data, ok := objects["test.bin"+ManifestExtension]
+	if !ok {
+		t.Fatal("manifest not uploaded")
+	}
+
+	manifest := &SplitManifest{}
+	assertNoError(t, json.Unmarshal(data, manifest))
+
+	assertStringsSame(t, "name", "test.bin", manifest.Name)
+	assertEquals(t, "size", int64(len(content)), manifest.Size)
+	assertStringsSame(t, "hash", sha256Hex(content), manifest.SHA256)
+	assertEquals(t, "parts count", 3, int64(len(manifest.Parts)))
+	assertEquals(t, "objects count", 4, int64(len(objects)))
+
+	var reassembled []byte
+	for i, part := range manifest.Parts {
+		assertStringsSame(t, "part name", PartName("test.bin", i+1), part.Name)
+
+		partContent, ok := objects[part.Name]
+		if !ok {
+			t.Fatalf("part '%s' not uploaded", part.Name)
+		}
+		assertEquals(t, "part size", int64(len(partContent)), part.Size)
+		assertStringsSame(t, "part hash", sha256Hex(partContent), part.SHA256)
+
+		reassembled = append(reassembled, partContent...)
+	}
+
+	assertStringsSame(t, "reassembled content", string(content), string(reassembled))
+	assertStringsSame(t, "last part name", "test.bin.part003", manifest.Parts[2].Name)
+}
+
+func TestSplitUploadNotExceeding(t *testing.T) {
+	content := []byte("small content")
+	file := writeSplitTestFile(t, content)
+	defer file.Close()
+
+	objects := make(map[string][]byte)
+	u := newSplitTestUploader(int64(len(content)), objects, nil)
+
+	assertNoError(t, u.UploadFile(file, false, nil))
+	assertEquals(t, "objects count", 1, int64(len(objects)))
+	assertStringsSame(t, "content", string(content), string(objects["test.bin"]))
+}
+
+func TestSplitUploadPartFailure(t *testing.T) {
+	file := writeSplitTestFile(t, []byte("content exceeding the part size"))
+	defer file.Close()
+
+	objects := make(map[string][]byte)
+	u := newSplitTestUploader(10, objects, errors.New("upload failed"))
+
+	assertError(t, u.UploadFile(file, false, nil))
+	assertEquals(t, "objects count", 0, int64(len(objects)))
+}
+
+func writeSplitTestFile(t *testing.T, content []byte) *os.File {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.bin")
+	assertNoError(t, os.WriteFile(path, content, 0600))
+
+	file, err := os.Open(path)
+	assertNoError(t, err)
+
+	return file
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
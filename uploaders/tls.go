@@ -0,0 +1,141 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Constants for the TLS 'start' operation options, common for all storage providers, which restrict the TLS
+// connections to the storage endpoints
+const (
+	TLSMinVersionProp   = "tls.min.version"
+	TLSMaxVersionProp   = "tls.max.version"
+	TLSCipherSuitesProp = "tls.cipher.suites"
+)
+
+// Minimum and maximum TLS versions of the storage endpoint and the MQTT broker connections
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+)
+
+// tlsPolicy restricts the TLS connections to the storage endpoints to a range of TLS versions and allowed cipher suites
+type tlsPolicy struct {
+	minVersion   uint16
+	maxVersion   uint16
+	cipherSuites []uint16
+}
+
+// ParseTLSVersion returns the TLS version with the given name - '1.2' (default, if empty) or '1.3'
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "", TLSVersion12:
+		return tls.VersionTLS12, nil
+	case TLSVersion13:
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version '%s' - supported are '%s' and '%s'", version, TLSVersion12, TLSVersion13)
+	}
+}
+
+// ParseTLSVersions returns the minimum and maximum TLS versions with the given names. The minimum version is '1.2'
+// and the maximum version is '1.3' by default, if empty. Returns error, if the minimum is larger than the maximum.
+func ParseTLSVersions(minVersion string, maxVersion string) (uint16, uint16, error) {
+	minID, err := ParseTLSVersion(minVersion)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid minimum TLS version: %w", err)
+	}
+
+	maxID := uint16(tls.VersionTLS13)
+	if maxVersion != "" {
+		if maxID, err = ParseTLSVersion(maxVersion); err != nil {
+			return 0, 0, fmt.Errorf("invalid maximum TLS version: %w", err)
+		}
+	}
+
+	if minID > maxID {
+		return 0, 0, fmt.Errorf("minimum TLS version '%s' is larger than maximum TLS version '%s'", minVersion, maxVersion)
+	}
+
+	return minID, maxID, nil
+}
+
+// ParseCipherSuites returns the IDs of the given comma-separated cipher suite names, e.g.
+// 'TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256'. Only the secure cipher suites are supported. Returns all secure cipher
+// suites, if no names are given. The cipher suites apply only to TLS 1.2 connections, TLS 1.3 suites are not configurable.
+func ParseCipherSuites(names string) ([]uint16, error) {
+	if strings.TrimSpace(names) == "" {
+		return SupportedCipherSuites(), nil
+	}
+
+	var ids []uint16
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+
+		found := false
+		for _, suite := range tls.CipherSuites() {
+			if suite.Name == name {
+				ids = append(ids, suite.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unsupported cipher suite '%s'", name)
+		}
+	}
+
+	return ids, nil
+}
+
+// parseTLSPolicy returns the TLS policy from the given 'start' operation options. The returned flag is false,
+// if no TLS options are specified, in which case the policy holds the defaults - TLS 1.2 to 1.3 and all secure
+// cipher suites.
+func parseTLSPolicy(options map[string]string) (*tlsPolicy, bool, error) {
+	versionMin, versionMax, suites := options[TLSMinVersionProp], options[TLSMaxVersionProp], options[TLSCipherSuitesProp]
+
+	minVersion, maxVersion, err := ParseTLSVersions(versionMin, versionMax)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid values '%s' and '%s' for parameters '%s' and '%s': %w",
+			versionMin, versionMax, TLSMinVersionProp, TLSMaxVersionProp, err)
+	}
+
+	cipherSuites, err := ParseCipherSuites(suites)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid value '%s' for parameter '%s': %w", suites, TLSCipherSuitesProp, err)
+	}
+
+	return &tlsPolicy{minVersion, maxVersion, cipherSuites}, versionMin != "" || versionMax != "" || suites != "", nil
+}
+
+// config returns TLS configuration, restricted by the policy
+func (p *tlsPolicy) config() *tls.Config {
+	return &tls.Config{
+		MinVersion:   p.minVersion,
+		MaxVersion:   p.maxVersion,
+		CipherSuites: p.cipherSuites,
+	}
+}
+
+// httpClient returns an HTTP client, based on the default transport, with TLS connections restricted by the policy.
+// Used as transport of the storage provider SDKs.
+func (p *tlsPolicy) httpClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = p.config()
+
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package uploaders
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// startWeakTLSServer starts a TLS server, which supports only TLS 1.2 with a CBC cipher suite
+func startWeakTLSServer(t *testing.T) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA},
+	}
+	server.StartTLS()
+
+	return server
+}
+
+func uploadWithTLSOptions(t *testing.T, server *httptest.Server, options map[string]string) error {
+	t.Helper()
+
+	options[URLProp] = server.URL + "/up"
+	u, err := NewHTTPUploader(options, writeServerCert(t, server))
+	assertNoError(t, err)
+
+	f, err := os.Open(testFile)
+	assertNoError(t, err)
+	defer f.Close()
+
+	return u.UploadFile(f, false, nil)
+}
+
+func TestHTTPSUploadTLSPolicy(t *testing.T) {
+	server := startWeakTLSServer(t)
+	defer server.Close()
+
+	assertNoError(t, uploadWithTLSOptions(t, server, map[string]string{}))
+	assertNoError(t, uploadWithTLSOptions(t, server, map[string]string{TLSMinVersionProp: TLSVersion12}))
+	assertNoError(t, uploadWithTLSOptions(t, server, map[string]string{
+		TLSCipherSuitesProp: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA",
+	}))
+
+	assertNoError(t, uploadWithTLSOptions(t, server, map[string]string{TLSMaxVersionProp: TLSVersion12}))
+
+	// refused, when restricted
+	assertError(t, uploadWithTLSOptions(t, server, map[string]string{TLSMinVersionProp: TLSVersion13}))
+	assertError(t, uploadWithTLSOptions(t, server, map[string]string{
+		TLSCipherSuitesProp: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	}))
+}
+
+func TestAzureTLSPolicy(t *testing.T) {
+	options := map[string]string{
+		AzureEndpoint:      "https://testaccount.blob.core.windows.net/",
+		AzureContainerName: "test",
+		AzureSAS:           "sig=test",
+	}
+
+	u, err := NewAzureUploader(options)
+	assertNoError(t, err)
+	if u.(*AzureUploader).clientOptions.Transporter != nil {
+		t.Error("default transport expected without TLS options")
+	}
+
+	options[TLSMinVersionProp] = TLSVersion13
+	options[TLSCipherSuitesProp] = "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"
+	u, err = NewAzureUploader(options)
+	assertNoError(t, err)
+
+	config := u.(*AzureUploader).clientOptions.Transporter.(*http.Client).Transport.(*http.Transport).TLSClientConfig
+	assertEquals(t, "min TLS version", tls.VersionTLS13, int64(config.MinVersion))
+	assertEquals(t, "max TLS version", tls.VersionTLS13, int64(config.MaxVersion))
+	assertDeepEquals(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, config.CipherSuites)
+}
+
+func TestAWSTLSPolicy(t *testing.T) {
+	u, err := NewAWSUploader(map[string]string{
+		AWSBucket:           "testBucket",
+		AWSRegion:           "eu-central-1",
+		AWSAccessKeyID:      "testKey",
+		AWSSecretAccessKey:  "testSecret",
+		TLSMinVersionProp:   TLSVersion13,
+		TLSCipherSuitesProp: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	})
+	assertNoError(t, err)
+	if u == nil {
+		t.Fatal("uploader expected")
+	}
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	suites, err := ParseCipherSuites("")
+	assertNoError(t, err)
+	assertDeepEquals(t, SupportedCipherSuites(), suites)
+
+	suites, err = ParseCipherSuites(" TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384 ,TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256")
+	assertNoError(t, err)
+	assertDeepEquals(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256}, suites)
+
+	for _, names := range []string{"TLS_RSA_WITH_RC4_128_SHA", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,unknown", ","} {
+		_, err = ParseCipherSuites(names)
+		assertError(t, err)
+	}
+}
+
+func TestParseTLSVersions(t *testing.T) {
+	minVersion, maxVersion, err := ParseTLSVersions("", "")
+	assertNoError(t, err)
+	assertEquals(t, "min TLS version", tls.VersionTLS12, int64(minVersion))
+	assertEquals(t, "max TLS version", tls.VersionTLS13, int64(maxVersion))
+
+	minVersion, maxVersion, err = ParseTLSVersions(TLSVersion13, TLSVersion13)
+	assertNoError(t, err)
+	assertEquals(t, "min TLS version", tls.VersionTLS13, int64(minVersion))
+	assertEquals(t, "max TLS version", tls.VersionTLS13, int64(maxVersion))
+
+	for _, versions := range [][]string{{"1.1", ""}, {"", "1.4"}, {TLSVersion13, TLSVersion12}} {
+		_, _, err = ParseTLSVersions(versions[0], versions[1])
+		assertError(t, err)
+	}
+}
+
+func TestTLSPolicyErrors(t *testing.T) {
+	for _, invalid := range []map[string]string{
+		{TLSMinVersionProp: "1.1"},
+		{TLSMaxVersionProp: "1.4"},
+		{TLSMinVersionProp: TLSVersion13, TLSMaxVersionProp: TLSVersion12},
+		{TLSCipherSuitesProp: "TLS_RSA_WITH_RC4_128_SHA"},
+	} {
+		options := map[string]string{URLProp: "https://localhost:1234/up"}
+		addAll(options, invalid)
+		u, err := NewHTTPUploader(options, "")
+		assertNil(t, u)
+		assertError(t, err)
+
+		options = map[string]string{
+			AWSBucket:          "testBucket",
+			AWSRegion:          "eu-central-1",
+			AWSAccessKeyID:     "testKey",
+			AWSSecretAccessKey: "testSecret",
+		}
+		addAll(options, invalid)
+		u, err = NewAWSUploader(options)
+		assertNil(t, u)
+		assertError(t, err)
+
+		options = map[string]string{
+			AzureEndpoint:      "https://testaccount.blob.core.windows.net/",
+			AzureContainerName: "test",
+			AzureSAS:           "sig=test",
+		}
+		addAll(options, invalid)
+		u, err = NewAzureUploader(options)
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}
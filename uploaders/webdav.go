@@ -0,0 +1,243 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package uploaders
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Constants for WebDAV upload 'start' operation options
+const (
+	StorageProviderWebDAV = "webdav"
+
+	WebDAVURL      = "webdav.url"
+	WebDAVUser     = "webdav.user"
+	WebDAVPassword = "webdav.password"
+	WebDAVPath     = "webdav.path"
+	WebDAVMkcol    = "webdav.mkcol"
+)
+
+// methodMkcol is the WebDAV method for creating collections
+const methodMkcol = "MKCOL"
+
+// WebDAVUploader handles upload to a WebDAV server, e.g. Nextcloud or ownCloud
+type WebDAVUploader struct {
+	url      *url.URL // the collection, below which the files are uploaded
+	user     string
+	password string
+	path     string
+	keys     *ObjectKeyNamer // names the resources after the files, if no path is specified
+	mkcol    bool
+
+	http *HTTPUploader // provides the HTTP client with the TLS settings of the generic HTTP uploads
+}
+
+// NewWebDAVUploader constructs new WebDAVUploader from the provided 'start' operation options. The files are
+// uploaded with a PUT request to the resource with the given path, relative to the WebDAV URL, or named after
+// the file, if no path is specified. If enabled, the missing collections on the path are created with MKCOL requests
+// before the upload. The requests are authenticated with basic authentication, if a user is specified.
+// HTTPS connections are secured like the generic HTTP uploads, further restricted by the 'tls.' options, if specified.
+func NewWebDAVUploader(options map[string]string, serverCert string) (Uploader, error) {
+	value := options[WebDAVURL]
+	if value == "" {
+		return nil, fmt.Errorf(missingParameterErrMsg, WebDAVURL)
+	}
+
+	endpoint, err := url.Parse(value)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid value '%s' for parameter '%s'", value, WebDAVURL)
+	}
+	if !strings.HasSuffix(endpoint.Path, "/") {
+		endpoint.Path += "/"
+	}
+
+	uploader := &WebDAVUploader{
+		url:      endpoint,
+		user:     options[WebDAVUser],
+		password: options[WebDAVPassword],
+	}
+
+	if uploader.password != "" && uploader.user == "" {
+		return nil, fmt.Errorf(missingParameterErrMsg, WebDAVUser)
+	}
+
+	if name := options[WebDAVPath]; name != "" {
+		uploader.path = path.Clean(strings.TrimPrefix(name, "/"))
+		if uploader.path == "." || uploader.path == ".." || strings.HasPrefix(uploader.path, "../") {
+			return nil, fmt.Errorf("invalid value '%s' for parameter '%s'", name, WebDAVPath)
+		}
+	}
+
+	if uploader.keys, err = NewObjectKeyNamer(options, nil); err != nil {
+		return nil, err
+	}
+
+	if value, ok := options[WebDAVMkcol]; ok {
+		if uploader.mkcol, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for parameter '%s'", value, WebDAVMkcol)
+		}
+	}
+
+	policy, _, err := parseTLSPolicy(options)
+	if err != nil {
+		return nil, err
+	}
+	uploader.http = &HTTPUploader{url: endpoint.String(), serverCert: serverCert, tlsPolicy: policy}
+
+	return uploader, nil
+}
+
+// name returns the path of the resource, to which the file is uploaded, relative to the WebDAV URL
+func (u *WebDAVUploader) name(file *os.File) string {
+	if u.path != "" {
+		return u.path
+	}
+	if key := u.keys.Key(file.Name()); key != "" {
+		return strings.TrimPrefix(path.Clean("/"+key), "/") // cannot escape the WebDAV URL
+	}
+
+	return filepath.Base(file.Name())
+}
+
+// resourceURL returns the URL of the resource with the given path, relative to the WebDAV URL
+func (u *WebDAVUploader) resourceURL(name string) string {
+	resource := *u.url
+	resource.Path += name
+
+	return resource.String()
+}
+
+// UploadFile performs WebDAV file upload
+func (u *WebDAVUploader) UploadFile(file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
+	return u.UploadFileContext(context.Background(), file, useChecksum, listener)
+}
+
+// UploadFileContext performs WebDAV file upload, which is aborted when the given context is done. The number
+// of bytes of the request body, written so far, is reported to the listener (if not nil).
+func (u *WebDAVUploader) UploadFileContext(ctx context.Context, file *os.File, useChecksum bool, listener func(bytesTransferred int64)) error {
+	name := u.name(file)
+
+	stats, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	client, err := u.http.getHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	if u.mkcol {
+		if err := u.createCollections(ctx, client, name); err != nil {
+			return err
+		}
+	}
+
+	var checksum string
+	if useChecksum {
+		if checksum, err = ComputeMD5(file, true); err != nil {
+			return err
+		}
+	}
+
+	body := io.Reader(file)
+	if listener != nil {
+		body = &progressReader{r: file, listener: listener}
+	}
+
+	req, err := u.newRequest(ctx, http.MethodPut, u.resourceURL(name), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = stats.Size()
+	req.Header.Set("Content-Type", defaultContentType)
+	if checksum != "" {
+		req.Header.Set(ContentMD5, checksum)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newHTTPError(resp)
+	}
+
+	return nil
+}
+
+// createCollections creates the missing collections on the path of the resource with the given name. Collections,
+// which already exist, are rejected by the server with 405 (Method Not Allowed).
+func (u *WebDAVUploader) createCollections(ctx context.Context, client *http.Client, name string) error {
+	elements := strings.Split(name, "/")
+	for i := 1; i < len(elements); i++ {
+		req, err := u.newRequest(ctx, methodMkcol, u.resourceURL(strings.Join(elements[:i], "/")+"/"), nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("failed to create collection '%s': %w", strings.Join(elements[:i], "/"), newHTTPError(resp))
+		}
+	}
+
+	return nil
+}
+
+// newRequest creates a request to the given target URL, authenticated with the configured credentials
+func (u *WebDAVUploader) newRequest(ctx context.Context, method string, target string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.user != "" {
+		req.SetBasicAuth(u.user, u.password)
+	}
+
+	return req, nil
+}
+
+// progressReader reports the number of bytes read so far to the listener
+type progressReader struct {
+	r        io.Reader
+	listener func(bytesTransferred int64)
+	read     int64
+}
+
+func (p *progressReader) Read(data []byte) (int, error) {
+	n, err := p.r.Read(data)
+	if n > 0 {
+		p.read += int64(n)
+		p.listener(p.read)
+	}
+
+	return n, err
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+//go:build unit
+
+package uploaders
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// webDAVStub is a minimal WebDAV server, keeping the uploaded resources and the created collections in memory
+type webDAVStub struct {
+	mutex       sync.Mutex
+	resources   map[string][]byte
+	headers     map[string]http.Header
+	collections map[string]bool
+}
+
+func startWebDAVStub(t *testing.T, secure bool) (*httptest.Server, *webDAVStub) {
+	stub := &webDAVStub{
+		resources:   make(map[string][]byte),
+		headers:     make(map[string]http.Header),
+		collections: map[string]bool{"/dav/": true},
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "kanto" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		stub.mutex.Lock()
+		defer stub.mutex.Unlock()
+
+		parent := r.URL.Path[:strings.LastIndex(strings.TrimSuffix(r.URL.Path, "/"), "/")+1]
+		switch r.Method {
+		case methodMkcol:
+			if stub.collections[r.URL.Path] {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			} else if !stub.collections[parent] {
+				w.WriteHeader(http.StatusConflict)
+			} else {
+				stub.collections[r.URL.Path] = true
+				w.WriteHeader(http.StatusCreated)
+			}
+		case http.MethodPut:
+			if !stub.collections[parent] {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			assertNoError(t, err)
+			stub.resources[r.URL.Path] = body
+			stub.headers[r.URL.Path] = r.Header.Clone()
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	if secure {
+		return httptest.NewTLSServer(handler), stub
+	}
+	return httptest.NewServer(handler), stub
+}
+
+func (s *webDAVStub) resource(path string) ([]byte, http.Header) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.resources[path], s.headers[path]
+}
+
+// webDAVTestUpload writes a test file with the given content and returns a function, which uploads it with
+// an uploader, created from the given options. The file is reopened for each upload, as it is closed by the request.
+func webDAVTestUpload(t *testing.T, content []byte) func(options map[string]string, serverCert string) error {
+	file := writeSplitTestFile(t, content)
+	file.Close()
+	name := file.Name()
+
+	return func(options map[string]string, serverCert string) error {
+		u, err := NewWebDAVUploader(options, serverCert)
+		assertNoError(t, err)
+
+		file, err := os.Open(name)
+		assertNoError(t, err)
+		defer file.Close()
+
+		return u.UploadFile(file, false, nil)
+	}
+}
+
+func TestWebDAVUpload(t *testing.T) {
+	server, stub := startWebDAVStub(t, false)
+	defer server.Close()
+
+	content := bytes.Repeat([]byte("0123456789"), 100000) // 1000000 bytes
+	file := writeSplitTestFile(t, content)
+	defer file.Close()
+
+	u, err := NewWebDAVUploader(map[string]string{
+		WebDAVURL:      server.URL + "/dav",
+		WebDAVUser:     "kanto",
+		WebDAVPassword: "secret",
+	}, "")
+	assertNoError(t, err)
+
+	var progress []int64
+	assertNoError(t, u.UploadFile(file, true, func(bytesTransferred int64) {
+		progress = append(progress, bytesTransferred)
+	}))
+
+	uploaded, header := stub.resource("/dav/test.bin")
+	if !bytes.Equal(content, uploaded) {
+		t.Error("uploaded resource does not match the file content")
+	}
+	sum := md5.Sum(content)
+	assertStringsSame(t, ContentMD5, base64.StdEncoding.EncodeToString(sum[:]), header.Get(ContentMD5))
+
+	if len(progress) < 2 {
+		t.Fatalf("progress not reported during the upload: %v", progress)
+	}
+	for i := 1; i < len(progress); i++ {
+		if progress[i] <= progress[i-1] {
+			t.Fatalf("progress does not advance: %v", progress)
+		}
+	}
+	assertEquals(t, "final progress", int64(len(content)), progress[len(progress)-1])
+}
+
+func TestWebDAVUploadCollections(t *testing.T) {
+	server, stub := startWebDAVStub(t, false)
+	defer server.Close()
+
+	options := map[string]string{
+		WebDAVURL:      server.URL + "/dav/",
+		WebDAVUser:     "kanto",
+		WebDAVPassword: "secret",
+		WebDAVPath:     "logs/device/test file.txt",
+	}
+
+	upload := webDAVTestUpload(t, []byte(testBody))
+
+	assertError(t, upload(options, "")) // the parent collections are missing
+
+	options[WebDAVMkcol] = "true"
+	assertNoError(t, upload(options, ""))
+
+	uploaded, _ := stub.resource("/dav/logs/device/test file.txt")
+	assertStringsSame(t, "uploaded content", testBody, string(uploaded))
+
+	// the existing collections are reused
+	assertNoError(t, upload(options, ""))
+
+	options[WebDAVPassword] = "wrong"
+	err := upload(options, "")
+	assertError(t, err)
+	if !IsAuthorizationError(err) {
+		t.Errorf("authorization error expected, got: %v", err)
+	}
+}
+
+func TestWebDAVUploadObjectKeyTemplate(t *testing.T) {
+	server, stub := startWebDAVStub(t, false)
+	defer server.Close()
+
+	options := map[string]string{
+		WebDAVURL:         server.URL + "/dav/",
+		WebDAVUser:        "kanto",
+		WebDAVPassword:    "secret",
+		WebDAVMkcol:       "true",
+		ObjectKeyTemplate: "{deviceId}/../../logs/{basename}.{ext}",
+		ObjectKeyDeviceID: "device-123",
+	}
+
+	upload := webDAVTestUpload(t, []byte(testBody))
+	assertNoError(t, upload(options, ""))
+
+	uploaded, _ := stub.resource("/dav/logs/test.bin") // cannot escape the WebDAV URL
+	assertStringsSame(t, "uploaded content", testBody, string(uploaded))
+
+	options[WebDAVPath] = "explicit.txt"
+	assertNoError(t, upload(options, ""))
+
+	uploaded, _ = stub.resource("/dav/explicit.txt")
+	assertStringsSame(t, "uploaded content", testBody, string(uploaded))
+}
+
+func TestWebDAVUploadHTTPS(t *testing.T) {
+	server, stub := startWebDAVStub(t, true)
+	defer server.Close()
+
+	upload := webDAVTestUpload(t, []byte(testBody))
+	options := map[string]string{
+		WebDAVURL:      server.URL + "/dav/",
+		WebDAVUser:     "kanto",
+		WebDAVPassword: "secret",
+	}
+
+	assertError(t, upload(options, "")) // the server certificate is not trusted
+	assertNoError(t, upload(options, writeServerCert(t, server)))
+
+	uploaded, _ := stub.resource("/dav/test.bin")
+	assertStringsSame(t, "uploaded content", testBody, string(uploaded))
+}
+
+func TestNewWebDAVUploaderErrors(t *testing.T) {
+	invalid := []map[string]string{
+		{},
+		{WebDAVURL: "ftp://localhost/dav"},
+		{WebDAVURL: "localhost/dav"},
+		{WebDAVURL: "http://localhost/dav", WebDAVPassword: "secret"},
+		{WebDAVURL: "http://localhost/dav", WebDAVPath: "../test.txt"},
+		{WebDAVURL: "http://localhost/dav", WebDAVMkcol: "yes"},
+		{WebDAVURL: "http://localhost/dav", TLSCipherSuitesProp: "invalid"},
+	}
+	for _, options := range invalid {
+		u, err := NewWebDAVUploader(options, "")
+		assertNil(t, u)
+		assertError(t, err)
+	}
+}